@@ -0,0 +1,159 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// maxAdminBodySize bounds the size of an admin request body
+	maxAdminBodySize = 1 << 10
+	// maxAdminRequestSkew bounds how far an admin request's timestamp may be from
+	// the aggregator's clock, so that a captured request cannot be replayed later
+	maxAdminRequestSkew = time.Minute
+)
+
+// QuorumThresholdUpdate is the body of POST /admin/quorum-threshold, signed by one
+// of the admin addresses like a task response
+type QuorumThresholdUpdate struct {
+	// Threshold is the new percentage of stake that must respond, from 1 to 100
+	Threshold uint32 `json:"threshold"`
+	// Timestamp is when the update was sent, in unix seconds
+	Timestamp int64 `json:"timestamp"`
+	// Nonce is unique to the update, so it can't be replayed while its timestamp
+	// is still accepted
+	Nonce string `json:"nonce"`
+}
+
+// QuorumThresholdStatus is the response of GET and POST /admin/quorum-threshold
+type QuorumThresholdStatus struct {
+	Threshold uint32 `json:"threshold"`
+}
+
+// currentQuorumThreshold returns quorum_threshold as last set at runtime
+func (a *Aggregator) currentQuorumThreshold() uint32 {
+	a.quorumThresholdMux.RLock()
+	defer a.quorumThresholdMux.RUnlock()
+	return uint32(a.quorumThreshold)
+}
+
+// authenticateAdmin verifies that body was signed by one of the configured admin
+// addresses and returns that address
+func (a *Aggregator) authenticateAdmin(body []byte, signature string) (common.Address, error) {
+	signer, err := RecoverRequestSigner(body, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	for _, admin := range a.config.AdminAddresses {
+		if strings.EqualFold(admin, signer.Hex()) {
+			return signer, nil
+		}
+	}
+	return common.Address{}, errors.New("signer is not an admin")
+}
+
+// updateQuorumThreshold authenticates a QuorumThresholdUpdate signed by an admin
+// and applies its threshold to the tasks finalized from then on. Updates are
+// refused in dual-quorum mode, where quorum_threshold is not used.
+func (a *Aggregator) updateQuorumThreshold(body []byte, signature, remoteAddr string) (uint32, error) {
+	caller, err := a.authenticateAdmin(body, signature)
+	if err != nil {
+		a.logger.Warn("Rejecting unauthenticated quorum threshold update", "remoteAddr", remoteAddr, "error", err)
+		return 0, &responseRejection{status: http.StatusUnauthorized, message: "Unauthorized"}
+	}
+
+	var update QuorumThresholdUpdate
+	if err := json.Unmarshal(body, &update); err != nil {
+		return 0, &responseRejection{status: http.StatusBadRequest, message: "Invalid JSON"}
+	}
+	if skew := a.now().Sub(time.Unix(update.Timestamp, 0)).Abs(); skew > maxAdminRequestSkew {
+		a.logger.Warn("Rejecting quorum threshold update with a skewed timestamp", "caller", caller.Hex(), "skew", skew)
+		return 0, &responseRejection{status: http.StatusBadRequest, message: "Request timestamp too far from server time"}
+	}
+	if update.Nonce == "" {
+		return 0, &responseRejection{status: http.StatusBadRequest, message: "Missing request nonce"}
+	}
+	if update.Threshold < 1 || update.Threshold > 100 {
+		return 0, &responseRejection{status: http.StatusBadRequest, message: "Threshold must be between 1 and 100"}
+	}
+	if a.hasDualQuorum() {
+		return 0, &responseRejection{status: http.StatusConflict, message: "Quorum threshold is unused with quorum_count_threshold and quorum_stake_threshold set"}
+	}
+
+	a.quorumThresholdMux.Lock()
+	if !a.useAdminNonce(update.Nonce) {
+		a.quorumThresholdMux.Unlock()
+		a.logger.Warn("Rejecting replayed quorum threshold update", "caller", caller.Hex(), "remoteAddr", remoteAddr)
+		return 0, &responseRejection{status: http.StatusBadRequest, message: "Request nonce already used"}
+	}
+	previous := uint32(a.quorumThreshold)
+	a.quorumThreshold = types.ThresholdPercentage(update.Threshold)
+	a.quorumThresholdMux.Unlock()
+
+	a.logger.Info("Quorum threshold updated",
+		"caller", caller.Hex(),
+		"remoteAddr", remoteAddr,
+		"previous", previous,
+		"threshold", update.Threshold,
+	)
+	return update.Threshold, nil
+}
+
+// useAdminNonce records an admin request nonce, and reports false if it was
+// already used. Nonces are forgotten once the requests carrying them would fail
+// the timestamp check. The caller holds quorumThresholdMux.
+func (a *Aggregator) useAdminNonce(nonce string) bool {
+	now := a.now()
+	for seen, usedAt := range a.adminNonces {
+		if now.Sub(usedAt) > 2*maxAdminRequestSkew {
+			delete(a.adminNonces, seen)
+		}
+	}
+	if _, used := a.adminNonces[nonce]; used {
+		return false
+	}
+	if a.adminNonces == nil {
+		a.adminNonces = make(map[string]time.Time)
+	}
+	a.adminNonces[nonce] = now
+	return true
+}
+
+// handleQuorumThreshold serves the quorum threshold on GET and updates it on a
+// POST signed by an admin
+func (a *Aggregator) handleQuorumThreshold(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(QuorumThresholdStatus{Threshold: a.currentQuorumThreshold()})
+	case http.MethodPost:
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAdminBodySize))
+		if err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		threshold, err := a.updateQuorumThreshold(body, r.Header.Get(OperatorSignatureHeader), r.RemoteAddr)
+		if err != nil {
+			status := http.StatusInternalServerError
+			var rejection *responseRejection
+			if errors.As(err, &rejection) {
+				status = rejection.status
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(QuorumThresholdStatus{Threshold: threshold})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,195 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+)
+
+// postQuorumThreshold posts a quorum threshold update timestamped at sentAt with a
+// fresh nonce, signed with key
+func postQuorumThreshold(t *testing.T, a *Aggregator, key *ecdsa.PrivateKey, threshold uint32, sentAt time.Time) *httptest.ResponseRecorder {
+	t.Helper()
+	return postQuorumThresholdUpdate(t, a, key, QuorumThresholdUpdate{
+		Threshold: threshold,
+		Timestamp: sentAt.Unix(),
+		Nonce:     fmt.Sprintf("%s-%d", t.Name(), sentAt.UnixNano()+int64(threshold)),
+	})
+}
+
+// postQuorumThresholdUpdate posts update signed with key
+func postQuorumThresholdUpdate(t *testing.T, a *Aggregator, key *ecdsa.PrivateKey, update QuorumThresholdUpdate) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(update)
+	if err != nil {
+		t.Fatalf("marshal update: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/admin/quorum-threshold", bytes.NewReader(body))
+	signature, err := operator.SignRequestBody(key, body)
+	if err != nil {
+		t.Fatalf("SignRequestBody: %v", err)
+	}
+	req.Header.Set(OperatorSignatureHeader, signature)
+
+	recorder := httptest.NewRecorder()
+	a.handleQuorumThreshold(recorder, req)
+	return recorder
+}
+
+// getQuorumThreshold fetches GET /admin/quorum-threshold
+func getQuorumThreshold(t *testing.T, a *Aggregator) uint32 {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	a.handleQuorumThreshold(recorder, httptest.NewRequest(http.MethodGet, "/admin/quorum-threshold", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /admin/quorum-threshold status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var status QuorumThresholdStatus
+	if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return status.Threshold
+}
+
+func TestAdminQuorumThresholdAppliesToLaterFinalization(t *testing.T) {
+	adminKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	state.addOperator(3, 100)
+	a := newTestAggregator(t, Config{
+		QuorumThreshold: 80,
+		AdminAddresses:  []string{crypto.PubkeyToAddress(adminKey.PublicKey).Hex()},
+	}, state)
+	responder := a.avsWriter.(*fakeTaskResponder)
+	ctx := context.Background()
+
+	if got := getQuorumThreshold(t, a); got != 80 {
+		t.Fatalf("threshold = %d, want the configured 80", got)
+	}
+
+	// Two of three operators fall short of 80%
+	for _, operatorId := range []types.OperatorId{op1, op2} {
+		body := marshalTestResponse(t, newSignedTestResponse(t, state, 1, operatorId, winnerX, 100))
+		if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+			t.Fatalf("submitting the response of operator %s = %d, want 200", operatorId.Hex(), got)
+		}
+	}
+	a.checkAndProcessCompletedTasks(ctx)
+	if responder.attempts != 0 {
+		t.Fatal("expected the task to stay below the configured threshold")
+	}
+
+	now := time.Now()
+	rejected := []struct {
+		name      string
+		key       *ecdsa.PrivateKey
+		threshold uint32
+		sentAt    time.Time
+		want      int
+	}{
+		{"operator", state.ecdsaKey(op1), 60, now, http.StatusUnauthorized},
+		{"zero threshold", adminKey, 0, now, http.StatusBadRequest},
+		{"threshold over 100", adminKey, 101, now, http.StatusBadRequest},
+		{"replayed", adminKey, 60, now.Add(-2 * maxAdminRequestSkew), http.StatusBadRequest},
+	}
+	for _, tc := range rejected {
+		if got := postQuorumThreshold(t, a, tc.key, tc.threshold, tc.sentAt).Code; got != tc.want {
+			t.Fatalf("%s update status = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+	if got := getQuorumThreshold(t, a); got != 80 {
+		t.Fatalf("threshold = %d after rejected updates, want 80", got)
+	}
+
+	if recorder := postQuorumThreshold(t, a, adminKey, 60, now); recorder.Code != http.StatusOK {
+		t.Fatalf("admin update status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if got := getQuorumThreshold(t, a); got != 60 {
+		t.Fatalf("threshold = %d, want the updated 60", got)
+	}
+
+	a.checkAndProcessCompletedTasks(ctx)
+	if responder.attempts != 1 || !a.finalizedTasks[1] {
+		t.Fatal("expected the task to be finalized at the updated threshold")
+	}
+}
+
+func TestAdminQuorumThresholdRefusedWithoutAdmins(t *testing.T) {
+	adminKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, newFakeOperatorState())
+
+	if got := postQuorumThreshold(t, a, adminKey, 50, time.Now()).Code; got != http.StatusUnauthorized {
+		t.Fatalf("update status = %d, want 401 with no admin addresses", got)
+	}
+	if got := getQuorumThreshold(t, a); got != 67 {
+		t.Fatalf("threshold = %d, want 67", got)
+	}
+}
+
+func TestAdminQuorumThresholdRejectsReplayedNonce(t *testing.T) {
+	adminKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	a := newTestAggregator(t, Config{
+		QuorumThreshold: 67,
+		AdminAddresses:  []string{crypto.PubkeyToAddress(adminKey.PublicKey).Hex()},
+	}, newFakeOperatorState())
+
+	lower := QuorumThresholdUpdate{Threshold: 50, Timestamp: time.Now().Unix(), Nonce: "lower"}
+	if got := postQuorumThresholdUpdate(t, a, adminKey, lower).Code; got != http.StatusOK {
+		t.Fatalf("update status = %d, want 200", got)
+	}
+	raise := QuorumThresholdUpdate{Threshold: 80, Timestamp: time.Now().Unix(), Nonce: "raise"}
+	if got := postQuorumThresholdUpdate(t, a, adminKey, raise).Code; got != http.StatusOK {
+		t.Fatalf("update status = %d, want 200", got)
+	}
+
+	// The captured first update is replayed within the timestamp skew
+	if got := postQuorumThresholdUpdate(t, a, adminKey, lower).Code; got != http.StatusBadRequest {
+		t.Fatalf("replayed update status = %d, want 400", got)
+	}
+	unsigned := QuorumThresholdUpdate{Threshold: 50, Timestamp: time.Now().Unix()}
+	if got := postQuorumThresholdUpdate(t, a, adminKey, unsigned).Code; got != http.StatusBadRequest {
+		t.Fatalf("update without a nonce status = %d, want 400", got)
+	}
+	if got := getQuorumThreshold(t, a); got != 80 {
+		t.Fatalf("threshold = %d, want 80 after the replay was refused", got)
+	}
+}
+
+func TestAdminQuorumThresholdRefusedInDualQuorumMode(t *testing.T) {
+	adminKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	a := newTestAggregator(t, Config{
+		QuorumCountThreshold: 50,
+		QuorumStakeThreshold: 67,
+		AdminAddresses:       []string{crypto.PubkeyToAddress(adminKey.PublicKey).Hex()},
+	}, newFakeOperatorState())
+
+	if got := postQuorumThreshold(t, a, adminKey, 50, time.Now()).Code; got != http.StatusConflict {
+		t.Fatalf("update status = %d, want 409 when the dual thresholds apply", got)
+	}
+}
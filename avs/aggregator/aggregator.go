@@ -1,98 +1,280 @@
 package aggregator
 
 import (
+	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/Layr-Labs/eigensdk-go/metrics"
 	"github.com/Layr-Labs/eigensdk-go/nodeapi"
 	"github.com/Layr-Labs/eigensdk-go/types"
 	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/lvr-auction-hook/avs/pkg/avsregistry"
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
 )
 
 const (
 	// SemVer is the semantic version of the aggregator
 	SemVer = "0.0.1"
+
+	// maxResponseBodySize bounds the size of a submitted task response
+	maxResponseBodySize = 1 << 20
 )
 
 type Aggregator struct {
-	config      Config
-	logger      logging.Logger
-	ethClient   eth.Client
-	metricsReg  *prometheus.Registry
-	metrics     metrics.Metrics
-	nodeApi     *nodeapi.NodeApi
+	config     Config
+	logger     logging.Logger
+	ethClient  eth.Client
+	metricsReg *prometheus.Registry
+	metrics    metrics.Metrics
+	nodeApi    *nodeapi.NodeApi
 
-	avsWriter avsregistry.AvsRegistryChainWriter
-	avsReader avsregistry.AvsRegistryChainReader
+	avsWriter   taskResponder
+	avsReader   operatorStateReader
+	blockReader blockNumberReader
 
 	// Aggregator specific fields
 	taskResponses    map[uint32][]SignedAuctionTaskResponse
 	taskResponsesMux sync.RWMutex
 	quorumThreshold  types.ThresholdPercentage
+	// quorumThresholdMux guards quorumThreshold, which admins can update at runtime,
+	// and adminNonces
+	quorumThresholdMux sync.RWMutex
+	// adminNonces holds when each admin request nonce was seen, for as long as a
+	// request carrying it could pass the timestamp check
+	adminNonces map[string]time.Time
+	// finalizedTasks holds the indexes of tasks whose consensus has been processed
+	finalizedTasks map[uint32]bool
+	// failedTasks holds the final submission error of tasks whose consensus could
+	// not be submitted on chain
+	failedTasks map[uint32]string
+	// cancelledTasks holds the reason of each task cancelled before consensus
+	cancelledTasks map[uint32]string
+	// taskFirstSeen is when each unfinalized task received its first response
+	taskFirstSeen map[uint32]time.Time
+	// quorumReachedAt is when each unfinalized task collecting responses for
+	// FinalizeDelay first met quorum
+	quorumReachedAt map[uint32]time.Time
+	// consensusResults holds the consensus submitted for each finalized task
+	consensusResults map[uint32]TaskConsensus
+	// finalizedAt is when each task was finalized, so it can be pruned once
+	// TaskRetention has passed
+	finalizedAt map[uint32]time.Time
+	// prunedBelow is one past the highest index of a pruned task. Tasks below it
+	// that are neither tracked nor responded to may have been pruned.
+	prunedBelow uint32
+
+	// tasks holds the metadata of tasks created on chain, keyed by task index
+	tasks    map[uint32]AuctionTask
+	tasksMux sync.RWMutex
+	// taskSubscribers receive new tasks for the operators streaming them over /rpc
+	taskSubscribers    map[chan StreamedTask]struct{}
+	taskSubscribersMux sync.Mutex
+
+	// responseStore persists taskResponses so they survive a restart
+	responseStore ResponseStore
+
+	// accuracy tracks how often each operator agreed with finalized consensus
+	accuracy    map[types.OperatorId]OperatorAccuracy
+	accuracyMux sync.RWMutex
+	// heartbeats holds when each operator last reported liveness on POST /heartbeat
+	heartbeats    map[types.OperatorId]time.Time
+	heartbeatsMux sync.RWMutex
+	// stakeSnapshots holds the operator stakes of unfinalized tasks at their
+	// creation blocks, fetched on first use. The snapshots must not be modified.
+	stakeSnapshots    map[uint32]*stakeSnapshot
+	stakeSnapshotsMux sync.Mutex
+	// queriedStakes holds the operator sets status queries read for tasks
+	// without a stake snapshot, reused for queryStakeTTL
+	queriedStakes    map[uint32]*queriedStakes
+	queriedStakesMux sync.Mutex
+	// mismatchHooks are notified of operators whose response conflicted with consensus
+	mismatchHooks []ConsensusMismatchHook
+	// notifiers are told of every finalized or failed consensus, and webhooks are
+	// the configured notifiers delivering in the background while running
+	notifiers []Notifier
+	webhooks  []*WebhookNotifier
+
+	// draining is set during shutdown, when only responses for in-progress tasks are accepted
+	draining atomic.Bool
+	// stopped is set once shutdown accepts no more responses
+	stopped atomic.Bool
+	// serving is set while the HTTP server is listening
+	serving atomic.Bool
+	// ethConn probes the eth client's connection, reconnecting it when lost
+	ethConn *ethConnection
+	// headers follows the chain head to drop tasks whose creation block is
+	// reorged out (nil to not follow it); lastHead is the last head seen
+	headers  chainHeadReader
+	lastHead *gethtypes.Header
+	headMux  sync.Mutex
+	// taskEvents is where the service manager's NewTaskCreated events are read
+	// from (nil to not ingest tasks); taskScanBlock is the last block scanned
+	taskEvents    taskEventSource
+	taskScanBlock uint64
+
+	// ipLimiter and operatorLimiter throttle task responses per client IP and per
+	// authenticated operator; nil limiters are unlimited
+	ipLimiter       *rateLimiter
+	operatorLimiter *rateLimiter
+
+	// lvrMetrics are the consensus metrics registered on metricsReg
+	lvrMetrics        *lvrMetrics
+	submissionBackoff time.Duration
+	// auctionStats are the auction metrics served on GET /metrics/auctions
+	auctionStats auctionStats
+
+	now func() time.Time
 }
 
 type Config struct {
-	EcdsaPrivateKeyStorePath      string `json:"ecdsa_private_key_store_path"`
-	EthRpcUrl                     string `json:"eth_rpc_url"`
-	EthWsUrl                      string `json:"eth_ws_url"`
-	RegistryCoordinatorAddress    string `json:"registry_coordinator_address"`
-	OperatorStateRetrieverAddress string `json:"operator_state_retriever_address"`
-	EigenMetricsIpPortAddress     string `json:"eigen_metrics_ip_port_address"`
-	EnableMetrics                 bool   `json:"enable_metrics"`
-	NodeApiIpPortAddress          string `json:"node_api_ip_port_address"`
-	EnableNodeApi                 bool   `json:"enable_node_api"`
-	AggregatorServerIpPortAddr    string `json:"aggregator_server_ip_port_address"`
-	QuorumThreshold               uint32 `json:"quorum_threshold"`
+	EcdsaPrivateKeyStorePath   string `json:"ecdsa_private_key_store_path"`
+	EthRpcUrl                  string `json:"eth_rpc_url"`
+	EthWsUrl                   string `json:"eth_ws_url"`
+	EigenMetricsIpPortAddress  string `json:"eigen_metrics_ip_port_address"`
+	EnableMetrics              bool   `json:"enable_metrics"`
+	NodeApiIpPortAddress       string `json:"node_api_ip_port_address"`
+	EnableNodeApi              bool   `json:"enable_node_api"`
+	AggregatorServerIpPortAddr string `json:"aggregator_server_ip_port_address"`
+	QuorumThreshold            uint32 `json:"quorum_threshold"`
+	// QuorumNumbers are the quorums used for tasks whose metadata is unknown (default [0])
+	QuorumNumbers []uint32 `json:"quorum_numbers"`
+	// QuorumCountThreshold and QuorumStakeThreshold are the percentages of operators
+	// by count and by stake that must respond before a task is finalized. When
+	// either is set, it replaces quorum_threshold, and both must be met when both are.
+	QuorumCountThreshold uint32 `json:"quorum_count_threshold"`
+	QuorumStakeThreshold uint32 `json:"quorum_stake_threshold"`
+	// MinDistinctOperators is how many distinct operators must respond before a
+	// task is finalized, whatever stake fewer of them hold. There is no minimum when zero.
+	MinDistinctOperators uint32 `json:"min_distinct_operators"`
+	// ResponseStoreEncryptionKeyPath points to a hex encoded AES-256 key used to
+	// encrypt persisted task responses. Responses are stored in plaintext when empty.
+	ResponseStoreEncryptionKeyPath string `json:"response_store_encryption_key_path"`
+	// ResponseStoreMode selects where task responses are kept until their task is
+	// finalized: "memory" (default) or "file", which persists them under ResponseStorePath
+	ResponseStoreMode string `json:"response_store_mode"`
+	ResponseStorePath string `json:"response_store_path"`
+	// ConsensusTieBreak selects how responses backed by equal stake and operator
+	// counts are resolved: "highest_bid" (default) prefers the highest winning bid,
+	// then the lowest operator id, and "accuracy" first prefers the responses backed
+	// by operators with the higher cumulative historical accuracy
+	ConsensusTieBreak string `json:"consensus_tie_break"`
+	// ConsensusStrategy selects how a task's result is decided from its responses:
+	// "plurality" (default), "stake_majority" or "median_bid"
+	ConsensusStrategy string `json:"consensus_strategy"`
+	// ConfidenceWeighting scales each operator's stake by the confidence of its
+	// response before the consensus strategy decides, so results built on stale or
+	// disagreeing prices count for less. Quorum is still met by unscaled stake.
+	ConfidenceWeighting bool `json:"confidence_weighting"`
+	// DrainTimeout bounds how long, in seconds, the aggregator keeps finalizing
+	// in-progress tasks after a shutdown signal. Shutdown is immediate when zero.
+	DrainTimeout uint32 `json:"drain_timeout_seconds"`
+	// ContractAddresses names the registryCoordinator, operatorStateRetriever and
+	// serviceManager contracts, the last being where consensus is submitted
+	ContractAddresses lvrtypes.ContractAddresses `json:"contract_addresses"`
+	// SubmissionRetries is how many times a failed consensus submission is retried,
+	// with exponential backoff, before the task is marked failed (default 3)
+	SubmissionRetries uint32 `json:"submission_retries"`
+	// TaskTTL is how long, in seconds, a task may wait for consensus after its first
+	// response before it is evicted and marked failed. Tasks never expire when zero.
+	TaskTTL uint32 `json:"task_ttl_seconds"`
+	// FinalizeDelay is how long, in seconds, a task keeps collecting responses once
+	// it first meets quorum before it is finalized with all of them. Tasks are
+	// finalized as soon as they meet quorum when zero.
+	FinalizeDelay uint32 `json:"finalize_delay_seconds"`
+	// TaskRetention is how long, in seconds, a finished task's state is kept for
+	// queries and to reject late responses before it is pruned (default 3600)
+	TaskRetention uint32 `json:"task_retention_seconds"`
+	// MEVSplit is how finalized winning bids are distributed, defaulting to the
+	// LVRAuctionHook contract's split when unset
+	MEVSplit MEVSplit `json:"mev_split"`
+	// RateLimit bounds how fast task responses are accepted from each client IP
+	// and each operator
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	// Webhooks are POSTed every finalized or failed consensus
+	Webhooks []WebhookConfig `json:"webhooks"`
+	// ResponseWindowBlocks is how many blocks after its creation a task accepts
+	// responses, after which they are rejected as replays. There is no window when zero.
+	ResponseWindowBlocks uint32 `json:"response_window_blocks"`
+	// HeartbeatTimeout is how long, in seconds, an operator is considered online
+	// after its last heartbeat (default 90)
+	HeartbeatTimeout uint32 `json:"heartbeat_timeout_seconds"`
+	// HTTPServer sets the HTTP server's timeouts and keep-alives
+	HTTPServer HTTPServerConfig `json:"http_server"`
+	// WinningBid sets the settlement token winning bids are denominated in and the
+	// range they must fall within
+	WinningBid WinningBidConfig `json:"winning_bid"`
+	// BidTolerance lets responses agree on a result despite small differences in
+	// their winning bids
+	BidTolerance BidTolerance `json:"bid_tolerance"`
+	// AdminAddresses may update the quorum threshold at runtime by signing
+	// POST /admin/quorum-threshold. Updates are refused when empty.
+	AdminAddresses []string `json:"admin_addresses"`
 }
 
 type AuctionTask struct {
-	PoolId                      common.Hash    `json:"poolId"`
-	BlockNumber                 uint32         `json:"blockNumber"`
-	TaskCreatedBlock            uint32         `json:"taskCreatedBlock"`
-	QuorumNumbers               types.QuorumNums `json:"quorumNumbers"`
-	QuorumThresholdPercentage   types.ThresholdPercentage `json:"quorumThresholdPercentage"`
+	PoolId           common.Hash `json:"poolId"`
+	BlockNumber      uint32      `json:"blockNumber"`
+	TaskCreatedBlock uint32      `json:"taskCreatedBlock"`
+	// TaskCreatedBlockHash is the hash of TaskCreatedBlock the task was seen in,
+	// taken from the chain when the task is first checked if unknown
+	TaskCreatedBlockHash      common.Hash               `json:"taskCreatedBlockHash,omitempty"`
+	QuorumNumbers             types.QuorumNums          `json:"quorumNumbers"`
+	QuorumThresholdPercentage types.ThresholdPercentage `json:"quorumThresholdPercentage"`
+	// Deadline is the service manager's response deadline in unix seconds, judged
+	// against the chain head's timestamp as the contract does (0 for no deadline)
+	Deadline uint64 `json:"deadline,omitempty"`
+	// ReservePrice is the smallest winning bid, in wei of the settlement token, the
+	// auction settles at; a consensus below it is not submitted (nil for no reserve)
+	ReservePrice *big.Int `json:"reservePrice,omitempty"`
 }
 
+// AuctionTaskResponse is an operator's result for a task. WinningBid is in wei of
+// the settlement token set by Config.WinningBid.
 type AuctionTaskResponse struct {
 	ReferenceTaskIndex uint32         `json:"referenceTaskIndex"`
 	Winner             common.Address `json:"winner"`
 	WinningBid         *big.Int       `json:"winningBid"`
 	TotalBids          uint32         `json:"totalBids"`
+	// Confidence is the operator's trust in the prices behind the response, in
+	// (0, 1]. It is not signed and zero means unreported.
+	Confidence float64 `json:"confidence,omitempty"`
 }
 
 type SignedAuctionTaskResponse struct {
 	AuctionTaskResponse
-	BlsSignature types.Signature `json:"blsSignature"`
+	BlsSignature *bls.Signature   `json:"blsSignature"`
 	OperatorId   types.OperatorId `json:"operatorId"`
+	// IdempotencyKey is the same on every re-send of an operator's response, so
+	// a retried submission is recognized and ignored
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
 type TaskResponseInfo struct {
 	TaskResponse *AuctionTaskResponse
-	BlsSignature types.Signature
+	BlsSignature *bls.Signature
 	OperatorId   types.OperatorId
 }
 
 func NewAggregator(config Config, logger logging.Logger) (*Aggregator, error) {
-	var logLevel logging.LogLevel
-	if config.EnableMetrics {
-		logLevel = logging.Development
-	} else {
-		logLevel = logging.Production
-	}
-
 	logger = logger.With("component", "aggregator")
 
 	ethClient, err := eth.NewClient(config.EthRpcUrl)
@@ -110,8 +292,8 @@ func NewAggregator(config Config, logger logging.Logger) (*Aggregator, error) {
 
 	// Create AVS clients
 	avsReader, err := avsregistry.NewAvsRegistryChainReader(
-		common.HexToAddress(config.RegistryCoordinatorAddress),
-		common.HexToAddress(config.OperatorStateRetrieverAddress),
+		config.ContractAddresses.Address(lvrtypes.ContractRegistryCoordinator),
+		config.ContractAddresses.Address(lvrtypes.ContractOperatorStateRetriever),
 		ethClient,
 		logger,
 	)
@@ -120,8 +302,8 @@ func NewAggregator(config Config, logger logging.Logger) (*Aggregator, error) {
 	}
 
 	avsWriter, err := avsregistry.NewAvsRegistryChainWriter(
-		common.HexToAddress(config.RegistryCoordinatorAddress),
-		common.HexToAddress(config.OperatorStateRetrieverAddress),
+		config.ContractAddresses.Address(lvrtypes.ContractRegistryCoordinator),
+		config.ContractAddresses.Address(lvrtypes.ContractOperatorStateRetriever),
 		ethClient,
 		operatorEcdsaPrivateKey,
 		logger,
@@ -130,6 +312,18 @@ func NewAggregator(config Config, logger logging.Logger) (*Aggregator, error) {
 		return nil, fmt.Errorf("failed to create avs registry chain writer: %w", err)
 	}
 
+	// Chain heads and new tasks are followed over the websocket endpoint when
+	// configured
+	var chainEvents eth.Client = ethClient
+	if config.EthWsUrl != "" {
+		wsClient, err := eth.NewClient(config.EthWsUrl)
+		if err != nil {
+			logger.Warn("Failed to connect to eth websocket endpoint, polling chain heads over RPC", "error", err)
+		} else {
+			chainEvents = wsClient
+		}
+	}
+
 	// Create metrics registry
 	var metricsReg *prometheus.Registry
 	var eigenMetrics metrics.Metrics
@@ -141,6 +335,10 @@ func NewAggregator(config Config, logger logging.Logger) (*Aggregator, error) {
 		metricsReg = prometheus.NewRegistry()
 		eigenMetrics = metrics.NewNoopMetrics()
 	}
+	lvrMetrics := newLvrMetrics()
+	if err := lvrMetrics.register(metricsReg); err != nil {
+		return nil, fmt.Errorf("failed to register metrics: %w", err)
+	}
 
 	// Create node API
 	var nodeApi *nodeapi.NodeApi
@@ -149,17 +347,55 @@ func NewAggregator(config Config, logger logging.Logger) (*Aggregator, error) {
 		go nodeApi.Start()
 	}
 
+	if len(config.QuorumNumbers) == 0 {
+		config.QuorumNumbers = []uint32{0}
+	}
+
+	responseStore, err := NewResponseStore(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create response store: %w", err)
+	}
+
 	aggregator := &Aggregator{
-		config:           config,
-		logger:           logger,
-		ethClient:        ethClient,
-		metricsReg:       metricsReg,
-		metrics:          eigenMetrics,
-		nodeApi:          nodeApi,
-		avsWriter:        *avsWriter,
-		avsReader:        *avsReader,
-		taskResponses:    make(map[uint32][]SignedAuctionTaskResponse),
-		quorumThreshold:  types.ThresholdPercentage(config.QuorumThreshold),
+		config:            config,
+		logger:            logger,
+		ethClient:         ethClient,
+		metricsReg:        metricsReg,
+		metrics:           eigenMetrics,
+		nodeApi:           nodeApi,
+		avsWriter:         avsWriter,
+		avsReader:         avsReader,
+		blockReader:       ethClient,
+		taskResponses:     make(map[uint32][]SignedAuctionTaskResponse),
+		tasks:             make(map[uint32]AuctionTask),
+		taskSubscribers:   make(map[chan StreamedTask]struct{}),
+		finalizedTasks:    make(map[uint32]bool),
+		failedTasks:       make(map[uint32]string),
+		cancelledTasks:    make(map[uint32]string),
+		taskFirstSeen:     make(map[uint32]time.Time),
+		quorumReachedAt:   make(map[uint32]time.Time),
+		consensusResults:  make(map[uint32]TaskConsensus),
+		finalizedAt:       make(map[uint32]time.Time),
+		accuracy:          make(map[types.OperatorId]OperatorAccuracy),
+		heartbeats:        make(map[types.OperatorId]time.Time),
+		stakeSnapshots:    make(map[uint32]*stakeSnapshot),
+		queriedStakes:     make(map[uint32]*queriedStakes),
+		quorumThreshold:   types.ThresholdPercentage(config.QuorumThreshold),
+		responseStore:     responseStore,
+		lvrMetrics:        lvrMetrics,
+		submissionBackoff: defaultSubmissionBackoff,
+		now:               time.Now,
+		ethConn:           newEthConnection(ethClient, logger, time.Now),
+		headers:           chainEvents,
+		taskEvents:        chainEvents,
+		ipLimiter:         newRateLimiter(config.RateLimit.PerIPRate, config.RateLimit.PerIPBurst, defaultPerIPRate, defaultPerIPBurst),
+		operatorLimiter:   newRateLimiter(config.RateLimit.PerOperatorRate, config.RateLimit.PerOperatorBurst, defaultPerOperatorRate, defaultPerOperatorBurst),
+	}
+
+	for _, webhook := range config.Webhooks {
+		notifier := NewWebhookNotifier(webhook, logger)
+		aggregator.webhooks = append(aggregator.webhooks, notifier)
+		aggregator.AddNotifier(notifier)
 	}
 
 	return aggregator, nil
@@ -168,31 +404,132 @@ func NewAggregator(config Config, logger logging.Logger) (*Aggregator, error) {
 func (a *Aggregator) Start(ctx context.Context) error {
 	a.logger.Info("Starting aggregator")
 
+	if err := a.rehydrateResponses(); err != nil {
+		return err
+	}
+	if err := a.rehydrateAccuracy(); err != nil {
+		return err
+	}
+
+	// The HTTP server outlives ctx so in-progress tasks can still receive
+	// responses while draining
+	serverCtx, stopServer := context.WithCancel(context.Background())
+	defer stopServer()
+
 	// Start HTTP server for receiving task responses
-	go a.startHTTPServer(ctx)
+	go a.startHTTPServer(serverCtx)
+	go a.ethConn.run(serverCtx)
+	go a.watchReorgs(serverCtx)
+	go a.watchTasks(serverCtx)
+	for _, webhook := range a.webhooks {
+		go webhook.Run(serverCtx)
+	}
 
-	// Start task processing
-	go a.processTaskResponses(ctx)
+	// Start task processing, which shuts down in order once ctx is done
+	processed := make(chan struct{})
+	go func() {
+		a.processTaskResponses(ctx)
+		close(processed)
+	}()
 
 	// Keep the aggregator running
 	<-ctx.Done()
+	<-processed
 	return nil
 }
 
-func (a *Aggregator) startHTTPServer(ctx context.Context) {
+// rehydrateResponses restores the responses of unfinalized tasks from the response store
+func (a *Aggregator) rehydrateResponses() error {
+	stored, err := a.responseStore.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load stored task responses: %w", err)
+	}
+
+	a.taskResponsesMux.Lock()
+	defer a.taskResponsesMux.Unlock()
+
+	count := 0
+	for taskIndex, responses := range stored {
+		a.taskResponses[taskIndex] = append(responses, a.taskResponses[taskIndex]...)
+		count += len(responses)
+		// Restored tasks get a full TTL from when the aggregator restarted
+		if _, exists := a.taskFirstSeen[taskIndex]; !exists {
+			a.taskFirstSeen[taskIndex] = a.now()
+		}
+	}
+
+	if count > 0 {
+		a.logger.Info("Restored stored task responses", "tasks", len(stored), "responses", count)
+	}
+	return nil
+}
+
+// AddTask records the metadata of a task created on chain so its responses are
+// evaluated against the task's quorums and creation block, and streams it to the
+// subscribed operators
+func (a *Aggregator) AddTask(taskIndex uint32, task AuctionTask) {
+	a.tasksMux.Lock()
+	_, known := a.tasks[taskIndex]
+	a.tasks[taskIndex] = task
+	a.tasksMux.Unlock()
+
+	if !known {
+		a.publishTask(taskIndex, task)
+	} else {
+		// The snapshot was taken for the task's previous metadata
+		a.forgetStakeSnapshot(taskIndex)
+	}
+}
+
+// httpHandler routes the aggregator's HTTP API, with the task stream JSON-RPC API
+// served over websocket at /rpc when taskStream is set
+func (a *Aggregator) httpHandler(taskStream *rpc.Server) http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/submit-response", a.handleTaskResponseSubmission)
-	mux.HandleFunc("/health", a.handleHealthCheck)
+	if taskStream != nil {
+		mux.Handle("/rpc", a.limitByIP(taskStream.WebsocketHandler([]string{"*"})))
+	}
+	mux.Handle("/submit-response", a.limitByIP(http.HandlerFunc(a.handleTaskResponseSubmission)))
+	mux.Handle("/submit-responses", a.limitByIP(http.HandlerFunc(a.handleBatchResponseSubmission)))
+	mux.HandleFunc("/healthz", a.handleLiveness)
+	mux.HandleFunc("/readyz", a.handleReadiness)
+	// /health predates the liveness/readiness split and remains a liveness check
+	mux.HandleFunc("/health", a.handleLiveness)
+	mux.Handle("/tasks", a.limitByIP(http.HandlerFunc(a.handleListTasks)))
+	mux.Handle("/task/", a.limitByIP(http.HandlerFunc(a.handleGetTask)))
+	mux.HandleFunc("/metrics/auctions", a.handleAuctionMetrics)
+	mux.HandleFunc("/operators", a.handleOperatorLeaderboard)
+	mux.Handle("/heartbeat", a.limitByIP(http.HandlerFunc(a.handleHeartbeat)))
+	mux.HandleFunc("/operators/online", a.handleOnlineOperators)
+	mux.Handle("/admin/quorum-threshold", a.limitByIP(http.HandlerFunc(a.handleQuorumThreshold)))
+	return mux
+}
 
-	server := &http.Server{
-		Addr:    a.config.AggregatorServerIpPortAddr,
-		Handler: mux,
+func (a *Aggregator) startHTTPServer(ctx context.Context) {
+	taskStream, err := a.newTaskStreamServer()
+	if err != nil {
+		a.logger.Error("HTTP server error", "error", err)
+		return
 	}
+	defer taskStream.Stop()
+
+	server := a.newHTTPServer(a.httpHandler(taskStream))
 
 	a.logger.Info("Starting HTTP server", "addr", a.config.AggregatorServerIpPortAddr)
 
+	addr := server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		a.logger.Error("HTTP server error", "error", err)
+		return
+	}
+	a.serving.Store(true)
+
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		defer a.serving.Store(false)
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			a.logger.Error("HTTP server error", "error", err)
 		}
 	}()
@@ -207,37 +544,319 @@ func (a *Aggregator) handleTaskResponseSubmission(w http.ResponseWriter, r *http
 		return
 	}
 
-	var signedResponse SignedAuctionTaskResponse
-	if err := json.NewDecoder(r.Body).Decode(&signedResponse); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxResponseBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Request body exceeds %d bytes", maxResponseBodySize), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.acceptTaskResponse(r.Context(), body, r.Header.Get(OperatorSignatureHeader), r.RemoteAddr); err != nil {
+		status := http.StatusInternalServerError
+		var rejection *responseRejection
+		if errors.As(err, &rejection) {
+			status = rejection.status
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
-	// Store the response
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// responseRejection is a task response refused by acceptTaskResponse, with the
+// HTTP status it is reported with
+type responseRejection struct {
+	status  int
+	message string
+}
+
+func (r *responseRejection) Error() string { return r.message }
+
+// decodeTaskResponse strictly decodes a single task response, refusing unknown
+// fields so that misspelled ones are not silently dropped
+func decodeTaskResponse(body []byte) (SignedAuctionTaskResponse, error) {
+	// The signature is decoded separately: bls.Signature unmarshals into its
+	// embedded point, which must already be allocated
+	var wire struct {
+		SignedAuctionTaskResponse
+		BlsSignature json.RawMessage `json:"blsSignature"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&wire)
+	response := wire.SignedAuctionTaskResponse
+	if err != nil {
+		// encoding/json reports unknown fields only through the error text
+		if field, unknown := strings.CutPrefix(err.Error(), "json: unknown field "); unknown {
+			return response, &responseRejection{status: http.StatusBadRequest, message: "Unknown field " + field}
+		}
+		return response, &responseRejection{status: http.StatusBadRequest, message: "Invalid JSON"}
+	}
+	if decoder.Decode(&struct{}{}) != io.EOF {
+		return response, &responseRejection{status: http.StatusBadRequest, message: "Unexpected data after the task response"}
+	}
+	if len(wire.BlsSignature) > 0 && string(wire.BlsSignature) != "null" {
+		response.BlsSignature = &bls.Signature{G1Point: bls.NewZeroG1Point()}
+		err := json.Unmarshal(wire.BlsSignature, response.BlsSignature)
+		// A null point would decode without error and fail on first use
+		if err != nil || response.BlsSignature.G1Point == nil || response.BlsSignature.G1Affine == nil {
+			return response, &responseRejection{status: http.StatusBadRequest, message: "Invalid blsSignature"}
+		}
+	}
+	return response, validateTaskResponse(response.AuctionTaskResponse)
+}
+
+// validateTaskResponse rejects responses consensus cannot handle. A zero winner
+// with a zero bid is an operator's vote that the auction has no winner; a
+// missing operator id is filled in from the request signer.
+func validateTaskResponse(response AuctionTaskResponse) error {
+	switch {
+	case response.WinningBid == nil:
+		return &responseRejection{status: http.StatusBadRequest, message: "Missing winningBid"}
+	case response.WinningBid.Sign() < 0 || response.WinningBid.BitLen() > 256:
+		return &responseRejection{status: http.StatusBadRequest, message: "winningBid must be a uint256"}
+	case response.Winner == (common.Address{}) && response.WinningBid.Sign() > 0:
+		return &responseRejection{status: http.StatusBadRequest, message: "Missing winner for a non-zero winningBid"}
+	case response.Confidence < 0 || response.Confidence > 1:
+		return &responseRejection{status: http.StatusBadRequest, message: "confidence must be between 0 and 1"}
+	}
+	return nil
+}
+
+// acceptTaskResponse authenticates a task response body signed by an operator
+// and stores it for consensus. It backs both POST /submit-response and the
+// lvr_submitResponse JSON-RPC method.
+func (a *Aggregator) acceptTaskResponse(ctx context.Context, body []byte, signature, remoteAddr string) error {
+	// Only registered operators may submit responses
+	operatorId, err := a.authenticateOperator(ctx, body, signature)
+	if err != nil {
+		a.logger.Warn("Rejecting unauthenticated task response", "remoteAddr", remoteAddr, "error", err)
+		return authenticationRejection(err)
+	}
+	if !a.operatorLimiter.allow(operatorId.Hex()) {
+		a.logger.Warn("Rate limiting operator", "operatorId", operatorId.Hex(), "remoteAddr", remoteAddr)
+		return &responseRejection{status: http.StatusTooManyRequests, message: "Too many requests"}
+	}
+
+	signedResponse, err := decodeTaskResponse(body)
+	if err != nil {
+		a.logger.Warn("Rejecting malformed task response", "operatorId", operatorId.Hex(), "error", err)
+		return err
+	}
+	return a.storeTaskResponse(ctx, operatorId, signedResponse)
+}
+
+// storeTaskResponse stores a decoded response submitted by an authenticated
+// operator, once it passes every check taken before consensus
+func (a *Aggregator) storeTaskResponse(ctx context.Context, operatorId types.OperatorId, signedResponse SignedAuctionTaskResponse) error {
+	signedResponse, err := a.checkTaskResponse(ctx, operatorId, signedResponse)
+	if err != nil {
+		return err
+	}
+	return a.storeTaskResponses([]SignedAuctionTaskResponse{signedResponse})[0]
+}
+
+// checkTaskResponse runs the checks of a response that don't depend on the
+// responses already stored, returning it attributed to the signing operator
+func (a *Aggregator) checkTaskResponse(ctx context.Context, operatorId types.OperatorId, signedResponse SignedAuctionTaskResponse) (SignedAuctionTaskResponse, error) {
+	if err := a.config.WinningBid.checkWinningBid(signedResponse.WinningBid); err != nil {
+		a.logger.Warn("Rejecting task response with an implausible winning bid", "operatorId", operatorId.Hex(), "error", err)
+		return signedResponse, &responseRejection{status: http.StatusBadRequest, message: err.Error()}
+	}
+
+	// The response is attributed to the operator that signed the request
+	if signedResponse.OperatorId == (types.OperatorId{}) {
+		signedResponse.OperatorId = operatorId
+	} else if signedResponse.OperatorId != operatorId {
+		a.logger.Warn("Rejecting task response for another operator",
+			"signer", operatorId.Hex(),
+			"operatorId", signedResponse.OperatorId.Hex(),
+		)
+		return signedResponse, &responseRejection{status: http.StatusUnauthorized, message: "Unauthorized"}
+	}
+
+	// Late responses are rejected by block timestamp, not wall-clock time
+	open, chainTime, err := a.taskAcceptingResponses(ctx, signedResponse.ReferenceTaskIndex)
+	if err != nil {
+		a.logger.Error("Failed to check task deadline", "taskIndex", signedResponse.ReferenceTaskIndex, "error", err)
+		return signedResponse, &responseRejection{status: http.StatusServiceUnavailable, message: "Failed to check task deadline"}
+	}
+	if !open {
+		a.logger.Warn("Rejecting task response after deadline",
+			"taskIndex", signedResponse.ReferenceTaskIndex,
+			"operatorId", signedResponse.OperatorId.Hex(),
+			"chainTime", chainTime,
+		)
+		return signedResponse, &responseRejection{status: http.StatusConflict, message: "Task deadline passed"}
+	}
+
+	// Replayed responses to old tasks must not revive them or skew metrics
+	tooOld, currentBlock, err := a.taskTooOld(ctx, signedResponse.ReferenceTaskIndex)
+	if err != nil {
+		a.logger.Error("Failed to check task age", "taskIndex", signedResponse.ReferenceTaskIndex, "error", err)
+		return signedResponse, &responseRejection{status: http.StatusServiceUnavailable, message: "Failed to check task age"}
+	}
+	if tooOld {
+		a.logger.Warn("Rejecting task response outside the response window",
+			"taskIndex", signedResponse.ReferenceTaskIndex,
+			"operatorId", signedResponse.OperatorId.Hex(),
+			"currentBlock", currentBlock,
+		)
+		return signedResponse, &responseRejection{status: http.StatusGone, message: "Task too old"}
+	}
+	return signedResponse, nil
+}
+
+// storeTaskResponses stores checked responses together and returns the outcome of
+// each. They are checked against the stored responses under one hold of
+// taskResponsesMux, re-sends are ignored, and if persisting any of them fails
+// every one of them is dropped again.
+func (a *Aggregator) storeTaskResponses(responses []SignedAuctionTaskResponse) []error {
+	tracked := make([]bool, len(responses))
+	a.tasksMux.RLock()
+	for i, response := range responses {
+		_, tracked[i] = a.tasks[response.ReferenceTaskIndex]
+	}
+	a.tasksMux.RUnlock()
+
+	errs := make([]error, len(responses))
+	var stored, resent []int
+	firstResponse := make(map[int]bool)
 	a.taskResponsesMux.Lock()
-	a.taskResponses[signedResponse.ReferenceTaskIndex] = append(
-		a.taskResponses[signedResponse.ReferenceTaskIndex],
-		signedResponse,
-	)
+	for i, response := range responses {
+		resend, err := a.admitResponse(response, tracked[i])
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if resend {
+			resent = append(resent, i)
+			continue
+		}
+		a.taskResponses[response.ReferenceTaskIndex] = append(a.taskResponses[response.ReferenceTaskIndex], response)
+		if _, seen := a.taskFirstSeen[response.ReferenceTaskIndex]; !seen {
+			a.taskFirstSeen[response.ReferenceTaskIndex] = a.now()
+			firstResponse[i] = true
+		}
+		stored = append(stored, i)
+	}
 	a.taskResponsesMux.Unlock()
 
-	a.logger.Info("Received task response",
-		"taskIndex", signedResponse.ReferenceTaskIndex,
-		"operatorId", signedResponse.OperatorId.Hex(),
-		"winner", signedResponse.Winner.Hex(),
-		"winningBid", signedResponse.WinningBid.String(),
-	)
+	for _, i := range resent {
+		a.logger.Info("Ignoring re-sent task response",
+			"taskIndex", responses[i].ReferenceTaskIndex,
+			"operatorId", responses[i].OperatorId.Hex(),
+			"idempotencyKey", responses[i].IdempotencyKey,
+		)
+	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	// The store rewrites the task's file, so it is written without holding
+	// taskResponsesMux and the responses are dropped again if that fails
+	for _, i := range stored {
+		if err := a.persistResponse(responses[i]); err != nil {
+			a.logger.Error("Failed to persist task response",
+				"taskIndex", responses[i].ReferenceTaskIndex,
+				"error", err,
+			)
+			dropped := make(map[uint32][]SignedAuctionTaskResponse)
+			for _, i := range stored {
+				dropped[responses[i].ReferenceTaskIndex] = append(dropped[responses[i].ReferenceTaskIndex], responses[i])
+				errs[i] = &responseRejection{status: http.StatusInternalServerError, message: "Failed to store response"}
+			}
+			for taskIndex, taskResponses := range dropped {
+				a.removeResponses(taskIndex, taskResponses)
+			}
+			return errs
+		}
+	}
+
+	for _, i := range stored {
+		a.lvrMetrics.observeResponse(responses[i].OperatorId, firstResponse[i])
+		a.logger.Info("Received task response",
+			"taskIndex", responses[i].ReferenceTaskIndex,
+			"operatorId", responses[i].OperatorId.Hex(),
+			"winner", responses[i].Winner.Hex(),
+			"winningBid", responses[i].WinningBid.String(),
+		)
+	}
+	return errs
 }
 
-func (a *Aggregator) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":    "healthy",
-		"timestamp": time.Now().Format(time.RFC3339),
-	})
+// admitResponse checks a response against the state of its task and the
+// responses already stored for it, reporting whether it re-sends one of them.
+// The caller must hold taskResponsesMux.
+func (a *Aggregator) admitResponse(response SignedAuctionTaskResponse, tracked bool) (bool, error) {
+	taskIndex := response.ReferenceTaskIndex
+	if _, cancelled := a.cancelledTasks[taskIndex]; cancelled {
+		return false, &responseRejection{status: http.StatusGone, message: "Task cancelled"}
+	}
+	if a.finalizedTasks[taskIndex] {
+		return false, &responseRejection{status: http.StatusGone, message: "Task already finalized"}
+	}
+	if _, responded := a.taskFirstSeen[taskIndex]; !responded && !tracked && taskIndex < a.prunedBelow {
+		return false, &responseRejection{status: http.StatusGone, message: "Task no longer tracked"}
+	}
+	if a.isResend(response) {
+		return true, nil
+	}
+	if a.hasResponded(response) {
+		return false, &responseRejection{status: http.StatusConflict, message: "Operator already responded to task"}
+	}
+	if a.stopped.Load() {
+		return false, &responseRejection{status: http.StatusServiceUnavailable, message: "Aggregator is shutting down"}
+	}
+	if a.draining.Load() && len(a.taskResponses[taskIndex]) == 0 {
+		return false, &responseRejection{status: http.StatusServiceUnavailable, message: "Aggregator is draining"}
+	}
+	return false, nil
+}
+
+// persistResponse saves a response already added to taskResponses. A task
+// finalized while the response was saved has its stored responses deleted again,
+// since its own deletion may have run first.
+func (a *Aggregator) persistResponse(response SignedAuctionTaskResponse) error {
+	if err := a.responseStore.Save(response.ReferenceTaskIndex, response); err != nil {
+		return err
+	}
+
+	a.taskResponsesMux.RLock()
+	finalized := a.finalizedTasks[response.ReferenceTaskIndex]
+	a.taskResponsesMux.RUnlock()
+	if finalized {
+		return a.responseStore.DeleteFinalized(response.ReferenceTaskIndex)
+	}
+	return nil
+}
+
+// hasResponded reports whether the operator already has a response stored for the
+// task. The caller must hold taskResponsesMux.
+func (a *Aggregator) hasResponded(response SignedAuctionTaskResponse) bool {
+	for _, stored := range a.taskResponses[response.ReferenceTaskIndex] {
+		if stored.OperatorId == response.OperatorId {
+			return true
+		}
+	}
+	return false
+}
+
+// isResend reports whether the operator already submitted a response to the task
+// under the same idempotency key. The caller must hold taskResponsesMux.
+func (a *Aggregator) isResend(response SignedAuctionTaskResponse) bool {
+	if response.IdempotencyKey == "" {
+		return false
+	}
+	for _, stored := range a.taskResponses[response.ReferenceTaskIndex] {
+		if stored.OperatorId == response.OperatorId && stored.IdempotencyKey == response.IdempotencyKey {
+			return true
+		}
+	}
+	return false
 }
 
 func (a *Aggregator) processTaskResponses(ctx context.Context) {
@@ -249,84 +868,238 @@ func (a *Aggregator) processTaskResponses(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
+			a.shutdown()
 			return
 		case <-ticker.C:
-			a.checkAndProcessCompletedTasks()
+			a.checkAndProcessCompletedTasks(ctx)
+			a.expireStaleTasks()
+			a.pruneFinishedTasks()
 		}
 	}
 }
 
-func (a *Aggregator) checkAndProcessCompletedTasks() {
+func (a *Aggregator) checkAndProcessCompletedTasks(ctx context.Context) {
+	// Copy the responses so quorum lookups don't hold the lock during RPC calls
 	a.taskResponsesMux.RLock()
-	defer a.taskResponsesMux.RUnlock()
-
+	pending := make(map[uint32][]SignedAuctionTaskResponse, len(a.taskResponses))
 	for taskIndex, responses := range a.taskResponses {
-		if len(responses) >= int(a.quorumThreshold) {
-			a.processCompletedTask(taskIndex, responses)
+		if a.finalizedTasks[taskIndex] {
+			continue
+		}
+		pending[taskIndex] = append([]SignedAuctionTaskResponse(nil), responses...)
+	}
+	a.taskResponsesMux.RUnlock()
+
+	for taskIndex, responses := range pending {
+		met, err := a.meetsQuorum(ctx, taskIndex, responses)
+		if err != nil {
+			a.logger.Error("Failed to evaluate quorum", "taskIndex", taskIndex, "error", err)
+			continue
+		}
+
+		if !met || a.dropIfReorged(ctx, taskIndex) || !a.collectionWindowElapsed(taskIndex) {
+			continue
 		}
+		if a.processCompletedTask(ctx, taskIndex, responses) {
+			a.markTaskFinalized(taskIndex)
+		} else {
+			a.stopCollecting(taskIndex)
+		}
+	}
+}
+
+// removeResponses drops the given responses from a task's responses, in memory
+// and in the response store
+func (a *Aggregator) removeResponses(taskIndex uint32, rejected []SignedAuctionTaskResponse) {
+	a.taskResponsesMux.Lock()
+
+	kept := a.taskResponses[taskIndex][:0]
+	for _, response := range a.taskResponses[taskIndex] {
+		isRejected := false
+		for _, r := range rejected {
+			if r.OperatorId == response.OperatorId && r.BlsSignature == response.BlsSignature {
+				isRejected = true
+				break
+			}
+		}
+		if !isRejected {
+			kept = append(kept, response)
+		}
+	}
+	a.taskResponses[taskIndex] = kept
+	a.taskResponsesMux.Unlock()
+
+	if err := a.responseStore.DeleteResponses(taskIndex, rejected); err != nil {
+		a.logger.Error("Failed to delete stored task responses", "taskIndex", taskIndex, "error", err)
 	}
 }
 
-func (a *Aggregator) processCompletedTask(taskIndex uint32, responses []SignedAuctionTaskResponse) {
+// markTaskFinalized drops the stored responses of a task and prevents it from
+// being processed again
+func (a *Aggregator) markTaskFinalized(taskIndex uint32) {
+	a.taskResponsesMux.Lock()
+	a.finalizedTasks[taskIndex] = true
+	a.finalizedAt[taskIndex] = a.now()
+	delete(a.taskResponses, taskIndex)
+	delete(a.taskFirstSeen, taskIndex)
+	delete(a.quorumReachedAt, taskIndex)
+	a.taskResponsesMux.Unlock()
+	a.forgetStakeSnapshot(taskIndex)
+
+	if err := a.responseStore.DeleteFinalized(taskIndex); err != nil {
+		a.logger.Error("Failed to delete stored task responses", "taskIndex", taskIndex, "error", err)
+	}
+}
+
+// markTaskFailed finalizes a task whose consensus could not be submitted on chain,
+// recording the error so the failure is not lost
+func (a *Aggregator) markTaskFailed(taskIndex uint32, err error) {
+	a.logger.Error("Task failed, consensus was not submitted on chain", "taskIndex", taskIndex, "error", err)
+
+	a.markTaskFinalized(taskIndex)
+
+	a.taskResponsesMux.Lock()
+	a.failedTasks[taskIndex] = err.Error()
+	a.taskResponsesMux.Unlock()
+
+	a.auctionStats.recordFailure(a.now())
+}
+
+// processCompletedTask verifies the responses of a task that reached quorum and
+// submits their consensus. It returns false if too few valid responses remain
+// after signature verification for the task to be finalized, if the operators
+// that signed the consensus response fall short of quorum, or if the task
+// failed because its consensus could not be submitted.
+func (a *Aggregator) processCompletedTask(ctx context.Context, taskIndex uint32, responses []SignedAuctionTaskResponse) bool {
 	a.logger.Info("Processing completed task",
 		"taskIndex", taskIndex,
 		"responseCount", len(responses),
 	)
 
-	// Find the most common response (consensus)
-	responseCounts := make(map[string]int)
-	for _, response := range responses {
-		responseKey := fmt.Sprintf("%s-%s-%d",
-			response.Winner.Hex(),
-			response.WinningBid.String(),
-			response.TotalBids,
-		)
-		responseCounts[responseKey]++
-	}
+	// Discard responses with invalid signatures before computing consensus
+	responses, invalid := a.filterValidResponses(ctx, taskIndex, responses)
+	if len(invalid) > 0 {
+		a.removeResponses(taskIndex, invalid)
 
-	// Find the response with the highest count
-	var consensusResponse *SignedAuctionTaskResponse
-	maxCount := 0
-	for _, response := range responses {
-		responseKey := fmt.Sprintf("%s-%s-%d",
-			response.Winner.Hex(),
-			response.WinningBid.String(),
-			response.TotalBids,
-		)
-		if responseCounts[responseKey] > maxCount {
-			maxCount = responseCounts[responseKey]
-			consensusResponse = &response
+		met, err := a.meetsQuorum(ctx, taskIndex, responses)
+		if err != nil || !met {
+			a.logger.Warn("Task below quorum after discarding invalid responses",
+				"taskIndex", taskIndex,
+				"validResponses", len(responses),
+				"invalidResponses", len(invalid),
+			)
+			a.lvrMetrics.observeFailure(failureInvalidSignatures)
+			return false
 		}
 	}
 
-	if consensusResponse != nil {
-		a.logger.Info("Task consensus reached",
+	// Decide the consensus result with the configured strategy
+	stakes, err := a.taskStakes(ctx, taskIndex)
+	if err != nil {
+		a.logger.Error("Failed to get operator stakes", "taskIndex", taskIndex, "error", err)
+		return false
+	}
+	if a.config.ConfidenceWeighting {
+		stakes = weighByConfidence(responses, stakes)
+	}
+	decision, err := a.consensusStrategy().Decide(responses, stakes)
+	if err != nil {
+		a.logger.Warn("Task responses did not reach consensus", "taskIndex", taskIndex, "error", err)
+		a.lvrMetrics.observeFailure(failureNoConsensus)
+		return false
+	}
+	clusters := clusterResponsesWithin(responses, a.config.BidTolerance, stakes)
+	assignStakes(clusters, stakes)
+	consensus := findCluster(clusters, *decision)
+	if consensus == nil {
+		a.logger.Error("Consensus strategy decided an unreported result", "taskIndex", taskIndex)
+		a.lvrMetrics.observeFailure(failureNoConsensus)
+		return false
+	}
+	consensusResponse := consensus.response
+
+	a.logger.Info("Task consensus reached",
+		"taskIndex", taskIndex,
+		"consensusCount", len(consensus.operators),
+		"consensusStake", consensus.stake.String(),
+		"totalResponses", len(responses),
+		"winner", consensusResponse.Winner.Hex(),
+		"winningBid", consensusResponse.WinningBid.String(),
+	)
+
+	if reserve := a.taskReservePrice(taskIndex); belowReserve(consensusResponse.WinningBid, reserve) {
+		a.finalizeBelowReserve(taskIndex, consensus, clusters, len(responses), reserve)
+		return true
+	}
+
+	attestation, err := a.aggregateSignatures(ctx, taskIndex, consensusResponse.AuctionTaskResponse, responses)
+	if err != nil {
+		a.logger.Error("Failed to aggregate signatures", "taskIndex", taskIndex, "error", err)
+		a.lvrMetrics.observeFailure(failureAggregation)
+		return false
+	}
+	// Under a bid tolerance the consensus may be backed by operators that signed
+	// other bids, which the attestation can't include
+	if met, err := a.signersMeetQuorum(ctx, taskIndex, attestation, responses); err != nil || !met {
+		a.logger.Warn("Signers of the consensus response fall short of quorum",
 			"taskIndex", taskIndex,
-			"consensusCount", maxCount,
-			"totalResponses", len(responses),
-			"winner", consensusResponse.Winner.Hex(),
-			"winningBid", consensusResponse.WinningBid.String(),
+			"signers", len(attestation.SignerIds),
+			"consensusCount", len(consensus.operators),
+			"error", err,
 		)
+		a.lvrMetrics.observeFailure(failureAggregation)
+		return false
+	}
 
-		// Here you would submit the consensus result to the smart contract
-		// For now, we'll just log it
-		a.submitConsensusToContract(taskIndex, consensusResponse)
+	if err := a.submitConsensusToContract(ctx, taskIndex, consensusResponse, attestation); err != nil {
+		a.markTaskFailed(taskIndex, err)
+		a.lvrMetrics.observeFailure(failureSubmission)
+		a.notifyConsensus(ConsensusEvent{
+			Type:      EventConsensusFailed,
+			TaskIndex: taskIndex,
+			Response:  consensusResponse.AuctionTaskResponse,
+			Error:     err.Error(),
+		})
+		return false
 	}
-}
+	mismatched := a.recordAccuracy(consensus, clusters)
+	a.lvrMetrics.observeConsensus(len(responses), consensusResponse.WinningBid)
 
-func (a *Aggregator) submitConsensusToContract(taskIndex uint32, consensus *SignedAuctionTaskResponse) {
-	a.logger.Info("Submitting consensus to contract",
-		"taskIndex", taskIndex,
-		"winner", consensus.Winner.Hex(),
-		"winningBid", consensus.WinningBid.String(),
-	)
+	distribution, err := a.distributeMEV(taskIndex, consensusResponse.AuctionTaskResponse)
+	if err != nil {
+		a.logger.Error("Failed to compute MEV distribution", "taskIndex", taskIndex, "error", err)
+	}
 
-	// In a real implementation, this would:
-	// 1. Verify BLS signatures
-	// 2. Submit the consensus result to the LVR Auction Service Manager
-	// 3. Handle any errors or retries
-	
-	// For now, we'll simulate this
-	time.Sleep(100 * time.Millisecond)
-	a.logger.Info("Consensus submitted successfully")
+	now := a.now()
+	a.taskResponsesMux.Lock()
+	auctionTime := time.Duration(-1)
+	if firstSeen, seen := a.taskFirstSeen[taskIndex]; seen {
+		auctionTime = now.Sub(firstSeen)
+	}
+	a.consensusResults[taskIndex] = TaskConsensus{
+		Winner:              consensusResponse.Winner,
+		WinningBid:          consensusResponse.WinningBid,
+		TotalBids:           consensusResponse.TotalBids,
+		Responses:           len(responses),
+		Signers:             len(attestation.SignerIds),
+		NonSigners:          len(attestation.NonSignerIds),
+		FinalizedTime:       now,
+		MismatchedOperators: operatorIdHexes(mismatched),
+		Distribution:        distribution,
+	}
+	a.taskResponsesMux.Unlock()
+
+	var lpAmount *big.Int
+	if distribution != nil {
+		lpAmount = distribution.LPAmount
+	}
+	a.auctionStats.recordSuccess(consensusResponse.WinningBid, lpAmount, auctionTime, now)
+	a.notifyConsensusMismatches(taskIndex, mismatched)
+	a.notifyConsensus(ConsensusEvent{
+		Type:         EventConsensusFinalized,
+		TaskIndex:    taskIndex,
+		Response:     consensusResponse.AuctionTaskResponse,
+		Distribution: distribution,
+	})
+	return true
 }
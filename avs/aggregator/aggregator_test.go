@@ -0,0 +1,475 @@
+package aggregator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeOperatorState is an in-memory operatorStateReader for tests
+type fakeOperatorState struct {
+	mutex     sync.Mutex
+	stakes    map[types.OperatorId]*big.Int
+	keys      map[types.OperatorId]*bls.KeyPair
+	ecdsaKeys map[types.OperatorId]*ecdsa.PrivateKey
+	addresses map[common.Address]types.OperatorId
+	// quorums holds the quorums of operators registered in only some of them;
+	// operators without an entry are registered in every quorum
+	quorums map[types.OperatorId][]types.QuorumNum
+	// stakeReads counts the GetOperatorStakesAtBlock calls
+	stakeReads int
+	// lookupErr fails GetRegisteredOperatorId when it is not nil
+	lookupErr error
+	// stakesRelease, when set, holds GetOperatorStakesAtBlock until it is closed,
+	// after signalling stakesStarted
+	stakesStarted chan struct{}
+	stakesRelease chan struct{}
+}
+
+func newFakeOperatorState() *fakeOperatorState {
+	return &fakeOperatorState{
+		stakes:    make(map[types.OperatorId]*big.Int),
+		keys:      make(map[types.OperatorId]*bls.KeyPair),
+		ecdsaKeys: make(map[types.OperatorId]*ecdsa.PrivateKey),
+		addresses: make(map[common.Address]types.OperatorId),
+		quorums:   make(map[types.OperatorId][]types.QuorumNum),
+	}
+}
+
+// setQuorums registers an operator in only the given quorums
+func (f *fakeOperatorState) setQuorums(operatorId types.OperatorId, quorums ...types.QuorumNum) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.quorums[operatorId] = quorums
+}
+
+// addOperator registers an operator with fresh BLS and ECDSA keys
+func (f *fakeOperatorState) addOperator(id byte, stake int64) types.OperatorId {
+	keyPair, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		panic(err)
+	}
+	ecdsaKey, err := crypto.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	operatorId := types.OperatorId{id}
+	f.stakes[operatorId] = big.NewInt(stake)
+	f.keys[operatorId] = keyPair
+	f.ecdsaKeys[operatorId] = ecdsaKey
+	f.addresses[crypto.PubkeyToAddress(ecdsaKey.PublicKey)] = operatorId
+	return operatorId
+}
+
+func (f *fakeOperatorState) ecdsaKey(operatorId types.OperatorId) *ecdsa.PrivateKey {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.ecdsaKeys[operatorId]
+}
+
+func (f *fakeOperatorState) GetRegisteredOperatorId(ctx context.Context, address common.Address) (types.OperatorId, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.lookupErr != nil {
+		return types.OperatorId{}, false, f.lookupErr
+	}
+	operatorId, registered := f.addresses[address]
+	return operatorId, registered, nil
+}
+
+func (f *fakeOperatorState) keyPair(operatorId types.OperatorId) *bls.KeyPair {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.keys[operatorId]
+}
+
+func (f *fakeOperatorState) GetOperatorPubkeys(ctx context.Context, operatorId types.OperatorId) (types.OperatorPubkeys, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	keyPair, exists := f.keys[operatorId]
+	if !exists {
+		return types.OperatorPubkeys{}, fmt.Errorf("unknown operator %s", operatorId.Hex())
+	}
+	return types.OperatorPubkeys{G1Pubkey: keyPair.GetPubKeyG1(), G2Pubkey: keyPair.GetPubKeyG2()}, nil
+}
+
+func (f *fakeOperatorState) GetOperatorStakesAtBlock(ctx context.Context, quorumNumbers types.QuorumNums, blockNumber uint32) (map[types.OperatorId]*big.Int, error) {
+	if f.stakesRelease != nil {
+		f.stakesStarted <- struct{}{}
+		<-f.stakesRelease
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.stakeReads++
+	stakes := make(map[types.OperatorId]*big.Int, len(f.stakes))
+	for id, stake := range f.stakes {
+		stakes[id] = new(big.Int).Set(stake)
+	}
+	return stakes, nil
+}
+
+func (f *fakeOperatorState) GetOperatorStakesPerQuorumAtBlock(ctx context.Context, quorumNumbers types.QuorumNums, blockNumber uint32) (map[types.QuorumNum]map[types.OperatorId]*big.Int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	stakesPerQuorum := make(map[types.QuorumNum]map[types.OperatorId]*big.Int, len(quorumNumbers))
+	for _, quorum := range quorumNumbers {
+		stakes := make(map[types.OperatorId]*big.Int)
+		for id, stake := range f.stakes {
+			quorums, restricted := f.quorums[id]
+			if restricted && !slices.Contains(quorums, quorum) {
+				continue
+			}
+			stakes[id] = new(big.Int).Set(stake)
+		}
+		stakesPerQuorum[quorum] = stakes
+	}
+	return stakesPerQuorum, nil
+}
+
+// newTestAggregator builds an aggregator with in-memory dependencies
+func newTestAggregator(t testing.TB, config Config, state *fakeOperatorState) *Aggregator {
+	t.Helper()
+	if len(config.QuorumNumbers) == 0 {
+		config.QuorumNumbers = []uint32{0}
+	}
+	return &Aggregator{
+		config:           config,
+		logger:           logging.NewNoopLogger(),
+		avsReader:        state,
+		taskResponses:    make(map[uint32][]SignedAuctionTaskResponse),
+		tasks:            make(map[uint32]AuctionTask),
+		taskSubscribers:  make(map[chan StreamedTask]struct{}),
+		finalizedTasks:   make(map[uint32]bool),
+		accuracy:         make(map[types.OperatorId]OperatorAccuracy),
+		heartbeats:       make(map[types.OperatorId]time.Time),
+		stakeSnapshots:   make(map[uint32]*stakeSnapshot),
+		queriedStakes:    make(map[uint32]*queriedStakes),
+		quorumThreshold:  types.ThresholdPercentage(config.QuorumThreshold),
+		responseStore:    memoryResponseStore{},
+		blockReader:      &fakeBlockReader{},
+		avsWriter:        &fakeTaskResponder{},
+		failedTasks:      make(map[uint32]string),
+		cancelledTasks:   make(map[uint32]string),
+		taskFirstSeen:    make(map[uint32]time.Time),
+		quorumReachedAt:  make(map[uint32]time.Time),
+		consensusResults: make(map[uint32]TaskConsensus),
+		finalizedAt:      make(map[uint32]time.Time),
+		lvrMetrics:       newLvrMetrics(),
+		now:              time.Now,
+	}
+}
+
+// fakeTaskResponder records submitted task responses, failing the first failures
+// calls with err, or with the node being unreachable if err is nil
+type fakeTaskResponder struct {
+	mutex      sync.Mutex
+	failures   int
+	err        error
+	attempts   int
+	signatures map[uint32][]byte
+}
+
+func (f *fakeTaskResponder) RespondToTask(ctx context.Context, serviceManagerAddr common.Address, taskIndex uint32, winner common.Address, winningBid *big.Int, signature []byte) (*gethtypes.Receipt, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.attempts++
+	if f.attempts <= f.failures {
+		if f.err != nil {
+			return nil, f.err
+		}
+		return nil, fmt.Errorf("failed to send respondToTask transaction: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	}
+	if f.signatures == nil {
+		f.signatures = make(map[uint32][]byte)
+	}
+	f.signatures[taskIndex] = signature
+	return &gethtypes.Receipt{Status: gethtypes.ReceiptStatusSuccessful}, nil
+}
+
+// fakeBlockReader reports a settable chain height
+type fakeBlockReader struct {
+	mutex sync.Mutex
+	block uint64
+}
+
+func (f *fakeBlockReader) BlockNumber(ctx context.Context) (uint64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.block, nil
+}
+
+func (f *fakeBlockReader) setBlock(block uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.block = block
+}
+
+func newTestResponse(taskIndex uint32, operatorId types.OperatorId, winner string, bid int64) SignedAuctionTaskResponse {
+	return SignedAuctionTaskResponse{
+		AuctionTaskResponse: AuctionTaskResponse{
+			ReferenceTaskIndex: taskIndex,
+			Winner:             common.HexToAddress(winner),
+			WinningBid:         big.NewInt(bid),
+			TotalBids:          1,
+		},
+		OperatorId: operatorId,
+	}
+}
+
+// newSignedTestResponse builds a response signed with the operator's registered key
+func newSignedTestResponse(t testing.TB, state *fakeOperatorState, taskIndex uint32, operatorId types.OperatorId, winner string, bid int64) SignedAuctionTaskResponse {
+	t.Helper()
+	response := newTestResponse(taskIndex, operatorId, winner, bid)
+	digest, err := ResponseDigest(response.AuctionTaskResponse)
+	if err != nil {
+		t.Fatalf("ResponseDigest: %v", err)
+	}
+	response.BlsSignature = state.keyPair(operatorId).SignMessage(digest)
+	return response
+}
+
+func TestDualQuorumRequiresCountAndStake(t *testing.T) {
+	state := newFakeOperatorState()
+	whale := state.addOperator(1, 80)
+	small1 := state.addOperator(2, 10)
+	small2 := state.addOperator(3, 10)
+
+	agg := newTestAggregator(t, Config{QuorumCountThreshold: 60, QuorumStakeThreshold: 60}, state)
+	ctx := context.Background()
+	winner := "0x00000000000000000000000000000000000000aa"
+
+	tests := []struct {
+		name      string
+		operators []types.OperatorId
+		want      bool
+	}{
+		// 1/3 operators but 80% of stake
+		{name: "stake only", operators: []types.OperatorId{whale}, want: false},
+		// 2/3 operators but 20% of stake
+		{name: "count only", operators: []types.OperatorId{small1, small2}, want: false},
+		// 2/3 operators and 90% of stake
+		{name: "both", operators: []types.OperatorId{whale, small1}, want: true},
+		// repeated responses from one operator count once
+		{name: "duplicate operator", operators: []types.OperatorId{whale, whale}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var responses []SignedAuctionTaskResponse
+			for _, operatorId := range tt.operators {
+				responses = append(responses, newTestResponse(1, operatorId, winner, 100))
+			}
+
+			met, err := agg.meetsDualQuorum(ctx, 1, responses)
+			if err != nil {
+				t.Fatalf("meetsDualQuorum: %v", err)
+			}
+			if met != tt.want {
+				t.Fatalf("meetsDualQuorum = %v, want %v", met, tt.want)
+			}
+		})
+	}
+}
+
+func TestDualQuorumSkipsUnsetThreshold(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 0)
+	op2 := state.addOperator(2, 0)
+	state.addOperator(3, 0)
+	ctx := context.Background()
+	responses := []SignedAuctionTaskResponse{
+		newTestResponse(1, op1, winnerX, 100),
+		newTestResponse(1, op2, winnerX, 100),
+	}
+
+	// Operators without stake meet a count threshold set on its own
+	countOnly := newTestAggregator(t, Config{QuorumCountThreshold: 60}, state)
+	if met, err := countOnly.meetsDualQuorum(ctx, 1, responses); err != nil || !met {
+		t.Fatalf("meetsDualQuorum with only the count threshold = %v, %v; want met", met, err)
+	}
+	if met, _ := countOnly.meetsDualQuorum(ctx, 1, responses[:1]); met {
+		t.Fatal("expected 1 of 3 operators to fall short of the count threshold")
+	}
+
+	// A stake threshold set on its own still requires stake
+	stakeOnly := newTestAggregator(t, Config{QuorumStakeThreshold: 60}, state)
+	if met, _ := stakeOnly.meetsDualQuorum(ctx, 1, responses); met {
+		t.Fatal("expected operators without stake to fall short of the stake threshold")
+	}
+}
+
+func TestQuorumProgressIgnoresUnregisteredOperators(t *testing.T) {
+	state := newFakeOperatorState()
+	registered := state.addOperator(1, 50)
+	state.addOperator(2, 50)
+
+	stakes, _ := state.GetOperatorStakesAtBlock(context.Background(), nil, 0)
+	progress := newQuorumProgress([]SignedAuctionTaskResponse{
+		newTestResponse(1, registered, "0x01", 1),
+		newTestResponse(1, types.OperatorId{9}, "0x01", 1),
+	}, stakes)
+
+	if progress.RespondedOperators != 1 || progress.RespondedStake.Int64() != 50 {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+	if !progress.meetsStakeThreshold(50) || progress.meetsStakeThreshold(51) {
+		t.Fatalf("unexpected stake threshold evaluation for %+v", progress)
+	}
+}
+
+func TestQuorumThresholdIsStakeWeighted(t *testing.T) {
+	state := newFakeOperatorState()
+	large := state.addOperator(1, 50)
+	medium := state.addOperator(2, 30)
+	small := state.addOperator(3, 20)
+
+	agg := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+	ctx := context.Background()
+	winner := "0x00000000000000000000000000000000000000aa"
+
+	submit := func(operatorId types.OperatorId) {
+		agg.taskResponses[1] = append(agg.taskResponses[1], newSignedTestResponse(t, state, 1, operatorId, winner, 100))
+	}
+
+	// 2 of 3 operators (66% by count) holding 50% of stake
+	submit(small)
+	submit(medium)
+	agg.checkAndProcessCompletedTasks(ctx)
+	if agg.finalizedTasks[1] {
+		t.Fatal("task finalized with 50% of stake")
+	}
+
+	// all three operators hold 100% of stake
+	submit(large)
+	agg.checkAndProcessCompletedTasks(ctx)
+	if !agg.finalizedTasks[1] {
+		t.Fatal("task not finalized with 100% of stake")
+	}
+	if _, exists := agg.taskResponses[1]; exists {
+		t.Fatal("responses of a finalized task should be dropped")
+	}
+}
+
+func TestMinDistinctOperatorsBlocksSingleOperatorFinalization(t *testing.T) {
+	state := newFakeOperatorState()
+	whale := state.addOperator(1, 90)
+	small := state.addOperator(2, 10)
+	unregistered := types.OperatorId{0xee}
+
+	for _, config := range []Config{
+		{QuorumThreshold: 67, MinDistinctOperators: 2},
+		{QuorumCountThreshold: 50, QuorumStakeThreshold: 67, MinDistinctOperators: 2},
+	} {
+		agg := newTestAggregator(t, config, state)
+		ctx := context.Background()
+		submit := func(operatorId types.OperatorId) {
+			agg.taskResponses[1] = append(agg.taskResponses[1], newSignedTestResponse(t, state, 1, operatorId, winnerX, 100))
+		}
+
+		// The whale alone meets the stake threshold, and a resend or a response from
+		// outside the operator set does not make a second operator
+		submit(whale)
+		submit(whale)
+		agg.taskResponses[1] = append(agg.taskResponses[1], newTestResponse(1, unregistered, winnerX, 100))
+		agg.checkAndProcessCompletedTasks(ctx)
+		if agg.finalizedTasks[1] {
+			t.Fatalf("%+v: task finalized on a single operator", config)
+		}
+
+		submit(small)
+		agg.checkAndProcessCompletedTasks(ctx)
+		if !agg.finalizedTasks[1] {
+			t.Fatalf("%+v: task not finalized once two operators responded", config)
+		}
+	}
+}
+
+func TestQuorumThresholdFallsBackToCount(t *testing.T) {
+	state := newFakeOperatorState()
+	first := state.addOperator(1, 0)
+	second := state.addOperator(2, 0)
+	state.addOperator(3, 0)
+
+	agg := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+	ctx := context.Background()
+	winner := "0x00000000000000000000000000000000000000aa"
+
+	responses := []SignedAuctionTaskResponse{newTestResponse(1, first, winner, 100)}
+	if met, _ := agg.meetsQuorumThreshold(ctx, 1, responses); met {
+		t.Fatal("1 of 3 operators should not meet a 67% threshold")
+	}
+
+	responses = append(responses, newTestResponse(1, second, winner, 100))
+	if met, _ := agg.meetsQuorumThreshold(ctx, 1, responses); met {
+		t.Fatal("2 of 3 operators (66%) should not meet a 67% threshold")
+	}
+
+	agg.quorumThreshold = 66
+	if met, _ := agg.meetsQuorumThreshold(ctx, 1, responses); !met {
+		t.Fatal("2 of 3 operators should meet a 66% threshold")
+	}
+}
+
+func TestEachQuorumMustMeetThreshold(t *testing.T) {
+	state := newFakeOperatorState()
+	first := state.addOperator(1, 100)
+	second := state.addOperator(2, 100)
+	third := state.addOperator(3, 100)
+	fourth := state.addOperator(4, 100)
+	state.setQuorums(first, 0)
+	state.setQuorums(second, 0)
+	state.setQuorums(third, 1)
+	state.setQuorums(fourth, 1)
+
+	agg := newTestAggregator(t, Config{QuorumThreshold: 100}, state)
+	agg.AddTask(1, AuctionTask{QuorumNumbers: types.QuorumNums{0, 1}, QuorumThresholdPercentage: 50})
+	ctx := context.Background()
+	winner := "0x00000000000000000000000000000000000000aa"
+
+	// Half of the total stake responded, but all of it from quorum 0
+	responses := []SignedAuctionTaskResponse{
+		newTestResponse(1, first, winner, 100),
+		newTestResponse(1, second, winner, 100),
+	}
+	if met, err := agg.meetsQuorum(ctx, 1, responses); err != nil || met {
+		t.Fatalf("meetsQuorum = %v, %v; want quorum 1 to be short", met, err)
+	}
+
+	responses = append(responses, newTestResponse(1, third, winner, 100))
+	if met, err := agg.meetsQuorum(ctx, 1, responses); err != nil || !met {
+		t.Fatalf("meetsQuorum = %v, %v; want both quorums at the task's 50%% threshold", met, err)
+	}
+
+	// The same responses fall short of the configured threshold for tasks without one
+	agg.AddTask(1, AuctionTask{QuorumNumbers: types.QuorumNums{0, 1}})
+	if met, _ := agg.meetsQuorum(ctx, 1, responses); met {
+		t.Fatal("expected quorum 1 to be short of the configured 100% threshold")
+	}
+
+	// Dual thresholds are also applied per quorum
+	agg.config.QuorumCountThreshold = 50
+	agg.config.QuorumStakeThreshold = 50
+	if met, _ := agg.meetsQuorum(ctx, 1, responses[:2]); met {
+		t.Fatal("expected the dual threshold to require quorum 1 as well")
+	}
+	if met, _ := agg.meetsQuorum(ctx, 1, responses); !met {
+		t.Fatal("expected both quorums to meet the dual thresholds")
+	}
+}
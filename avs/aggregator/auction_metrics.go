@@ -0,0 +1,92 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// auctionStats accumulates the AuctionMetrics of finalized tasks. Its zero value
+// is ready to use.
+type auctionStats struct {
+	total      uint64
+	successful uint64
+	totalMEV   big.Int
+	lpAmount   big.Int
+	// auctionTime sums the time from first response to consensus of timedAuctions
+	// successful tasks; tasks rehydrated without a first seen time are not timed
+	auctionTime   time.Duration
+	timedAuctions uint64
+	lastUpdated   time.Time
+	mutex         sync.Mutex
+}
+
+// recordSuccess accounts for a task whose consensus was submitted on chain.
+// auctionTime is ignored when negative.
+func (s *auctionStats) recordSuccess(winningBid, lpAmount *big.Int, auctionTime time.Duration, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.total++
+	s.successful++
+	if winningBid != nil {
+		s.totalMEV.Add(&s.totalMEV, winningBid)
+	}
+	if lpAmount != nil {
+		s.lpAmount.Add(&s.lpAmount, lpAmount)
+	}
+	if auctionTime >= 0 {
+		s.auctionTime += auctionTime
+		s.timedAuctions++
+	}
+	s.lastUpdated = now
+}
+
+// recordFailure accounts for a task finalized without consensus
+func (s *auctionStats) recordFailure(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.total++
+	s.lastUpdated = now
+}
+
+// snapshot returns the accumulated metrics. The average bid is rounded down to
+// the wei and the average auction time is in seconds.
+func (s *auctionStats) snapshot() lvrtypes.AuctionMetrics {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	metrics := lvrtypes.AuctionMetrics{
+		TotalAuctions:      s.total,
+		SuccessfulAuctions: s.successful,
+		TotalMEVRecovered:  new(big.Int).Set(&s.totalMEV),
+		AverageBidAmount:   new(big.Int),
+		LastUpdated:        s.lastUpdated,
+	}
+	if s.successful > 0 {
+		metrics.AverageBidAmount.Quo(&s.totalMEV, new(big.Int).SetUint64(s.successful))
+	}
+	if s.timedAuctions > 0 {
+		metrics.AverageAuctionTime = s.auctionTime.Seconds() / float64(s.timedAuctions)
+	}
+	if s.totalMEV.Sign() > 0 {
+		metrics.LPCompensationRate, _ = new(big.Rat).SetFrac(&s.lpAmount, &s.totalMEV).Float64()
+	}
+	return metrics
+}
+
+// handleAuctionMetrics serves the auction metrics accumulated over finalized tasks
+func (a *Aggregator) handleAuctionMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.auctionStats.snapshot())
+}
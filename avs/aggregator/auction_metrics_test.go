@@ -0,0 +1,60 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestAuctionMetricsAccumulateFinalizedTasks(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+
+	start := time.Unix(1700000000, 0)
+	now := start
+	a.now = func() time.Time { return now }
+
+	// Three auctions reach consensus after 2s, 4s and 6s, with bids summing to 1000 wei
+	for i, bid := range []int64{100, 300, 600} {
+		taskIndex := uint32(i + 1)
+		a.taskFirstSeen[taskIndex] = now
+		now = now.Add(time.Duration(2*(i+1)) * time.Second)
+
+		responses := []SignedAuctionTaskResponse{newSignedTestResponse(t, state, taskIndex, op1, winnerX, bid)}
+		if !a.processCompletedTask(context.Background(), taskIndex, responses) {
+			t.Fatalf("expected task %d to be processed", taskIndex)
+		}
+		a.markTaskFinalized(taskIndex)
+	}
+	a.markTaskFailed(4, errors.New("reverted"))
+
+	server := httptest.NewServer(a.httpHandler(nil))
+	defer server.Close()
+
+	var metrics lvrtypes.AuctionMetrics
+	if status := getJSON(t, server.URL+"/metrics/auctions", &metrics); status != http.StatusOK {
+		t.Fatalf("GET /metrics/auctions status = %d", status)
+	}
+	if metrics.TotalAuctions != 4 || metrics.SuccessfulAuctions != 3 {
+		t.Fatalf("auctions = %d/%d, want 3/4 successful", metrics.SuccessfulAuctions, metrics.TotalAuctions)
+	}
+	// The 333.33 wei average bid is rounded down
+	if metrics.TotalMEVRecovered.Int64() != 1000 || metrics.AverageBidAmount.Int64() != 333 {
+		t.Fatalf("MEV recovered = %s averaging %s, want 1000 averaging 333", metrics.TotalMEVRecovered, metrics.AverageBidAmount)
+	}
+	if metrics.AverageAuctionTime != 4 {
+		t.Fatalf("average auction time = %vs, want 4s", metrics.AverageAuctionTime)
+	}
+	if metrics.LPCompensationRate != 0.85 {
+		t.Fatalf("LP compensation rate = %v, want the default split's 0.85", metrics.LPCompensationRate)
+	}
+	if !metrics.LastUpdated.Equal(now) {
+		t.Fatalf("last updated = %s, want %s", metrics.LastUpdated, now)
+	}
+}
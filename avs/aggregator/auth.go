@@ -0,0 +1,79 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// OperatorSignatureHeader carries the operator's ECDSA signature over the request
+// body. The signature is over the EIP-191 personal message hash of the raw body,
+// hex encoded as 65 bytes with the recovery id as 27 or 28.
+const OperatorSignatureHeader = "X-Operator-Signature"
+
+var (
+	// ErrMissingOperatorSignature is returned when a request has no operator signature
+	ErrMissingOperatorSignature = errors.New("missing operator signature")
+	// ErrUnregisteredOperator is returned when a request is signed by an address that
+	// is not a registered operator
+	ErrUnregisteredOperator = errors.New("signer is not a registered operator")
+	// ErrOperatorLookup is returned when the operator registry could not be read to
+	// check a request's signer
+	ErrOperatorLookup = errors.New("failed to look up operator")
+)
+
+// RecoverRequestSigner returns the address that produced the OperatorSignatureHeader
+// value signature over body
+func RecoverRequestSigner(body []byte, signature string) (common.Address, error) {
+	if signature == "" {
+		return common.Address{}, ErrMissingOperatorSignature
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, errors.New("malformed operator signature")
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubkey, err := crypto.SigToPub(accounts.TextHash(body), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid operator signature: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// authenticateOperator verifies that body was signed by a registered operator and
+// returns that operator's id
+func (a *Aggregator) authenticateOperator(ctx context.Context, body []byte, signature string) (types.OperatorId, error) {
+	signer, err := RecoverRequestSigner(body, signature)
+	if err != nil {
+		return types.OperatorId{}, err
+	}
+
+	operatorId, registered, err := a.avsReader.GetRegisteredOperatorId(ctx, signer)
+	if err != nil {
+		return types.OperatorId{}, fmt.Errorf("%w %s: %w", ErrOperatorLookup, signer.Hex(), err)
+	}
+	if !registered {
+		return types.OperatorId{}, fmt.Errorf("%w: %s", ErrUnregisteredOperator, signer.Hex())
+	}
+	return operatorId, nil
+}
+
+// authenticationRejection is the rejection of a request authenticateOperator
+// failed: 503 when the operator registry could not be read, otherwise 401
+func authenticationRejection(err error) *responseRejection {
+	if errors.Is(err, ErrOperatorLookup) {
+		return &responseRejection{status: http.StatusServiceUnavailable, message: "Failed to look up operator"}
+	}
+	return &responseRejection{status: http.StatusUnauthorized, message: "Unauthorized"}
+}
@@ -0,0 +1,385 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// submitTestResponse posts body to the submission handler, signed with key when it is not nil
+func submitTestResponse(t *testing.T, a *Aggregator, key *ecdsa.PrivateKey, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/submit-response", bytes.NewReader(body))
+	if key != nil {
+		signature, err := operator.SignRequestBody(key, body)
+		if err != nil {
+			t.Fatalf("SignRequestBody: %v", err)
+		}
+		req.Header.Set(OperatorSignatureHeader, signature)
+	}
+
+	recorder := httptest.NewRecorder()
+	a.handleTaskResponseSubmission(recorder, req)
+	return recorder
+}
+
+func marshalTestResponse(t *testing.T, response SignedAuctionTaskResponse) []byte {
+	t.Helper()
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return body
+}
+
+func TestSubmitResponseRequiresRegisteredOperatorSignature(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{}, state)
+
+	winner := "0x00000000000000000000000000000000000000aa"
+	body := marshalTestResponse(t, newTestResponse(1, op1, winner, 10))
+
+	outsider, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		key  *ecdsa.PrivateKey
+		body []byte
+		want int
+	}{
+		{"unsigned", nil, body, http.StatusUnauthorized},
+		{"unregistered signer", outsider, body, http.StatusUnauthorized},
+		{"signed for another operator", state.ecdsaKey(op2), body, http.StatusUnauthorized},
+		{"registered operator", state.ecdsaKey(op1), body, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := submitTestResponse(t, a, tc.key, tc.body).Code; got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	if got := len(a.taskResponses[1]); got != 1 {
+		t.Fatalf("expected only the authenticated response to be stored, got %d", got)
+	}
+}
+
+func TestSubmitResponseUnavailableWhenOperatorLookupFails(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+	state.lookupErr = errors.New("connection refused")
+
+	body := marshalTestResponse(t, newTestResponse(1, operatorId, winnerX, 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 when the operator registry can't be read", got)
+	}
+	if len(a.taskResponses[1]) != 0 {
+		t.Fatal("expected the response not to be stored")
+	}
+}
+
+func TestSubmitResponseAttributesToSigner(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+
+	// Responses without an operator id are attributed to the signing operator
+	body := marshalTestResponse(t, newTestResponse(4, types.OperatorId{}, "0x00000000000000000000000000000000000000aa", 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+		t.Fatalf("status = %d, want %d", got, http.StatusOK)
+	}
+	if a.taskResponses[4][0].OperatorId != operatorId {
+		t.Fatalf("response attributed to %s, want %s", a.taskResponses[4][0].OperatorId.Hex(), operatorId.Hex())
+	}
+}
+
+func TestSubmitResponseIgnoresResends(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+
+	// An operator whose request timed out after the aggregator stored it re-sends
+	// the same response under the same key
+	response := newTestResponse(4, operatorId, "0x00000000000000000000000000000000000000aa", 10)
+	response.IdempotencyKey = lvrtypes.TaskResponseIdempotencyKey(operatorId, 4)
+	body := marshalTestResponse(t, response)
+	for attempt := 1; attempt <= 2; attempt++ {
+		if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+			t.Fatalf("attempt %d: status = %d, want %d", attempt, got, http.StatusOK)
+		}
+	}
+	if got := len(a.taskResponses[4]); got != 1 {
+		t.Fatalf("stored %d responses, want the re-send ignored", got)
+	}
+
+	// Responses to other tasks are unaffected by the key
+	response = newTestResponse(5, operatorId, "0x00000000000000000000000000000000000000aa", 10)
+	response.IdempotencyKey = lvrtypes.TaskResponseIdempotencyKey(operatorId, 5)
+	if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), marshalTestResponse(t, response)).Code; got != http.StatusOK {
+		t.Fatalf("status = %d, want %d", got, http.StatusOK)
+	}
+	if got := len(a.taskResponses[5]); got != 1 {
+		t.Fatalf("stored %d responses for another task, want 1", got)
+	}
+}
+
+func TestSubmitResponseRejectsSecondResponse(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+	key := state.ecdsaKey(operatorId)
+
+	body := marshalTestResponse(t, newTestResponse(4, operatorId, "0x00000000000000000000000000000000000000aa", 10))
+	if got := submitTestResponse(t, a, key, body).Code; got != http.StatusOK {
+		t.Fatalf("first response: status = %d, want %d", got, http.StatusOK)
+	}
+
+	// A different response from the same operator would count its stake twice
+	body = marshalTestResponse(t, newTestResponse(4, operatorId, "0x00000000000000000000000000000000000000bb", 20))
+	if got := submitTestResponse(t, a, key, body).Code; got != http.StatusConflict {
+		t.Fatalf("second response: status = %d, want %d", got, http.StatusConflict)
+	}
+	if got := len(a.taskResponses[4]); got != 1 {
+		t.Fatalf("stored %d responses, want only the first", got)
+	}
+	if got := a.taskResponses[4][0].WinningBid.Int64(); got != 10 {
+		t.Fatalf("stored winning bid = %d, want the first response's 10", got)
+	}
+}
+
+func TestSubmitResponseRejectsMalformedBodies(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+	key := state.ecdsaKey(operatorId)
+
+	oversized := bytes.Repeat([]byte(" "), maxResponseBodySize+1)
+	recorder := submitTestResponse(t, a, key, oversized)
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "exceeds") {
+		t.Fatalf("oversized body: status %d %q, want 400 reporting the size limit", recorder.Code, recorder.Body.String())
+	}
+
+	body := []byte(`{"referenceTaskIndex":1,"winner":"0x00000000000000000000000000000000000000aa","winningBid":10,"totalBid":1}`)
+	recorder = submitTestResponse(t, a, key, body)
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), `"totalBid"`) {
+		t.Fatalf("unknown field: status %d %q, want 400 naming the field", recorder.Code, recorder.Body.String())
+	}
+
+	body = append(marshalTestResponse(t, newTestResponse(1, operatorId, "0x00000000000000000000000000000000000000aa", 10)), []byte(`{}`)...)
+	if got := submitTestResponse(t, a, key, body).Code; got != http.StatusBadRequest {
+		t.Fatalf("trailing data: status %d, want 400", got)
+	}
+
+	if got := len(a.taskResponses[1]); got != 0 {
+		t.Fatalf("expected no malformed response to be stored, got %d", got)
+	}
+}
+
+func TestSubmitResponseRejectsIncompleteResponses(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+	key := state.ecdsaKey(operatorId)
+
+	cases := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"missing winning bid", `{"referenceTaskIndex":1,"winner":"0x00000000000000000000000000000000000000aa","totalBids":1}`, http.StatusBadRequest},
+		{"null winning bid", `{"referenceTaskIndex":1,"winner":"0x00000000000000000000000000000000000000aa","winningBid":null}`, http.StatusBadRequest},
+		{"negative winning bid", `{"referenceTaskIndex":1,"winner":"0x00000000000000000000000000000000000000aa","winningBid":-1}`, http.StatusBadRequest},
+		{"bid without winner", `{"referenceTaskIndex":1,"winningBid":10}`, http.StatusBadRequest},
+		{"no winner", `{"referenceTaskIndex":1,"winner":"0x0000000000000000000000000000000000000000","winningBid":0}`, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := submitTestResponse(t, a, key, []byte(tc.body)).Code; got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	// Only the no-winner vote is stored for consensus
+	if got := len(a.taskResponses[1]); got != 1 {
+		t.Fatalf("expected only the complete response to be stored, got %d", got)
+	}
+}
+
+func TestRecoverRequestSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	body := []byte(`{"referenceTaskIndex":1}`)
+	signature, err := operator.SignRequestBody(key, body)
+	if err != nil {
+		t.Fatalf("SignRequestBody: %v", err)
+	}
+
+	signer, err := RecoverRequestSigner(body, signature)
+	if err != nil {
+		t.Fatalf("RecoverRequestSigner: %v", err)
+	}
+	if signer != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("recovered %s, want %s", signer.Hex(), crypto.PubkeyToAddress(key.PublicKey).Hex())
+	}
+
+	// A signature over a different body recovers a different address
+	tampered, err := RecoverRequestSigner([]byte(`{"referenceTaskIndex":2}`), signature)
+	if err == nil && tampered == signer {
+		t.Fatal("expected tampered body not to recover the signer")
+	}
+
+	if _, err := RecoverRequestSigner(body, ""); !errors.Is(err, ErrMissingOperatorSignature) {
+		t.Fatalf("expected ErrMissingOperatorSignature, got %v", err)
+	}
+	if _, err := RecoverRequestSigner(body, "0x1234"); err == nil {
+		t.Fatal("expected malformed signature to be rejected")
+	}
+}
+
+func TestSubmitResponseRejectedAfterContractDeadline(t *testing.T) {
+	h := newChainHarness(t)
+	timely := h.registerOperator(1, 100)
+	late := h.registerOperator(2, 100)
+	a := h.newAggregator(Config{})
+	ctx := context.Background()
+
+	taskIndex, _ := h.createTask(common.HexToHash("0x01"))
+	if err := a.scanTasks(ctx); err != nil {
+		t.Fatalf("scanTasks: %v", err)
+	}
+	task := a.tasks[taskIndex]
+	if task.Deadline == 0 {
+		t.Fatal("expected the task to carry the service manager's deadline")
+	}
+	submit := func(operatorId types.OperatorId) int {
+		t.Helper()
+		body := marshalTestResponse(t, newTestResponse(taskIndex, operatorId, "0x00000000000000000000000000000000000000aa", 10))
+		return submitTestResponse(t, a, h.state.ecdsaKey(operatorId), body).Code
+	}
+
+	if got := submit(timely); got != http.StatusOK {
+		t.Fatalf("status before deadline = %d, want %d", got, http.StatusOK)
+	}
+	// The chain's timestamps decide, not the local clock
+	for {
+		head, err := h.backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			t.Fatalf("HeaderByNumber: %v", err)
+		}
+		if head.Time > task.Deadline {
+			break
+		}
+		h.backend.Commit()
+	}
+	if got := submit(late); got != http.StatusConflict {
+		t.Fatalf("status after deadline = %d, want %d", got, http.StatusConflict)
+	}
+	if got := len(a.taskResponses[taskIndex]); got != 1 {
+		t.Fatalf("expected only the timely response to be stored, got %d", got)
+	}
+}
+
+func TestOperatorSignedResponsesVerify(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+	server := httptest.NewServer(a.httpHandler(nil))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	config := &lvrtypes.OperatorConfig{AggregatorURL: server.URL}
+	coord, err := operator.NewAuctionCoordinator(config, state.ecdsaKey(operatorId), state.keyPair(operatorId), nil, logger)
+	if err != nil {
+		t.Fatalf("NewAuctionCoordinator: %v", err)
+	}
+
+	response := &lvrtypes.TaskResponse{Winner: "0x00000000000000000000000000000000000000aa", WinningBid: big.NewInt(250)}
+	if err := coord.SubmitTaskResponse(3, response); err != nil {
+		t.Fatalf("SubmitTaskResponse: %v", err)
+	}
+
+	a.taskResponsesMux.RLock()
+	stored := a.taskResponses[3]
+	a.taskResponsesMux.RUnlock()
+	if len(stored) != 1 {
+		t.Fatalf("stored %d responses, want 1", len(stored))
+	}
+	if err := a.verifyResponse(context.Background(), stored[0]); err != nil {
+		t.Fatalf("operator signature did not verify: %v", err)
+	}
+}
+
+func TestSubmitResponseRejectedForFinalizedTask(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+
+	a.markTaskFinalized(4)
+	body := marshalTestResponse(t, newTestResponse(4, operatorId, "0x00000000000000000000000000000000000000aa", 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusGone {
+		t.Fatalf("status for a finalized task = %d, want %d", got, http.StatusGone)
+	}
+	if _, stored := a.taskResponses[4]; stored {
+		t.Fatal("expected the response to a finalized task not to be stored")
+	}
+}
+
+func TestSubmitResponseRejectedOutsideResponseWindow(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{ResponseWindowBlocks: 100}, state)
+	blocks := a.blockReader.(*fakeBlockReader)
+
+	a.AddTask(5, AuctionTask{TaskCreatedBlock: 1000})
+	a.AddTask(9, AuctionTask{TaskCreatedBlock: 1080})
+	blocks.setBlock(1150)
+
+	cases := []struct {
+		taskIndex uint32
+		want      int
+	}{
+		{5, http.StatusGone}, // created 150 blocks ago
+		{2, http.StatusGone}, // untracked, but older than task 5
+		{7, http.StatusOK},   // untracked, no older than task 9
+		{9, http.StatusOK},   // created 70 blocks ago
+		{12, http.StatusOK},  // newer than every tracked task
+	}
+	for _, tc := range cases {
+		body := marshalTestResponse(t, newTestResponse(tc.taskIndex, operatorId, "0x00000000000000000000000000000000000000aa", 10))
+		if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != tc.want {
+			t.Fatalf("status for task %d = %d, want %d", tc.taskIndex, got, tc.want)
+		}
+	}
+	if _, stored := a.taskResponses[5]; stored {
+		t.Fatal("expected the response to the old task not to be stored")
+	}
+}
@@ -0,0 +1,140 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// maxBatchResponses bounds the responses of a POST /submit-responses batch
+	maxBatchResponses = 100
+	// maxBatchBodySize bounds the size of a submitted batch
+	maxBatchBodySize = 4 << 20
+)
+
+// SubmissionResult is the outcome of one response of a POST /submit-responses
+// batch, with the HTTP status POST /submit-response would have answered it with
+type SubmissionResult struct {
+	// Index is the response's position in the batch
+	Index     int    `json:"index"`
+	TaskIndex uint32 `json:"taskIndex"`
+	Status    int    `json:"status"`
+	// Message is "success" or why the response was rejected
+	Message string `json:"message"`
+}
+
+// BatchSubmissionResponse is the body POST /submit-responses answers a batch with
+type BatchSubmissionResponse struct {
+	Accepted int                `json:"accepted"`
+	Rejected int                `json:"rejected"`
+	Results  []SubmissionResult `json:"results"`
+}
+
+func (a *Aggregator) handleBatchResponseSubmission(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Request body exceeds %d bytes", maxBatchBodySize), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	results, err := a.acceptTaskResponses(r.Context(), body, r.Header.Get(OperatorSignatureHeader), r.RemoteAddr)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var rejection *responseRejection
+		if errors.As(err, &rejection) {
+			status = rejection.status
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
+
+// acceptTaskResponses authenticates a signed batch of task responses and stores
+// the ones that pass the checks of acceptTaskResponse. The batch is rejected as a
+// whole only when it is unauthenticated, rate limited or not a JSON array;
+// otherwise every response is checked before any is stored, and the ones that
+// pass are stored together, none of them being kept if persisting one fails.
+func (a *Aggregator) acceptTaskResponses(ctx context.Context, body []byte, signature, remoteAddr string) (*BatchSubmissionResponse, error) {
+	operatorId, err := a.authenticateOperator(ctx, body, signature)
+	if err != nil {
+		a.logger.Warn("Rejecting unauthenticated task response batch", "remoteAddr", remoteAddr, "error", err)
+		return nil, authenticationRejection(err)
+	}
+	if !a.operatorLimiter.allow(operatorId.Hex()) {
+		a.logger.Warn("Rate limiting operator", "operatorId", operatorId.Hex(), "remoteAddr", remoteAddr)
+		return nil, &responseRejection{status: http.StatusTooManyRequests, message: "Too many requests"}
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, &responseRejection{status: http.StatusBadRequest, message: "Invalid JSON, expected an array of task responses"}
+	}
+	switch {
+	case len(items) == 0:
+		return nil, &responseRejection{status: http.StatusBadRequest, message: "Empty batch"}
+	case len(items) > maxBatchResponses:
+		return nil, &responseRejection{status: http.StatusBadRequest, message: fmt.Sprintf("Batch exceeds %d responses", maxBatchResponses)}
+	}
+
+	results := make([]SubmissionResult, len(items))
+	errs := make([]error, len(items))
+	var checked []SignedAuctionTaskResponse
+	var checkedIndexes []int
+	for i, item := range items {
+		signedResponse, err := decodeTaskResponse(item)
+		if err == nil {
+			signedResponse, err = a.checkTaskResponse(ctx, operatorId, signedResponse)
+		}
+		results[i] = SubmissionResult{Index: i, TaskIndex: signedResponse.ReferenceTaskIndex}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		checked = append(checked, signedResponse)
+		checkedIndexes = append(checkedIndexes, i)
+	}
+	for j, err := range a.storeTaskResponses(checked) {
+		errs[checkedIndexes[j]] = err
+	}
+
+	batch := &BatchSubmissionResponse{Results: results}
+	for i, err := range errs {
+		result := &batch.Results[i]
+		result.Status, result.Message = http.StatusOK, "success"
+		if err != nil {
+			result.Status, result.Message = http.StatusInternalServerError, err.Error()
+			var rejection *responseRejection
+			if errors.As(err, &rejection) {
+				result.Status = rejection.status
+			}
+			a.logger.Warn("Rejecting task response in batch",
+				"operatorId", operatorId.Hex(),
+				"index", i,
+				"taskIndex", result.TaskIndex,
+				"error", err,
+			)
+			batch.Rejected++
+		} else {
+			batch.Accepted++
+		}
+	}
+	return batch, nil
+}
@@ -0,0 +1,155 @@
+package aggregator
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+)
+
+// postTestBatch posts body to POST /submit-responses, signed with key when set
+func postTestBatch(t *testing.T, a *Aggregator, key *ecdsa.PrivateKey, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/submit-responses", bytes.NewReader(body))
+	if key != nil {
+		signature, err := operator.SignRequestBody(key, body)
+		if err != nil {
+			t.Fatalf("SignRequestBody: %v", err)
+		}
+		req.Header.Set(OperatorSignatureHeader, signature)
+	}
+
+	recorder := httptest.NewRecorder()
+	a.handleBatchResponseSubmission(recorder, req)
+	return recorder
+}
+
+// submitTestBatch posts a batch of raw responses signed with key
+func submitTestBatch(t *testing.T, a *Aggregator, key *ecdsa.PrivateKey, items ...json.RawMessage) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(items)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return postTestBatch(t, a, key, body)
+}
+
+func TestSubmitResponsesAcceptsValidItemsOfMixedBatch(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{}, state)
+
+	missingBid := newTestResponse(3, op1, winnerX, 0)
+	missingBid.WinningBid = nil
+	recorder := submitTestBatch(t, a, state.ecdsaKey(op1),
+		marshalTestResponse(t, newTestResponse(1, op1, winnerX, 100)),
+		marshalTestResponse(t, newTestResponse(2, op2, winnerX, 100)),
+		marshalTestResponse(t, missingBid),
+		json.RawMessage(`{"referenceTaskIndex": 4, "winningBid": 1, "bogus": true}`),
+		marshalTestResponse(t, newTestResponse(5, op1, winnerY, 200)),
+	)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+
+	var batch BatchSubmissionResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &batch); err != nil {
+		t.Fatalf("decode batch response: %v", err)
+	}
+	wantStatus := []int{http.StatusOK, http.StatusUnauthorized, http.StatusBadRequest, http.StatusBadRequest, http.StatusOK}
+	if batch.Accepted != 2 || batch.Rejected != 3 || len(batch.Results) != len(wantStatus) {
+		t.Fatalf("batch = %+v, want 2 accepted and 3 rejected", batch)
+	}
+	for i, want := range wantStatus {
+		if result := batch.Results[i]; result.Index != i || result.Status != want {
+			t.Fatalf("result %d = %+v, want status %d", i, result, want)
+		}
+	}
+	if batch.Results[4].TaskIndex != 5 || batch.Results[2].Message != "Missing winningBid" {
+		t.Fatalf("results = %+v, want task indexes and rejection messages reported", batch.Results)
+	}
+
+	// Only the accepted responses were stored
+	for taskIndex, want := range map[uint32]int{1: 1, 2: 0, 3: 0, 4: 0, 5: 1} {
+		if got := len(a.taskResponses[taskIndex]); got != want {
+			t.Fatalf("task %d has %d stored responses, want %d", taskIndex, got, want)
+		}
+	}
+}
+
+func TestSubmitResponsesRejectsWholeBatch(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+
+	response := marshalTestResponse(t, newTestResponse(1, op1, winnerX, 100))
+	if recorder := submitTestBatch(t, a, nil, response); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned batch status = %d, want 401", recorder.Code)
+	}
+	// A single response is not a batch
+	if recorder := postTestBatch(t, a, state.ecdsaKey(op1), response); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("non-array batch status = %d, want 400", recorder.Code)
+	}
+	if recorder := submitTestBatch(t, a, state.ecdsaKey(op1)); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("empty batch status = %d, want 400", recorder.Code)
+	}
+	if len(a.taskResponses) != 0 {
+		t.Fatalf("stored responses %v from rejected batches", a.taskResponses)
+	}
+}
+
+// failingSaveStore fails every Save after the first saves succeed
+type failingSaveStore struct {
+	memoryResponseStore
+	saves   int
+	succeed int
+}
+
+func (s *failingSaveStore) Save(uint32, SignedAuctionTaskResponse) error {
+	s.saves++
+	if s.saves > s.succeed {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func TestSubmitResponsesStoresAcceptedItemsTogether(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+	a.responseStore = &failingSaveStore{succeed: 1}
+
+	// The second response fails to persist after the first was saved
+	recorder := submitTestBatch(t, a, state.ecdsaKey(op1),
+		marshalTestResponse(t, newTestResponse(1, op1, winnerX, 100)),
+		marshalTestResponse(t, newTestResponse(2, op1, winnerX, 100)),
+		marshalTestResponse(t, newTestResponse(2, op1, winnerY, 200)),
+	)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+	var batch BatchSubmissionResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &batch); err != nil {
+		t.Fatalf("decode batch response: %v", err)
+	}
+	wantStatus := []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusConflict}
+	if batch.Accepted != 0 || batch.Rejected != 3 {
+		t.Fatalf("batch = %+v, want every response rejected", batch)
+	}
+	for i, want := range wantStatus {
+		if got := batch.Results[i].Status; got != want {
+			t.Fatalf("result %d status = %d, want %d", i, got, want)
+		}
+	}
+	for _, taskIndex := range []uint32{1, 2} {
+		if got := len(a.taskResponses[taskIndex]); got != 0 {
+			t.Fatalf("task %d kept %d responses of a batch that failed to persist", taskIndex, got)
+		}
+	}
+}
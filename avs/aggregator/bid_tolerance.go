@@ -0,0 +1,149 @@
+package aggregator
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BidTolerance groups responses whose winning bids differ by at most Wei, or by
+// at most Bps basis points of the larger bid, as agreeing on one result, so
+// honest operators rounding differently still reach consensus. The group's
+// result is the response reporting its stake-weighted median bid, and only the
+// operators that reported exactly that bid sign the attestation. The zero value
+// requires bids to be equal.
+type BidTolerance struct {
+	Bps uint64 `json:"bps"`
+	Wei uint64 `json:"wei"`
+}
+
+// enabled reports whether any difference between bids is tolerated
+func (t BidTolerance) enabled() bool {
+	return t.Bps > 0 || t.Wei > 0
+}
+
+// within reports whether bids x and y are close enough to agree
+func (t BidTolerance) within(x, y *big.Int) bool {
+	if compareBids(x, y) > 0 {
+		x, y = y, x
+	}
+	diff := new(big.Int)
+	if y != nil {
+		diff.Set(y)
+	}
+	if x != nil {
+		diff.Sub(diff, x)
+	}
+	if diff.Cmp(new(big.Int).SetUint64(t.Wei)) <= 0 {
+		return true
+	}
+	if t.Bps == 0 || y == nil {
+		return false
+	}
+	// diff / y <= bps / 10000, without rounding
+	relative := new(big.Int).Mul(y, new(big.Int).SetUint64(t.Bps))
+	return diff.Mul(diff, big.NewInt(10_000)).Cmp(relative) <= 0
+}
+
+// toleranceGroup identifies the responses a tolerance may merge, those agreeing
+// on everything but the winning bid
+type toleranceGroup struct {
+	taskIndex uint32
+	winner    common.Address
+	totalBids uint32
+}
+
+// clusterResponsesWithin groups responses by result as clusterResponses does,
+// then merges the clusters whose winning bids are within tolerance of the lowest
+// bid among them. A merged cluster reports the response of its stake-weighted
+// median bid. Clusters are ordered by when their first response was received.
+func clusterResponsesWithin(responses []SignedAuctionTaskResponse, tolerance BidTolerance, stakes map[types.OperatorId]*big.Int) []*responseCluster {
+	exact := clusterResponses(responses)
+	if !tolerance.enabled() {
+		return exact
+	}
+	assignStakes(exact, stakes)
+
+	arrival := make(map[*responseCluster]int, len(exact))
+	groups := make(map[toleranceGroup][]*responseCluster)
+	var order []toleranceGroup
+	for i, cluster := range exact {
+		arrival[cluster] = i
+		response := cluster.response
+		group := toleranceGroup{response.ReferenceTaskIndex, response.Winner, response.TotalBids}
+		if _, exists := groups[group]; !exists {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], cluster)
+	}
+
+	var merged []*responseCluster
+	first := make(map[*responseCluster]int)
+	for _, group := range order {
+		members := groups[group]
+		sortByBid(members)
+		for start := 0; start < len(members); {
+			end := start + 1
+			for end < len(members) && tolerance.within(members[start].response.WinningBid, members[end].response.WinningBid) {
+				end++
+			}
+			cluster := mergeClusters(members[start:end])
+			first[cluster] = arrival[members[start]]
+			for _, member := range members[start:end] {
+				first[cluster] = min(first[cluster], arrival[member])
+			}
+			merged = append(merged, cluster)
+			start = end
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool { return first[merged[i]] < first[merged[j]] })
+	return merged
+}
+
+// sortByBid orders clusters by winning bid, equal bids by their lowest operator id
+func sortByBid(clusters []*responseCluster) {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if order := compareBids(clusters[i].response.WinningBid, clusters[j].response.WinningBid); order != 0 {
+			return order < 0
+		}
+		return bytes.Compare(clusters[i].lowestOperatorId(), clusters[j].lowestOperatorId()) < 0
+	})
+}
+
+// mergeClusters merges clusters sorted by bid into one reporting the response of
+// their stake-weighted median bid, the lowest bid backed by at least half of
+// their stake. Operators count equally when none of them has stake.
+func mergeClusters(members []*responseCluster) *responseCluster {
+	if len(members) == 1 {
+		return members[0]
+	}
+
+	weights := make([]*big.Int, len(members))
+	total := new(big.Int)
+	for i, member := range members {
+		weights[i] = member.stake
+		total.Add(total, member.stake)
+	}
+	if total.Sign() == 0 {
+		for i, member := range members {
+			weights[i] = big.NewInt(int64(len(member.operators)))
+			total.Add(total, weights[i])
+		}
+	}
+
+	merged := &responseCluster{stake: new(big.Int)}
+	cumulative := new(big.Int)
+	for i, member := range members {
+		cumulative.Add(cumulative, weights[i])
+		if merged.response == nil && new(big.Int).Lsh(cumulative, 1).Cmp(total) >= 0 {
+			merged.response = member.response
+		}
+		merged.operators = append(merged.operators, member.operators...)
+		merged.stake.Add(merged.stake, member.stake)
+	}
+	return merged
+}
@@ -0,0 +1,130 @@
+package aggregator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBidToleranceWithin(t *testing.T) {
+	cases := []struct {
+		name      string
+		tolerance BidTolerance
+		x, y      int64
+		want      bool
+	}{
+		{"exact without tolerance", BidTolerance{}, 1000, 1000, true},
+		{"1 wei apart without tolerance", BidTolerance{}, 1000, 1001, false},
+		{"1 wei apart", BidTolerance{Wei: 1}, 1001, 1000, true},
+		{"2 wei apart", BidTolerance{Wei: 1}, 1000, 1002, false},
+		// 100 is 0.9999 bps of 1000100
+		{"within 1 bps of the larger bid", BidTolerance{Bps: 1}, 1_000_000, 1_000_100, true},
+		{"beyond 1 bps of the larger bid", BidTolerance{Bps: 1}, 1_000_000, 1_000_101, false},
+		{"either bound", BidTolerance{Bps: 1, Wei: 5}, 10, 15, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.tolerance.within(big.NewInt(tc.x), big.NewInt(tc.y)); got != tc.want {
+				t.Fatalf("within(%d, %d) = %v, want %v", tc.x, tc.y, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestToleranceClusterReportsStakeWeightedMedianBid(t *testing.T) {
+	small, large, other := types.OperatorId{1}, types.OperatorId{2}, types.OperatorId{3}
+	stakes := map[types.OperatorId]*big.Int{small: big.NewInt(100), large: big.NewInt(300), other: big.NewInt(100)}
+	responses := []SignedAuctionTaskResponse{
+		newTestResponse(1, small, winnerX, 1000),
+		newTestResponse(1, large, winnerX, 1001),
+		newTestResponse(1, other, winnerX, 1002),
+	}
+
+	clusters := clusterResponsesWithin(responses, BidTolerance{Wei: 2}, stakes)
+	if len(clusters) != 1 || len(clusters[0].operators) != 3 || clusters[0].stake.Int64() != 500 {
+		t.Fatalf("clusters = %+v, want the three bids in one cluster of stake 500", clusters)
+	}
+	if bid := clusters[0].response.WinningBid; bid.Int64() != 1001 {
+		t.Fatalf("cluster bid = %s, want the stake-weighted median 1001", bid)
+	}
+
+	// Bids are compared with the lowest bid of a cluster, not chained
+	clusters = clusterResponsesWithin(responses, BidTolerance{Wei: 1}, stakes)
+	if len(clusters) != 2 || clusters[0].response.WinningBid.Int64() != 1001 || clusters[1].response.WinningBid.Int64() != 1002 {
+		t.Fatalf("clusters = %+v, want 1000 and 1001 merged and 1002 apart", clusters)
+	}
+}
+
+func TestConsensusConvergesOnBidsOneWeiApart(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 150)
+	responses := func() []SignedAuctionTaskResponse {
+		return []SignedAuctionTaskResponse{
+			newSignedTestResponse(t, state, 1, op1, winnerX, 1000),
+			newSignedTestResponse(t, state, 1, op2, winnerX, 1001),
+			newSignedTestResponse(t, state, 1, op3, winnerY, 1000),
+		}
+	}
+
+	// Exact equality leaves no result backed by a majority of the 350 stake
+	exact := newTestAggregator(t, Config{ConsensusStrategy: ConsensusStakeMajority}, state)
+	if exact.processCompletedTask(context.Background(), 1, responses()) {
+		t.Fatal("expected no consensus without a bid tolerance")
+	}
+
+	a := newTestAggregator(t, Config{ConsensusStrategy: ConsensusStakeMajority, BidTolerance: BidTolerance{Wei: 1}}, state)
+	if !a.processCompletedTask(context.Background(), 1, responses()) {
+		t.Fatal("expected bids 1 wei apart to reach consensus")
+	}
+	result := a.consensusResults[1]
+	if result.Winner != common.HexToAddress(winnerX) || result.WinningBid.Int64() != 1000 {
+		t.Fatalf("consensus = %s with %s, want %s with the median bid 1000", result.Winner.Hex(), result.WinningBid, winnerX)
+	}
+	// Only the operator that signed the median bid is in the attestation, while
+	// both agreeing operators are credited with agreement
+	if result.Signers != 1 || len(result.MismatchedOperators) != 1 || result.MismatchedOperators[0] != op3.Hex() {
+		t.Fatalf("signers = %d, mismatched = %v; want 1 signer and only %s mismatched", result.Signers, result.MismatchedOperators, op3.Hex())
+	}
+}
+
+func TestToleranceConsensusRequiresSignersToMeetQuorum(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+	config := Config{QuorumThreshold: 60, BidTolerance: BidTolerance{Wei: 2}}
+
+	// All three bids agree within tolerance, but only the operator that signed
+	// the median bid could be in the attestation
+	spread := newTestAggregator(t, config, state)
+	spreadResponder := spread.avsWriter.(*fakeTaskResponder)
+	if spread.processCompletedTask(context.Background(), 1, []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, op1, winnerX, 1000),
+		newSignedTestResponse(t, state, 1, op2, winnerX, 1001),
+		newSignedTestResponse(t, state, 1, op3, winnerX, 1002),
+	}) {
+		t.Fatal("expected no consensus when the signers of the median bid fall short of quorum")
+	}
+	if spreadResponder.attempts != 0 {
+		t.Fatalf("consensus submitted %d times, want none below the signer quorum", spreadResponder.attempts)
+	}
+
+	// Two of three sign the median bid, which is quorum on its own
+	a := newTestAggregator(t, config, state)
+	responder := a.avsWriter.(*fakeTaskResponder)
+	if !a.processCompletedTask(context.Background(), 1, []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, op1, winnerX, 1000),
+		newSignedTestResponse(t, state, 1, op2, winnerX, 1000),
+		newSignedTestResponse(t, state, 1, op3, winnerX, 1001),
+	}) {
+		t.Fatal("expected consensus when the signers of the median bid meet quorum")
+	}
+	if responder.attempts != 1 || a.consensusResults[1].Signers != 2 {
+		t.Fatalf("consensus submitted %d times with %d signers, want once with 2", responder.attempts, a.consensusResults[1].Signers)
+	}
+}
@@ -0,0 +1,150 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/types"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+var (
+	// ErrMissingSignature is returned when a response carries no BLS signature
+	ErrMissingSignature = errors.New("missing bls signature")
+	// ErrInvalidSignature is returned when a BLS signature doesn't match the operator's pubkey
+	ErrInvalidSignature = errors.New("invalid bls signature")
+)
+
+// SignedAttestation is the BLS attestation over a task's consensus response,
+// in the form expected by the service manager contract
+type SignedAttestation struct {
+	// AggregatedSignature is the sum of the signers' signatures over the consensus response
+	AggregatedSignature *bls.Signature
+	// SignerIds are the operators whose signatures are included in the aggregate
+	SignerIds []types.OperatorId
+	// NonSignerIds and NonSignerPubkeys identify the operators in the task's quorums
+	// that did not sign the consensus response
+	NonSignerIds     []types.OperatorId
+	NonSignerPubkeys []*bls.G1Point
+}
+
+// EncodeForSigning returns the canonical encoding of the response, whose keccak256
+// is the digest operators sign
+func (r AuctionTaskResponse) EncodeForSigning() []byte {
+	return lvrtypes.EncodeTaskResponse(r.ReferenceTaskIndex, r.Winner, r.WinningBid, r.TotalBids)
+}
+
+// ResponseDigest returns the message operators sign for a task response
+func ResponseDigest(response AuctionTaskResponse) ([32]byte, error) {
+	return lvrtypes.TaskResponseDigest(response.ReferenceTaskIndex, response.Winner, response.WinningBid, response.TotalBids)
+}
+
+// verifyResponse checks a response's BLS signature against the operator's registered pubkey
+func (a *Aggregator) verifyResponse(ctx context.Context, response SignedAuctionTaskResponse) error {
+	if response.BlsSignature == nil || response.BlsSignature.G1Point == nil {
+		return ErrMissingSignature
+	}
+
+	pubkeys, err := a.avsReader.GetOperatorPubkeys(ctx, response.OperatorId)
+	if err != nil {
+		return fmt.Errorf("failed to get operator pubkeys: %w", err)
+	}
+
+	digest, err := ResponseDigest(response.AuctionTaskResponse)
+	if err != nil {
+		return err
+	}
+
+	ok, err := response.BlsSignature.Verify(pubkeys.G2Pubkey, digest)
+	if err != nil || !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// filterValidResponses returns the responses whose BLS signatures verify, logging
+// and returning the rejected ones separately
+func (a *Aggregator) filterValidResponses(ctx context.Context, taskIndex uint32, responses []SignedAuctionTaskResponse) (valid, invalid []SignedAuctionTaskResponse) {
+	for _, response := range responses {
+		if err := a.verifyResponse(ctx, response); err != nil {
+			a.logger.Warn("Rejecting task response",
+				"taskIndex", taskIndex,
+				"operatorId", response.OperatorId.Hex(),
+				"error", err,
+			)
+			invalid = append(invalid, response)
+			continue
+		}
+		valid = append(valid, response)
+	}
+	return valid, invalid
+}
+
+// aggregateSignatures builds the attestation for consensus from the verified
+// responses that agree with it. Every other operator registered in the task's
+// quorums is reported as a non-signer.
+func (a *Aggregator) aggregateSignatures(ctx context.Context, taskIndex uint32, consensus AuctionTaskResponse, responses []SignedAuctionTaskResponse) (*SignedAttestation, error) {
+	consensusDigest, err := ResponseDigest(consensus)
+	if err != nil {
+		return nil, err
+	}
+
+	attestation := &SignedAttestation{AggregatedSignature: bls.NewZeroSignature()}
+	signed := make(map[types.OperatorId]bool)
+	for _, response := range responses {
+		digest, err := ResponseDigest(response.AuctionTaskResponse)
+		if err != nil || digest != consensusDigest || signed[response.OperatorId] {
+			continue
+		}
+		signed[response.OperatorId] = true
+		attestation.AggregatedSignature.Add(response.BlsSignature)
+		attestation.SignerIds = append(attestation.SignerIds, response.OperatorId)
+	}
+
+	stakes, err := a.taskStakes(ctx, taskIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator set: %w", err)
+	}
+
+	nonSigners := make([]types.OperatorId, 0, len(stakes))
+	for operatorId := range stakes {
+		if !signed[operatorId] {
+			nonSigners = append(nonSigners, operatorId)
+		}
+	}
+	sort.Slice(nonSigners, func(i, j int) bool {
+		return bytes.Compare(nonSigners[i][:], nonSigners[j][:]) < 0
+	})
+
+	for _, operatorId := range nonSigners {
+		pubkeys, err := a.avsReader.GetOperatorPubkeys(ctx, operatorId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get non-signer pubkeys: %w", err)
+		}
+		attestation.NonSignerIds = append(attestation.NonSignerIds, operatorId)
+		attestation.NonSignerPubkeys = append(attestation.NonSignerPubkeys, pubkeys.G1Pubkey)
+	}
+
+	return attestation, nil
+}
+
+// signersMeetQuorum checks the operators whose signatures are in the attestation
+// against the task's quorum on their own
+func (a *Aggregator) signersMeetQuorum(ctx context.Context, taskIndex uint32, attestation *SignedAttestation, responses []SignedAuctionTaskResponse) (bool, error) {
+	signed := make(map[types.OperatorId]bool, len(attestation.SignerIds))
+	for _, operatorId := range attestation.SignerIds {
+		signed[operatorId] = true
+	}
+	var signers []SignedAuctionTaskResponse
+	for _, response := range responses {
+		if signed[response.OperatorId] {
+			signers = append(signers, response)
+		}
+	}
+	return a.meetsQuorum(ctx, taskIndex, signers)
+}
@@ -0,0 +1,160 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyResponseRejectsTamperedSignatures(t *testing.T) {
+	state := newFakeOperatorState()
+	operator := state.addOperator(1, 100)
+	other := state.addOperator(2, 100)
+	agg := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	ctx := context.Background()
+	winner := "0x00000000000000000000000000000000000000aa"
+
+	valid := newSignedTestResponse(t, state, 1, operator, winner, 100)
+	if err := agg.verifyResponse(ctx, valid); err != nil {
+		t.Fatalf("valid response rejected: %v", err)
+	}
+
+	tampered := newSignedTestResponse(t, state, 1, operator, winner, 100)
+	tampered.WinningBid = big.NewInt(1)
+	if err := agg.verifyResponse(ctx, tampered); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature for tampered bid, got %v", err)
+	}
+
+	impersonated := newSignedTestResponse(t, state, 1, other, winner, 100)
+	impersonated.OperatorId = operator
+	if err := agg.verifyResponse(ctx, impersonated); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature for another operator's signature, got %v", err)
+	}
+
+	unsigned := newTestResponse(1, operator, winner, 100)
+	if err := agg.verifyResponse(ctx, unsigned); err != ErrMissingSignature {
+		t.Fatalf("expected ErrMissingSignature, got %v", err)
+	}
+}
+
+func TestInvalidResponsesRejectedBeforeConsensus(t *testing.T) {
+	state := newFakeOperatorState()
+	honest1 := state.addOperator(1, 30)
+	honest2 := state.addOperator(2, 30)
+	forger := state.addOperator(3, 40)
+	agg := newTestAggregator(t, Config{QuorumThreshold: 60}, state)
+	ctx := context.Background()
+
+	honestWinner := "0x00000000000000000000000000000000000000aa"
+	forgedWinner := "0x00000000000000000000000000000000000000bb"
+
+	// The forger signs one response but submits a different one under two identities
+	forged := newSignedTestResponse(t, state, 1, forger, honestWinner, 100)
+	forged.Winner = newTestResponse(1, forger, forgedWinner, 100).Winner
+	impersonated := forged
+	impersonated.OperatorId = honest2
+
+	agg.taskResponses[1] = []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, honest1, honestWinner, 100),
+		forged,
+		impersonated,
+	}
+
+	// Quorum is met by raw responses but not once invalid signatures are discarded
+	agg.checkAndProcessCompletedTasks(ctx)
+	if agg.finalizedTasks[1] {
+		t.Fatal("task finalized on forged responses")
+	}
+	if got := len(agg.taskResponses[1]); got != 1 {
+		t.Fatalf("expected invalid responses to be discarded, %d remain", got)
+	}
+
+	agg.taskResponses[1] = append(agg.taskResponses[1], newSignedTestResponse(t, state, 1, honest2, honestWinner, 100))
+	agg.checkAndProcessCompletedTasks(ctx)
+	if !agg.finalizedTasks[1] {
+		t.Fatal("task not finalized after enough valid responses")
+	}
+}
+
+func TestAggregateSignatures(t *testing.T) {
+	state := newFakeOperatorState()
+	signer1 := state.addOperator(1, 10)
+	signer2 := state.addOperator(2, 10)
+	dissenter := state.addOperator(3, 10)
+	absent := state.addOperator(4, 10)
+	agg := newTestAggregator(t, Config{}, state)
+	ctx := context.Background()
+	winner := "0x00000000000000000000000000000000000000aa"
+
+	responses := []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, signer1, winner, 100),
+		newSignedTestResponse(t, state, 1, signer2, winner, 100),
+		newSignedTestResponse(t, state, 1, dissenter, winner, 99),
+	}
+
+	attestation, err := agg.aggregateSignatures(ctx, 1, responses[0].AuctionTaskResponse, responses)
+	if err != nil {
+		t.Fatalf("aggregateSignatures: %v", err)
+	}
+
+	if len(attestation.SignerIds) != 2 {
+		t.Fatalf("expected 2 signers, got %d", len(attestation.SignerIds))
+	}
+	wantNonSigners := []types.OperatorId{dissenter, absent}
+	if len(attestation.NonSignerIds) != len(wantNonSigners) {
+		t.Fatalf("expected non-signers %v, got %v", wantNonSigners, attestation.NonSignerIds)
+	}
+	for i, id := range wantNonSigners {
+		if attestation.NonSignerIds[i] != id {
+			t.Fatalf("expected non-signers %v, got %v", wantNonSigners, attestation.NonSignerIds)
+		}
+	}
+
+	aggregatedPubkey := bls.NewZeroG2Point()
+	aggregatedPubkey.Add(state.keyPair(signer1).GetPubKeyG2())
+	aggregatedPubkey.Add(state.keyPair(signer2).GetPubKeyG2())
+
+	digest, _ := ResponseDigest(responses[0].AuctionTaskResponse)
+	ok, err := attestation.AggregatedSignature.Verify(aggregatedPubkey, digest)
+	if err != nil || !ok {
+		t.Fatalf("aggregated signature does not verify: %v", err)
+	}
+}
+
+func TestEncodeForSigningIsStable(t *testing.T) {
+	bid, _ := new(big.Int).SetString("1000000000000000000", 10)
+	response := AuctionTaskResponse{
+		ReferenceTaskIndex: 7,
+		Winner:             common.HexToAddress("0x00000000000000000000000000000000000000aa"),
+		WinningBid:         bid,
+		TotalBids:          3,
+	}
+
+	// abi.encodePacked(uint32(7), address(0xaa), uint256(1e18), uint32(3))
+	const wantEncoding = "00000007" +
+		"00000000000000000000000000000000000000aa" +
+		"0000000000000000000000000000000000000000000000000de0b6b3a7640000" +
+		"00000003"
+	if got := hex.EncodeToString(response.EncodeForSigning()); got != wantEncoding {
+		t.Fatalf("encoding = %s, want %s", got, wantEncoding)
+	}
+
+	const wantDigest = "cce4dec04975c9002e2937c185b1f6a65869d8dbb23900872b7e61e2e20d757e"
+	digest, err := ResponseDigest(response)
+	if err != nil {
+		t.Fatalf("ResponseDigest: %v", err)
+	}
+	if got := hex.EncodeToString(digest[:]); got != wantDigest {
+		t.Fatalf("digest = %s, want %s", got, wantDigest)
+	}
+
+	// Encoding does not modify the response's bid
+	if response.EncodeForSigning(); response.WinningBid.Cmp(bid) != 0 {
+		t.Fatalf("winning bid changed to %s", response.WinningBid)
+	}
+}
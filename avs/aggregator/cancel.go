@@ -0,0 +1,37 @@
+package aggregator
+
+import "fmt"
+
+// CancelTask cancels a task whose auction was cancelled, such as when the block it
+// was created in is reorged out. Its stored responses are dropped, later responses
+// are rejected and the cancellation is streamed to the subscribed operators so
+// they abstain. Cancelling a cancelled task has no effect; a task whose consensus
+// was already processed can't be cancelled.
+func (a *Aggregator) CancelTask(taskIndex uint32, reason string) error {
+	a.taskResponsesMux.Lock()
+	if _, cancelled := a.cancelledTasks[taskIndex]; cancelled {
+		a.taskResponsesMux.Unlock()
+		return nil
+	}
+	if a.finalizedTasks[taskIndex] {
+		a.taskResponsesMux.Unlock()
+		return fmt.Errorf("task %d is already finalized", taskIndex)
+	}
+	a.cancelledTasks[taskIndex] = reason
+	dropped := len(a.taskResponses[taskIndex])
+	a.taskResponsesMux.Unlock()
+
+	a.logger.Warn("Task cancelled, dropping its responses",
+		"taskIndex", taskIndex,
+		"reason", reason,
+		"responses", dropped,
+	)
+	a.markTaskFinalized(taskIndex)
+	a.lvrMetrics.observeFailure(failureCancelled)
+
+	a.tasksMux.RLock()
+	task := a.tasks[taskIndex]
+	a.tasksMux.RUnlock()
+	a.publish(StreamedTask{TaskIndex: taskIndex, AuctionTask: task, Cancelled: true})
+	return nil
+}
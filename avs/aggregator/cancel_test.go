@@ -0,0 +1,82 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCancelTaskAfterPartialResponses(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 60}, state)
+	responder := a.avsWriter.(*fakeTaskResponder)
+	ctx := context.Background()
+
+	tasks := a.subscribeTasks()
+	defer a.unsubscribeTasks(tasks)
+	a.AddTask(1, AuctionTask{TaskCreatedBlock: 10})
+	<-tasks
+
+	// One of three operators responds before the task's block is reorged out
+	body := marshalTestResponse(t, newSignedTestResponse(t, state, 1, op1, winnerX, 100))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op1), body).Code; got != http.StatusOK {
+		t.Fatalf("submitting a response = %d, want 200", got)
+	}
+
+	if err := a.CancelTask(1, "reorged"); err != nil {
+		t.Fatalf("CancelTask: %v", err)
+	}
+	if responses := a.taskResponses[1]; len(responses) != 0 {
+		t.Fatalf("%d responses stored after cancellation, want none", len(responses))
+	}
+	select {
+	case streamed := <-tasks:
+		if streamed.TaskIndex != 1 || !streamed.Cancelled || streamed.TaskCreatedBlock != 10 {
+			t.Fatalf("streamed %+v, want task 1 cancelled", streamed)
+		}
+	default:
+		t.Fatal("expected the cancellation to be streamed to subscribers")
+	}
+
+	// Responses arriving after the cancellation would have met quorum
+	for _, operatorId := range []types.OperatorId{op2, op3} {
+		body := marshalTestResponse(t, newSignedTestResponse(t, state, 1, operatorId, winnerX, 100))
+		if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusGone {
+			t.Fatalf("submitting a response to the cancelled task = %d, want 410", got)
+		}
+	}
+	a.checkAndProcessCompletedTasks(ctx)
+	if responder.attempts != 0 {
+		t.Fatalf("consensus submitted %d times for a cancelled task", responder.attempts)
+	}
+
+	recorder := httptest.NewRecorder()
+	a.handleGetTask(recorder, httptest.NewRequest(http.MethodGet, "/task/1", nil))
+	var status TaskStatus
+	if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if status.Status != TaskStatusCancelled || status.Error != "reorged" {
+		t.Fatalf("task status = %+v, want cancelled for reorged", status)
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.consensusFailures.WithLabelValues(failureCancelled)); got != 1 {
+		t.Fatalf("cancelled failures = %v, want 1", got)
+	}
+
+	// Cancelling again has no effect, a finalized task can't be cancelled
+	if err := a.CancelTask(1, "reorged"); err != nil {
+		t.Fatalf("CancelTask of a cancelled task: %v", err)
+	}
+	a.markTaskFinalized(2)
+	if err := a.CancelTask(2, "reorged"); err == nil {
+		t.Fatal("expected cancelling a finalized task to fail")
+	}
+}
@@ -0,0 +1,473 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// mockStakeRegistryCode is the runtime code of a minimal stake registry. A call
+// with 64 bytes of calldata, an operator id and a stake, registers the operator
+// with that stake and a call with 32 bytes, an operator id, returns its stake.
+//
+//	PUSH1 0x40 CALLDATASIZE EQ PUSH1 0x13 JUMPI
+//	PUSH1 0x00 CALLDATALOAD SLOAD PUSH1 0x00 MSTORE PUSH1 0x20 PUSH1 0x00 RETURN
+//	JUMPDEST PUSH1 0x20 CALLDATALOAD PUSH1 0x00 CALLDATALOAD SSTORE STOP
+var mockStakeRegistryCode = common.FromHex("0x604036146013576000355460005260206000f35b6020356000355500")
+
+// mockServiceManagerCode is the runtime code of a minimal service manager that
+// accepts any call and logs its calldata, so respondToTask calls can be decoded
+// from the receipts. Calldata of whole words is emitted as an event instead, its
+// first two words the topics and the rest the data, so tasks can be created.
+//
+//	CALLDATASIZE PUSH1 0x1f AND PUSH1 0x1e JUMPI
+//	PUSH1 0x40 CALLDATASIZE SUB PUSH1 0x40 PUSH1 0x00 CALLDATACOPY
+//	PUSH1 0x20 CALLDATALOAD PUSH1 0x00 CALLDATALOAD
+//	PUSH1 0x40 CALLDATASIZE SUB PUSH1 0x00 LOG2 STOP
+//	JUMPDEST CALLDATASIZE PUSH1 0x00 PUSH1 0x00 CALLDATACOPY CALLDATASIZE PUSH1 0x00 LOG0 STOP
+var mockServiceManagerCode = common.FromHex("0x36601f16601e57604036036040600037602035600035604036036000a2005b366000600037366000a000")
+
+// mockServiceManagerABI mirrors the respondToTask function the aggregator's chain
+// writer calls on the LVRAuctionServiceManager
+var mockServiceManagerABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(`[{
+		"type": "function",
+		"name": "respondToTask",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "taskIndex", "type": "uint32"},
+			{"name": "winner", "type": "address"},
+			{"name": "winningBid", "type": "uint256"},
+			{"name": "signature", "type": "bytes"}
+		],
+		"outputs": []
+	}]`))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// deploymentCode wraps runtime code in init code that returns it
+func deploymentCode(runtime []byte) []byte {
+	size := byte(len(runtime))
+	// PUSH1 size PUSH1 0x0c PUSH1 0x00 CODECOPY PUSH1 size PUSH1 0x00 RETURN
+	init := []byte{0x60, size, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, size, 0x60, 0x00, 0xf3}
+	return append(init, runtime...)
+}
+
+// chainHarness is a simulated chain with a mock stake registry and service
+// manager deployed, for running the aggregator's task, consensus and submission
+// flow end to end against mined transactions
+type chainHarness struct {
+	t       testing.TB
+	backend *backends.SimulatedBackend
+	chainID *big.Int
+	// deployer deploys the mock contracts and registers operators
+	deployer *ecdsa.PrivateKey
+	// aggregatorKey signs the aggregator's respondToTask transactions
+	aggregatorKey *ecdsa.PrivateKey
+
+	stakeRegistry  common.Address
+	serviceManager common.Address
+
+	// state holds the BLS and ECDSA keys of the registered operators
+	state *fakeOperatorState
+
+	mutex     sync.Mutex
+	operators []types.OperatorId
+	// tasks is the number of tasks created on the service manager
+	tasks uint32
+}
+
+// newChainHarness starts a simulated chain with funded deployer and aggregator
+// accounts and deploys the mock contracts, closing the chain when t ends
+func newChainHarness(t testing.TB) *chainHarness {
+	t.Helper()
+	deployer, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	aggregatorKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	funds := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(deployer.PublicKey):      {Balance: funds},
+		crypto.PubkeyToAddress(aggregatorKey.PublicKey): {Balance: funds},
+	}, 30_000_000)
+	t.Cleanup(func() { backend.Close() })
+
+	h := &chainHarness{
+		t:             t,
+		backend:       backend,
+		chainID:       backend.Blockchain().Config().ChainID,
+		deployer:      deployer,
+		aggregatorKey: aggregatorKey,
+		state:         newFakeOperatorState(),
+	}
+	h.stakeRegistry = h.deploy(mockStakeRegistryCode)
+	h.serviceManager = h.deploy(mockServiceManagerCode)
+	return h
+}
+
+// deploy deploys a contract with the given runtime code from the deployer account
+func (h *chainHarness) deploy(runtime []byte) common.Address {
+	h.t.Helper()
+	opts, err := bind.NewKeyedTransactorWithChainID(h.deployer, h.chainID)
+	if err != nil {
+		h.t.Fatalf("NewKeyedTransactorWithChainID: %v", err)
+	}
+	address, tx, _, err := bind.DeployContract(opts, abi.ABI{}, deploymentCode(runtime), h.backend)
+	if err != nil {
+		h.t.Fatalf("DeployContract: %v", err)
+	}
+	h.mine(tx)
+	return address
+}
+
+// mine commits a block with the pending transactions and returns tx's receipt,
+// failing the test if it reverted
+func (h *chainHarness) mine(tx *gethtypes.Transaction) *gethtypes.Receipt {
+	h.t.Helper()
+	h.backend.Commit()
+	receipt, err := h.backend.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		h.t.Fatalf("TransactionReceipt(%s): %v", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+		h.t.Fatalf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return receipt
+}
+
+// registerOperator registers an operator with fresh keys and the given stake in
+// the stake registry
+func (h *chainHarness) registerOperator(id byte, stake int64) types.OperatorId {
+	h.t.Helper()
+	operatorId := h.state.addOperator(id, stake)
+	h.setStake(operatorId, stake)
+
+	h.mutex.Lock()
+	h.operators = append(h.operators, operatorId)
+	h.mutex.Unlock()
+	return operatorId
+}
+
+// setStake updates a registered operator's stake in the stake registry
+func (h *chainHarness) setStake(operatorId types.OperatorId, stake int64) {
+	h.t.Helper()
+	h.mine(h.transact(h.stakeRegistry, append(operatorId[:], common.BigToHash(big.NewInt(stake)).Bytes()...)))
+}
+
+// createTask emits a NewTaskCreated event for the next task from the service
+// manager, as LVRAuctionServiceManager does when an auction starts
+func (h *chainHarness) createTask(poolId [32]byte) (uint32, *gethtypes.Receipt) {
+	h.t.Helper()
+	h.mutex.Lock()
+	taskIndex := h.tasks
+	h.tasks++
+	h.mutex.Unlock()
+
+	block := h.backend.Blockchain().CurrentBlock()
+	var event newTaskCreatedEvent
+	event.Task.AuctionId = crypto.Keccak256Hash(poolId[:], block.Number.Bytes())
+	event.Task.PoolId = poolId
+	event.Task.TaskCreatedBlock = uint32(block.Number.Uint64() + 1)
+	event.Task.Deadline = new(big.Int).SetUint64(block.Time + 60)
+
+	newTaskCreated := serviceManagerEvents.Events["NewTaskCreated"]
+	data, err := newTaskCreated.Inputs.NonIndexed().Pack(event.Task)
+	if err != nil {
+		h.t.Fatalf("Pack NewTaskCreated: %v", err)
+	}
+	topics := append(newTaskCreated.ID.Bytes(), common.BigToHash(big.NewInt(int64(taskIndex))).Bytes()...)
+	return taskIndex, h.mine(h.transact(h.serviceManager, append(topics, data...)))
+}
+
+// transact sends a call with data to a contract from the deployer account, to be
+// mined with the next block
+func (h *chainHarness) transact(to common.Address, data []byte) *gethtypes.Transaction {
+	h.t.Helper()
+	ctx := context.Background()
+	from := crypto.PubkeyToAddress(h.deployer.PublicKey)
+	nonce, err := h.backend.PendingNonceAt(ctx, from)
+	if err != nil {
+		h.t.Fatalf("PendingNonceAt: %v", err)
+	}
+	gasPrice, err := h.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		h.t.Fatalf("SuggestGasPrice: %v", err)
+	}
+
+	tx, err := gethtypes.SignNewTx(h.deployer, gethtypes.LatestSignerForChainID(h.chainID), &gethtypes.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Gas:      100_000,
+		GasPrice: gasPrice,
+		Data:     data,
+	})
+	if err != nil {
+		h.t.Fatalf("SignNewTx: %v", err)
+	}
+	if err := h.backend.SendTransaction(ctx, tx); err != nil {
+		h.t.Fatalf("SendTransaction: %v", err)
+	}
+	return tx
+}
+
+// newAggregator builds an aggregator with in-memory dependencies that reads
+// operator stakes from the stake registry and submits consensus to the service
+// manager
+func (h *chainHarness) newAggregator(config Config) *Aggregator {
+	h.t.Helper()
+	config.ContractAddresses = lvrtypes.ContractAddresses{
+		lvrtypes.ContractServiceManager: h.serviceManager.Hex(),
+		lvrtypes.ContractStakeRegistry:  h.stakeRegistry.Hex(),
+	}
+	a := newTestAggregator(h.t, config, h.state)
+	a.avsReader = &chainOperatorState{fakeOperatorState: h.state, harness: h}
+	a.avsWriter = &chainTaskResponder{harness: h}
+	a.blockReader = h
+	a.headers = h.backend
+	a.taskEvents = h
+	return a
+}
+
+// BlockNumber returns the height of the simulated chain
+func (h *chainHarness) BlockNumber(ctx context.Context) (uint64, error) {
+	return h.backend.Blockchain().CurrentBlock().Number.Uint64(), nil
+}
+
+// FilterLogs filters the simulated chain's logs
+func (h *chainHarness) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]gethtypes.Log, error) {
+	return h.backend.FilterLogs(ctx, query)
+}
+
+// SubscribeFilterLogs subscribes to the simulated chain's logs
+func (h *chainHarness) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- gethtypes.Log) (ethereum.Subscription, error) {
+	return h.backend.SubscribeFilterLogs(ctx, query, ch)
+}
+
+// stakeOf reads an operator's stake from the stake registry
+func (h *chainHarness) stakeOf(ctx context.Context, operatorId types.OperatorId) (*big.Int, error) {
+	output, err := h.backend.CallContract(ctx, ethereum.CallMsg{To: &h.stakeRegistry, Data: operatorId[:]}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(output), nil
+}
+
+// respondToTaskCall is a respondToTask call received by the service manager
+type respondToTaskCall struct {
+	TaskIndex  uint32
+	Winner     common.Address
+	WinningBid *big.Int
+	Signature  []byte
+	TxHash     common.Hash
+}
+
+// respondToTaskCalls decodes the respondToTask calls the service manager logged,
+// in the order they were mined
+func (h *chainHarness) respondToTaskCalls() []respondToTaskCall {
+	h.t.Helper()
+	logs, err := h.backend.FilterLogs(context.Background(), ethereum.FilterQuery{
+		FromBlock: big.NewInt(0),
+		Addresses: []common.Address{h.serviceManager},
+	})
+	if err != nil {
+		h.t.Fatalf("FilterLogs: %v", err)
+	}
+
+	method := mockServiceManagerABI.Methods["respondToTask"]
+	calls := make([]respondToTaskCall, 0, len(logs))
+	for _, log := range logs {
+		// Logs with topics are events, such as NewTaskCreated
+		if len(log.Topics) > 0 {
+			continue
+		}
+		if len(log.Data) < 4 || !bytes.Equal(log.Data[:4], method.ID) {
+			h.t.Fatalf("service manager called with unknown calldata %x", log.Data)
+		}
+		values, err := method.Inputs.Unpack(log.Data[4:])
+		if err != nil {
+			h.t.Fatalf("Unpack respondToTask calldata: %v", err)
+		}
+		calls = append(calls, respondToTaskCall{
+			TaskIndex:  values[0].(uint32),
+			Winner:     values[1].(common.Address),
+			WinningBid: values[2].(*big.Int),
+			Signature:  values[3].([]byte),
+			TxHash:     log.TxHash,
+		})
+	}
+	return calls
+}
+
+// chainOperatorState reads operator stakes from the harness's stake registry,
+// with identities and pubkeys from its in-memory operator keys. The simulated
+// chain only executes calls at its head, so stakes are read at the current block
+// whatever block is requested, and every operator is in every quorum.
+type chainOperatorState struct {
+	*fakeOperatorState
+	harness *chainHarness
+}
+
+func (s *chainOperatorState) GetOperatorStakesAtBlock(ctx context.Context, quorumNumbers types.QuorumNums, blockNumber uint32) (map[types.OperatorId]*big.Int, error) {
+	s.harness.mutex.Lock()
+	operators := append([]types.OperatorId(nil), s.harness.operators...)
+	s.harness.mutex.Unlock()
+
+	stakes := make(map[types.OperatorId]*big.Int, len(operators))
+	for _, operatorId := range operators {
+		stake, err := s.harness.stakeOf(ctx, operatorId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stake of operator %s: %w", operatorId.Hex(), err)
+		}
+		if stake.Sign() > 0 {
+			stakes[operatorId] = stake
+		}
+	}
+	return stakes, nil
+}
+
+func (s *chainOperatorState) GetOperatorStakesPerQuorumAtBlock(ctx context.Context, quorumNumbers types.QuorumNums, blockNumber uint32) (map[types.QuorumNum]map[types.OperatorId]*big.Int, error) {
+	stakes, err := s.GetOperatorStakesAtBlock(ctx, quorumNumbers, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	stakesPerQuorum := make(map[types.QuorumNum]map[types.OperatorId]*big.Int, len(quorumNumbers))
+	for _, quorum := range quorumNumbers {
+		quorumStakes := make(map[types.OperatorId]*big.Int, len(stakes))
+		for operatorId, stake := range stakes {
+			quorumStakes[operatorId] = new(big.Int).Set(stake)
+		}
+		stakesPerQuorum[quorum] = quorumStakes
+	}
+	return stakesPerQuorum, nil
+}
+
+// chainTaskResponder sends respondToTask transactions to the simulated chain from
+// the aggregator account, mining each one, as AvsRegistryChainWriter does against
+// a live node
+type chainTaskResponder struct {
+	harness *chainHarness
+}
+
+func (r *chainTaskResponder) RespondToTask(ctx context.Context, serviceManagerAddr common.Address, taskIndex uint32, winner common.Address, winningBid *big.Int, signature []byte) (*gethtypes.Receipt, error) {
+	h := r.harness
+	opts, err := bind.NewKeyedTransactorWithChainID(h.aggregatorKey, h.chainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+
+	contract := bind.NewBoundContract(serviceManagerAddr, mockServiceManagerABI, h.backend, h.backend, h.backend)
+	tx, err := contract.Transact(opts, "respondToTask", taskIndex, winner, winningBid, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send respondToTask transaction: %w", err)
+	}
+	h.backend.Commit()
+
+	receipt, err := h.backend.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get respondToTask receipt: %w", err)
+	}
+	if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("respondToTask transaction %s reverted", receipt.TxHash.Hex())
+	}
+	return receipt, nil
+}
+
+func TestChainHarnessSubmitsConsensusOnChain(t *testing.T) {
+	h := newChainHarness(t)
+	op1 := h.registerOperator(1, 50)
+	op2 := h.registerOperator(2, 30)
+	op3 := h.registerOperator(3, 20)
+	a := h.newAggregator(Config{QuorumThreshold: 60})
+	ctx := context.Background()
+
+	created, _ := h.BlockNumber(ctx)
+	a.AddTask(1, AuctionTask{TaskCreatedBlock: uint32(created)})
+
+	submit := func(operatorId types.OperatorId, winner string, bid int64) {
+		t.Helper()
+		body := marshalTestResponse(t, newSignedTestResponse(t, h.state, 1, operatorId, winner, bid))
+		if got := submitTestResponse(t, a, h.state.ecdsaKey(operatorId), body).Code; got != 200 {
+			t.Fatalf("submitting the response of operator %s = %d, want 200", operatorId.Hex(), got)
+		}
+	}
+
+	// Half of the registered stake falls short of the quorum
+	submit(op1, winnerX, 1000)
+	a.checkAndProcessCompletedTasks(ctx)
+	if calls := h.respondToTaskCalls(); len(calls) != 0 {
+		t.Fatalf("submitted %d responses below quorum, want none", len(calls))
+	}
+
+	submit(op2, winnerX, 1000)
+	a.checkAndProcessCompletedTasks(ctx)
+
+	calls := h.respondToTaskCalls()
+	if len(calls) != 1 {
+		t.Fatalf("service manager received %d respondToTask calls, want 1", len(calls))
+	}
+	call := calls[0]
+	if call.TaskIndex != 1 || call.Winner != common.HexToAddress(winnerX) || call.WinningBid.Int64() != 1000 {
+		t.Fatalf("respondToTask(%d, %s, %s), want task 1 won by %s for 1000", call.TaskIndex, call.Winner.Hex(), call.WinningBid, winnerX)
+	}
+	values, err := attestationArguments.Unpack(call.Signature)
+	if err != nil {
+		t.Fatalf("Unpack attestation: %v", err)
+	}
+	if nonSigners := values[1].([][32]byte); len(nonSigners) != 1 || nonSigners[0] != op3 {
+		t.Fatalf("attested non-signers = %x, want [%x]", nonSigners, op3)
+	}
+	if _, failed := a.failedTasks[1]; failed || !a.finalizedTasks[1] {
+		t.Fatalf("task 1 finalized = %v, failed = %v; want it finalized", a.finalizedTasks[1], failed)
+	}
+}
+
+func TestChainHarnessReadsStakeFromRegistry(t *testing.T) {
+	h := newChainHarness(t)
+	op1 := h.registerOperator(1, 40)
+	op2 := h.registerOperator(2, 60)
+	a := h.newAggregator(Config{QuorumThreshold: 50, MinDistinctOperators: 1})
+	ctx := context.Background()
+
+	// op1's stake grows on chain before the task is created
+	h.setStake(op1, 90)
+	created, _ := h.BlockNumber(ctx)
+	a.AddTask(1, AuctionTask{TaskCreatedBlock: uint32(created)})
+
+	stakes, err := a.taskStakes(ctx, 1)
+	if err != nil {
+		t.Fatalf("taskStakes: %v", err)
+	}
+	if stakes[op1].Int64() != 90 || stakes[op2].Int64() != 60 {
+		t.Fatalf("taskStakes = %v, want the registry's stakes", stakes)
+	}
+	responses := []SignedAuctionTaskResponse{newSignedTestResponse(t, h.state, 1, op1, winnerX, 10)}
+	if met, err := a.meetsQuorum(ctx, 1, responses); err != nil || !met {
+		t.Fatalf("meetsQuorum = %v, %v; want 60%% of the registered stake to meet it", met, err)
+	}
+}
@@ -0,0 +1,39 @@
+package aggregator
+
+import "time"
+
+// collectionWindowElapsed reports whether a task that meets quorum has waited
+// FinalizeDelay since it first did, so responses arriving shortly after quorum are
+// still included in its consensus. The window is skipped when there is no delay
+// and on the last pass at shutdown.
+func (a *Aggregator) collectionWindowElapsed(taskIndex uint32) bool {
+	delay := time.Duration(a.config.FinalizeDelay) * time.Second
+	if delay <= 0 || a.stopped.Load() {
+		return true
+	}
+
+	now := a.now()
+	a.taskResponsesMux.Lock()
+	defer a.taskResponsesMux.Unlock()
+
+	reached, collecting := a.quorumReachedAt[taskIndex]
+	if !collecting {
+		a.quorumReachedAt[taskIndex] = now
+		a.logger.Info("Task reached quorum, collecting late responses before finalizing",
+			"taskIndex", taskIndex,
+			"responses", len(a.taskResponses[taskIndex]),
+			"delay", delay,
+		)
+		return false
+	}
+	return !now.Before(reached.Add(delay))
+}
+
+// stopCollecting closes the collection window of a task whose processing failed,
+// so it expires like any other unfinalized task. A task still meeting quorum on a
+// later pass opens a new window.
+func (a *Aggregator) stopCollecting(taskIndex uint32) {
+	a.taskResponsesMux.Lock()
+	defer a.taskResponsesMux.Unlock()
+	delete(a.quorumReachedAt, taskIndex)
+}
@@ -0,0 +1,117 @@
+package aggregator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+)
+
+func TestFinalizeDelayIncludesLateResponses(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 60, FinalizeDelay: 5, TaskTTL: 2}, state)
+	responder := a.avsWriter.(*fakeTaskResponder)
+	ctx := context.Background()
+
+	clock := time.Now()
+	a.now = func() time.Time { return clock }
+	submit := func(operatorId types.OperatorId) {
+		t.Helper()
+		body := marshalTestResponse(t, newSignedTestResponse(t, state, 1, operatorId, winnerX, 100))
+		if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+			t.Fatalf("submitting the response of operator %s = %d, want 200", operatorId.Hex(), got)
+		}
+	}
+
+	// Two of three operators meet quorum, which opens the collection window
+	submit(op1)
+	submit(op2)
+	a.checkAndProcessCompletedTasks(ctx)
+	if responder.attempts != 0 {
+		t.Fatal("expected the task to keep collecting responses once it meets quorum")
+	}
+
+	// A late response arrives within the window, after the task's TTL
+	clock = clock.Add(3 * time.Second)
+	submit(op3)
+	a.expireStaleTasks()
+	a.checkAndProcessCompletedTasks(ctx)
+	if responder.attempts != 0 || a.finalizedTasks[1] {
+		t.Fatal("expected the task to be kept until its collection window elapses")
+	}
+
+	clock = clock.Add(2 * time.Second)
+	a.checkAndProcessCompletedTasks(ctx)
+	if responder.attempts != 1 {
+		t.Fatalf("consensus submitted %d times, want once after the window", responder.attempts)
+	}
+	if consensus := a.consensusResults[1]; consensus.Responses != 3 || consensus.Signers != 3 {
+		t.Fatalf("consensus from %d responses and %d signers, want the late response included", consensus.Responses, consensus.Signers)
+	}
+	if _, collecting := a.quorumReachedAt[1]; collecting {
+		t.Fatal("expected the finalized task to stop being tracked")
+	}
+}
+
+func TestTaskFailingAfterCollectionWindowExpires(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	state.addOperator(3, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 60, FinalizeDelay: 5, TaskTTL: 2}, state)
+	ctx := context.Background()
+
+	clock := time.Now()
+	a.now = func() time.Time { return clock }
+
+	// The responses meet quorum but carry no BLS signatures, so processing the
+	// task once its window elapses discards them
+	for _, operatorId := range []types.OperatorId{op1, op2} {
+		body := marshalTestResponse(t, newTestResponse(1, operatorId, winnerX, 100))
+		if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+			t.Fatalf("submitting the response of operator %s = %d, want 200", operatorId.Hex(), got)
+		}
+	}
+	a.checkAndProcessCompletedTasks(ctx)
+	a.expireStaleTasks()
+	if _, collecting := a.quorumReachedAt[1]; !collecting || a.finalizedTasks[1] {
+		t.Fatal("expected the task to be collecting responses")
+	}
+
+	clock = clock.Add(5 * time.Second)
+	a.checkAndProcessCompletedTasks(ctx)
+	a.expireStaleTasks()
+	if _, collecting := a.quorumReachedAt[1]; collecting {
+		t.Fatal("expected the failed task to stop collecting responses")
+	}
+	if !a.finalizedTasks[1] || a.failedTasks[1] != "expired without consensus" {
+		t.Fatalf("failed task finalized = %v with failure %q, want it expired", a.finalizedTasks[1], a.failedTasks[1])
+	}
+}
+
+func TestFinalizeDelayIsSkippedAtShutdown(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50, MinDistinctOperators: 1, FinalizeDelay: 60}, state)
+	responder := a.avsWriter.(*fakeTaskResponder)
+
+	body := marshalTestResponse(t, newSignedTestResponse(t, state, 1, op1, winnerX, 100))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op1), body).Code; got != http.StatusOK {
+		t.Fatalf("submitting a response = %d, want 200", got)
+	}
+	a.checkAndProcessCompletedTasks(context.Background())
+	if responder.attempts != 0 {
+		t.Fatal("expected the task to keep collecting responses once it meets quorum")
+	}
+
+	a.shutdown()
+	if responder.attempts != 1 {
+		t.Fatalf("consensus submitted %d times at shutdown, want once", responder.attempts)
+	}
+}
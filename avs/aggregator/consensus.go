@@ -0,0 +1,263 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// TieBreakHighestBid resolves responses backed by equal stake in favour of the
+	// highest winning bid, then the lowest operator id among their operators
+	TieBreakHighestBid = "highest_bid"
+	// TieBreakAccuracy resolves responses backed by equal stake in favour of the
+	// cluster whose operators have the higher cumulative historical accuracy,
+	// falling back to the highest_bid order
+	TieBreakAccuracy = "accuracy"
+)
+
+// responseCluster groups the responses of a task that agree on the result
+type responseCluster struct {
+	response  *SignedAuctionTaskResponse
+	operators []types.OperatorId
+	// stake is the total stake of the cluster's operators
+	stake *big.Int
+}
+
+// OperatorAccuracy tracks how often an operator agreed with the finalized consensus
+type OperatorAccuracy struct {
+	Agreed uint64 `json:"agreed"`
+	Total  uint64 `json:"total"`
+}
+
+// ratio returns the fraction of tasks the operator agreed with consensus on
+func (acc OperatorAccuracy) ratio() float64 {
+	if acc.Total == 0 {
+		return 0
+	}
+	return float64(acc.Agreed) / float64(acc.Total)
+}
+
+// responseKey identifies the result a response reports, the keccak256 of its
+// canonical EncodeForSigning encoding, so responses group exactly when operators
+// signed the same result
+func responseKey(response AuctionTaskResponse) common.Hash {
+	return crypto.Keccak256Hash(response.EncodeForSigning())
+}
+
+// clusterResponses groups responses by result, in the order each result was first
+// received. Repeated responses from the same operator are counted once.
+func clusterResponses(responses []SignedAuctionTaskResponse) []*responseCluster {
+	var clusters []*responseCluster
+	byKey := make(map[common.Hash]*responseCluster)
+	seen := make(map[types.OperatorId]bool)
+
+	for i := range responses {
+		if seen[responses[i].OperatorId] {
+			continue
+		}
+		seen[responses[i].OperatorId] = true
+
+		key := responseKey(responses[i].AuctionTaskResponse)
+		cluster, exists := byKey[key]
+		if !exists {
+			cluster = &responseCluster{response: &responses[i], stake: new(big.Int)}
+			byKey[key] = cluster
+			clusters = append(clusters, cluster)
+		}
+		cluster.operators = append(cluster.operators, responses[i].OperatorId)
+	}
+	return clusters
+}
+
+// findCluster returns the cluster of responses reporting result, if any
+func findCluster(clusters []*responseCluster, result AuctionTaskResponse) *responseCluster {
+	key := responseKey(result)
+	for _, cluster := range clusters {
+		if responseKey(cluster.response.AuctionTaskResponse) == key {
+			return cluster
+		}
+	}
+	return nil
+}
+
+// weighClusters sets the stake backing each cluster from the operator stakes of
+// the task's quorums
+func (a *Aggregator) weighClusters(ctx context.Context, taskIndex uint32, clusters []*responseCluster) error {
+	stakes, err := a.taskStakes(ctx, taskIndex)
+	if err != nil {
+		return err
+	}
+	assignStakes(clusters, stakes)
+	return nil
+}
+
+// assignStakes sets the stake backing each cluster, counting unregistered operators as zero
+func assignStakes(clusters []*responseCluster, stakes map[types.OperatorId]*big.Int) {
+	for _, cluster := range clusters {
+		cluster.stake = new(big.Int)
+		for _, operatorId := range cluster.operators {
+			if stake, registered := stakes[operatorId]; registered {
+				cluster.stake.Add(cluster.stake, stake)
+			}
+		}
+	}
+}
+
+// confidenceScale is the fixed point precision confidence scales stakes with
+const confidenceScale = 1_000_000
+
+// weighByConfidence returns the operator stakes scaled by the confidence of each
+// operator's first response. Operators that reported no confidence keep their
+// full stake.
+func weighByConfidence(responses []SignedAuctionTaskResponse, stakes map[types.OperatorId]*big.Int) map[types.OperatorId]*big.Int {
+	weighted := make(map[types.OperatorId]*big.Int, len(stakes))
+	for operatorId, stake := range stakes {
+		weighted[operatorId] = stake
+	}
+
+	seen := make(map[types.OperatorId]bool)
+	for _, response := range responses {
+		stake, registered := stakes[response.OperatorId]
+		if seen[response.OperatorId] || !registered {
+			continue
+		}
+		seen[response.OperatorId] = true
+
+		confidence := response.Confidence
+		if confidence <= 0 || confidence > 1 {
+			continue
+		}
+		scaled := new(big.Int).Mul(stake, big.NewInt(int64(confidence*confidenceScale)))
+		weighted[response.OperatorId] = scaled.Quo(scaled, big.NewInt(confidenceScale))
+	}
+	return weighted
+}
+
+// selectConsensus returns the cluster backed by the most stake, then by the most
+// operators. Remaining ties are resolved by the configured tie-break policy, so
+// the result does not depend on the order responses arrived in.
+func (a *Aggregator) selectConsensus(clusters []*responseCluster) *responseCluster {
+	var best *responseCluster
+	var tied bool
+	for _, cluster := range clusters {
+		if best == nil {
+			best = cluster
+			continue
+		}
+
+		order := cluster.stake.Cmp(best.stake)
+		if order == 0 {
+			order = len(cluster.operators) - len(best.operators)
+		}
+		switch {
+		case order > 0:
+			best, tied = cluster, false
+		case order == 0:
+			tied = true
+			if a.breaksTie(cluster, best) {
+				best = cluster
+			}
+		}
+	}
+
+	if tied && best != nil {
+		a.logger.Info("Resolved tied consensus",
+			"policy", a.tieBreakPolicy(),
+			"winner", best.response.Winner.Hex(),
+			"winningBid", best.response.WinningBid.String(),
+			"operators", len(best.operators),
+		)
+	}
+	return best
+}
+
+// breaksTie reports whether cluster is preferred over the equally backed best
+func (a *Aggregator) breaksTie(cluster, best *responseCluster) bool {
+	if a.config.ConsensusTieBreak == TieBreakAccuracy {
+		if accuracy, bestAccuracy := a.clusterAccuracy(cluster), a.clusterAccuracy(best); accuracy != bestAccuracy {
+			return accuracy > bestAccuracy
+		}
+	}
+	if order := compareBids(cluster.response.WinningBid, best.response.WinningBid); order != 0 {
+		return order > 0
+	}
+	return bytes.Compare(cluster.lowestOperatorId(), best.lowestOperatorId()) < 0
+}
+
+// compareBids compares winning bids, treating a missing bid as zero
+func compareBids(x, y *big.Int) int {
+	if x == nil {
+		x = new(big.Int)
+	}
+	if y == nil {
+		y = new(big.Int)
+	}
+	return x.Cmp(y)
+}
+
+// lowestOperatorId returns the smallest id among the cluster's operators
+func (c *responseCluster) lowestOperatorId() []byte {
+	var lowest []byte
+	for i := range c.operators {
+		if id := c.operators[i][:]; lowest == nil || bytes.Compare(id, lowest) < 0 {
+			lowest = id
+		}
+	}
+	return lowest
+}
+
+// tieBreakPolicy returns the configured tie-break policy name
+func (a *Aggregator) tieBreakPolicy() string {
+	if a.config.ConsensusTieBreak == "" {
+		return TieBreakHighestBid
+	}
+	return a.config.ConsensusTieBreak
+}
+
+// clusterAccuracy returns the cumulative historical accuracy of a cluster's operators
+func (a *Aggregator) clusterAccuracy(cluster *responseCluster) float64 {
+	a.accuracyMux.RLock()
+	defer a.accuracyMux.RUnlock()
+
+	var total float64
+	for _, operatorId := range cluster.operators {
+		total += a.accuracy[operatorId].ratio()
+	}
+	return total
+}
+
+// recordAccuracy updates and persists the accuracy history of every operator that
+// responded to a finalized task, and returns the operators whose response
+// conflicted with consensus
+func (a *Aggregator) recordAccuracy(consensus *responseCluster, clusters []*responseCluster) []types.OperatorId {
+	a.accuracyMux.Lock()
+	defer a.accuracyMux.Unlock()
+
+	var mismatched []types.OperatorId
+	for _, cluster := range clusters {
+		for _, operatorId := range cluster.operators {
+			acc := a.accuracy[operatorId]
+			acc.Total++
+			if cluster == consensus {
+				acc.Agreed++
+			} else {
+				mismatched = append(mismatched, operatorId)
+			}
+			a.accuracy[operatorId] = acc
+		}
+	}
+	if err := a.responseStore.SaveAccuracy(a.accuracy); err != nil {
+		a.logger.Error("Failed to persist operator accuracy", "error", err)
+	}
+
+	sort.Slice(mismatched, func(i, j int) bool {
+		return bytes.Compare(mismatched[i][:], mismatched[j][:]) < 0
+	})
+	return mismatched
+}
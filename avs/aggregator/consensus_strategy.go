@@ -0,0 +1,110 @@
+package aggregator
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// Consensus strategies selected by Config.ConsensusStrategy
+const (
+	// ConsensusPlurality picks the result backed by the most stake, then the most
+	// operators, resolving ties by Config.ConsensusTieBreak
+	ConsensusPlurality = "plurality"
+	// ConsensusStakeMajority picks the result backed by more than half of the
+	// responding stake, and fails when no result is
+	ConsensusStakeMajority = "stake_majority"
+	// ConsensusMedianBid picks the result reporting the median winning bid among
+	// the responding operators, the lower one for an even count
+	ConsensusMedianBid = "median_bid"
+)
+
+// ErrNoConsensus is returned by a consensus strategy when the responses do not agree
+// closely enough to decide on a result
+var ErrNoConsensus = errors.New("no consensus")
+
+// ConsensusStrategy decides the result of a task from its valid responses, given
+// the stake of each operator in the task's quorums. The result must be one of the
+// reported responses, since only the signatures of the operators reporting it are
+// aggregated.
+type ConsensusStrategy interface {
+	Decide(responses []SignedAuctionTaskResponse, stakes map[types.OperatorId]*big.Int) (*AuctionTaskResponse, error)
+}
+
+// consensusStrategy returns the configured consensus strategy
+func (a *Aggregator) consensusStrategy() ConsensusStrategy {
+	switch a.config.ConsensusStrategy {
+	case ConsensusStakeMajority:
+		return stakeMajorityStrategy{a.config.BidTolerance}
+	case ConsensusMedianBid:
+		return medianBidStrategy{a.config.BidTolerance}
+	default:
+		return pluralityStrategy{a}
+	}
+}
+
+// pluralityStrategy is the aggregator's stake-weighted plurality, which needs the
+// aggregator for the accuracy tie-break
+type pluralityStrategy struct {
+	a *Aggregator
+}
+
+func (s pluralityStrategy) Decide(responses []SignedAuctionTaskResponse, stakes map[types.OperatorId]*big.Int) (*AuctionTaskResponse, error) {
+	clusters := clusterResponsesWithin(responses, s.a.config.BidTolerance, stakes)
+	assignStakes(clusters, stakes)
+	best := s.a.selectConsensus(clusters)
+	if best == nil {
+		return nil, ErrNoConsensus
+	}
+	return &best.response.AuctionTaskResponse, nil
+}
+
+type stakeMajorityStrategy struct {
+	tolerance BidTolerance
+}
+
+func (s stakeMajorityStrategy) Decide(responses []SignedAuctionTaskResponse, stakes map[types.OperatorId]*big.Int) (*AuctionTaskResponse, error) {
+	clusters := clusterResponsesWithin(responses, s.tolerance, stakes)
+	assignStakes(clusters, stakes)
+
+	total := new(big.Int)
+	for _, cluster := range clusters {
+		total.Add(total, cluster.stake)
+	}
+	for _, cluster := range clusters {
+		// stake > total/2, without rounding
+		if new(big.Int).Lsh(cluster.stake, 1).Cmp(total) > 0 {
+			return &cluster.response.AuctionTaskResponse, nil
+		}
+	}
+	return nil, ErrNoConsensus
+}
+
+type medianBidStrategy struct {
+	tolerance BidTolerance
+}
+
+func (s medianBidStrategy) Decide(responses []SignedAuctionTaskResponse, stakes map[types.OperatorId]*big.Int) (*AuctionTaskResponse, error) {
+	clusters := clusterResponsesWithin(responses, s.tolerance, stakes)
+	if len(clusters) == 0 {
+		return nil, ErrNoConsensus
+	}
+
+	// Each operator reports one bid; equal bids are ordered by their lowest
+	// operator id so the median does not depend on arrival order
+	sortByBid(clusters)
+
+	var operators int
+	for _, cluster := range clusters {
+		operators += len(cluster.operators)
+	}
+	median := (operators - 1) / 2
+	for _, cluster := range clusters {
+		if median < len(cluster.operators) {
+			return &cluster.response.AuctionTaskResponse, nil
+		}
+		median -= len(cluster.operators)
+	}
+	return nil, ErrNoConsensus
+}
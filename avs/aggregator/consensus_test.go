@@ -0,0 +1,386 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const (
+	winnerX = "0x00000000000000000000000000000000000000aa"
+	winnerY = "0x00000000000000000000000000000000000000bb"
+)
+
+// newTiedResponses returns a 2-2 split where the low accuracy operators respond first
+func newTiedResponses(accurate, inaccurate [2]types.OperatorId) []SignedAuctionTaskResponse {
+	return []SignedAuctionTaskResponse{
+		newTestResponse(1, inaccurate[0], winnerX, 100),
+		newTestResponse(1, inaccurate[1], winnerX, 100),
+		newTestResponse(1, accurate[0], winnerY, 100),
+		newTestResponse(1, accurate[1], winnerY, 100),
+	}
+}
+
+func TestConsensusTieBreakPrefersAccurateOperators(t *testing.T) {
+	accurate := [2]types.OperatorId{{1}, {2}}
+	inaccurate := [2]types.OperatorId{{3}, {4}}
+	responses := newTiedResponses(accurate, inaccurate)
+
+	a := newTestAggregator(t, Config{ConsensusTieBreak: TieBreakAccuracy}, newFakeOperatorState())
+	a.accuracy[accurate[0]] = OperatorAccuracy{Agreed: 9, Total: 10}
+	a.accuracy[accurate[1]] = OperatorAccuracy{Agreed: 8, Total: 10}
+	a.accuracy[inaccurate[0]] = OperatorAccuracy{Agreed: 3, Total: 10}
+	a.accuracy[inaccurate[1]] = OperatorAccuracy{Agreed: 5, Total: 10}
+
+	consensus := a.selectConsensus(clusterResponses(responses))
+	if consensus.response.Winner != common.HexToAddress(winnerY) {
+		t.Fatalf("consensus winner = %s, want the accurate cluster's %s", consensus.response.Winner.Hex(), winnerY)
+	}
+
+	// Equally accurate clusters fall back to the highest bid order
+	for _, operatorId := range append(accurate[:], inaccurate[:]...) {
+		a.accuracy[operatorId] = OperatorAccuracy{Agreed: 5, Total: 10}
+	}
+	responses[0].WinningBid = big.NewInt(200)
+	responses[1].WinningBid = big.NewInt(200)
+	consensus = a.selectConsensus(clusterResponses(responses))
+	if consensus.response.Winner != common.HexToAddress(winnerX) {
+		t.Fatalf("consensus winner = %s, want the higher bid's %s", consensus.response.Winner.Hex(), winnerX)
+	}
+}
+
+func TestResponseKeyHashesTheSignedResult(t *testing.T) {
+	base := newTestResponse(1, types.OperatorId{1}, winnerX, 100).AuctionTaskResponse
+
+	// Same result from another operator, with a distinct bid value and an unsigned confidence
+	same := newTestResponse(1, types.OperatorId{2}, winnerX, 100).AuctionTaskResponse
+	same.Confidence = 0.5
+	if responseKey(base) != responseKey(same) {
+		t.Fatal("identical results hash differently")
+	}
+
+	different := map[string]func(*AuctionTaskResponse){
+		"winner":     func(r *AuctionTaskResponse) { r.Winner = common.HexToAddress(winnerY) },
+		"bid":        func(r *AuctionTaskResponse) { r.WinningBid = big.NewInt(101) },
+		"total bids": func(r *AuctionTaskResponse) { r.TotalBids++ },
+		"task":       func(r *AuctionTaskResponse) { r.ReferenceTaskIndex++ },
+	}
+	for name, mutate := range different {
+		response := base
+		response.WinningBid = new(big.Int).Set(base.WinningBid)
+		mutate(&response)
+		if responseKey(base) == responseKey(response) {
+			t.Fatalf("results differing in %s hash identically", name)
+		}
+	}
+}
+
+func TestConsensusIsStakeWeighted(t *testing.T) {
+	state := newFakeOperatorState()
+	small1 := state.addOperator(1, 100)
+	small2 := state.addOperator(2, 100)
+	large := state.addOperator(3, 300)
+	a := newTestAggregator(t, Config{}, state)
+
+	responses := []SignedAuctionTaskResponse{
+		newTestResponse(1, small1, winnerX, 100),
+		newTestResponse(1, small2, winnerX, 100),
+		newTestResponse(1, large, winnerY, 100),
+	}
+	clusters := clusterResponses(responses)
+	if err := a.weighClusters(context.Background(), 1, clusters); err != nil {
+		t.Fatalf("weighClusters: %v", err)
+	}
+	consensus := a.selectConsensus(clusters)
+	if consensus.response.Winner != common.HexToAddress(winnerY) || consensus.stake.Int64() != 300 {
+		t.Fatalf("consensus = %s backed by %s, want %s backed by 300", consensus.response.Winner.Hex(), consensus.stake, winnerY)
+	}
+}
+
+func TestConsensusTieBreakIsDeterministic(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{}, state)
+
+	selectWinner := func(responses ...SignedAuctionTaskResponse) common.Address {
+		t.Helper()
+		clusters := clusterResponses(responses)
+		if err := a.weighClusters(context.Background(), 1, clusters); err != nil {
+			t.Fatalf("weighClusters: %v", err)
+		}
+		return a.selectConsensus(clusters).response.Winner
+	}
+
+	// Equal stake: the highest winning bid wins, whichever response arrived first
+	low, high := newTestResponse(1, op1, winnerX, 100), newTestResponse(1, op2, winnerY, 200)
+	for _, winner := range []common.Address{selectWinner(low, high), selectWinner(high, low)} {
+		if winner != common.HexToAddress(winnerY) {
+			t.Fatalf("consensus winner = %s, want the higher bid's %s", winner.Hex(), winnerY)
+		}
+	}
+
+	// Equal stake and bid: the response of the lowest operator id wins
+	x, y := newTestResponse(1, op2, winnerX, 100), newTestResponse(1, op1, winnerY, 100)
+	for _, winner := range []common.Address{selectWinner(x, y), selectWinner(y, x)} {
+		if winner != common.HexToAddress(winnerY) {
+			t.Fatalf("consensus winner = %s, want %s reported by the lowest operator id", winner.Hex(), winnerY)
+		}
+	}
+}
+
+func TestConsensusMajorityBeatsAccuracy(t *testing.T) {
+	a := newTestAggregator(t, Config{ConsensusTieBreak: TieBreakAccuracy}, newFakeOperatorState())
+	a.accuracy[types.OperatorId{1}] = OperatorAccuracy{Agreed: 10, Total: 10}
+
+	responses := []SignedAuctionTaskResponse{
+		newTestResponse(1, types.OperatorId{1}, winnerY, 100),
+		newTestResponse(1, types.OperatorId{2}, winnerX, 100),
+		newTestResponse(1, types.OperatorId{3}, winnerX, 100),
+	}
+	consensus := a.selectConsensus(clusterResponses(responses))
+	if consensus.response.Winner != common.HexToAddress(winnerX) {
+		t.Fatalf("consensus winner = %s, want majority %s", consensus.response.Winner.Hex(), winnerX)
+	}
+}
+
+func TestAccuracyHistoryBuiltFromFinalizedTasks(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+	op4 := state.addOperator(4, 100)
+
+	a := newTestAggregator(t, Config{QuorumThreshold: 50, ConsensusTieBreak: TieBreakAccuracy}, state)
+	ctx := context.Background()
+
+	// op3 disagrees with the majority on the first task
+	first := []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, op1, winnerY, 100),
+		newSignedTestResponse(t, state, 1, op2, winnerY, 100),
+		newSignedTestResponse(t, state, 1, op3, winnerX, 100),
+	}
+	if !a.processCompletedTask(ctx, 1, first) {
+		t.Fatal("expected first task to be processed")
+	}
+	if got := a.accuracy[op3]; got.Agreed != 0 || got.Total != 1 {
+		t.Fatalf("op3 accuracy = %+v, want 0/1", got)
+	}
+
+	// On a 2-2 split op1 and op2's history outweighs op3 and the new op4
+	second := []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 2, op3, winnerX, 100),
+		newSignedTestResponse(t, state, 2, op4, winnerX, 100),
+		newSignedTestResponse(t, state, 2, op1, winnerY, 100),
+		newSignedTestResponse(t, state, 2, op2, winnerY, 100),
+	}
+	if !a.processCompletedTask(ctx, 2, second) {
+		t.Fatal("expected second task to be processed")
+	}
+	for _, operatorId := range []types.OperatorId{op1, op2} {
+		if got := a.accuracy[operatorId]; got.Agreed != 2 || got.Total != 2 {
+			t.Fatalf("operator %s accuracy = %+v, want 2/2", operatorId.Hex(), got)
+		}
+	}
+	if got := a.accuracy[op4]; got.Agreed != 0 || got.Total != 1 {
+		t.Fatalf("op4 accuracy = %+v, want 0/1", got)
+	}
+}
+
+// recordingMismatchHook records the consensus mismatches it is notified of
+type recordingMismatchHook struct {
+	mismatches map[uint32][]types.OperatorId
+}
+
+func (h *recordingMismatchHook) OnConsensusMismatch(taskIndex uint32, operatorId types.OperatorId) {
+	h.mismatches[taskIndex] = append(h.mismatches[taskIndex], operatorId)
+}
+
+func TestConsensusMismatchesTrackAccuracy(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	hook := &recordingMismatchHook{mismatches: make(map[uint32][]types.OperatorId)}
+	a.AddConsensusMismatchHook(hook)
+	ctx := context.Background()
+
+	// op3 disagrees on tasks 1 and 2, op2 on task 3 and everyone agrees on task 4
+	dissenters := map[uint32]types.OperatorId{1: op3, 2: op3, 3: op2}
+	for taskIndex := uint32(1); taskIndex <= 4; taskIndex++ {
+		var responses []SignedAuctionTaskResponse
+		for _, operatorId := range []types.OperatorId{op1, op2, op3} {
+			winner := winnerY
+			if dissenter, ok := dissenters[taskIndex]; ok && dissenter == operatorId {
+				winner = winnerX
+			}
+			responses = append(responses, newSignedTestResponse(t, state, taskIndex, operatorId, winner, 100))
+		}
+		if !a.processCompletedTask(ctx, taskIndex, responses) {
+			t.Fatalf("expected task %d to be processed", taskIndex)
+		}
+	}
+
+	for taskIndex := uint32(1); taskIndex <= 4; taskIndex++ {
+		var want []string
+		if dissenter, ok := dissenters[taskIndex]; ok {
+			want = []string{dissenter.Hex()}
+		}
+		got := a.consensusResults[taskIndex].MismatchedOperators
+		if len(got) != len(want) || (len(want) > 0 && got[0] != want[0]) {
+			t.Fatalf("task %d mismatched operators = %v, want %v", taskIndex, got, want)
+		}
+		if len(hook.mismatches[taskIndex]) != len(want) {
+			t.Fatalf("task %d hook notified of %v, want %v", taskIndex, hook.mismatches[taskIndex], want)
+		}
+	}
+
+	for _, tc := range []struct {
+		operatorId types.OperatorId
+		successful uint64
+		accuracy   float64
+	}{
+		{op1, 4, 1},
+		{op2, 3, 0.75},
+		{op3, 2, 0.5},
+	} {
+		got := a.OperatorPerformance(tc.operatorId)
+		if got.TotalTasks != 4 || got.SuccessfulTasks != tc.successful || got.Accuracy != tc.accuracy {
+			t.Fatalf("operator %s performance = %d/%d (%v), want %d/4 (%v)",
+				tc.operatorId.Hex(), got.SuccessfulTasks, got.TotalTasks, got.Accuracy, tc.successful, tc.accuracy)
+		}
+	}
+}
+
+func TestConsensusStrategies(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 150)
+	op4 := state.addOperator(4, 50)
+	op5 := state.addOperator(5, 10)
+	a := newTestAggregator(t, Config{}, state)
+	stakes, err := a.taskStakes(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("taskStakes: %v", err)
+	}
+
+	// X at 100 has the most stake but not a majority, and Z at 250 is the median bid
+	winnerZ, winnerW := "0x00000000000000000000000000000000000000cc", "0x00000000000000000000000000000000000000dd"
+	responses := []SignedAuctionTaskResponse{
+		newTestResponse(1, op1, winnerX, 100),
+		newTestResponse(1, op2, winnerX, 100),
+		newTestResponse(1, op3, winnerY, 300),
+		newTestResponse(1, op4, winnerZ, 250),
+		newTestResponse(1, op5, winnerW, 400),
+	}
+
+	cases := []struct {
+		strategy ConsensusStrategy
+		winner   string
+		bid      int64
+	}{
+		{pluralityStrategy{a}, winnerX, 100},
+		{stakeMajorityStrategy{}, "", 0},
+		{medianBidStrategy{}, winnerZ, 250},
+	}
+	for _, tc := range cases {
+		decision, err := tc.strategy.Decide(responses, stakes)
+		if tc.winner == "" {
+			if !errors.Is(err, ErrNoConsensus) {
+				t.Fatalf("%T: expected ErrNoConsensus without a stake majority, got %v", tc.strategy, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%T: Decide: %v", tc.strategy, err)
+		}
+		if decision.Winner != common.HexToAddress(tc.winner) || decision.WinningBid.Int64() != tc.bid {
+			t.Fatalf("%T decided %s at %s, want %s at %d", tc.strategy, decision.Winner.Hex(), decision.WinningBid, tc.winner, tc.bid)
+		}
+	}
+
+	// Without X, Y at 300 has a stake majority and is the median bid
+	responses = responses[2:]
+	for _, strategy := range []ConsensusStrategy{pluralityStrategy{a}, stakeMajorityStrategy{}, medianBidStrategy{}} {
+		decision, err := strategy.Decide(responses, stakes)
+		if err != nil {
+			t.Fatalf("%T: Decide: %v", strategy, err)
+		}
+		if decision.Winner != common.HexToAddress(winnerY) {
+			t.Fatalf("%T decided %s, want %s", strategy, decision.Winner.Hex(), winnerY)
+		}
+	}
+}
+
+func TestProcessCompletedTaskUsesConfiguredStrategy(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50, ConsensusStrategy: ConsensusStakeMajority}, state)
+
+	// An even split has a plurality by tie-break but no stake majority
+	responses := []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, op1, winnerX, 100),
+		newSignedTestResponse(t, state, 1, op2, winnerY, 100),
+	}
+	if a.processCompletedTask(context.Background(), 1, responses) {
+		t.Fatal("expected no consensus without a stake majority")
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.consensusFailures.WithLabelValues(failureNoConsensus)); got != 1 {
+		t.Fatalf("no consensus failures = %v, want 1", got)
+	}
+}
+
+func TestConfidenceWeightingDiscountsUnsureOperators(t *testing.T) {
+	for _, weighted := range []bool{false, true} {
+		state := newFakeOperatorState()
+		op1 := state.addOperator(1, 100)
+		op2 := state.addOperator(2, 60)
+		// The signers of either result hold enough stake to submit it
+		a := newTestAggregator(t, Config{QuorumThreshold: 30, ConfidenceWeighting: weighted}, state)
+
+		// Confidence is not signed, so it can be set after signing
+		unsure := newSignedTestResponse(t, state, 1, op1, winnerX, 100)
+		unsure.Confidence = 0.2
+		sure := newSignedTestResponse(t, state, 1, op2, winnerY, 100)
+		sure.Confidence = 1
+
+		if !a.processCompletedTask(context.Background(), 1, []SignedAuctionTaskResponse{unsure, sure}) {
+			t.Fatalf("weighted=%v: expected task to be processed", weighted)
+		}
+
+		want := winnerX
+		if weighted {
+			want = winnerY
+		}
+		if winner := a.consensusResults[1].Winner; winner != common.HexToAddress(want) {
+			t.Fatalf("weighted=%v decided %s, want %s", weighted, winner.Hex(), want)
+		}
+	}
+}
+
+func TestWeighByConfidenceKeepsUnreportedStake(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+
+	reported := newTestResponse(1, op1, winnerX, 100)
+	reported.Confidence = 0.5
+	stakes := map[types.OperatorId]*big.Int{op1: big.NewInt(100), op2: big.NewInt(100)}
+
+	weighted := weighByConfidence([]SignedAuctionTaskResponse{reported, newTestResponse(1, op2, winnerX, 100)}, stakes)
+	if weighted[op1].Int64() != 50 || weighted[op2].Int64() != 100 {
+		t.Fatalf("weighted stakes = %v and %v, want 50 and 100", weighted[op1], weighted[op2])
+	}
+	if stakes[op1].Int64() != 100 {
+		t.Fatalf("input stake changed to %v", stakes[op1])
+	}
+}
@@ -0,0 +1,65 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+)
+
+// blockNumberReader reports the current chain height
+type blockNumberReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// taskAcceptingResponses reports whether a task still accepts responses, judged
+// by the chain head's timestamp against the deadline the service manager set, as
+// respondToTask is, so that neither the aggregator's nor an operator's clock
+// decides. It also returns that timestamp. Tasks not yet ingested, or without a
+// deadline, accept responses.
+func (a *Aggregator) taskAcceptingResponses(ctx context.Context, taskIndex uint32) (bool, uint64, error) {
+	a.tasksMux.RLock()
+	task, exists := a.tasks[taskIndex]
+	a.tasksMux.RUnlock()
+
+	if !exists || task.Deadline == 0 || a.headers == nil {
+		return true, 0, nil
+	}
+
+	head, err := a.headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, 0, err
+	}
+	if head == nil {
+		return false, 0, errors.New("chain head unavailable")
+	}
+	return head.Time <= task.Deadline, head.Time, nil
+}
+
+// taskTooOld reports whether a task was created more than ResponseWindowBlocks
+// blocks ago. Task indices grow with creation, so a task the aggregator does not
+// track is as old as the next tracked task after it, or newer than every tracked
+// task. Tasks are never too old when no window is configured.
+func (a *Aggregator) taskTooOld(ctx context.Context, taskIndex uint32) (bool, uint64, error) {
+	if a.config.ResponseWindowBlocks == 0 {
+		return false, 0, nil
+	}
+
+	a.tasksMux.RLock()
+	var next AuctionTask
+	var nextIndex uint32
+	found := false
+	for index, task := range a.tasks {
+		if index >= taskIndex && (!found || index < nextIndex) {
+			next, nextIndex, found = task, index, true
+		}
+	}
+	a.tasksMux.RUnlock()
+	if !found {
+		return false, 0, nil
+	}
+
+	currentBlock, err := a.blockReader.BlockNumber(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	return currentBlock > uint64(next.TaskCreatedBlock)+uint64(a.config.ResponseWindowBlocks), currentBlock, nil
+}
@@ -0,0 +1,109 @@
+package aggregator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// basisPoints is the denominator of MEVSplit shares
+const basisPoints = 10000
+
+// MEVSplit is how a winning bid is shared out, in basis points that sum to 10000
+type MEVSplit struct {
+	LPBps       uint32 `json:"lp_bps"`
+	AVSBps      uint32 `json:"avs_bps"`
+	ProtocolBps uint32 `json:"protocol_bps"`
+	GasBps      uint32 `json:"gas_bps"`
+}
+
+// DefaultMEVSplit mirrors the reward percentages of the LVRAuctionHook contract
+var DefaultMEVSplit = MEVSplit{LPBps: 8500, AVSBps: 1000, ProtocolBps: 300, GasBps: 200}
+
+// IsZero reports whether no share is set
+func (s MEVSplit) IsZero() bool {
+	return s == MEVSplit{}
+}
+
+// Validate checks that the shares sum to exactly 10000 basis points
+func (s MEVSplit) Validate() error {
+	total := uint64(s.LPBps) + uint64(s.AVSBps) + uint64(s.ProtocolBps) + uint64(s.GasBps)
+	if total != basisPoints {
+		return fmt.Errorf("shares must sum to %d basis points, got %d", basisPoints, total)
+	}
+	return nil
+}
+
+// CalculateMEVDistribution splits a winning bid into its LP, AVS, protocol and gas
+// amounts. Each share is rounded down and the remaining wei go to LPs, so the
+// amounts always sum to exactly total.
+func CalculateMEVDistribution(total *big.Int, split MEVSplit) (*lvrtypes.MEVDistribution, error) {
+	if total == nil || total.Sign() < 0 {
+		return nil, errors.New("total must be a non-negative amount")
+	}
+	if err := split.Validate(); err != nil {
+		return nil, err
+	}
+
+	share := func(bps uint32) *big.Int {
+		amount := new(big.Int).Mul(total, big.NewInt(int64(bps)))
+		return amount.Quo(amount, big.NewInt(basisPoints))
+	}
+	avsAmount := share(split.AVSBps)
+	protocolAmount := share(split.ProtocolBps)
+	gasAmount := share(split.GasBps)
+
+	lpAmount := new(big.Int).Sub(total, avsAmount)
+	lpAmount.Sub(lpAmount, protocolAmount)
+	lpAmount.Sub(lpAmount, gasAmount)
+
+	return &lvrtypes.MEVDistribution{
+		TotalAmount:    new(big.Int).Set(total),
+		LPAmount:       lpAmount,
+		AVSAmount:      avsAmount,
+		ProtocolAmount: protocolAmount,
+		GasAmount:      gasAmount,
+	}, nil
+}
+
+// mevSplit returns the configured split of winning bids
+func (a *Aggregator) mevSplit() MEVSplit {
+	if a.config.MEVSplit.IsZero() {
+		return DefaultMEVSplit
+	}
+	return a.config.MEVSplit
+}
+
+// distributeMEV computes the distribution of a finalized task's winning bid
+func (a *Aggregator) distributeMEV(taskIndex uint32, response AuctionTaskResponse) (*lvrtypes.MEVDistribution, error) {
+	winningBid := response.WinningBid
+	if winningBid == nil {
+		winningBid = new(big.Int)
+	}
+	distribution, err := CalculateMEVDistribution(winningBid, a.mevSplit())
+	if err != nil {
+		return nil, err
+	}
+
+	a.tasksMux.RLock()
+	task, known := a.tasks[taskIndex]
+	a.tasksMux.RUnlock()
+	if known {
+		distribution.PoolID = task.PoolId.Hex()
+		distribution.BlockNumber = uint64(task.BlockNumber)
+	}
+	distribution.Timestamp = a.now()
+
+	a.logger.Info("MEV distribution computed",
+		"taskIndex", taskIndex,
+		"poolId", distribution.PoolID,
+		"total", distribution.TotalAmount.String(),
+		"lp", distribution.LPAmount.String(),
+		"avs", distribution.AVSAmount.String(),
+		"protocol", distribution.ProtocolAmount.String(),
+		"gas", distribution.GasAmount.String(),
+	)
+	return distribution, nil
+}
@@ -0,0 +1,77 @@
+package aggregator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+func TestMEVDistributionSumsToTotal(t *testing.T) {
+	uneven := MEVSplit{LPBps: 3333, AVSBps: 3333, ProtocolBps: 3333, GasBps: 1}
+	for _, tc := range []struct {
+		total int64
+		split MEVSplit
+	}{
+		{0, DefaultMEVSplit},
+		{1, DefaultMEVSplit},
+		{9999, DefaultMEVSplit},
+		{1e18 + 7, DefaultMEVSplit},
+		{1, uneven},
+		{10001, uneven},
+		{123456789, MEVSplit{GasBps: basisPoints}},
+	} {
+		total := big.NewInt(tc.total)
+		d, err := CalculateMEVDistribution(total, tc.split)
+		if err != nil {
+			t.Fatalf("CalculateMEVDistribution(%d, %+v): %v", tc.total, tc.split, err)
+		}
+		sum := new(big.Int).Add(d.LPAmount, d.AVSAmount)
+		sum.Add(sum, d.ProtocolAmount).Add(sum, d.GasAmount)
+		if sum.Cmp(total) != 0 || d.TotalAmount.Cmp(total) != 0 {
+			t.Fatalf("distribution of %d with %+v sums to %s", tc.total, tc.split, sum)
+		}
+		for _, amount := range []*big.Int{d.LPAmount, d.AVSAmount, d.ProtocolAmount, d.GasAmount} {
+			if amount.Sign() < 0 {
+				t.Fatalf("distribution of %d with %+v has a negative share: %+v", tc.total, tc.split, d)
+			}
+		}
+	}
+}
+
+func TestMEVDistributionRoundsDustToLPs(t *testing.T) {
+	d, err := CalculateMEVDistribution(big.NewInt(10099), DefaultMEVSplit)
+	if err != nil {
+		t.Fatalf("CalculateMEVDistribution: %v", err)
+	}
+	// 10% of 10099 is 1009.9, 3% is 302.97 and 2% is 201.98, each rounded down
+	if d.AVSAmount.Int64() != 1009 || d.ProtocolAmount.Int64() != 302 || d.GasAmount.Int64() != 201 {
+		t.Fatalf("unexpected shares %+v", d)
+	}
+	if d.LPAmount.Int64() != 10099-1009-302-201 {
+		t.Fatalf("LP amount = %s, want the remainder", d.LPAmount)
+	}
+}
+
+func TestMEVDistributionRejectsInvalidSplits(t *testing.T) {
+	if _, err := CalculateMEVDistribution(big.NewInt(100), MEVSplit{LPBps: 9000}); err == nil {
+		t.Fatal("expected a split not summing to 10000 basis points to be rejected")
+	}
+	if _, err := CalculateMEVDistribution(big.NewInt(-1), DefaultMEVSplit); err == nil {
+		t.Fatal("expected a negative total to be rejected")
+	}
+}
+
+func TestMEVDistributionRecordedWithConsensus(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+
+	responses := []SignedAuctionTaskResponse{newSignedTestResponse(t, state, 1, op1, winnerX, 1000)}
+	if !a.processCompletedTask(context.Background(), 1, responses) {
+		t.Fatal("expected task to be processed")
+	}
+	d := a.consensusResults[1].Distribution
+	if d == nil || d.TotalAmount.Int64() != 1000 || d.LPAmount.Int64() != 850 || d.AVSAmount.Int64() != 100 {
+		t.Fatalf("unexpected distribution %+v", d)
+	}
+}
@@ -0,0 +1,87 @@
+package aggregator
+
+import (
+	"context"
+	"time"
+)
+
+// drainCheckInterval is how often in-progress tasks are re-evaluated while draining
+const drainCheckInterval = 500 * time.Millisecond
+
+// finalizeTimeout bounds the last consensus pass of shutdown
+const finalizeTimeout = 30 * time.Second
+
+// shutdown stops the aggregator in order once its context is done. Responses for
+// new tasks are refused and in-progress tasks drained for up to DrainTimeout;
+// then no response is accepted, the tasks already at quorum are finalized and the
+// response store is flushed.
+func (a *Aggregator) shutdown() {
+	a.draining.Store(true)
+	a.drain()
+
+	// The last pass sees every response stored before this point
+	a.stopped.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), finalizeTimeout)
+	defer cancel()
+	a.checkAndProcessCompletedTasks(ctx)
+
+	if err := a.responseStore.Flush(); err != nil {
+		a.logger.Error("Failed to flush the response store", "error", err)
+	}
+	a.logger.Info("Aggregator shut down", "pendingTasks", a.pendingTaskCount())
+}
+
+// drain finalizes the tasks already in progress before shutdown. Responses for
+// tasks that have not received any are rejected while draining; responses for
+// in-progress tasks are still accepted so tasks close to quorum can reach it. It
+// returns once no tasks are pending or the configured drain timeout elapses.
+func (a *Aggregator) drain() {
+	timeout := time.Duration(a.config.DrainTimeout) * time.Second
+	if timeout <= 0 {
+		return
+	}
+
+	a.draining.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	a.logger.Info("Draining in-progress tasks before shutdown",
+		"pendingTasks", a.pendingTaskCount(),
+		"timeout", timeout,
+	)
+
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		a.checkAndProcessCompletedTasks(ctx)
+
+		pending := a.pendingTaskCount()
+		if pending == 0 {
+			a.logger.Info("Drained all in-progress tasks")
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			a.logger.Warn("Drain timeout elapsed, abandoning unfinalized tasks", "pendingTasks", pending)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pendingTaskCount returns the number of tasks with responses that are not finalized
+func (a *Aggregator) pendingTaskCount() int {
+	a.taskResponsesMux.RLock()
+	defer a.taskResponsesMux.RUnlock()
+
+	pending := 0
+	for taskIndex, responses := range a.taskResponses {
+		if len(responses) > 0 && !a.finalizedTasks[taskIndex] {
+			pending++
+		}
+	}
+	return pending
+}
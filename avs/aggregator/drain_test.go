@@ -0,0 +1,141 @@
+package aggregator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDrainFinalizesNearQuorumTask(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67, DrainTimeout: 10}, state)
+
+	winner := "0x00000000000000000000000000000000000000aa"
+	a.taskResponses[1] = []SignedAuctionTaskResponse{newSignedTestResponse(t, state, 1, op1, winner, 10)}
+
+	drained := make(chan struct{})
+	go func() {
+		a.drain()
+		close(drained)
+	}()
+	for !a.draining.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The last response needed for quorum arrives during the drain
+	a.taskResponsesMux.Lock()
+	a.taskResponses[1] = append(a.taskResponses[1], newSignedTestResponse(t, state, 1, op2, winner, 10))
+	a.taskResponsesMux.Unlock()
+
+	select {
+	case <-drained:
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain did not return after the in-progress task reached quorum")
+	}
+
+	a.taskResponsesMux.RLock()
+	defer a.taskResponsesMux.RUnlock()
+	if !a.finalizedTasks[1] {
+		t.Fatal("expected the near-quorum task to be finalized during drain")
+	}
+}
+
+func TestSubmitResponseWhileDraining(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{DrainTimeout: 10}, state)
+
+	winner := "0x00000000000000000000000000000000000000aa"
+	a.taskResponses[1] = []SignedAuctionTaskResponse{newTestResponse(1, op1, winner, 10)}
+	a.draining.Store(true)
+
+	// New tasks are refused while draining, the in-progress task still accepts responses
+	newTask := marshalTestResponse(t, newTestResponse(2, op2, winner, 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op2), newTask).Code; got != http.StatusServiceUnavailable {
+		t.Fatalf("new task status = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	inProgress := marshalTestResponse(t, newTestResponse(1, op2, winner, 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op2), inProgress).Code; got != http.StatusOK {
+		t.Fatalf("in-progress task status = %d, want %d", got, http.StatusOK)
+	}
+
+	if len(a.taskResponses[1]) != 2 || len(a.taskResponses[2]) != 0 {
+		t.Fatalf("unexpected stored responses: task 1 has %d, task 2 has %d", len(a.taskResponses[1]), len(a.taskResponses[2]))
+	}
+}
+
+func TestDrainGivesUpAfterTimeout(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67, DrainTimeout: 1}, state)
+
+	a.taskResponses[1] = []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, op1, "0x00000000000000000000000000000000000000aa", 10),
+	}
+
+	start := time.Now()
+	a.drain()
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 3*time.Second {
+		t.Fatalf("drain returned after %s, want about the 1s timeout", elapsed)
+	}
+	if a.finalizedTasks[1] {
+		t.Fatal("expected the task below quorum to remain unfinalized")
+	}
+}
+
+func TestDrainDisabledReturnsImmediately(t *testing.T) {
+	a := newTestAggregator(t, Config{}, newFakeOperatorState())
+	a.drain()
+	if a.draining.Load() {
+		t.Fatal("expected drain to be skipped without a drain timeout")
+	}
+}
+
+// flushRecordingStore records whether the response store was flushed
+type flushRecordingStore struct {
+	memoryResponseStore
+	flushed bool
+}
+
+func (s *flushRecordingStore) Flush() error {
+	s.flushed = true
+	return nil
+}
+
+func TestShutdownFinalizesTaskAtQuorum(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	// Without a drain timeout shutdown does not wait for tasks that could still reach quorum
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+	store := &flushRecordingStore{}
+	a.responseStore = store
+
+	a.taskResponses[1] = []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, op1, winnerX, 10),
+		newSignedTestResponse(t, state, 1, op2, winnerX, 10),
+	}
+	a.taskResponses[2] = []SignedAuctionTaskResponse{newSignedTestResponse(t, state, 2, op1, winnerX, 10)}
+
+	// The task reached quorum after the processor's last tick
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a.processTaskResponses(ctx)
+
+	if !a.finalizedTasks[1] || a.finalizedTasks[2] {
+		t.Fatalf("finalized tasks = %v, want only the task at quorum", a.finalizedTasks)
+	}
+	if !store.flushed {
+		t.Fatal("expected the response store to be flushed on shutdown")
+	}
+
+	late := marshalTestResponse(t, newTestResponse(2, op2, winnerX, 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op2), late).Code; got != http.StatusServiceUnavailable {
+		t.Fatalf("response after shutdown status = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+}
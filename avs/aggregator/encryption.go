@@ -0,0 +1,91 @@
+package aggregator
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// responseKeySize is the required length of the task store encryption key (AES-256)
+const responseKeySize = 32
+
+// ErrCiphertextTooShort is returned when persisted data is too short to contain a nonce
+var ErrCiphertextTooShort = errors.New("ciphertext too short")
+
+// ResponseCipher encrypts task responses before they are written to disk and
+// decrypts them when they are read back
+type ResponseCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// aesGCMCipher implements ResponseCipher with AES-256-GCM. Each record is
+// sealed with a fresh random nonce which is prepended to the ciphertext.
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher creates a ResponseCipher from a 32 byte key
+func NewAESGCMCipher(key []byte) (ResponseCipher, error) {
+	if len(key) != responseKeySize {
+		return nil, fmt.Errorf("invalid encryption key length: got %d bytes, want %d", len(key), responseKeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create block cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm cipher: %w", err)
+	}
+
+	return &aesGCMCipher{aead: aead}, nil
+}
+
+// LoadResponseCipher reads a hex encoded 32 byte key from keyPath and creates
+// a ResponseCipher from it
+func LoadResponseCipher(keyPath string) (ResponseCipher, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %w", err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption key: %w", err)
+	}
+
+	return NewAESGCMCipher(key)
+}
+
+func (c *aesGCMCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *aesGCMCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrCiphertextTooShort
+	}
+
+	nonce, sealed := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt task response data: %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,96 @@
+package aggregator
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, responseKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func TestResponseCipherRoundTrip(t *testing.T) {
+	c, err := NewAESGCMCipher(newTestKey(t))
+	if err != nil {
+		t.Fatalf("NewAESGCMCipher: %v", err)
+	}
+
+	plaintext := []byte(`{"referenceTaskIndex":7,"blsSignature":"secret-signature"}`)
+	ciphertext, err := c.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	if bytes.Contains(ciphertext, []byte("secret-signature")) {
+		t.Fatal("ciphertext contains plaintext data")
+	}
+
+	decrypted, err := c.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("round trip mismatch: got %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestResponseCipherRejectsWrongKey(t *testing.T) {
+	writer, err := NewAESGCMCipher(newTestKey(t))
+	if err != nil {
+		t.Fatalf("NewAESGCMCipher: %v", err)
+	}
+	reader, err := NewAESGCMCipher(newTestKey(t))
+	if err != nil {
+		t.Fatalf("NewAESGCMCipher: %v", err)
+	}
+
+	ciphertext, err := writer.Encrypt([]byte("task response"))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	if _, err := reader.Decrypt(ciphertext); err == nil {
+		t.Fatal("expected decryption with the wrong key to fail")
+	}
+	if _, err := reader.Decrypt(ciphertext[:4]); err != ErrCiphertextTooShort {
+		t.Fatalf("expected ErrCiphertextTooShort, got %v", err)
+	}
+}
+
+func TestLoadResponseCipher(t *testing.T) {
+	key := newTestKey(t)
+	keyPath := filepath.Join(t.TempDir(), "store.key")
+	if err := os.WriteFile(keyPath, []byte("0x"+hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	loaded, err := LoadResponseCipher(keyPath)
+	if err != nil {
+		t.Fatalf("LoadResponseCipher: %v", err)
+	}
+	direct, err := NewAESGCMCipher(key)
+	if err != nil {
+		t.Fatalf("NewAESGCMCipher: %v", err)
+	}
+
+	ciphertext, err := direct.Encrypt([]byte("task response"))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if _, err := loaded.Decrypt(ciphertext); err != nil {
+		t.Fatalf("loaded cipher failed to decrypt: %v", err)
+	}
+
+	if _, err := NewAESGCMCipher(key[:16]); err == nil {
+		t.Fatal("expected short key to be rejected")
+	}
+}
@@ -0,0 +1,114 @@
+package aggregator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+const (
+	// ethProbeInterval is how often a healthy eth client connection is probed
+	ethProbeInterval = 10 * time.Second
+	// minEthReconnectBackoff is the delay before re-probing a lost connection,
+	// doubled after each failed probe up to maxEthReconnectBackoff
+	minEthReconnectBackoff = time.Second
+	maxEthReconnectBackoff = time.Minute
+)
+
+// ethConnection tracks whether the eth client's RPC node is reachable. The
+// go-ethereum client re-dials a dropped connection on its next request, so
+// probing the lost connection with exponential backoff is what reconnects it,
+// and the outcome is reported on GET /readyz.
+type ethConnection struct {
+	client blockNumberReader
+	logger logging.Logger
+	now    func() time.Time
+
+	connected  bool
+	lastError  string
+	since      time.Time
+	reconnects uint64
+	backoff    time.Duration
+	mutex      sync.Mutex
+}
+
+func newEthConnection(client blockNumberReader, logger logging.Logger, now func() time.Time) *ethConnection {
+	return &ethConnection{client: client, logger: logger, now: now, connected: true, since: now()}
+}
+
+// run probes the connection until ctx is cancelled
+func (c *ethConnection) run(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	for {
+		delay := c.probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// probe reads the chain head, recording the outcome, and returns the delay
+// before the next probe
+func (c *ethConnection) probe(ctx context.Context) time.Duration {
+	probeCtx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+	_, err := c.client.BlockNumber(probeCtx)
+	if ctx.Err() != nil {
+		// Shutting down, not disconnected
+		return 0
+	}
+	c.observe(err)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.connected {
+		return c.backoff
+	}
+	return ethProbeInterval
+}
+
+// observe records the outcome of a request made over the connection
+func (c *ethConnection) observe(err error) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch {
+	case err != nil && c.connected:
+		c.connected, c.since, c.backoff = false, c.now(), minEthReconnectBackoff
+		c.logger.Warn("Lost eth client connection, reconnecting", "error", err)
+	case err != nil:
+		c.backoff = min(2*c.backoff, maxEthReconnectBackoff)
+	case !c.connected:
+		c.connected, c.since = true, c.now()
+		c.reconnects++
+		c.logger.Info("Reconnected eth client", "reconnects", c.reconnects)
+	}
+	if err != nil {
+		c.lastError = err.Error()
+	} else {
+		c.lastError = ""
+	}
+}
+
+// status adds the connection state to an eth client readiness check
+func (c *ethConnection) status(check DependencyStatus) DependencyStatus {
+	if c == nil {
+		return check
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	since := c.since
+	check.Since = &since
+	check.Reconnects = c.reconnects
+	return check
+}
@@ -0,0 +1,110 @@
+package aggregator
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeEthService serves eth_blockNumber
+type fakeEthService struct{}
+
+func (fakeEthService) BlockNumber() hexutil.Uint64 { return 42 }
+
+// serveEthRPC serves a websocket RPC node on addr until the returned stop func
+// drops it along with its websocket connections
+func serveEthRPC(t *testing.T, addr string) (stop func()) {
+	t.Helper()
+	node := rpc.NewServer()
+	if err := node.RegisterName("eth", fakeEthService{}); err != nil {
+		t.Fatalf("RegisterName: %v", err)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	// Closing the server leaves hijacked websocket connections open
+	var mutex sync.Mutex
+	var hijacked []net.Conn
+	server := &http.Server{
+		Handler: node.WebsocketHandler([]string{"*"}),
+		ConnState: func(conn net.Conn, state http.ConnState) {
+			if state == http.StateHijacked {
+				mutex.Lock()
+				hijacked = append(hijacked, conn)
+				mutex.Unlock()
+			}
+		},
+	}
+	go server.Serve(listener)
+
+	stop = func() {
+		server.Close()
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, conn := range hijacked {
+			conn.Close()
+		}
+		hijacked = nil
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestEthConnectionRecoversFromDroppedConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	stopNode := serveEthRPC(t, addr)
+	client, err := ethclient.Dial("ws://" + addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer client.Close()
+
+	state := newFakeOperatorState()
+	a := newTestAggregator(t, Config{}, state)
+	a.blockReader = client
+	a.ethConn = newEthConnection(client, logging.NewNoopLogger(), time.Now)
+	a.serving.Store(true)
+	ctx := context.Background()
+
+	if delay := a.ethConn.probe(ctx); delay != ethProbeInterval {
+		t.Fatalf("probe delay = %s while connected, want %s", delay, ethProbeInterval)
+	}
+
+	// The node restarts: probes fail, backing off, and readiness reports it
+	stopNode()
+	if delay := a.ethConn.probe(ctx); delay != minEthReconnectBackoff {
+		t.Fatalf("probe delay = %s after the connection dropped, want %s", delay, minEthReconnectBackoff)
+	}
+	if delay := a.ethConn.probe(ctx); delay != 2*minEthReconnectBackoff {
+		t.Fatalf("probe delay = %s after a failed reconnect, want %s", delay, 2*minEthReconnectBackoff)
+	}
+	code, body := getReadiness(t, a)
+	if check := body.Checks["ethClient"]; code != http.StatusServiceUnavailable || check.Status != dependencyDown || check.Since == nil {
+		t.Fatalf("readyz = %d with eth client %+v, want 503 reporting it down", code, check)
+	}
+
+	// Once the node is back the next probe re-dials it
+	serveEthRPC(t, addr)
+	if delay := a.ethConn.probe(ctx); delay != ethProbeInterval {
+		t.Fatalf("probe delay = %s after the node came back, want %s", delay, ethProbeInterval)
+	}
+	code, body = getReadiness(t, a)
+	if check := body.Checks["ethClient"]; code != http.StatusOK || check.BlockNumber != 42 || check.Reconnects != 1 {
+		t.Fatalf("readyz = %d with eth client %+v, want 200 after one reconnect", code, check)
+	}
+}
@@ -0,0 +1,49 @@
+package aggregator
+
+import "time"
+
+// expireStaleTasks evicts the unfinalized tasks whose first response arrived more
+// than TaskTTL ago, marking them failed so their responses are released. Tasks
+// collecting responses after meeting quorum are kept until their processing fails.
+func (a *Aggregator) expireStaleTasks() {
+	ttl := time.Duration(a.config.TaskTTL) * time.Second
+	if ttl <= 0 {
+		return
+	}
+
+	cutoff := a.now().Add(-ttl)
+
+	a.taskResponsesMux.RLock()
+	var expired []uint32
+	for taskIndex, firstSeen := range a.taskFirstSeen {
+		if _, collecting := a.quorumReachedAt[taskIndex]; collecting {
+			continue
+		}
+		if !a.finalizedTasks[taskIndex] && firstSeen.Before(cutoff) {
+			expired = append(expired, taskIndex)
+		}
+	}
+	a.taskResponsesMux.RUnlock()
+
+	for _, taskIndex := range expired {
+		a.taskResponsesMux.RLock()
+		responses := len(a.taskResponses[taskIndex])
+		a.taskResponsesMux.RUnlock()
+
+		a.logger.Warn("Task expired without consensus",
+			"taskIndex", taskIndex,
+			"responses", responses,
+			"ttl", ttl,
+		)
+
+		a.markTaskFinalized(taskIndex)
+
+		a.taskResponsesMux.Lock()
+		a.failedTasks[taskIndex] = "expired without consensus"
+		a.taskResponsesMux.Unlock()
+
+		a.auctionStats.recordFailure(a.now())
+		a.lvrMetrics.expiredTasks.Inc()
+		a.lvrMetrics.observeFailure(failureExpired)
+	}
+}
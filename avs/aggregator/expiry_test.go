@@ -0,0 +1,71 @@
+package aggregator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSubQuorumTaskExpiresAfterTTL(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	state.addOperator(2, 100)
+	state.addOperator(3, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67, TaskTTL: 60}, state)
+
+	clock := time.Now()
+	a.now = func() time.Time { return clock }
+
+	winner := "0x00000000000000000000000000000000000000aa"
+	body := marshalTestResponse(t, newTestResponse(1, op1, winner, 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op1), body).Code; got != http.StatusOK {
+		t.Fatalf("status = %d, want %d", got, http.StatusOK)
+	}
+
+	// One of three operators is below quorum, so the task stays pending
+	clock = clock.Add(59 * time.Second)
+	a.checkAndProcessCompletedTasks(context.Background())
+	a.expireStaleTasks()
+	if len(a.taskResponses[1]) != 1 {
+		t.Fatal("expected the task to be kept before its TTL elapses")
+	}
+
+	clock = clock.Add(2 * time.Second)
+	a.checkAndProcessCompletedTasks(context.Background())
+	a.expireStaleTasks()
+
+	if _, exists := a.taskResponses[1]; exists {
+		t.Fatal("expected the expired task's responses to be removed")
+	}
+	if _, exists := a.taskFirstSeen[1]; exists {
+		t.Fatal("expected the expired task to stop being tracked")
+	}
+	if a.failedTasks[1] == "" {
+		t.Fatal("expected the expired task to be marked failed")
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.expiredTasks); got != 1 {
+		t.Fatalf("expired tasks = %v, want 1", got)
+	}
+
+	// Late responses for the expired task are refused rather than accumulating again
+	if got := submitTestResponse(t, a, state.ecdsaKey(op1), body).Code; got != http.StatusGone {
+		t.Fatalf("late response status = %d, want %d", got, http.StatusGone)
+	}
+}
+
+func TestTasksNeverExpireWithoutTTL(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+
+	a.taskResponses[1] = []SignedAuctionTaskResponse{newTestResponse(1, op1, "0x00000000000000000000000000000000000000aa", 10)}
+	a.taskFirstSeen[1] = time.Now().Add(-24 * time.Hour)
+	a.expireStaleTasks()
+
+	if len(a.taskResponses[1]) != 1 {
+		t.Fatal("expected tasks to be kept when no TTL is configured")
+	}
+}
@@ -0,0 +1,104 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// readinessCheckTimeout bounds the dependency calls made by a readiness check
+const readinessCheckTimeout = 5 * time.Second
+
+// Dependency states reported by GET /readyz
+const (
+	dependencyUp   = "up"
+	dependencyDown = "down"
+)
+
+// DependencyStatus is the state of one dependency the aggregator needs to serve
+type DependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// BlockNumber is the eth client's chain head
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	// Operators is the number of operators registered in the default quorums
+	Operators *int `json:"operators,omitempty"`
+	// Since is when the eth client connection last went up or down, and
+	// Reconnects how many times it has recovered
+	Since      *time.Time `json:"since,omitempty"`
+	Reconnects uint64     `json:"reconnects,omitempty"`
+}
+
+// handleLiveness reports that the process is alive and serving requests
+func (a *Aggregator) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	status := "healthy"
+	if a.draining.Load() {
+		status = "draining"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":    status,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleReadiness reports whether the aggregator's dependencies are reachable,
+// responding 503 when any of them is down
+func (a *Aggregator) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	checks := map[string]DependencyStatus{
+		"ethClient":  a.checkEthClient(ctx),
+		"registry":   a.checkRegistry(ctx),
+		"httpServer": a.checkHTTPServer(),
+	}
+
+	status, code := "ready", http.StatusOK
+	for _, check := range checks {
+		if check.Status != dependencyUp {
+			status, code = "not_ready", http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    status,
+		"checks":    checks,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// checkEthClient reads the chain head through the eth client, reporting the
+// state of its connection
+func (a *Aggregator) checkEthClient(ctx context.Context) DependencyStatus {
+	block, err := a.blockReader.BlockNumber(ctx)
+	if ctx.Err() == nil {
+		a.ethConn.observe(err)
+	}
+	if err != nil {
+		return a.ethConn.status(DependencyStatus{Status: dependencyDown, Error: err.Error()})
+	}
+	return a.ethConn.status(DependencyStatus{Status: dependencyUp, BlockNumber: block})
+}
+
+// checkRegistry reads the operators registered in the default quorums
+func (a *Aggregator) checkRegistry(ctx context.Context) DependencyStatus {
+	stakes, err := a.avsReader.GetOperatorStakesAtBlock(ctx, a.configuredQuorumNumbers(), 0)
+	if err != nil {
+		return DependencyStatus{Status: dependencyDown, Error: err.Error()}
+	}
+	operators := len(stakes)
+	return DependencyStatus{Status: dependencyUp, Operators: &operators}
+}
+
+// checkHTTPServer reports whether the HTTP server is listening
+func (a *Aggregator) checkHTTPServer() DependencyStatus {
+	if !a.serving.Load() {
+		return DependencyStatus{Status: dependencyDown, Error: "HTTP server not listening"}
+	}
+	return DependencyStatus{Status: dependencyUp}
+}
@@ -0,0 +1,82 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// unreachableBlockReader is an eth client whose RPC endpoint is down
+type unreachableBlockReader struct{}
+
+func (unreachableBlockReader) BlockNumber(ctx context.Context) (uint64, error) {
+	return 0, errors.New("dial tcp 127.0.0.1:8545: connection refused")
+}
+
+type readinessBody struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
+
+func getReadiness(t *testing.T, a *Aggregator) (int, readinessBody) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	a.httpHandler(nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var body readinessBody
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return recorder.Code, body
+}
+
+func TestReadinessReportsDependencies(t *testing.T) {
+	state := newFakeOperatorState()
+	state.addOperator(1, 100)
+	state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	a.blockReader = &fakeBlockReader{block: 42}
+	a.serving.Store(true)
+
+	code, body := getReadiness(t, a)
+	if code != http.StatusOK || body.Status != "ready" {
+		t.Fatalf("readyz = %d %q, want 200 ready (checks %+v)", code, body.Status, body.Checks)
+	}
+	if eth := body.Checks["ethClient"]; eth.Status != dependencyUp || eth.BlockNumber != 42 {
+		t.Fatalf("unexpected eth client check %+v", eth)
+	}
+	if registry := body.Checks["registry"]; registry.Status != dependencyUp || registry.Operators == nil || *registry.Operators != 2 {
+		t.Fatalf("unexpected registry check %+v", registry)
+	}
+
+	// Liveness does not depend on the eth client
+	a.blockReader = unreachableBlockReader{}
+	recorder := httptest.NewRecorder()
+	a.httpHandler(nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("healthz = %d, want 200 while the eth client is down", recorder.Code)
+	}
+
+	code, body = getReadiness(t, a)
+	if code != http.StatusServiceUnavailable || body.Status != "not_ready" {
+		t.Fatalf("readyz = %d %q, want 503 not_ready", code, body.Status)
+	}
+	if eth := body.Checks["ethClient"]; eth.Status != dependencyDown || eth.Error == "" {
+		t.Fatalf("unexpected eth client check %+v", eth)
+	}
+	if body.Checks["registry"].Status != dependencyUp || body.Checks["httpServer"].Status != dependencyUp {
+		t.Fatalf("expected only the eth client to be down, got %+v", body.Checks)
+	}
+}
+
+func TestReadinessRequiresListeningServer(t *testing.T) {
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, newFakeOperatorState())
+
+	code, body := getReadiness(t, a)
+	if code != http.StatusServiceUnavailable || body.Checks["httpServer"].Status != dependencyDown {
+		t.Fatalf("readyz = %d with checks %+v, want 503 before the server listens", code, body.Checks)
+	}
+}
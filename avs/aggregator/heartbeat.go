@@ -0,0 +1,179 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+)
+
+const (
+	// defaultHeartbeatTimeout is how long an operator is considered online after its
+	// last heartbeat when heartbeat_timeout_seconds is unset
+	defaultHeartbeatTimeout = 90 * time.Second
+	// maxHeartbeatSkew bounds how far a heartbeat's timestamp may be from the
+	// aggregator's clock, so that a captured heartbeat cannot be replayed later
+	maxHeartbeatSkew = time.Minute
+	// maxHeartbeatBodySize bounds the size of a heartbeat request body
+	maxHeartbeatBodySize = 1 << 10
+)
+
+// Heartbeat is the body of POST /heartbeat, signed by the operator like a task response
+type Heartbeat struct {
+	// Timestamp is when the heartbeat was sent, in unix seconds
+	Timestamp int64 `json:"timestamp"`
+}
+
+// OnlineOperator is an operator's entry in GET /operators/online
+type OnlineOperator struct {
+	OperatorId string    `json:"operatorId"`
+	LastSeen   time.Time `json:"lastSeen"`
+
+	id types.OperatorId
+}
+
+// heartbeatTimeout returns how long an operator stays online after a heartbeat
+func (a *Aggregator) heartbeatTimeout() time.Duration {
+	if a.config.HeartbeatTimeout > 0 {
+		return time.Duration(a.config.HeartbeatTimeout) * time.Second
+	}
+	return defaultHeartbeatTimeout
+}
+
+// recordHeartbeat authenticates a heartbeat body signed by an operator and marks
+// the operator as seen now
+func (a *Aggregator) recordHeartbeat(ctx context.Context, body []byte, signature, remoteAddr string) error {
+	operatorId, err := a.authenticateOperator(ctx, body, signature)
+	if err != nil {
+		a.logger.Warn("Rejecting unauthenticated heartbeat", "remoteAddr", remoteAddr, "error", err)
+		return authenticationRejection(err)
+	}
+
+	var heartbeat Heartbeat
+	if err := json.Unmarshal(body, &heartbeat); err != nil {
+		return &responseRejection{status: http.StatusBadRequest, message: "Invalid JSON"}
+	}
+	now := a.now()
+	if skew := now.Sub(time.Unix(heartbeat.Timestamp, 0)).Abs(); skew > maxHeartbeatSkew {
+		a.logger.Warn("Rejecting heartbeat with a skewed timestamp", "operatorId", operatorId.Hex(), "skew", skew)
+		return &responseRejection{status: http.StatusBadRequest, message: "Heartbeat timestamp too far from server time"}
+	}
+
+	a.heartbeatsMux.Lock()
+	a.heartbeats[operatorId] = now
+	a.heartbeatsMux.Unlock()
+	return nil
+}
+
+// lastSeen returns when an operator last sent a heartbeat, the zero time if never
+func (a *Aggregator) lastSeen(operatorId types.OperatorId) time.Time {
+	a.heartbeatsMux.RLock()
+	defer a.heartbeatsMux.RUnlock()
+	return a.heartbeats[operatorId]
+}
+
+// onlineOperators returns the operators whose last heartbeat is within the
+// heartbeat timeout, by operator id
+func (a *Aggregator) onlineOperators() []OnlineOperator {
+	cutoff := a.now().Add(-a.heartbeatTimeout())
+
+	a.heartbeatsMux.RLock()
+	online := make([]OnlineOperator, 0, len(a.heartbeats))
+	for operatorId, lastSeen := range a.heartbeats {
+		if lastSeen.After(cutoff) {
+			online = append(online, OnlineOperator{OperatorId: operatorId.Hex(), LastSeen: lastSeen, id: operatorId})
+		}
+	}
+	a.heartbeatsMux.RUnlock()
+
+	sort.Slice(online, func(i, j int) bool { return online[i].OperatorId < online[j].OperatorId })
+	return online
+}
+
+// expectedParticipation evaluates the online operators against the operator set
+// registered in the configured quorums at the current block, reporting whether a
+// task created now would be expected to reach quorum
+func (a *Aggregator) expectedParticipation(ctx context.Context, online []OnlineOperator) (quorumProgress, bool, error) {
+	block, err := a.blockReader.BlockNumber(ctx)
+	if err != nil {
+		return quorumProgress{}, false, fmt.Errorf("failed to read current block: %w", err)
+	}
+	stakes, err := a.avsReader.GetOperatorStakesAtBlock(ctx, a.configuredQuorumNumbers(), uint32(block))
+	if err != nil {
+		return quorumProgress{}, false, fmt.Errorf("failed to read operator stakes: %w", err)
+	}
+
+	operatorIds := make([]types.OperatorId, 0, len(online))
+	for _, operator := range online {
+		operatorIds = append(operatorIds, operator.id)
+	}
+	progress := newParticipation(operatorIds, stakes)
+
+	// A task is held to its quorum rule in each of its quorums separately
+	stakesPerQuorum, err := a.avsReader.GetOperatorStakesPerQuorumAtBlock(ctx, a.configuredQuorumNumbers(), uint32(block))
+	if err != nil {
+		return quorumProgress{}, false, fmt.Errorf("failed to read operator stakes: %w", err)
+	}
+	progressPerQuorum := make(map[types.QuorumNum]quorumProgress, len(a.config.QuorumNumbers))
+	for _, quorum := range a.configuredQuorumNumbers() {
+		progressPerQuorum[quorum] = newParticipation(operatorIds, stakesPerQuorum[quorum])
+	}
+	return progress, a.quorumRuleMet(progress, progressPerQuorum, a.currentQuorumThreshold()), nil
+}
+
+// handleHeartbeat records a signed operator heartbeat
+func (a *Aggregator) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHeartbeatBodySize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.recordHeartbeat(r.Context(), body, r.Header.Get(OperatorSignatureHeader), r.RemoteAddr); err != nil {
+		status := http.StatusInternalServerError
+		var rejection *responseRejection
+		if errors.As(err, &rejection) {
+			status = rejection.status
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// handleOnlineOperators serves the operators currently sending heartbeats, with
+// the quorum participation they are expected to provide
+func (a *Aggregator) handleOnlineOperators(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	online := a.onlineOperators()
+	participation, meetsQuorum, err := a.expectedParticipation(r.Context(), online)
+	if err != nil {
+		a.logger.Warn("Failed to compute expected participation", "error", err)
+		http.Error(w, "Operator set unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"operators":             online,
+		"expectedParticipation": participation,
+		"expectedQuorumMet":     meetsQuorum,
+	})
+}
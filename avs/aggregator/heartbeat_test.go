@@ -0,0 +1,149 @@
+package aggregator
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+)
+
+// sendTestHeartbeat posts a heartbeat timestamped at sentAt, signed with key
+func sendTestHeartbeat(t *testing.T, a *Aggregator, key *ecdsa.PrivateKey, sentAt time.Time) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(Heartbeat{Timestamp: sentAt.Unix()})
+	if err != nil {
+		t.Fatalf("marshal heartbeat: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/heartbeat", bytes.NewReader(body))
+	signature, err := operator.SignRequestBody(key, body)
+	if err != nil {
+		t.Fatalf("SignRequestBody: %v", err)
+	}
+	req.Header.Set(OperatorSignatureHeader, signature)
+
+	recorder := httptest.NewRecorder()
+	a.handleHeartbeat(recorder, req)
+	return recorder
+}
+
+// getOnlineOperators fetches GET /operators/online
+func getOnlineOperators(t *testing.T, a *Aggregator) (online []OnlineOperator, participation quorumProgress, quorumMet bool) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	a.handleOnlineOperators(recorder, httptest.NewRequest(http.MethodGet, "/operators/online", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /operators/online status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var body struct {
+		Operators             []OnlineOperator `json:"operators"`
+		ExpectedParticipation quorumProgress   `json:"expectedParticipation"`
+		ExpectedQuorumMet     bool             `json:"expectedQuorumMet"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode online operators: %v", err)
+	}
+	return body.Operators, body.ExpectedParticipation, body.ExpectedQuorumMet
+}
+
+func TestOperatorGoesStaleAfterMissedHeartbeats(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 60)
+	op2 := state.addOperator(2, 40)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50, HeartbeatTimeout: 30}, state)
+	now := time.Unix(1_700_000_000, 0)
+	a.now = func() time.Time { return now }
+
+	for _, op := range []struct {
+		name string
+		key  *ecdsa.PrivateKey
+	}{{"op1", state.ecdsaKey(op1)}, {"op2", state.ecdsaKey(op2)}} {
+		if rec := sendTestHeartbeat(t, a, op.key, now); rec.Code != http.StatusOK {
+			t.Fatalf("%s heartbeat status = %d: %s", op.name, rec.Code, rec.Body.String())
+		}
+	}
+	online, participation, quorumMet := getOnlineOperators(t, a)
+	if len(online) != 2 || participation.RespondedStake.Int64() != 100 || !quorumMet {
+		t.Fatalf("online = %+v, participation %+v, quorum met %v, want both operators online", online, participation, quorumMet)
+	}
+
+	// op1 keeps sending heartbeats while op2 misses them past the timeout
+	for i := 0; i < 3; i++ {
+		now = now.Add(15 * time.Second)
+		if rec := sendTestHeartbeat(t, a, state.ecdsaKey(op1), now); rec.Code != http.StatusOK {
+			t.Fatalf("op1 heartbeat status = %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	online, participation, quorumMet = getOnlineOperators(t, a)
+	if len(online) != 1 || online[0].OperatorId != op1.Hex() || !online[0].LastSeen.Equal(now) {
+		t.Fatalf("online = %+v, want only op1 seen at %s", online, now)
+	}
+	if participation.RespondedOperators != 1 || participation.RespondedStake.Int64() != 60 || !quorumMet {
+		t.Fatalf("participation %+v, quorum met %v, want op1's 60%% stake to meet quorum", participation, quorumMet)
+	}
+	if lastSeen := a.OperatorPerformance(op2).LastSeen; !lastSeen.Equal(now.Add(-45 * time.Second)) {
+		t.Fatalf("op2 last seen %s, want its last heartbeat", lastSeen)
+	}
+
+	// Once op1 goes quiet too, no quorum is expected
+	now = now.Add(time.Minute)
+	online, participation, quorumMet = getOnlineOperators(t, a)
+	if len(online) != 0 || participation.RespondedStake.Sign() != 0 || quorumMet {
+		t.Fatalf("online = %+v, participation %+v, quorum met %v, want every operator stale", online, participation, quorumMet)
+	}
+}
+
+func TestHeartbeatRejectsUnregisteredAndReplayedRequests(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	now := time.Unix(1_700_000_000, 0)
+	a.now = func() time.Time { return now }
+
+	unregistered, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if rec := sendTestHeartbeat(t, a, unregistered, now); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unregistered heartbeat status = %d, want 401", rec.Code)
+	}
+	if rec := sendTestHeartbeat(t, a, state.ecdsaKey(op1), now.Add(-5*time.Minute)); rec.Code != http.StatusBadRequest {
+		t.Fatalf("replayed heartbeat status = %d, want 400", rec.Code)
+	}
+	if online, _, _ := getOnlineOperators(t, a); len(online) != 0 {
+		t.Fatalf("online = %+v, want no operator", online)
+	}
+}
+
+func TestExpectedParticipationAppliesTheQuorumRule(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 0)
+	op2 := state.addOperator(2, 0)
+	state.addOperator(3, 0)
+	// Only the count threshold is set, so operators without stake can reach quorum
+	a := newTestAggregator(t, Config{QuorumCountThreshold: 30, MinDistinctOperators: 2}, state)
+	now := time.Unix(1_700_000_000, 0)
+	a.now = func() time.Time { return now }
+
+	if rec := sendTestHeartbeat(t, a, state.ecdsaKey(op1), now); rec.Code != http.StatusOK {
+		t.Fatalf("op1 heartbeat status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, _, quorumMet := getOnlineOperators(t, a); quorumMet {
+		t.Fatal("expected quorum not to be met by fewer than min_distinct_operators operators")
+	}
+
+	if rec := sendTestHeartbeat(t, a, state.ecdsaKey(op2), now); rec.Code != http.StatusOK {
+		t.Fatalf("op2 heartbeat status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, participation, quorumMet := getOnlineOperators(t, a); participation.RespondedOperators != 2 || !quorumMet {
+		t.Fatalf("participation %+v, quorum met %v, want two unstaked operators to meet the count threshold", participation, quorumMet)
+	}
+}
@@ -0,0 +1,54 @@
+package aggregator
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultReadHeaderTimeout bounds how long a client may take to send request headers
+	defaultReadHeaderTimeout = 5 * time.Second
+	// defaultReadTimeout bounds how long a client may take to send a whole request
+	defaultReadTimeout = 15 * time.Second
+	// defaultWriteTimeout bounds how long a request may take to be answered
+	defaultWriteTimeout = 30 * time.Second
+	// defaultIdleTimeout is how long an idle keep-alive connection is kept open
+	defaultIdleTimeout = 2 * time.Minute
+)
+
+// HTTPServerConfig bounds how long clients may hold connections to the HTTP
+// server, in seconds. Unset fields use the defaults, so a slow client can never
+// hold a connection indefinitely.
+type HTTPServerConfig struct {
+	ReadHeaderTimeout uint32 `json:"read_header_timeout_seconds"`
+	ReadTimeout       uint32 `json:"read_timeout_seconds"`
+	WriteTimeout      uint32 `json:"write_timeout_seconds"`
+	IdleTimeout       uint32 `json:"idle_timeout_seconds"`
+	// DisableKeepAlives closes every connection after its response
+	DisableKeepAlives bool `json:"disable_keep_alives"`
+}
+
+// timeoutOrDefault converts a timeout in seconds, falling back when it is unset
+func timeoutOrDefault(seconds uint32, fallback time.Duration) time.Duration {
+	if seconds == 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// newHTTPServer creates the HTTP server for handler with the configured timeouts.
+// The /rpc task stream outlives them: its websocket connection manages its own
+// deadlines once upgraded.
+func (a *Aggregator) newHTTPServer(handler http.Handler) *http.Server {
+	config := a.config.HTTPServer
+	server := &http.Server{
+		Addr:              a.config.AggregatorServerIpPortAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: timeoutOrDefault(config.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		ReadTimeout:       timeoutOrDefault(config.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:      timeoutOrDefault(config.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       timeoutOrDefault(config.IdleTimeout, defaultIdleTimeout),
+	}
+	server.SetKeepAlivesEnabled(!config.DisableKeepAlives)
+	return server
+}
@@ -0,0 +1,107 @@
+package aggregator
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+)
+
+// startTestHTTPServer serves the aggregator's HTTP API on an http.Server made by
+// newHTTPServer, with its timeouts shortened to timeout
+func startTestHTTPServer(t *testing.T, a *Aggregator, timeout time.Duration) *httptest.Server {
+	t.Helper()
+	taskStream, err := a.newTaskStreamServer()
+	if err != nil {
+		t.Fatalf("newTaskStreamServer: %v", err)
+	}
+	t.Cleanup(taskStream.Stop)
+
+	server := httptest.NewUnstartedServer(nil)
+	server.Config = a.newHTTPServer(a.httpHandler(taskStream))
+	server.Config.ReadHeaderTimeout = timeout
+	server.Config.ReadTimeout = timeout
+	server.Config.WriteTimeout = timeout
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewHTTPServerUsesConfiguredTimeouts(t *testing.T) {
+	a := newTestAggregator(t, Config{HTTPServer: HTTPServerConfig{ReadTimeout: 3, IdleTimeout: 60}}, newFakeOperatorState())
+	server := a.newHTTPServer(nil)
+	if server.ReadHeaderTimeout != defaultReadHeaderTimeout || server.WriteTimeout != defaultWriteTimeout {
+		t.Fatalf("unset timeouts = %s/%s, want the defaults", server.ReadHeaderTimeout, server.WriteTimeout)
+	}
+	if server.ReadTimeout != 3*time.Second || server.IdleTimeout != time.Minute {
+		t.Fatalf("configured timeouts = %s/%s, want 3s/1m", server.ReadTimeout, server.IdleTimeout)
+	}
+}
+
+func TestHTTPServerTimesOutSlowClients(t *testing.T) {
+	a := newTestAggregator(t, Config{}, newFakeOperatorState())
+	server := startTestHTTPServer(t, a, 100*time.Millisecond)
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	// The request headers are never finished
+	if _, err := io.WriteString(conn, "POST /submit-response HTTP/1.1\r\nHost: aggregator\r\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	start := time.Now()
+	io.ReadAll(conn)
+	if elapsed := time.Since(start); elapsed >= 5*time.Second {
+		t.Fatal("expected the server to close the connection of a slow client")
+	}
+}
+
+func TestTaskStreamOutlivesHTTPServerTimeouts(t *testing.T) {
+	state := newFakeOperatorState()
+	op := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	server := startTestHTTPServer(t, a, 100*time.Millisecond)
+
+	client, err := rpc.DialWebsocket(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")+"/rpc", "")
+	if err != nil {
+		t.Fatalf("DialWebsocket: %v", err)
+	}
+	defer client.Close()
+
+	timestamp := time.Now().Unix()
+	signature, err := operator.SignRequestBody(state.ecdsaKey(op), TaskSubscriptionMessage(timestamp))
+	if err != nil {
+		t.Fatalf("SignRequestBody: %v", err)
+	}
+	tasks := make(chan StreamedTask, 1)
+	subscription, err := client.Subscribe(context.Background(), taskStreamNamespace, tasks, "tasks", timestamp, signature)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer subscription.Unsubscribe()
+
+	time.Sleep(300 * time.Millisecond)
+	a.AddTask(3, AuctionTask{PoolId: common.HexToHash("0x01")})
+	select {
+	case task := <-tasks:
+		if task.TaskIndex != 3 {
+			t.Fatalf("unexpected streamed task %+v", task)
+		}
+	case err := <-subscription.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("expected the task stream to stay open past the server timeouts")
+	}
+}
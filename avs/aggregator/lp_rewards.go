@@ -0,0 +1,138 @@
+package aggregator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+var (
+	// ErrNothingToClaim is returned when an LP has no accrued reward in a pool,
+	// including when it was already claimed
+	ErrNothingToClaim = errors.New("no reward to claim")
+	// ErrNoLiquidity is returned when accruing to a pool without liquidity shares
+	ErrNoLiquidity = errors.New("pool has no liquidity shares")
+)
+
+// LPRewardLedger accrues the LP share of MEV distributions to liquidity providers
+// in proportion to their liquidity, and tracks what they have claimed. A reward's
+// RewardAmount is its unclaimed balance and ClaimedAmount what was paid out.
+type LPRewardLedger struct {
+	// rewards are keyed by pool id, then by lowercased LP address
+	rewards map[string]map[string]*lvrtypes.LPReward
+	mutex   sync.Mutex
+
+	now func() time.Time
+}
+
+// NewLPRewardLedger creates an empty ledger
+func NewLPRewardLedger() *LPRewardLedger {
+	return &LPRewardLedger{
+		rewards: make(map[string]map[string]*lvrtypes.LPReward),
+		now:     time.Now,
+	}
+}
+
+// Accrue splits a distribution's LP amount among the pool's LPs by liquidity
+// share. Shares are rounded down and the remaining wei go one each to the LPs
+// with the largest remainders, so exactly the LP amount is accrued.
+func (l *LPRewardLedger) Accrue(distribution *lvrtypes.MEVDistribution, shares map[string]*big.Int) error {
+	if distribution.PoolID == "" {
+		return errors.New("distribution has no pool id")
+	}
+
+	totalLiquidity := new(big.Int)
+	lps := make([]string, 0, len(shares))
+	for lpAddress, share := range shares {
+		if share == nil || share.Sign() < 0 {
+			return fmt.Errorf("invalid liquidity share for %s", lpAddress)
+		}
+		totalLiquidity.Add(totalLiquidity, share)
+		lps = append(lps, lpAddress)
+	}
+	if totalLiquidity.Sign() == 0 {
+		return fmt.Errorf("pool %s: %w", distribution.PoolID, ErrNoLiquidity)
+	}
+	sort.Strings(lps)
+
+	amounts := make(map[string]*big.Int, len(lps))
+	remainders := make(map[string]*big.Int, len(lps))
+	dust := new(big.Int).Set(distribution.LPAmount)
+	for _, lpAddress := range lps {
+		amount, remainder := new(big.Int).QuoRem(
+			new(big.Int).Mul(distribution.LPAmount, shares[lpAddress]), totalLiquidity, new(big.Int))
+		amounts[lpAddress], remainders[lpAddress] = amount, remainder
+		dust.Sub(dust, amount)
+	}
+	sort.SliceStable(lps, func(i, j int) bool {
+		return remainders[lps[i]].Cmp(remainders[lps[j]]) > 0
+	})
+	for i := int64(0); i < dust.Int64(); i++ {
+		amounts[lps[i]].Add(amounts[lps[i]], big.NewInt(1))
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	pool := l.rewards[distribution.PoolID]
+	if pool == nil {
+		pool = make(map[string]*lvrtypes.LPReward)
+		l.rewards[distribution.PoolID] = pool
+	}
+	for _, lpAddress := range lps {
+		key := strings.ToLower(lpAddress)
+		reward := pool[key]
+		if reward == nil {
+			reward = &lvrtypes.LPReward{
+				LPAddress:     lpAddress,
+				PoolID:        distribution.PoolID,
+				RewardAmount:  new(big.Int),
+				ClaimedAmount: new(big.Int),
+			}
+			pool[key] = reward
+		}
+		reward.LiquidityShare = new(big.Int).Set(shares[lpAddress])
+		reward.RewardAmount.Add(reward.RewardAmount, amounts[lpAddress])
+	}
+	return nil
+}
+
+// Claim pays out an LP's unclaimed reward in a pool and returns the amount
+// claimed. Claiming again before more is accrued returns ErrNothingToClaim.
+func (l *LPRewardLedger) Claim(lpAddress, poolID string) (*big.Int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	reward := l.rewards[poolID][strings.ToLower(lpAddress)]
+	if reward == nil || reward.RewardAmount.Sign() == 0 {
+		return nil, fmt.Errorf("%s in pool %s: %w", lpAddress, poolID, ErrNothingToClaim)
+	}
+
+	claimed := reward.RewardAmount
+	reward.ClaimedAmount = new(big.Int).Add(reward.ClaimedAmount, claimed)
+	reward.RewardAmount = new(big.Int)
+	reward.LastClaimTime = l.now()
+	return new(big.Int).Set(claimed), nil
+}
+
+// Reward returns a copy of an LP's reward in a pool
+func (l *LPRewardLedger) Reward(lpAddress, poolID string) (lvrtypes.LPReward, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	reward := l.rewards[poolID][strings.ToLower(lpAddress)]
+	if reward == nil {
+		return lvrtypes.LPReward{}, false
+	}
+	copied := *reward
+	copied.LiquidityShare = new(big.Int).Set(reward.LiquidityShare)
+	copied.RewardAmount = new(big.Int).Set(reward.RewardAmount)
+	copied.ClaimedAmount = new(big.Int).Set(reward.ClaimedAmount)
+	return copied, true
+}
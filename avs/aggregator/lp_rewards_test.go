@@ -0,0 +1,105 @@
+package aggregator
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	testRewardPool = "0x01"
+	lpA            = "0x000000000000000000000000000000000000000A"
+	lpB            = "0x000000000000000000000000000000000000000B"
+	lpC            = "0x000000000000000000000000000000000000000C"
+)
+
+func TestLPRewardsAccrueProportionally(t *testing.T) {
+	ledger := NewLPRewardLedger()
+	distribution := &lvrtypes.MEVDistribution{PoolID: testRewardPool, LPAmount: big.NewInt(100)}
+	shares := map[string]*big.Int{lpA: big.NewInt(1), lpB: big.NewInt(1), lpC: big.NewInt(1)}
+
+	// 100 wei over three equal shares leaves 1 wei of dust, given to the first LP by address
+	if err := ledger.Accrue(distribution, shares); err != nil {
+		t.Fatalf("Accrue: %v", err)
+	}
+	// 100 wei over 1:2:3 is 16.67, 33.33 and 50, so the dust goes to lpA's larger remainder
+	shares = map[string]*big.Int{lpA: big.NewInt(1), lpB: big.NewInt(2), lpC: big.NewInt(3)}
+	if err := ledger.Accrue(distribution, shares); err != nil {
+		t.Fatalf("Accrue: %v", err)
+	}
+
+	total := new(big.Int)
+	for lp, want := range map[string]int64{lpA: 34 + 17, lpB: 33 + 33, lpC: 33 + 50} {
+		reward, ok := ledger.Reward(lp, testRewardPool)
+		if !ok || reward.RewardAmount.Int64() != want {
+			t.Fatalf("%s reward = %+v, want %d", lp, reward, want)
+		}
+		total.Add(total, reward.RewardAmount)
+	}
+	if total.Int64() != 200 {
+		t.Fatalf("accrued %s in total, want exactly both LP amounts", total)
+	}
+
+	if err := ledger.Accrue(distribution, map[string]*big.Int{lpA: big.NewInt(0)}); !errors.Is(err, ErrNoLiquidity) {
+		t.Fatalf("Accrue without liquidity error = %v, want ErrNoLiquidity", err)
+	}
+}
+
+func TestLPRewardsRejectDoubleClaims(t *testing.T) {
+	ledger := NewLPRewardLedger()
+	claimTime := time.Unix(1700000000, 0)
+	ledger.now = func() time.Time { return claimTime }
+
+	distribution := &lvrtypes.MEVDistribution{PoolID: testRewardPool, LPAmount: big.NewInt(90)}
+	if err := ledger.Accrue(distribution, map[string]*big.Int{lpA: big.NewInt(2), lpB: big.NewInt(1)}); err != nil {
+		t.Fatalf("Accrue: %v", err)
+	}
+
+	// Concurrent claims of the same reward pay it out once
+	var wg sync.WaitGroup
+	results := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ledger.Claim(lpA, testRewardPool)
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var succeeded int
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrNothingToClaim):
+			t.Fatalf("Claim error = %v, want ErrNothingToClaim", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d claims succeeded, want 1", succeeded)
+	}
+
+	reward, _ := ledger.Reward(lpA, testRewardPool)
+	if reward.RewardAmount.Sign() != 0 || reward.ClaimedAmount.Int64() != 60 || !reward.LastClaimTime.Equal(claimTime) {
+		t.Fatalf("unexpected reward after claiming %+v", reward)
+	}
+
+	// Rewards accrued after a claim can be claimed again
+	if err := ledger.Accrue(distribution, map[string]*big.Int{lpA: big.NewInt(1)}); err != nil {
+		t.Fatalf("Accrue: %v", err)
+	}
+	claimed, err := ledger.Claim(lpA, testRewardPool)
+	if err != nil || claimed.Int64() != 90 {
+		t.Fatalf("Claim = %v, %v, want 90", claimed, err)
+	}
+	if reward, _ = ledger.Reward(lpA, testRewardPool); reward.ClaimedAmount.Int64() != 150 {
+		t.Fatalf("claimed amount = %s, want 150", reward.ClaimedAmount)
+	}
+}
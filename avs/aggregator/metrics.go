@@ -0,0 +1,135 @@
+package aggregator
+
+import (
+	"math/big"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Consensus failure reasons reported by lvr_aggregator_consensus_failures_total
+const (
+	failureInvalidSignatures = "invalid_signatures"
+	failureNoConsensus       = "no_consensus"
+	failureAggregation       = "aggregation"
+	failureSubmission        = "submission"
+	failureExpired           = "expired"
+	failureBelowReserve      = "below_reserve"
+	failureCancelled         = "cancelled"
+)
+
+// lvrMetrics are the LVR auction consensus metrics, served on the aggregator's
+// metrics endpoint:
+//
+//   - lvr_aggregator_tasks_received_total: tasks that received their first response
+//   - lvr_aggregator_responses_received_total{operator_id}: accepted responses per operator
+//   - lvr_aggregator_consensus_reached_total: tasks whose consensus was submitted on chain
+//   - lvr_aggregator_consensus_failures_total{reason}: tasks finalized or rejected without
+//     consensus; reason is invalid_signatures, no_consensus, aggregation, submission,
+//     expired, below_reserve or cancelled
+//   - lvr_aggregator_responses_to_quorum: responses a task had when it reached consensus,
+//     whose _sum over _count is the average responses to quorum
+//   - lvr_aggregator_winning_bid_wei: winning bid of each consensus, in wei
+//   - lvr_aggregator_contract_submissions_total{result}: service manager transactions,
+//     result is success or failure
+//   - lvr_aggregator_tasks_expired_total: tasks evicted after the task TTL
+type lvrMetrics struct {
+	tasksReceived     prometheus.Counter
+	responsesReceived *prometheus.CounterVec
+	consensusReached  prometheus.Counter
+	consensusFailures *prometheus.CounterVec
+	responsesToQuorum prometheus.Histogram
+	winningBid        prometheus.Histogram
+	submissions       *prometheus.CounterVec
+	expiredTasks      prometheus.Counter
+}
+
+func newLvrMetrics() *lvrMetrics {
+	const namespace = "lvr_aggregator"
+	return &lvrMetrics{
+		tasksReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_received_total",
+			Help:      "Tasks that received their first response",
+		}),
+		responsesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "responses_received_total",
+			Help:      "Task responses accepted, by operator",
+		}, []string{"operator_id"}),
+		consensusReached: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "consensus_reached_total",
+			Help:      "Tasks whose consensus was submitted on chain",
+		}),
+		consensusFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "consensus_failures_total",
+			Help:      "Tasks that failed to reach or submit consensus, by reason",
+		}, []string{"reason"}),
+		responsesToQuorum: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "responses_to_quorum",
+			Help:      "Responses a task had when it reached consensus",
+			Buckets:   prometheus.LinearBuckets(1, 1, 20),
+		}),
+		winningBid: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "winning_bid_wei",
+			Help:      "Winning bid of each consensus, in wei",
+			Buckets:   prometheus.ExponentialBuckets(1e12, 10, 10),
+		}),
+		submissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "contract_submissions_total",
+			Help:      "Consensus submissions to the service manager, by result",
+		}, []string{"result"}),
+		expiredTasks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_expired_total",
+			Help:      "Tasks evicted after the task TTL without reaching consensus",
+		}),
+	}
+}
+
+// register adds the metrics to reg
+func (m *lvrMetrics) register(reg prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		m.tasksReceived,
+		m.responsesReceived,
+		m.consensusReached,
+		m.consensusFailures,
+		m.responsesToQuorum,
+		m.winningBid,
+		m.submissions,
+		m.expiredTasks,
+	} {
+		if err := reg.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// observeResponse records an accepted response, and the task's arrival on its first
+func (m *lvrMetrics) observeResponse(operatorId types.OperatorId, firstResponse bool) {
+	if firstResponse {
+		m.tasksReceived.Inc()
+	}
+	m.responsesReceived.WithLabelValues(operatorId.Hex()).Inc()
+}
+
+// observeConsensus records a consensus submitted on chain
+func (m *lvrMetrics) observeConsensus(responses int, winningBid *big.Int) {
+	m.consensusReached.Inc()
+	m.responsesToQuorum.Observe(float64(responses))
+	if winningBid != nil {
+		bid, _ := new(big.Float).SetInt(winningBid).Float64()
+		m.winningBid.Observe(bid)
+	}
+}
+
+// observeFailure records a task that failed to reach or submit consensus
+func (m *lvrMetrics) observeFailure(reason string) {
+	m.consensusFailures.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,63 @@
+package aggregator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestConsensusMetrics(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+
+	registry := prometheus.NewRegistry()
+	if err := a.lvrMetrics.register(registry); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	winner := "0x00000000000000000000000000000000000000aa"
+	for _, operatorId := range [][32]byte{op1, op2} {
+		body := marshalTestResponse(t, newTestResponse(1, operatorId, winner, 10))
+		if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+			t.Fatalf("status = %d, want %d", got, http.StatusOK)
+		}
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.tasksReceived); got != 1 {
+		t.Fatalf("tasks received = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.responsesReceived.WithLabelValues(op1.Hex())); got != 1 {
+		t.Fatalf("responses received from op1 = %v, want 1", got)
+	}
+
+	// The submitted responses carry no BLS signatures, so none survive verification
+	if a.processCompletedTask(context.Background(), 1, a.taskResponses[1]) {
+		t.Fatal("expected unsigned responses not to reach consensus")
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.consensusFailures.WithLabelValues(failureInvalidSignatures)); got != 1 {
+		t.Fatalf("invalid signature failures = %v, want 1", got)
+	}
+
+	responses := []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 2, op1, winner, 2e15),
+		newSignedTestResponse(t, state, 2, op2, winner, 2e15),
+	}
+	if !a.processCompletedTask(context.Background(), 2, responses) {
+		t.Fatal("expected task 2 to reach consensus")
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.consensusReached); got != 1 {
+		t.Fatalf("consensus reached = %v, want 1", got)
+	}
+
+	count, err := testutil.GatherAndCount(registry, "lvr_aggregator_responses_to_quorum", "lvr_aggregator_winning_bid_wei")
+	if err != nil {
+		t.Fatalf("GatherAndCount: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("gathered %d histogram series, want 2", count)
+	}
+}
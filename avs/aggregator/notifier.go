@@ -0,0 +1,218 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// Consensus event types
+const (
+	EventConsensusFinalized = "consensus_finalized"
+	EventConsensusFailed    = "consensus_failed"
+)
+
+// Webhook payload formats
+const (
+	// WebhookFormatJSON posts the ConsensusEvent itself
+	WebhookFormatJSON = "json"
+	// WebhookFormatSlack posts a Slack incoming webhook message describing the event
+	WebhookFormatSlack = "slack"
+)
+
+const (
+	// defaultWebhookQueueSize is the number of events a webhook buffers before dropping new ones
+	defaultWebhookQueueSize = 100
+	// defaultWebhookRetries is the number of times a failed delivery is retried
+	defaultWebhookRetries = 3
+	// defaultWebhookBackoff is the delay before the first retry, doubled after each attempt
+	defaultWebhookBackoff = time.Second
+	// maxWebhookBackoff caps the delay between retries
+	maxWebhookBackoff = 30 * time.Second
+	// webhookTimeout bounds a single delivery attempt
+	webhookTimeout = 10 * time.Second
+)
+
+// ConsensusEvent is the outcome of a task that reached quorum
+type ConsensusEvent struct {
+	Type         string                    `json:"type"`
+	TaskIndex    uint32                    `json:"taskIndex"`
+	Response     AuctionTaskResponse       `json:"response"`
+	Distribution *lvrtypes.MEVDistribution `json:"distribution,omitempty"`
+	// Error is why a failed task's consensus was not submitted
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Notifier is told of every task whose consensus was finalized or failed.
+// Notify is called from the consensus loop and must not block.
+type Notifier interface {
+	Notify(event ConsensusEvent)
+}
+
+// WebhookConfig configures a webhook notified of consensus events. Unset fields
+// use the defaults.
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// Format is WebhookFormatJSON (default) or WebhookFormatSlack
+	Format string `json:"format"`
+	// QueueSize is the number of undelivered events buffered before new ones are dropped
+	QueueSize int `json:"queue_size"`
+	// Retries is how many times a failed delivery is retried with exponential backoff
+	Retries uint32 `json:"retries"`
+}
+
+// AddNotifier registers a notifier of consensus events. Notifiers must be
+// registered before Start.
+func (a *Aggregator) AddNotifier(notifier Notifier) {
+	a.notifiers = append(a.notifiers, notifier)
+}
+
+// notifyConsensus reports a consensus event to every notifier
+func (a *Aggregator) notifyConsensus(event ConsensusEvent) {
+	event.Timestamp = a.now()
+	for _, notifier := range a.notifiers {
+		notifier.Notify(event)
+	}
+}
+
+// WebhookNotifier POSTs consensus events to a URL. Events are queued by Notify
+// and delivered in order by Run, retrying failed deliveries.
+type WebhookNotifier struct {
+	url     string
+	format  string
+	retries uint32
+	backoff time.Duration
+	client  *http.Client
+	queue   chan ConsensusEvent
+	logger  logging.Logger
+}
+
+// NewWebhookNotifier creates a webhook notifier, which delivers nothing until Run
+func NewWebhookNotifier(config WebhookConfig, logger logging.Logger) *WebhookNotifier {
+	format := config.Format
+	if format == "" {
+		format = WebhookFormatJSON
+	}
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultWebhookQueueSize
+	}
+	retries := config.Retries
+	if retries == 0 {
+		retries = defaultWebhookRetries
+	}
+	return &WebhookNotifier{
+		url:     config.URL,
+		format:  format,
+		retries: retries,
+		backoff: defaultWebhookBackoff,
+		client:  &http.Client{Timeout: webhookTimeout},
+		queue:   make(chan ConsensusEvent, queueSize),
+		logger:  logger.With("webhook", config.URL),
+	}
+}
+
+// Notify queues event for delivery, dropping it if the queue is full
+func (w *WebhookNotifier) Notify(event ConsensusEvent) {
+	select {
+	case w.queue <- event:
+	default:
+		w.logger.Warn("Webhook queue full, dropping consensus event", "type", event.Type, "taskIndex", event.TaskIndex)
+	}
+}
+
+// Run delivers queued events until ctx is cancelled
+func (w *WebhookNotifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-w.queue:
+			if err := w.deliver(ctx, event); err != nil {
+				w.logger.Error("Failed to deliver consensus event", "type", event.Type, "taskIndex", event.TaskIndex, "error", err)
+			}
+		}
+	}
+}
+
+// deliver posts an event, retrying with exponential backoff. It returns the last
+// error once the retries are exhausted.
+func (w *WebhookNotifier) deliver(ctx context.Context, event ConsensusEvent) error {
+	body, err := w.payload(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := w.backoff
+	for attempt := uint32(0); ; attempt++ {
+		err = w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= w.retries {
+			return fmt.Errorf("delivery failed after %d attempts: %w", w.retries+1, err)
+		}
+
+		w.logger.Warn("Webhook delivery failed, retrying", "taskIndex", event.TaskIndex, "attempt", attempt+1, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxWebhookBackoff)
+	}
+}
+
+// post sends a single delivery attempt, failing on any non-2xx status
+func (w *WebhookNotifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// payload encodes an event in the webhook's format
+func (w *WebhookNotifier) payload(event ConsensusEvent) ([]byte, error) {
+	if w.format != WebhookFormatSlack {
+		return json.Marshal(event)
+	}
+	return json.Marshal(map[string]string{"text": slackMessage(event)})
+}
+
+// slackMessage describes an event in a single line of Slack markdown
+func slackMessage(event ConsensusEvent) string {
+	winningBid := "0"
+	if event.Response.WinningBid != nil {
+		winningBid = event.Response.WinningBid.String()
+	}
+	if event.Type == EventConsensusFailed {
+		return fmt.Sprintf(":x: Task %d consensus failed (winner %s, bid %s): %s",
+			event.TaskIndex, event.Response.Winner.Hex(), winningBid, event.Error)
+	}
+
+	message := fmt.Sprintf(":white_check_mark: Task %d consensus finalized: winner %s, bid %s, %d bids",
+		event.TaskIndex, event.Response.Winner.Hex(), winningBid, event.Response.TotalBids)
+	if event.Distribution != nil {
+		message += fmt.Sprintf(", LPs receive %s", event.Distribution.LPAmount)
+	}
+	return message
+}
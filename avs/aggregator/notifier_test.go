@@ -0,0 +1,151 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// webhookRecorder is a webhook server failing the first failures deliveries
+type webhookRecorder struct {
+	mutex    sync.Mutex
+	failures int
+	attempts int
+	bodies   chan []byte
+}
+
+func newWebhookRecorder(failures int) *webhookRecorder {
+	return &webhookRecorder{failures: failures, bodies: make(chan []byte, 10)}
+}
+
+func (rec *webhookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec.mutex.Lock()
+	rec.attempts++
+	failed := rec.attempts <= rec.failures
+	rec.mutex.Unlock()
+	if failed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rec.bodies <- body
+}
+
+// receive waits for the next delivered payload
+func (rec *webhookRecorder) receive(t *testing.T) []byte {
+	t.Helper()
+	select {
+	case body := <-rec.bodies:
+		return body
+	case <-time.After(time.Second):
+		t.Fatal("expected the webhook to receive a payload")
+		return nil
+	}
+}
+
+// newTestWebhook starts a webhook notifier delivering to server, without retry delays
+func newTestWebhook(t *testing.T, server *httptest.Server, format string) *WebhookNotifier {
+	t.Helper()
+	webhook := NewWebhookNotifier(WebhookConfig{URL: server.URL, Format: format}, logging.NewNoopLogger())
+	webhook.backoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go webhook.Run(ctx)
+	return webhook
+}
+
+func TestWebhookReceivesFinalizedConsensus(t *testing.T) {
+	recorder := newWebhookRecorder(1)
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	a.AddNotifier(newTestWebhook(t, server, ""))
+
+	responses := []SignedAuctionTaskResponse{newSignedTestResponse(t, state, 1, op1, winnerX, 1000)}
+	if !a.processCompletedTask(context.Background(), 1, responses) {
+		t.Fatal("expected task to be processed")
+	}
+
+	var event ConsensusEvent
+	if err := json.Unmarshal(recorder.receive(t), &event); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if event.Type != EventConsensusFinalized || event.TaskIndex != 1 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if event.Response.Winner != common.HexToAddress(winnerX) || event.Response.WinningBid.Int64() != 1000 {
+		t.Fatalf("unexpected response %+v", event.Response)
+	}
+	if d := event.Distribution; d == nil || d.TotalAmount.Int64() != 1000 || d.LPAmount.Int64() != 850 {
+		t.Fatalf("unexpected distribution %+v", event.Distribution)
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if recorder.attempts != 2 {
+		t.Fatalf("webhook attempts = %d, want the failed delivery retried once", recorder.attempts)
+	}
+}
+
+func TestWebhookReceivesFailedConsensus(t *testing.T) {
+	recorder := newWebhookRecorder(0)
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	a.avsWriter = &fakeTaskResponder{failures: 10}
+	a.submissionBackoff = time.Millisecond
+	a.AddNotifier(newTestWebhook(t, server, WebhookFormatSlack))
+
+	responses := []SignedAuctionTaskResponse{newSignedTestResponse(t, state, 1, op1, winnerX, 1000)}
+	if a.processCompletedTask(context.Background(), 1, responses) {
+		t.Fatal("expected the task to fail submission")
+	}
+
+	var message struct{ Text string }
+	if err := json.Unmarshal(recorder.receive(t), &message); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if !strings.Contains(message.Text, "Task 1 consensus failed") || !strings.Contains(message.Text, "connection refused") {
+		t.Fatalf("unexpected slack message %q", message.Text)
+	}
+}
+
+func TestWebhookNotifyDropsWhenQueueFull(t *testing.T) {
+	webhook := NewWebhookNotifier(WebhookConfig{URL: "http://localhost", QueueSize: 1}, logging.NewNoopLogger())
+
+	done := make(chan struct{})
+	go func() {
+		webhook.Notify(ConsensusEvent{TaskIndex: 1})
+		webhook.Notify(ConsensusEvent{TaskIndex: 2})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Notify blocked on a full queue")
+	}
+
+	if queued := <-webhook.queue; queued.TaskIndex != 1 || len(webhook.queue) != 0 {
+		t.Fatalf("queued task %d with %d more, want only the first event", queued.TaskIndex, len(webhook.queue))
+	}
+}
@@ -0,0 +1,92 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// OperatorPerformance returns how often an operator agreed with the consensus
+// of the finalized tasks it responded to, and when it last sent a heartbeat
+func (a *Aggregator) OperatorPerformance(operatorId types.OperatorId) lvrtypes.Operator {
+	a.accuracyMux.RLock()
+	acc := a.accuracy[operatorId]
+	a.accuracyMux.RUnlock()
+
+	return lvrtypes.Operator{
+		Accuracy:        acc.ratio(),
+		TotalTasks:      acc.Total,
+		SuccessfulTasks: acc.Agreed,
+		LastSeen:        a.lastSeen(operatorId),
+	}
+}
+
+// OperatorStanding is an operator's entry in the GET /operators leaderboard
+type OperatorStanding struct {
+	OperatorId      string  `json:"operatorId"`
+	Accuracy        float64 `json:"accuracy"`
+	TotalTasks      uint64  `json:"totalTasks"`
+	SuccessfulTasks uint64  `json:"successfulTasks"`
+}
+
+// operatorLeaderboard ranks the operators that responded to finalized tasks by
+// accuracy, then by tasks agreed with consensus, then by operator id
+func (a *Aggregator) operatorLeaderboard() []OperatorStanding {
+	a.accuracyMux.RLock()
+	standings := make([]OperatorStanding, 0, len(a.accuracy))
+	for operatorId, acc := range a.accuracy {
+		standings = append(standings, OperatorStanding{
+			OperatorId:      operatorId.Hex(),
+			Accuracy:        acc.ratio(),
+			TotalTasks:      acc.Total,
+			SuccessfulTasks: acc.Agreed,
+		})
+	}
+	a.accuracyMux.RUnlock()
+
+	sort.Slice(standings, func(i, j int) bool {
+		x, y := standings[i], standings[j]
+		if x.Accuracy != y.Accuracy {
+			return x.Accuracy > y.Accuracy
+		}
+		if x.SuccessfulTasks != y.SuccessfulTasks {
+			return x.SuccessfulTasks > y.SuccessfulTasks
+		}
+		return x.OperatorId < y.OperatorId
+	})
+	return standings
+}
+
+// handleOperatorLeaderboard serves the operator leaderboard
+func (a *Aggregator) handleOperatorLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"operators": a.operatorLeaderboard()})
+}
+
+// rehydrateAccuracy restores the operators' accuracy history from the response store
+func (a *Aggregator) rehydrateAccuracy() error {
+	stored, err := a.responseStore.LoadAccuracy()
+	if err != nil {
+		return fmt.Errorf("failed to load stored operator accuracy: %w", err)
+	}
+
+	a.accuracyMux.Lock()
+	defer a.accuracyMux.Unlock()
+	for operatorId, acc := range stored {
+		a.accuracy[operatorId] = acc
+	}
+	if len(stored) > 0 {
+		a.logger.Info("Restored operator accuracy history", "operators", len(stored))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package aggregator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+)
+
+func TestOperatorLeaderboardTracksAccuracyAcrossRestarts(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+	dir := t.TempDir()
+
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	a.responseStore = newTestFileStore(t, dir, nil)
+	ctx := context.Background()
+
+	// op3 is wrong on task 1, op2 on task 2, and op3 skips task 3
+	tasks := map[uint32]map[types.OperatorId]string{
+		1: {op1: winnerY, op2: winnerY, op3: winnerX},
+		2: {op1: winnerY, op2: winnerX, op3: winnerY},
+		3: {op1: winnerY, op2: winnerY},
+	}
+	for _, taskIndex := range []uint32{1, 2, 3} {
+		var responses []SignedAuctionTaskResponse
+		for _, operatorId := range []types.OperatorId{op1, op2, op3} {
+			if winner, responded := tasks[taskIndex][operatorId]; responded {
+				responses = append(responses, newSignedTestResponse(t, state, taskIndex, operatorId, winner, 100))
+			}
+		}
+		if !a.processCompletedTask(ctx, taskIndex, responses) {
+			t.Fatalf("expected task %d to be processed", taskIndex)
+		}
+	}
+
+	want := []OperatorStanding{
+		{OperatorId: op1.Hex(), Accuracy: 1, TotalTasks: 3, SuccessfulTasks: 3},
+		{OperatorId: op2.Hex(), Accuracy: 2.0 / 3, TotalTasks: 3, SuccessfulTasks: 2},
+		{OperatorId: op3.Hex(), Accuracy: 0.5, TotalTasks: 2, SuccessfulTasks: 1},
+	}
+
+	server := httptest.NewServer(a.httpHandler(nil))
+	defer server.Close()
+	var leaderboard struct {
+		Operators []OperatorStanding `json:"operators"`
+	}
+	if status := getJSON(t, server.URL+"/operators", &leaderboard); status != http.StatusOK {
+		t.Fatalf("GET /operators status = %d", status)
+	}
+	if !reflect.DeepEqual(leaderboard.Operators, want) {
+		t.Fatalf("leaderboard = %+v, want %+v", leaderboard.Operators, want)
+	}
+
+	// A restarted aggregator restores the history from the response store
+	restarted := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	restarted.responseStore = newTestFileStore(t, dir, nil)
+	if err := restarted.rehydrateAccuracy(); err != nil {
+		t.Fatalf("rehydrateAccuracy: %v", err)
+	}
+	if got := restarted.operatorLeaderboard(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("restored leaderboard = %+v, want %+v", got, want)
+	}
+	if performance := restarted.OperatorPerformance(op2); performance.SuccessfulTasks != 2 || performance.TotalTasks != 3 {
+		t.Fatalf("restored op2 performance = %+v, want 2/3", performance)
+	}
+}
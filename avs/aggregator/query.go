@@ -0,0 +1,143 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// Task statuses reported by the query API
+const (
+	TaskStatusPending   = "pending"
+	TaskStatusFinalized = "finalized"
+	TaskStatusFailed    = "failed"
+	TaskStatusCancelled = "cancelled"
+)
+
+// TaskConsensus is the consensus submitted for a finalized task
+type TaskConsensus struct {
+	Winner        common.Address `json:"winner"`
+	WinningBid    *big.Int       `json:"winningBid"`
+	TotalBids     uint32         `json:"totalBids"`
+	Responses     int            `json:"responses"`
+	Signers       int            `json:"signers"`
+	NonSigners    int            `json:"nonSigners"`
+	FinalizedTime time.Time      `json:"finalizedTime"`
+	// MismatchedOperators are the ids of operators whose response conflicted with consensus
+	MismatchedOperators []string `json:"mismatchedOperators,omitempty"`
+	// Distribution is how the winning bid is shared out
+	Distribution *lvrtypes.MEVDistribution `json:"distribution,omitempty"`
+	// BelowReserve is set when the winning bid fell short of the auction's
+	// ReservePrice, so the consensus was not submitted and the auction did not settle
+	BelowReserve bool     `json:"belowReserve,omitempty"`
+	ReservePrice *big.Int `json:"reservePrice,omitempty"`
+}
+
+// TaskStatus is the state of a task as reported by GET /task/{index}
+type TaskStatus struct {
+	TaskIndex     uint32 `json:"taskIndex"`
+	Status        string `json:"status"`
+	ResponseCount int    `json:"responseCount"`
+	// QuorumProgress is reported for pending tasks only
+	QuorumProgress *quorumProgress `json:"quorumProgress,omitempty"`
+	Consensus      *TaskConsensus  `json:"consensus,omitempty"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// ActiveTask summarizes a pending task for GET /tasks
+type ActiveTask struct {
+	TaskIndex     uint32    `json:"taskIndex"`
+	ResponseCount int       `json:"responseCount"`
+	FirstSeen     time.Time `json:"firstSeen"`
+}
+
+// handleGetTask reports the responses, quorum progress and consensus of a task
+func (a *Aggregator) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	index, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/task/"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid task index", http.StatusBadRequest)
+		return
+	}
+	taskIndex := uint32(index)
+
+	// Copy the task's state so quorum lookups don't hold the lock during RPC calls
+	a.taskResponsesMux.RLock()
+	responses := append([]SignedAuctionTaskResponse(nil), a.taskResponses[taskIndex]...)
+	finalized := a.finalizedTasks[taskIndex]
+	failure, failed := a.failedTasks[taskIndex]
+	cancelReason, cancelled := a.cancelledTasks[taskIndex]
+	consensus, hasConsensus := a.consensusResults[taskIndex]
+	a.taskResponsesMux.RUnlock()
+
+	status := TaskStatus{TaskIndex: taskIndex, ResponseCount: len(responses)}
+	switch {
+	case cancelled:
+		status.Status = TaskStatusCancelled
+		status.Error = cancelReason
+	case failed:
+		status.Status = TaskStatusFailed
+		status.Error = failure
+	case finalized:
+		status.Status = TaskStatusFinalized
+		if hasConsensus {
+			status.Consensus = &consensus
+			status.ResponseCount = consensus.Responses
+		}
+	case len(responses) > 0:
+		status.Status = TaskStatusPending
+		stakes, err := a.queryTaskStakes(r.Context(), taskIndex)
+		if err != nil {
+			a.logger.Error("Failed to evaluate quorum progress", "taskIndex", taskIndex, "error", err)
+			http.Error(w, "Failed to evaluate quorum progress", http.StatusServiceUnavailable)
+			return
+		}
+		progress := newQuorumProgress(responses, stakes)
+		status.QuorumProgress = &progress
+	default:
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleListTasks lists the tasks that have responses and are not finalized
+func (a *Aggregator) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.taskResponsesMux.RLock()
+	tasks := make([]ActiveTask, 0, len(a.taskResponses))
+	for taskIndex, responses := range a.taskResponses {
+		if a.finalizedTasks[taskIndex] || len(responses) == 0 {
+			continue
+		}
+		tasks = append(tasks, ActiveTask{
+			TaskIndex:     taskIndex,
+			ResponseCount: len(responses),
+			FirstSeen:     a.taskFirstSeen[taskIndex],
+		})
+	}
+	a.taskResponsesMux.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].TaskIndex < tasks[j].TaskIndex })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"tasks": tasks})
+}
@@ -0,0 +1,181 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getJSON(t *testing.T, url string, out interface{}) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s: %v", url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestTaskQueryAPI(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+
+	server := httptest.NewServer(a.httpHandler(nil))
+	defer server.Close()
+
+	winner := "0x00000000000000000000000000000000000000aa"
+	for _, submission := range []struct {
+		taskIndex uint32
+		operator  [32]byte
+	}{{1, op1}, {1, op2}, {2, op3}} {
+		body := marshalTestResponse(t, newTestResponse(submission.taskIndex, submission.operator, winner, 10))
+		if got := submitTestResponse(t, a, state.ecdsaKey(submission.operator), body).Code; got != http.StatusOK {
+			t.Fatalf("submit status = %d, want %d", got, http.StatusOK)
+		}
+	}
+
+	// Task 3 reaches consensus
+	responses := []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 3, op1, winner, 50),
+		newSignedTestResponse(t, state, 3, op2, winner, 50),
+		newSignedTestResponse(t, state, 3, op3, winner, 50),
+	}
+	if !a.processCompletedTask(context.Background(), 3, responses) {
+		t.Fatal("expected task 3 to be finalized")
+	}
+	a.markTaskFinalized(3)
+
+	var list struct {
+		Tasks []ActiveTask `json:"tasks"`
+	}
+	if code := getJSON(t, server.URL+"/tasks", &list); code != http.StatusOK {
+		t.Fatalf("GET /tasks status = %d", code)
+	}
+	if len(list.Tasks) != 2 || list.Tasks[0].TaskIndex != 1 || list.Tasks[0].ResponseCount != 2 || list.Tasks[1].TaskIndex != 2 {
+		t.Fatalf("unexpected active tasks %+v", list.Tasks)
+	}
+
+	var pending TaskStatus
+	if code := getJSON(t, server.URL+"/task/1", &pending); code != http.StatusOK {
+		t.Fatalf("GET /task/1 status = %d", code)
+	}
+	if pending.Status != TaskStatusPending || pending.ResponseCount != 2 || pending.QuorumProgress == nil ||
+		pending.QuorumProgress.RespondedOperators != 2 || pending.QuorumProgress.TotalOperators != 3 {
+		t.Fatalf("unexpected pending task status %+v", pending)
+	}
+
+	var finalized TaskStatus
+	if code := getJSON(t, server.URL+"/task/3", &finalized); code != http.StatusOK {
+		t.Fatalf("GET /task/3 status = %d", code)
+	}
+	if finalized.Status != TaskStatusFinalized || finalized.Consensus == nil ||
+		finalized.Consensus.WinningBid.Int64() != 50 || finalized.Consensus.Signers != 3 || finalized.ResponseCount != 3 {
+		t.Fatalf("unexpected finalized task status %+v", finalized)
+	}
+
+	if code := getJSON(t, server.URL+"/task/9", &TaskStatus{}); code != http.StatusNotFound {
+		t.Fatalf("GET /task/9 status = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := getJSON(t, server.URL+"/task/abc", &TaskStatus{}); code != http.StatusBadRequest {
+		t.Fatalf("GET /task/abc status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestTaskQueryReusesOperatorStakes(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+	clock := time.Now()
+	a.now = func() time.Time { return clock }
+
+	server := httptest.NewServer(a.httpHandler(nil))
+	defer server.Close()
+
+	body := marshalTestResponse(t, newTestResponse(1, op1, winnerX, 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op1), body).Code; got != http.StatusOK {
+		t.Fatalf("submit status = %d, want %d", got, http.StatusOK)
+	}
+
+	query := func() {
+		t.Helper()
+		var status TaskStatus
+		if code := getJSON(t, server.URL+"/task/1", &status); code != http.StatusOK || status.QuorumProgress == nil {
+			t.Fatalf("GET /task/1 status = %d, progress %+v", code, status.QuorumProgress)
+		}
+	}
+	state.stakeReads = 0
+	for i := 0; i < 5; i++ {
+		query()
+	}
+	if state.stakeReads != 1 {
+		t.Fatalf("5 queries read the operator stakes %d times, want once", state.stakeReads)
+	}
+
+	clock = clock.Add(queryStakeTTL)
+	query()
+	if state.stakeReads != 2 {
+		t.Fatalf("operator stakes read %d times, want a re-read after %s", state.stakeReads, queryStakeTTL)
+	}
+}
+
+func TestSlowTaskQueryDoesNotBlockFinalization(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+
+	server := httptest.NewServer(a.httpHandler(nil))
+	defer server.Close()
+
+	body := marshalTestResponse(t, newTestResponse(1, op1, winnerX, 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op1), body).Code; got != http.StatusOK {
+		t.Fatalf("submit status = %d, want %d", got, http.StatusOK)
+	}
+
+	// Two queries wait on one stake read that doesn't return
+	state.stakesStarted = make(chan struct{}, 2)
+	state.stakesRelease = make(chan struct{})
+	queried := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() { queried <- getJSON(t, server.URL+"/task/1", &TaskStatus{}) }()
+	}
+	select {
+	case <-state.stakesStarted:
+	case <-time.After(time.Second):
+		t.Fatal("expected the query to read the operator stakes")
+	}
+
+	finalized := make(chan struct{})
+	go func() {
+		a.markTaskFinalized(2)
+		close(finalized)
+	}()
+	select {
+	case <-finalized:
+	case <-time.After(time.Second):
+		t.Fatal("finalizing a task waited on a status query's stake read")
+	}
+
+	close(state.stakesRelease)
+	for i := 0; i < 2; i++ {
+		if code := <-queried; code != http.StatusOK {
+			t.Fatalf("GET /task/1 status = %d, want %d", code, http.StatusOK)
+		}
+	}
+	select {
+	case <-state.stakesStarted:
+		t.Fatal("concurrent queries read the operator stakes twice")
+	default:
+	}
+}
@@ -0,0 +1,235 @@
+package aggregator
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// operatorStateReader is the subset of the AVS registry reader used to authenticate
+// operators and evaluate quorum
+type operatorStateReader interface {
+	GetRegisteredOperatorId(ctx context.Context, address common.Address) (types.OperatorId, bool, error)
+	GetOperatorStakesAtBlock(ctx context.Context, quorumNumbers types.QuorumNums, blockNumber uint32) (map[types.OperatorId]*big.Int, error)
+	GetOperatorStakesPerQuorumAtBlock(ctx context.Context, quorumNumbers types.QuorumNums, blockNumber uint32) (map[types.QuorumNum]map[types.OperatorId]*big.Int, error)
+	GetOperatorPubkeys(ctx context.Context, operatorId types.OperatorId) (types.OperatorPubkeys, error)
+}
+
+// quorumProgress summarizes how much of the registered operator set has responded to a task
+type quorumProgress struct {
+	RespondedOperators int      `json:"respondedOperators"`
+	TotalOperators     int      `json:"totalOperators"`
+	RespondedStake     *big.Int `json:"respondedStake"`
+	TotalStake         *big.Int `json:"totalStake"`
+}
+
+// newQuorumProgress computes participation for the responses against the operator stakes.
+// Responses from operators outside the operator set, and repeated responses from the
+// same operator, are not counted.
+func newQuorumProgress(responses []SignedAuctionTaskResponse, stakes map[types.OperatorId]*big.Int) quorumProgress {
+	operatorIds := make([]types.OperatorId, len(responses))
+	for i, response := range responses {
+		operatorIds[i] = response.OperatorId
+	}
+	return newParticipation(operatorIds, stakes)
+}
+
+// newParticipation computes participation for the operators against the operator
+// stakes, ignoring operators outside the operator set and repeated ones
+func newParticipation(operatorIds []types.OperatorId, stakes map[types.OperatorId]*big.Int) quorumProgress {
+	progress := quorumProgress{
+		TotalOperators: len(stakes),
+		RespondedStake: new(big.Int),
+		TotalStake:     new(big.Int),
+	}
+
+	for _, stake := range stakes {
+		progress.TotalStake.Add(progress.TotalStake, stake)
+	}
+
+	seen := make(map[types.OperatorId]bool)
+	for _, operatorId := range operatorIds {
+		stake, registered := stakes[operatorId]
+		if !registered || seen[operatorId] {
+			continue
+		}
+		seen[operatorId] = true
+		progress.RespondedOperators++
+		progress.RespondedStake.Add(progress.RespondedStake, stake)
+	}
+
+	return progress
+}
+
+// meetsCountThreshold reports whether the responding operators make up at least
+// thresholdPercentage of the operator set by count
+func (p quorumProgress) meetsCountThreshold(thresholdPercentage uint32) bool {
+	if p.TotalOperators == 0 {
+		return false
+	}
+	return uint64(p.RespondedOperators)*100 >= uint64(thresholdPercentage)*uint64(p.TotalOperators)
+}
+
+// meetsStakeThreshold reports whether the responding operators hold at least
+// thresholdPercentage of the total stake
+func (p quorumProgress) meetsStakeThreshold(thresholdPercentage uint32) bool {
+	if p.TotalStake.Sign() == 0 {
+		return false
+	}
+	responded := new(big.Int).Mul(p.RespondedStake, big.NewInt(100))
+	required := new(big.Int).Mul(p.TotalStake, big.NewInt(int64(thresholdPercentage)))
+	return responded.Cmp(required) >= 0
+}
+
+// taskQuorumNumbers returns the quorums a task is evaluated against
+func (a *Aggregator) taskQuorumNumbers(taskIndex uint32) (types.QuorumNums, uint32) {
+	a.tasksMux.RLock()
+	task, exists := a.tasks[taskIndex]
+	a.tasksMux.RUnlock()
+
+	if exists && len(task.QuorumNumbers) > 0 {
+		return task.QuorumNumbers, task.TaskCreatedBlock
+	}
+	if exists {
+		return a.configuredQuorumNumbers(), task.TaskCreatedBlock
+	}
+	return a.configuredQuorumNumbers(), 0
+}
+
+// configuredQuorumNumbers returns the quorum_numbers tasks without metadata use
+func (a *Aggregator) configuredQuorumNumbers() types.QuorumNums {
+	quorumNumbers := make(types.QuorumNums, len(a.config.QuorumNumbers))
+	for i, quorum := range a.config.QuorumNumbers {
+		quorumNumbers[i] = types.QuorumNum(quorum)
+	}
+	return quorumNumbers
+}
+
+// hasDualQuorum reports whether the combined count and stake quorum is configured
+func (a *Aggregator) hasDualQuorum() bool {
+	return a.config.QuorumCountThreshold > 0 || a.config.QuorumStakeThreshold > 0
+}
+
+// taskQuorumProgress evaluates the responses against the operator set registered
+// in the task's quorums at its creation block
+func (a *Aggregator) taskQuorumProgress(ctx context.Context, taskIndex uint32, responses []SignedAuctionTaskResponse) (quorumProgress, error) {
+	stakes, err := a.taskStakes(ctx, taskIndex)
+	if err != nil {
+		return quorumProgress{}, err
+	}
+
+	return newQuorumProgress(responses, stakes), nil
+}
+
+// taskQuorumProgressPerQuorum evaluates the responses against the operator set
+// of each of the task's quorums separately, as registered at its creation block
+func (a *Aggregator) taskQuorumProgressPerQuorum(ctx context.Context, taskIndex uint32, responses []SignedAuctionTaskResponse) (map[types.QuorumNum]quorumProgress, error) {
+	quorumNumbers, _ := a.taskQuorumNumbers(taskIndex)
+	stakesPerQuorum, err := a.taskStakesPerQuorum(ctx, taskIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[types.QuorumNum]quorumProgress, len(quorumNumbers))
+	for _, quorum := range quorumNumbers {
+		progress[quorum] = newQuorumProgress(responses, stakesPerQuorum[quorum])
+	}
+	return progress, nil
+}
+
+// taskThreshold returns the threshold percentage each of a task's quorums must
+// meet: the task's own threshold when it has one, otherwise quorum_threshold
+func (a *Aggregator) taskThreshold(taskIndex uint32) uint32 {
+	a.tasksMux.RLock()
+	task, exists := a.tasks[taskIndex]
+	a.tasksMux.RUnlock()
+
+	if exists && task.QuorumThresholdPercentage > 0 {
+		return uint32(task.QuorumThresholdPercentage)
+	}
+	return a.currentQuorumThreshold()
+}
+
+// meetsQuorumThreshold checks that in every one of the task's quorums, the
+// responding operators hold at least the task's threshold percent of the stake
+// registered in that quorum. In a quorum with no registered stake the threshold
+// is applied to the operator count instead.
+func (a *Aggregator) meetsQuorumThreshold(ctx context.Context, taskIndex uint32, responses []SignedAuctionTaskResponse) (bool, error) {
+	progressPerQuorum, err := a.taskQuorumProgressPerQuorum(ctx, taskIndex, responses)
+	if err != nil {
+		return false, err
+	}
+	return meetsThresholdPerQuorum(progressPerQuorum, a.taskThreshold(taskIndex)), nil
+}
+
+// meetsThresholdPerQuorum reports whether every quorum meets thresholdPercentage
+// of its stake, or of its operator count in a quorum with no registered stake
+func meetsThresholdPerQuorum(progressPerQuorum map[types.QuorumNum]quorumProgress, thresholdPercentage uint32) bool {
+	for _, progress := range progressPerQuorum {
+		met := progress.meetsStakeThreshold(thresholdPercentage)
+		if progress.TotalStake.Sign() == 0 {
+			met = progress.meetsCountThreshold(thresholdPercentage)
+		}
+		if !met {
+			return false
+		}
+	}
+	return len(progressPerQuorum) > 0
+}
+
+// meetsQuorum applies the configured quorum rule to the responses of a task
+func (a *Aggregator) meetsQuorum(ctx context.Context, taskIndex uint32, responses []SignedAuctionTaskResponse) (bool, error) {
+	// The participation across the operator set is only needed for min_distinct_operators
+	var progress quorumProgress
+	if a.config.MinDistinctOperators > 0 {
+		var err error
+		if progress, err = a.taskQuorumProgress(ctx, taskIndex, responses); err != nil {
+			return false, err
+		}
+	}
+	progressPerQuorum, err := a.taskQuorumProgressPerQuorum(ctx, taskIndex, responses)
+	if err != nil {
+		return false, err
+	}
+	return a.quorumRuleMet(progress, progressPerQuorum, a.taskThreshold(taskIndex)), nil
+}
+
+// quorumRuleMet applies the configured quorum rule to participation measured
+// across the operator set and in each quorum, thresholdPercentage being the
+// threshold of the single-threshold rule
+func (a *Aggregator) quorumRuleMet(progress quorumProgress, progressPerQuorum map[types.QuorumNum]quorumProgress, thresholdPercentage uint32) bool {
+	// At least min_distinct_operators operators must respond, however much stake
+	// fewer of them hold
+	if a.config.MinDistinctOperators > 0 && uint32(progress.RespondedOperators) < a.config.MinDistinctOperators {
+		return false
+	}
+	if a.hasDualQuorum() {
+		return a.meetsDualThresholds(progressPerQuorum)
+	}
+	return meetsThresholdPerQuorum(progressPerQuorum, thresholdPercentage)
+}
+
+// meetsDualQuorum checks the configured count and stake thresholds, requiring both
+// to be met in every one of the task's quorums. A threshold left unset is not
+// checked, so a count threshold alone also passes operators without stake.
+func (a *Aggregator) meetsDualQuorum(ctx context.Context, taskIndex uint32, responses []SignedAuctionTaskResponse) (bool, error) {
+	progressPerQuorum, err := a.taskQuorumProgressPerQuorum(ctx, taskIndex, responses)
+	if err != nil {
+		return false, err
+	}
+	return a.meetsDualThresholds(progressPerQuorum), nil
+}
+
+// meetsDualThresholds reports whether every quorum meets the configured count and
+// stake thresholds that are set
+func (a *Aggregator) meetsDualThresholds(progressPerQuorum map[types.QuorumNum]quorumProgress) bool {
+	for _, progress := range progressPerQuorum {
+		if a.config.QuorumCountThreshold > 0 && !progress.meetsCountThreshold(a.config.QuorumCountThreshold) ||
+			a.config.QuorumStakeThreshold > 0 && !progress.meetsStakeThreshold(a.config.QuorumStakeThreshold) {
+			return false
+		}
+	}
+	return len(progressPerQuorum) > 0
+}
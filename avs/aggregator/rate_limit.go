@@ -0,0 +1,125 @@
+package aggregator
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default rate limits. An operator responds about once per task, so these leave
+// ample headroom for busy blocks while bounding a misbehaving client.
+const (
+	defaultPerIPRate        = 50
+	defaultPerIPBurst       = 200
+	defaultPerOperatorRate  = 20
+	defaultPerOperatorBurst = 100
+
+	// rateLimitPruneInterval is how often idle buckets are dropped
+	rateLimitPruneInterval = time.Minute
+)
+
+// RateLimitConfig bounds how fast task responses may be submitted. Rates are
+// sustained requests per second and bursts the requests allowed at once; unset
+// fields use the defaults, and a negative rate disables that limit.
+type RateLimitConfig struct {
+	PerIPRate        float64 `json:"per_ip_rate"`
+	PerIPBurst       int     `json:"per_ip_burst"`
+	PerOperatorRate  float64 `json:"per_operator_rate"`
+	PerOperatorBurst int     `json:"per_operator_burst"`
+}
+
+// rateLimiter is a set of token buckets, one per key, each refilled at rate
+// tokens per second up to burst. A nil rateLimiter allows every request.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+	mutex     sync.Mutex
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a limiter, or returns nil when rate is negative. Zero
+// values take the given defaults.
+func newRateLimiter(rate float64, burst int, defaultRate float64, defaultBurst int) *rateLimiter {
+	if rate < 0 {
+		return nil
+	}
+	if rate == 0 {
+		rate = defaultRate
+	}
+	if burst <= 0 {
+		burst = defaultBurst
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from key's bucket, reporting false when it is empty
+func (l *rateLimiter) allow(key string) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// prune drops the buckets that have refilled completely, which behave exactly
+// like new ones. Callers must hold the mutex.
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// limitByIP rejects requests with 429 once their client IP exceeds the per-IP limit
+func (a *Aggregator) limitByIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		if !a.ipLimiter.allow(ip) {
+			a.logger.Warn("Rate limiting client", "remoteAddr", r.RemoteAddr)
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,131 @@
+package aggregator
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+)
+
+// newTestRateLimiter creates a limiter whose clock only moves when advanced
+func newTestRateLimiter(rate float64, burst int) (*rateLimiter, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := newRateLimiter(rate, burst, defaultPerIPRate, defaultPerIPBurst)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestRateLimiterRefillsOverTime(t *testing.T) {
+	limiter, now := newTestRateLimiter(2, 3)
+
+	for i := 0; i < 3; i++ {
+		if !limiter.allow("a") {
+			t.Fatalf("request %d within the burst was refused", i)
+		}
+	}
+	if limiter.allow("a") {
+		t.Fatal("expected the request past the burst to be refused")
+	}
+	if !limiter.allow("b") {
+		t.Fatal("expected other keys to have their own bucket")
+	}
+
+	*now = now.Add(500 * time.Millisecond)
+	if !limiter.allow("a") {
+		t.Fatal("expected a token to be refilled after half a second")
+	}
+	if limiter.allow("a") {
+		t.Fatal("expected only the refilled token to be available")
+	}
+
+	// Idle buckets refill to the burst and are pruned
+	*now = now.Add(rateLimitPruneInterval)
+	limiter.allow("c")
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("%d buckets after pruning, want 1", len(limiter.buckets))
+	}
+
+	if newRateLimiter(-1, 0, defaultPerIPRate, defaultPerIPBurst) != nil {
+		t.Fatal("expected a negative rate to disable the limiter")
+	}
+	var disabled *rateLimiter
+	if !disabled.allow("a") {
+		t.Fatal("expected a nil limiter to allow every request")
+	}
+}
+
+func TestSubmitResponseRateLimitedPerIP(t *testing.T) {
+	state := newFakeOperatorState()
+	op := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+	a.ipLimiter, _ = newTestRateLimiter(1, 2)
+	handler := a.httpHandler(nil)
+
+	submit := func(remoteAddr string, taskIndex uint32) *httptest.ResponseRecorder {
+		body := marshalTestResponse(t, newTestResponse(taskIndex, op, "0x00000000000000000000000000000000000000aa", 10))
+		signature, err := operator.SignRequestBody(state.ecdsaKey(op), body)
+		if err != nil {
+			t.Fatalf("SignRequestBody: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/submit-response", bytes.NewReader(body))
+		req.Header.Set(OperatorSignatureHeader, signature)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	for taskIndex := uint32(1); taskIndex <= 2; taskIndex++ {
+		if recorder := submit("10.0.0.1:4000", taskIndex); recorder.Code != http.StatusOK {
+			t.Fatalf("response %d: status %d, want 200", taskIndex, recorder.Code)
+		}
+	}
+
+	// The limit applies to the client IP whatever its source port
+	recorder := submit("10.0.0.1:4001", 3)
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d past the per-IP limit, want 429", recorder.Code)
+	}
+	if recorder.Header().Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header")
+	}
+
+	if recorder := submit("10.0.0.2:4000", 3); recorder.Code != http.StatusOK {
+		t.Fatalf("status %d from another IP, want 200", recorder.Code)
+	}
+}
+
+func TestSubmitResponseRateLimitedPerOperator(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	a := newTestAggregator(t, Config{}, state)
+	a.operatorLimiter, _ = newTestRateLimiter(1, 2)
+
+	winner := "0x00000000000000000000000000000000000000aa"
+	for taskIndex := uint32(1); taskIndex <= 2; taskIndex++ {
+		body := marshalTestResponse(t, newTestResponse(taskIndex, op1, winner, 10))
+		if recorder := submitTestResponse(t, a, state.ecdsaKey(op1), body); recorder.Code != http.StatusOK {
+			t.Fatalf("response %d: status %d, want 200", taskIndex, recorder.Code)
+		}
+	}
+
+	body := marshalTestResponse(t, newTestResponse(3, op1, winner, 10))
+	if recorder := submitTestResponse(t, a, state.ecdsaKey(op1), body); recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d past the per-operator limit, want 429", recorder.Code)
+	}
+	a.taskResponsesMux.RLock()
+	stored := len(a.taskResponses[3])
+	a.taskResponsesMux.RUnlock()
+	if stored != 0 {
+		t.Fatal("expected the rate limited response not to be stored")
+	}
+
+	body = marshalTestResponse(t, newTestResponse(3, op2, winner, 10))
+	if recorder := submitTestResponse(t, a, state.ecdsaKey(op2), body); recorder.Code != http.StatusOK {
+		t.Fatalf("status %d from another operator, want 200", recorder.Code)
+	}
+}
@@ -0,0 +1,191 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// cancelReasonReorged cancels tasks whose creation block was reorged out
+	cancelReasonReorged = "reorged"
+	// headPollInterval is how often the chain head is read while new heads can't
+	// be subscribed to
+	headPollInterval = 2 * time.Second
+	// headResubscribeInterval is how long heads are polled for before subscribing
+	// to them is retried
+	headResubscribeInterval = time.Minute
+)
+
+// chainHeadReader is the chain access reorgs of task creation blocks are detected with
+type chainHeadReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*gethtypes.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *gethtypes.Header) (ethereum.Subscription, error)
+}
+
+// watchReorgs follows the chain head until ctx is cancelled, over a subscription
+// when the client supports one and by polling otherwise, dropping the tasks whose
+// creation block is reorged out
+func (a *Aggregator) watchReorgs(ctx context.Context) {
+	if a.headers == nil {
+		return
+	}
+	for {
+		err := a.streamHeads(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		a.logger.Warn("Chain head subscription unavailable, polling for new heads", "error", err)
+		a.pollHeads(ctx, headResubscribeInterval)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// streamHeads checks task blocks on every new head until the subscription fails
+func (a *Aggregator) streamHeads(ctx context.Context) error {
+	heads := make(chan *gethtypes.Header, taskStreamBuffer)
+	subscription, err := a.headers.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer subscription.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-subscription.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case head := <-heads:
+			a.onNewHead(ctx, head)
+		}
+	}
+}
+
+// pollHeads checks task blocks against the chain head read every headPollInterval,
+// for up to duration
+func (a *Aggregator) pollHeads(ctx context.Context, duration time.Duration) {
+	ticker := time.NewTicker(headPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(duration)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			return
+		case <-ticker.C:
+			head, err := a.headers.HeaderByNumber(ctx, nil)
+			if err != nil {
+				a.logger.Warn("Failed to read chain head", "error", err)
+				continue
+			}
+			a.onNewHead(ctx, head)
+		}
+	}
+}
+
+// onNewHead checks the creation blocks of unfinalized tasks when head does not
+// extend the last head seen, since only then can earlier blocks have been reorged
+// out. Tasks whose creation block hash is not yet known have it recorded.
+func (a *Aggregator) onNewHead(ctx context.Context, head *gethtypes.Header) {
+	a.headMux.Lock()
+	last := a.lastHead
+	a.lastHead = head
+	a.headMux.Unlock()
+	if last != nil && head.Hash() == last.Hash() {
+		return
+	}
+	reorged := last != nil && head.ParentHash != last.Hash()
+
+	a.taskResponsesMux.RLock()
+	a.tasksMux.RLock()
+	var pending []uint32
+	for taskIndex, task := range a.tasks {
+		if a.finalizedTasks[taskIndex] || task.TaskCreatedBlock == 0 {
+			continue
+		}
+		if reorged || task.TaskCreatedBlockHash == (common.Hash{}) {
+			pending = append(pending, taskIndex)
+		}
+	}
+	a.tasksMux.RUnlock()
+	a.taskResponsesMux.RUnlock()
+
+	if reorged {
+		a.logger.Warn("Chain reorg detected, checking task creation blocks",
+			"head", head.Number,
+			"previousHead", last.Number,
+			"tasks", len(pending),
+		)
+	}
+	for _, taskIndex := range pending {
+		a.dropIfReorged(ctx, taskIndex)
+	}
+}
+
+// dropIfReorged cancels a task whose creation block is no longer on the canonical
+// chain, dropping its responses, and reports whether it did. Tasks without a
+// known creation block, or whose block can't be read, are kept.
+func (a *Aggregator) dropIfReorged(ctx context.Context, taskIndex uint32) bool {
+	if a.headers == nil {
+		return false
+	}
+	a.tasksMux.RLock()
+	task, known := a.tasks[taskIndex]
+	a.tasksMux.RUnlock()
+	if !known || task.TaskCreatedBlock == 0 {
+		return false
+	}
+
+	header, err := a.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(uint64(task.TaskCreatedBlock)))
+	var canonical common.Hash
+	switch {
+	case err != nil && !errors.Is(err, ethereum.NotFound):
+		a.logger.Warn("Failed to read task creation block", "taskIndex", taskIndex, "block", task.TaskCreatedBlock, "error", err)
+		return false
+	case err == nil && header != nil:
+		canonical = header.Hash()
+	}
+	// Otherwise the chain no longer reaches the creation block
+
+	if task.TaskCreatedBlockHash == (common.Hash{}) {
+		if canonical == (common.Hash{}) {
+			return false
+		}
+		// First seen without its block hash, so it is taken from the current chain
+		a.tasksMux.Lock()
+		if current, exists := a.tasks[taskIndex]; exists && current.TaskCreatedBlockHash == (common.Hash{}) {
+			current.TaskCreatedBlockHash = canonical
+			a.tasks[taskIndex] = current
+		}
+		a.tasksMux.Unlock()
+		return false
+	}
+	if canonical == task.TaskCreatedBlockHash {
+		return false
+	}
+
+	a.logger.Warn("Task creation block reorged out, dropping task",
+		"taskIndex", taskIndex,
+		"block", task.TaskCreatedBlock,
+		"blockHash", task.TaskCreatedBlockHash.Hex(),
+		"canonicalHash", canonical.Hex(),
+	)
+	if err := a.CancelTask(taskIndex, cancelReasonReorged); err != nil {
+		a.logger.Error("Failed to drop reorged task", "taskIndex", taskIndex, "error", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package aggregator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestReorgedTaskIsDropped(t *testing.T) {
+	h := newChainHarness(t)
+	op1 := h.registerOperator(1, 100)
+	op2 := h.registerOperator(2, 100)
+	h.registerOperator(3, 100)
+	a := h.newAggregator(Config{QuorumThreshold: 60})
+	ctx := context.Background()
+
+	// The canonical task is created in a block that stays canonical, the reorged
+	// task in the next one
+	canonicalTask, canonicalReceipt := h.createTask(common.HexToHash("0x01"))
+	reorgedTask, _ := h.createTask(common.HexToHash("0x02"))
+	if err := a.scanTasks(ctx); err != nil {
+		t.Fatalf("scanTasks: %v", err)
+	}
+	head, err := h.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("HeaderByNumber: %v", err)
+	}
+	a.onNewHead(ctx, head)
+	if got := a.tasks[canonicalTask].TaskCreatedBlockHash; got != canonicalReceipt.BlockHash {
+		t.Fatalf("canonical task block hash = %s, want the ingested %s", got.Hex(), canonicalReceipt.BlockHash.Hex())
+	}
+	if got := a.tasks[reorgedTask].TaskCreatedBlockHash; got != head.Hash() {
+		t.Fatalf("reorged task block hash = %s, want the ingested %s", got.Hex(), head.Hash().Hex())
+	}
+
+	submit := func(taskIndex uint32, operatorId types.OperatorId, want int) {
+		t.Helper()
+		body := marshalTestResponse(t, newSignedTestResponse(t, h.state, taskIndex, operatorId, winnerX, 1000))
+		if got := submitTestResponse(t, a, h.state.ecdsaKey(operatorId), body).Code; got != want {
+			t.Fatalf("submitting the response of operator %s to task %d = %d, want %d", operatorId.Hex(), taskIndex, got, want)
+		}
+	}
+	submit(reorgedTask, op1, http.StatusOK)
+	submit(canonicalTask, op1, http.StatusOK)
+
+	// A longer side chain forking off below the reorged task's block replaces it
+	if err := h.backend.Fork(ctx, head.ParentHash); err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if err := h.backend.AdjustTime(time.Second); err != nil {
+		t.Fatalf("AdjustTime: %v", err)
+	}
+	h.backend.Commit()
+	h.backend.Commit()
+	newHead, err := h.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("HeaderByNumber: %v", err)
+	}
+	if newHead.Number.Uint64() <= head.Number.Uint64() {
+		t.Fatalf("side chain head %d is not past the reorged head %d", newHead.Number, head.Number)
+	}
+	if err := a.scanTasks(ctx); err != nil {
+		t.Fatalf("scanTasks: %v", err)
+	}
+	a.onNewHead(ctx, newHead)
+
+	if reason := a.cancelledTasks[reorgedTask]; reason != cancelReasonReorged {
+		t.Fatalf("reorged task cancelled for %q, want %q", reason, cancelReasonReorged)
+	}
+	if responses := a.taskResponses[reorgedTask]; len(responses) != 0 {
+		t.Fatalf("%d responses kept for the reorged task, want none", len(responses))
+	}
+	if _, cancelled := a.cancelledTasks[canonicalTask]; cancelled {
+		t.Fatal("canonical task cancelled though its block is still canonical")
+	}
+
+	// Only the canonical task reaches consensus
+	submit(reorgedTask, op2, http.StatusGone)
+	submit(canonicalTask, op2, http.StatusOK)
+	a.checkAndProcessCompletedTasks(ctx)
+	calls := h.respondToTaskCalls()
+	if len(calls) != 1 || calls[0].TaskIndex != canonicalTask {
+		t.Fatalf("respondToTask calls = %+v, want one for task %d", calls, canonicalTask)
+	}
+}
+
+func TestReorgedTaskIsDroppedAtQuorum(t *testing.T) {
+	h := newChainHarness(t)
+	op1 := h.registerOperator(1, 50)
+	op2 := h.registerOperator(2, 50)
+	a := h.newAggregator(Config{QuorumThreshold: 60})
+	ctx := context.Background()
+
+	taskIndex, receipt := h.createTask(common.HexToHash("0x01"))
+	if err := a.scanTasks(ctx); err != nil {
+		t.Fatalf("scanTasks: %v", err)
+	}
+	head, err := h.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("HeaderByNumber: %v", err)
+	}
+	if got := a.tasks[taskIndex].TaskCreatedBlockHash; got != receipt.BlockHash {
+		t.Fatalf("task block hash = %s, want the ingested %s", got.Hex(), receipt.BlockHash.Hex())
+	}
+
+	// The reorg happens without its new head being seen
+	if err := h.backend.Fork(ctx, head.ParentHash); err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if err := h.backend.AdjustTime(time.Second); err != nil {
+		t.Fatalf("AdjustTime: %v", err)
+	}
+	h.backend.Commit()
+	h.backend.Commit()
+
+	for _, operatorId := range []types.OperatorId{op1, op2} {
+		body := marshalTestResponse(t, newSignedTestResponse(t, h.state, taskIndex, operatorId, winnerX, 1000))
+		if got := submitTestResponse(t, a, h.state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+			t.Fatalf("submitting the response of operator %s = %d, want 200", operatorId.Hex(), got)
+		}
+	}
+	a.checkAndProcessCompletedTasks(ctx)
+
+	if calls := h.respondToTaskCalls(); len(calls) != 0 {
+		t.Fatalf("submitted %d responses for a reorged task, want none", len(calls))
+	}
+	if reason := a.cancelledTasks[taskIndex]; reason != cancelReasonReorged {
+		t.Fatalf("task cancelled for %q, want %q", reason, cancelReasonReorged)
+	}
+}
+
+func TestRemovedTaskEventDropsTask(t *testing.T) {
+	h := newChainHarness(t)
+	a := h.newAggregator(Config{QuorumThreshold: 60})
+	ctx := context.Background()
+
+	taskIndex, _ := h.createTask(common.HexToHash("0x01"))
+	logs, err := h.backend.FilterLogs(ctx, a.taskQuery())
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("FilterLogs = %d logs, %v; want the NewTaskCreated event", len(logs), err)
+	}
+	a.ingestTaskLog(logs[0])
+	if _, known := a.tasks[taskIndex]; !known {
+		t.Fatal("expected the created task to be ingested")
+	}
+
+	// The subscription redelivers the event as removed when its block is reorged out
+	removed := logs[0]
+	removed.Removed = true
+	a.ingestTaskLog(removed)
+	if reason := a.cancelledTasks[taskIndex]; reason != cancelReasonReorged {
+		t.Fatalf("task cancelled for %q, want %q", reason, cancelReasonReorged)
+	}
+}
+
+func TestReorgedTaskIsReopenedWhenRecreated(t *testing.T) {
+	h := newChainHarness(t)
+	op1 := h.registerOperator(1, 100)
+	a := h.newAggregator(Config{QuorumThreshold: 60})
+	ctx := context.Background()
+
+	taskIndex, _ := h.createTask(common.HexToHash("0x01"))
+	logs, err := h.backend.FilterLogs(ctx, a.taskQuery())
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("FilterLogs = %d logs, %v; want the NewTaskCreated event", len(logs), err)
+	}
+	a.ingestTaskLog(logs[0])
+	removed := logs[0]
+	removed.Removed = true
+	a.ingestTaskLog(removed)
+	if reason := a.cancelledTasks[taskIndex]; reason != cancelReasonReorged {
+		t.Fatalf("task cancelled for %q, want %q", reason, cancelReasonReorged)
+	}
+
+	// The new chain creates the task again under the same index in another block
+	recreated := logs[0]
+	recreated.BlockHash = common.HexToHash("0xfe")
+	a.ingestTaskLog(recreated)
+
+	if _, cancelled := a.cancelledTasks[taskIndex]; cancelled {
+		t.Fatal("expected the re-created task to no longer be cancelled")
+	}
+	if got := a.tasks[taskIndex].TaskCreatedBlockHash; got != recreated.BlockHash {
+		t.Fatalf("task block hash = %s, want the re-created %s", got.Hex(), recreated.BlockHash.Hex())
+	}
+	body := marshalTestResponse(t, newSignedTestResponse(t, h.state, taskIndex, op1, winnerX, 1000))
+	if got := submitTestResponse(t, a, h.state.ecdsaKey(op1), body).Code; got != http.StatusOK {
+		t.Fatalf("submitting to the re-created task = %d, want 200", got)
+	}
+}
@@ -0,0 +1,61 @@
+package aggregator
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// taskReservePrice returns the reserve price of a task's auction, nil if it has
+// none or the task's metadata is unknown
+func (a *Aggregator) taskReservePrice(taskIndex uint32) *big.Int {
+	a.tasksMux.RLock()
+	defer a.tasksMux.RUnlock()
+	return a.tasks[taskIndex].ReservePrice
+}
+
+// belowReserve reports whether a winning bid falls short of the reserve. A zero
+// bid, voting that the auction has no winner, has nothing to settle.
+func belowReserve(winningBid, reserve *big.Int) bool {
+	if reserve == nil || winningBid == nil || winningBid.Sign() == 0 {
+		return false
+	}
+	return winningBid.Cmp(reserve) < 0
+}
+
+// finalizeBelowReserve records a consensus whose winning bid is below the
+// auction's reserve as not settling: it is neither submitted on chain nor
+// distributed, but still counts towards operator accuracy
+func (a *Aggregator) finalizeBelowReserve(taskIndex uint32, consensus *responseCluster, clusters []*responseCluster, responses int, reserve *big.Int) {
+	response := consensus.response.AuctionTaskResponse
+	a.logger.Warn("Consensus winning bid is below the reserve price, auction does not settle",
+		"taskIndex", taskIndex,
+		"winningBid", response.WinningBid.String(),
+		"reservePrice", reserve.String(),
+	)
+
+	mismatched := a.recordAccuracy(consensus, clusters)
+	a.lvrMetrics.observeFailure(failureBelowReserve)
+
+	now := a.now()
+	a.taskResponsesMux.Lock()
+	a.consensusResults[taskIndex] = TaskConsensus{
+		Winner:              response.Winner,
+		WinningBid:          response.WinningBid,
+		TotalBids:           response.TotalBids,
+		Responses:           responses,
+		FinalizedTime:       now,
+		MismatchedOperators: operatorIdHexes(mismatched),
+		BelowReserve:        true,
+		ReservePrice:        reserve,
+	}
+	a.taskResponsesMux.Unlock()
+
+	a.auctionStats.recordFailure(now)
+	a.notifyConsensusMismatches(taskIndex, mismatched)
+	a.notifyConsensus(ConsensusEvent{
+		Type:      EventConsensusFailed,
+		TaskIndex: taskIndex,
+		Response:  response,
+		Error:     fmt.Sprintf("winning bid %s is below the reserve price %s", response.WinningBid, reserve),
+	})
+}
@@ -0,0 +1,55 @@
+package aggregator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestConsensusBelowReserveDoesNotSettle(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	responder := a.avsWriter.(*fakeTaskResponder)
+
+	cases := []struct {
+		name    string
+		bid     int64
+		reserve *big.Int
+		settles bool
+	}{
+		{"no reserve", 999, nil, true},
+		{"bid at the reserve", 1000, big.NewInt(1000), true},
+		{"bid above the reserve", 1001, big.NewInt(1000), true},
+		{"bid below the reserve", 999, big.NewInt(1000), false},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskIndex := uint32(i + 1)
+			a.AddTask(taskIndex, AuctionTask{ReservePrice: tc.reserve})
+			responses := []SignedAuctionTaskResponse{newSignedTestResponse(t, state, taskIndex, op1, winnerX, tc.bid)}
+			if !a.processCompletedTask(context.Background(), taskIndex, responses) {
+				t.Fatal("expected the task to be finalized")
+			}
+
+			result := a.consensusResults[taskIndex]
+			responder.mutex.Lock()
+			_, submitted := responder.signatures[taskIndex]
+			responder.mutex.Unlock()
+			if submitted != tc.settles || result.BelowReserve == tc.settles || (result.Distribution != nil) != tc.settles {
+				t.Fatalf("submitted = %v, belowReserve = %v, distribution = %+v; want settled %v", submitted, result.BelowReserve, result.Distribution, tc.settles)
+			}
+		})
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.consensusFailures.WithLabelValues(failureBelowReserve)); got != 1 {
+		t.Fatalf("below_reserve failures = %v, want 1", got)
+	}
+}
+
+func TestNoWinnerConsensusIsNeverBelowReserve(t *testing.T) {
+	if belowReserve(big.NewInt(0), big.NewInt(1000)) {
+		t.Fatal("a zero bid reporting no winner was treated as below the reserve")
+	}
+}
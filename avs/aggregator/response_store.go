@@ -0,0 +1,388 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/types"
+)
+
+const (
+	// ResponseStoreMemory keeps task responses in memory only
+	ResponseStoreMemory = "memory"
+	// ResponseStoreFile persists task responses to files under ResponseStorePath
+	ResponseStoreFile = "file"
+)
+
+// responseFilePrefix and responseFileSuffix name the file holding a task's responses
+const (
+	responseFilePrefix = "task-"
+	responseFileSuffix = ".json"
+)
+
+// accuracyFile names the file holding the operators' accuracy history
+const accuracyFile = "operator-accuracy.json"
+
+// ResponseStore persists the responses of tasks that have not been finalized yet so
+// that in-flight consensus survives an aggregator restart, along with the
+// operators' accuracy history
+type ResponseStore interface {
+	// Save appends a response to the stored responses of its task
+	Save(taskIndex uint32, response SignedAuctionTaskResponse) error
+	// Load returns the stored responses of every task
+	Load() (map[uint32][]SignedAuctionTaskResponse, error)
+	// DeleteResponses removes the given responses from the stored responses of
+	// their task, leaving the others
+	DeleteResponses(taskIndex uint32, responses []SignedAuctionTaskResponse) error
+	// DeleteFinalized removes the stored responses of a finalized task
+	DeleteFinalized(taskIndex uint32) error
+	// SaveAccuracy replaces the stored accuracy history of every operator
+	SaveAccuracy(accuracy map[types.OperatorId]OperatorAccuracy) error
+	// LoadAccuracy returns the stored accuracy history of every operator
+	LoadAccuracy() (map[types.OperatorId]OperatorAccuracy, error)
+	// Flush makes everything stored so far durable, before the aggregator exits
+	Flush() error
+}
+
+// NewResponseStore creates the ResponseStore selected by config. Persisted
+// responses are encrypted with the key at ResponseStoreEncryptionKeyPath when
+// one is configured, which the memory store, persisting nothing, rejects.
+func NewResponseStore(config Config) (ResponseStore, error) {
+	switch config.ResponseStoreMode {
+	case "", ResponseStoreMemory:
+		if config.ResponseStoreEncryptionKeyPath != "" {
+			return nil, fmt.Errorf("response_store_encryption_key_path requires response_store_mode %q", ResponseStoreFile)
+		}
+		return memoryResponseStore{}, nil
+	case ResponseStoreFile:
+		var responseCipher ResponseCipher
+		if config.ResponseStoreEncryptionKeyPath != "" {
+			var err error
+			responseCipher, err = LoadResponseCipher(config.ResponseStoreEncryptionKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load response store encryption key: %w", err)
+			}
+		}
+		return NewFileResponseStore(config.ResponseStorePath, responseCipher)
+	default:
+		return nil, fmt.Errorf("unknown response store mode %q", config.ResponseStoreMode)
+	}
+}
+
+// memoryResponseStore persists nothing; responses live only in the aggregator's map
+type memoryResponseStore struct{}
+
+func (memoryResponseStore) Save(uint32, SignedAuctionTaskResponse) error { return nil }
+
+func (memoryResponseStore) Load() (map[uint32][]SignedAuctionTaskResponse, error) {
+	return map[uint32][]SignedAuctionTaskResponse{}, nil
+}
+
+func (memoryResponseStore) DeleteResponses(uint32, []SignedAuctionTaskResponse) error { return nil }
+
+func (memoryResponseStore) DeleteFinalized(uint32) error { return nil }
+
+func (memoryResponseStore) SaveAccuracy(map[types.OperatorId]OperatorAccuracy) error { return nil }
+
+func (memoryResponseStore) LoadAccuracy() (map[types.OperatorId]OperatorAccuracy, error) {
+	return map[types.OperatorId]OperatorAccuracy{}, nil
+}
+
+func (memoryResponseStore) Flush() error { return nil }
+
+// storedResponse is the persisted form of a SignedAuctionTaskResponse. The
+// signature is kept as its affine coordinates.
+type storedResponse struct {
+	AuctionTaskResponse
+	OperatorId     types.OperatorId `json:"operatorId"`
+	SignatureX     *big.Int         `json:"signatureX,omitempty"`
+	SignatureY     *big.Int         `json:"signatureY,omitempty"`
+	IdempotencyKey string           `json:"idempotencyKey,omitempty"`
+}
+
+func newStoredResponse(response SignedAuctionTaskResponse) storedResponse {
+	stored := storedResponse{
+		AuctionTaskResponse: response.AuctionTaskResponse,
+		OperatorId:          response.OperatorId,
+		IdempotencyKey:      response.IdempotencyKey,
+	}
+	if response.BlsSignature != nil && response.BlsSignature.G1Point != nil {
+		stored.SignatureX = response.BlsSignature.X.BigInt(new(big.Int))
+		stored.SignatureY = response.BlsSignature.Y.BigInt(new(big.Int))
+	}
+	return stored
+}
+
+// storedAccuracy is the persisted accuracy history of an operator
+type storedAccuracy struct {
+	OperatorAccuracy
+	OperatorId types.OperatorId `json:"operatorId"`
+}
+
+func (s storedResponse) response() SignedAuctionTaskResponse {
+	response := SignedAuctionTaskResponse{
+		AuctionTaskResponse: s.AuctionTaskResponse,
+		OperatorId:          s.OperatorId,
+		IdempotencyKey:      s.IdempotencyKey,
+	}
+	if s.SignatureX != nil && s.SignatureY != nil {
+		response.BlsSignature = &bls.Signature{G1Point: bls.NewG1Point(s.SignatureX, s.SignatureY)}
+	}
+	return response
+}
+
+// fileResponseStore keeps each task's responses in its own file under dir
+type fileResponseStore struct {
+	dir    string
+	cipher ResponseCipher
+	mutex  sync.Mutex
+}
+
+// NewFileResponseStore creates a ResponseStore that writes task responses under dir,
+// creating the directory if needed
+func NewFileResponseStore(dir string, responseCipher ResponseCipher) (ResponseStore, error) {
+	if dir == "" {
+		return nil, errors.New("response store path is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create response store directory: %w", err)
+	}
+
+	return &fileResponseStore{dir: dir, cipher: responseCipher}, nil
+}
+
+func (s *fileResponseStore) Save(taskIndex uint32, response SignedAuctionTaskResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, err := s.read(s.taskPath(taskIndex))
+	if err != nil {
+		return err
+	}
+	return s.write(taskIndex, append(stored, newStoredResponse(response)))
+}
+
+func (s *fileResponseStore) Load() (map[uint32][]SignedAuctionTaskResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response store: %w", err)
+	}
+
+	responses := make(map[uint32][]SignedAuctionTaskResponse)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, responseFilePrefix) || !strings.HasSuffix(name, responseFileSuffix) {
+			continue
+		}
+
+		index, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, responseFilePrefix), responseFileSuffix), 10, 32)
+		if err != nil {
+			continue
+		}
+
+		stored, err := s.read(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("task %d: %w", index, err)
+		}
+		for _, record := range stored {
+			responses[uint32(index)] = append(responses[uint32(index)], record.response())
+		}
+	}
+	return responses, nil
+}
+
+func (s *fileResponseStore) DeleteResponses(taskIndex uint32, responses []SignedAuctionTaskResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, err := s.read(s.taskPath(taskIndex))
+	if err != nil {
+		return err
+	}
+	kept := stored[:0]
+	for _, record := range stored {
+		if !containsStoredResponse(responses, record) {
+			kept = append(kept, record)
+		}
+	}
+	if len(kept) == len(stored) {
+		return nil
+	}
+	return s.write(taskIndex, kept)
+}
+
+// containsStoredResponse reports whether record is the stored form of one of
+// responses, matched by operator and signature
+func containsStoredResponse(responses []SignedAuctionTaskResponse, record storedResponse) bool {
+	for _, response := range responses {
+		candidate := newStoredResponse(response)
+		if candidate.OperatorId == record.OperatorId &&
+			bigIntsEqual(candidate.SignatureX, record.SignatureX) &&
+			bigIntsEqual(candidate.SignatureY, record.SignatureY) {
+			return true
+		}
+	}
+	return false
+}
+
+// bigIntsEqual reports whether x and y are both nil or hold the same value
+func bigIntsEqual(x, y *big.Int) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return x.Cmp(y) == 0
+}
+
+func (s *fileResponseStore) DeleteFinalized(taskIndex uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := os.Remove(s.taskPath(taskIndex))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete task responses: %w", err)
+	}
+	return nil
+}
+
+func (s *fileResponseStore) SaveAccuracy(accuracy map[types.OperatorId]OperatorAccuracy) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := make([]storedAccuracy, 0, len(accuracy))
+	for operatorId, acc := range accuracy {
+		stored = append(stored, storedAccuracy{OperatorAccuracy: acc, OperatorId: operatorId})
+	}
+	if err := s.writeJSON(filepath.Join(s.dir, accuracyFile), stored); err != nil {
+		return fmt.Errorf("failed to write operator accuracy: %w", err)
+	}
+	return nil
+}
+
+func (s *fileResponseStore) LoadAccuracy() (map[types.OperatorId]OperatorAccuracy, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var stored []storedAccuracy
+	if err := s.readJSON(filepath.Join(s.dir, accuracyFile), &stored); err != nil {
+		return nil, fmt.Errorf("failed to read operator accuracy: %w", err)
+	}
+
+	accuracy := make(map[types.OperatorId]OperatorAccuracy, len(stored))
+	for _, record := range stored {
+		accuracy[record.OperatorId] = record.OperatorAccuracy
+	}
+	return accuracy, nil
+}
+
+// Flush syncs every stored file and the directory holding them to disk
+func (s *fileResponseStore) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read response store: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := syncFile(filepath.Join(s.dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to flush %s: %w", entry.Name(), err)
+		}
+	}
+	if err := syncFile(s.dir); err != nil {
+		return fmt.Errorf("failed to flush response store directory: %w", err)
+	}
+	return nil
+}
+
+// syncFile commits the file or directory at path to disk
+func syncFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
+
+func (s *fileResponseStore) taskPath(taskIndex uint32) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s%d%s", responseFilePrefix, taskIndex, responseFileSuffix))
+}
+
+func (s *fileResponseStore) read(path string) ([]storedResponse, error) {
+	var stored []storedResponse
+	if err := s.readJSON(path, &stored); err != nil {
+		return nil, fmt.Errorf("failed to read task responses: %w", err)
+	}
+	return stored, nil
+}
+
+func (s *fileResponseStore) write(taskIndex uint32, stored []storedResponse) error {
+	if err := s.writeJSON(s.taskPath(taskIndex), stored); err != nil {
+		return fmt.Errorf("failed to write task responses: %w", err)
+	}
+	return nil
+}
+
+// readJSON decrypts and decodes the file at path into out, leaving out unchanged
+// if the file does not exist
+func (s *fileResponseStore) readJSON(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.cipher != nil {
+		data, err = s.cipher.Decrypt(data)
+		if err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, out)
+}
+
+// writeJSON encodes and encrypts value, atomically replacing the file at path
+func (s *fileResponseStore) writeJSON(path string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if s.cipher != nil {
+		data, err = s.cipher.Encrypt(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".responses-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
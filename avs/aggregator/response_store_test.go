@@ -0,0 +1,303 @@
+package aggregator
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func newTestFileStore(t *testing.T, dir string, responseCipher ResponseCipher) ResponseStore {
+	t.Helper()
+	store, err := NewFileResponseStore(dir, responseCipher)
+	if err != nil {
+		t.Fatalf("NewFileResponseStore: %v", err)
+	}
+	return store
+}
+
+func TestFileResponseStoreRoundTrip(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+
+	responseCipher, err := NewAESGCMCipher(newTestKey(t))
+	if err != nil {
+		t.Fatalf("NewAESGCMCipher: %v", err)
+	}
+
+	dir := t.TempDir()
+	store := newTestFileStore(t, dir, responseCipher)
+
+	winner := "0x00000000000000000000000000000000000000aa"
+	saved := []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, 1, op1, winner, 1000),
+		newSignedTestResponse(t, state, 1, op2, winner, 1000),
+	}
+	for _, response := range saved {
+		if err := store.Save(1, response); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	if err := store.Save(2, newSignedTestResponse(t, state, 2, op1, winner, 5)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	// Responses are encrypted on disk
+	data, err := os.ReadFile(filepath.Join(dir, "task-1.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if bytes.Contains(bytes.ToLower(data), []byte(strings.TrimPrefix(winner, "0x"))) {
+		t.Fatal("stored responses contain plaintext winner")
+	}
+
+	// A new store over the same directory sees the persisted responses
+	loaded, err := newTestFileStore(t, dir, responseCipher).Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded) != 2 || len(loaded[1]) != 2 || len(loaded[2]) != 1 {
+		t.Fatalf("unexpected loaded responses: %v", loaded)
+	}
+
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	for i, response := range loaded[1] {
+		if response.OperatorId != saved[i].OperatorId || response.WinningBid.Cmp(saved[i].WinningBid) != 0 {
+			t.Fatalf("response %d does not match saved response", i)
+		}
+		if err := a.verifyResponse(context.Background(), response); err != nil {
+			t.Fatalf("loaded signature does not verify: %v", err)
+		}
+	}
+
+	if err := store.DeleteFinalized(1); err != nil {
+		t.Fatalf("DeleteFinalized: %v", err)
+	}
+	loaded, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, exists := loaded[1]; exists || len(loaded[2]) != 1 {
+		t.Fatalf("expected only task 2 to remain, got %v", loaded)
+	}
+}
+
+func TestAggregatorRehydratesResponsesAfterRestart(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+
+	dir := t.TempDir()
+	config := Config{QuorumThreshold: 67}
+	winner := "0x00000000000000000000000000000000000000aa"
+
+	// Two of three operators respond before the aggregator restarts
+	before := newTestAggregator(t, config, state)
+	before.responseStore = newTestFileStore(t, dir, nil)
+	for _, operatorId := range []types.OperatorId{op1, op2} {
+		if err := before.responseStore.Save(7, newSignedTestResponse(t, state, 7, operatorId, winner, 1000)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	after := newTestAggregator(t, config, state)
+	after.responseStore = newTestFileStore(t, dir, nil)
+	if err := after.rehydrateResponses(); err != nil {
+		t.Fatalf("rehydrateResponses: %v", err)
+	}
+	if got := len(after.taskResponses[7]); got != 2 {
+		t.Fatalf("expected 2 restored responses, got %d", got)
+	}
+
+	// The third response completes the quorum started before the restart
+	after.taskResponses[7] = append(after.taskResponses[7], newSignedTestResponse(t, state, 7, op3, winner, 1000))
+	after.checkAndProcessCompletedTasks(context.Background())
+	if !after.finalizedTasks[7] {
+		t.Fatal("expected task to be finalized after restart")
+	}
+
+	loaded, err := after.responseStore.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Fatalf("expected finalized task to be deleted from store, got %v", loaded)
+	}
+}
+
+func TestResendIsRecognizedAfterRestart(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+	dir := t.TempDir()
+
+	response := newSignedTestResponse(t, state, 7, operatorId, winnerX, 1000)
+	response.IdempotencyKey = lvrtypes.TaskResponseIdempotencyKey(operatorId, 7)
+	body := marshalTestResponse(t, response)
+
+	before := newTestAggregator(t, Config{}, state)
+	before.responseStore = newTestFileStore(t, dir, nil)
+	if got := submitTestResponse(t, before, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+		t.Fatalf("submitting before the restart = %d, want 200", got)
+	}
+
+	// The operator re-sends the response the restarted aggregator already stored
+	after := newTestAggregator(t, Config{}, state)
+	after.responseStore = newTestFileStore(t, dir, nil)
+	if err := after.rehydrateResponses(); err != nil {
+		t.Fatalf("rehydrateResponses: %v", err)
+	}
+	if got := after.taskResponses[7][0].IdempotencyKey; got != response.IdempotencyKey {
+		t.Fatalf("restored idempotency key = %q, want %q", got, response.IdempotencyKey)
+	}
+	if got := submitTestResponse(t, after, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+		t.Fatalf("re-sending after the restart = %d, want 200", got)
+	}
+	if got := len(after.taskResponses[7]); got != 1 {
+		t.Fatalf("stored %d responses, want the re-send ignored", got)
+	}
+}
+
+func TestHandleTaskResponseSubmissionPersists(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+
+	a := newTestAggregator(t, Config{}, state)
+	a.responseStore = newTestFileStore(t, t.TempDir(), nil)
+
+	body, err := json.Marshal(newTestResponse(3, operatorId, "0x00000000000000000000000000000000000000aa", 10))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	recorder := submitTestResponse(t, a, state.ecdsaKey(operatorId), body)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	loaded, err := a.responseStore.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded[3]) != 1 || loaded[3][0].OperatorId != operatorId {
+		t.Fatalf("expected submitted response to be persisted, got %v", loaded)
+	}
+}
+
+func TestRejectedResponsesAreDeletedFromStore(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+	a.responseStore = newTestFileStore(t, t.TempDir(), nil)
+
+	// The third response is unsigned and is rejected once the task is processed
+	responses := map[types.OperatorId]SignedAuctionTaskResponse{
+		op1: newSignedTestResponse(t, state, 3, op1, winnerX, 1000),
+		op2: newSignedTestResponse(t, state, 3, op2, winnerX, 1000),
+		op3: newTestResponse(3, op3, winnerX, 1000),
+	}
+	for operatorId, response := range responses {
+		if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), marshalTestResponse(t, response)).Code; got != http.StatusOK {
+			t.Fatalf("submitting the response of operator %s = %d, want 200", operatorId.Hex(), got)
+		}
+	}
+	a.checkAndProcessCompletedTasks(context.Background())
+	if a.finalizedTasks[3] {
+		t.Fatal("expected the task to fall short of quorum without the rejected response")
+	}
+
+	loaded, err := a.responseStore.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded[3]) != 2 {
+		t.Fatalf("stored %d responses, want the 2 valid ones", len(loaded[3]))
+	}
+	for _, response := range loaded[3] {
+		if response.OperatorId == op3 {
+			t.Fatal("rejected response is still stored")
+		}
+	}
+}
+
+func TestNewResponseStoreModes(t *testing.T) {
+	if _, err := NewResponseStore(Config{}); err != nil {
+		t.Fatalf("default mode: %v", err)
+	}
+	if _, err := NewResponseStore(Config{ResponseStoreMode: ResponseStoreFile}); err == nil {
+		t.Fatal("expected error for file mode without a path")
+	}
+	if _, err := NewResponseStore(Config{ResponseStoreMode: "bolt"}); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+	if _, err := NewResponseStore(Config{ResponseStoreEncryptionKeyPath: "/etc/lvr/response-store.key"}); err == nil {
+		t.Fatal("expected error for an encryption key in memory mode")
+	}
+}
+
+func TestConfiguredResponseStoreEncryptsSubmittedResponses(t *testing.T) {
+	state := newFakeOperatorState()
+	operatorId := state.addOperator(1, 100)
+
+	dir := t.TempDir()
+	keyPath := filepath.Join(t.TempDir(), "store.key")
+	if err := os.WriteFile(keyPath, []byte(hex.EncodeToString(newTestKey(t))), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	config := Config{
+		ResponseStoreMode:              ResponseStoreFile,
+		ResponseStorePath:              dir,
+		ResponseStoreEncryptionKeyPath: keyPath,
+	}
+	store, err := NewResponseStore(config)
+	if err != nil {
+		t.Fatalf("NewResponseStore: %v", err)
+	}
+
+	a := newTestAggregator(t, config, state)
+	a.responseStore = store
+	body := marshalTestResponse(t, newTestResponse(4, operatorId, winnerX, 10))
+	if got := submitTestResponse(t, a, state.ecdsaKey(operatorId), body).Code; got != http.StatusOK {
+		t.Fatalf("status = %d, want %d", got, http.StatusOK)
+	}
+
+	// The file holds ciphertext, not the submitted JSON
+	data, err := os.ReadFile(filepath.Join(dir, "task-4.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if json.Valid(data) || bytes.Contains(bytes.ToLower(data), []byte(strings.TrimPrefix(strings.ToLower(winnerX), "0x"))) {
+		t.Fatalf("stored task responses are not encrypted: %q", data)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded[4]) != 1 || loaded[4][0].OperatorId != operatorId {
+		t.Fatalf("expected the encrypted response to load back, got %v", loaded)
+	}
+	plaintext, err := NewResponseStore(Config{ResponseStoreMode: ResponseStoreFile, ResponseStorePath: dir})
+	if err != nil {
+		t.Fatalf("NewResponseStore: %v", err)
+	}
+	if _, err := plaintext.Load(); err == nil {
+		t.Fatal("expected the responses not to load without the key")
+	}
+}
@@ -0,0 +1,67 @@
+package aggregator
+
+import "time"
+
+// defaultTaskRetention is how long finished tasks are kept when task_retention_seconds
+// is unset
+const defaultTaskRetention = time.Hour
+
+// taskRetention returns how long a finished task's state is kept
+func (a *Aggregator) taskRetention() time.Duration {
+	if a.config.TaskRetention == 0 {
+		return defaultTaskRetention
+	}
+	return time.Duration(a.config.TaskRetention) * time.Second
+}
+
+// pruneFinishedTasks forgets the tasks finalized more than TaskRetention ago, and
+// the tasks whose contract deadline passed that long ago without a response, so
+// the aggregator's task state stays bounded. Responses to untracked tasks below
+// the highest index forgotten are rejected from then on.
+func (a *Aggregator) pruneFinishedTasks() {
+	cutoff := a.now().Add(-a.taskRetention())
+
+	a.tasksMux.RLock()
+	var unanswered []uint32
+	for taskIndex, task := range a.tasks {
+		if task.Deadline > 0 && int64(task.Deadline) < cutoff.Unix() {
+			unanswered = append(unanswered, taskIndex)
+		}
+	}
+	a.tasksMux.RUnlock()
+
+	a.taskResponsesMux.Lock()
+	var pruned []uint32
+	for taskIndex, finalizedAt := range a.finalizedAt {
+		if finalizedAt.Before(cutoff) {
+			pruned = append(pruned, taskIndex)
+		}
+	}
+	for _, taskIndex := range unanswered {
+		if _, responded := a.taskFirstSeen[taskIndex]; !responded && !a.finalizedTasks[taskIndex] {
+			pruned = append(pruned, taskIndex)
+		}
+	}
+	for _, taskIndex := range pruned {
+		delete(a.finalizedTasks, taskIndex)
+		delete(a.finalizedAt, taskIndex)
+		delete(a.failedTasks, taskIndex)
+		delete(a.cancelledTasks, taskIndex)
+		delete(a.consensusResults, taskIndex)
+		if taskIndex >= a.prunedBelow {
+			a.prunedBelow = taskIndex + 1
+		}
+	}
+	a.taskResponsesMux.Unlock()
+
+	if len(pruned) == 0 {
+		return
+	}
+	a.tasksMux.Lock()
+	for _, taskIndex := range pruned {
+		delete(a.tasks, taskIndex)
+	}
+	a.tasksMux.Unlock()
+
+	a.logger.Debug("Pruned finished tasks", "tasks", len(pruned), "retention", a.taskRetention())
+}
@@ -0,0 +1,94 @@
+package aggregator
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+)
+
+func TestFinishedTasksArePrunedAfterRetention(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	op3 := state.addOperator(3, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67, TaskTTL: 60, TaskRetention: 600}, state)
+	ctx := context.Background()
+
+	clock := time.Now()
+	a.now = func() time.Time { return clock }
+	submit := func(response SignedAuctionTaskResponse, want int) {
+		t.Helper()
+		body := marshalTestResponse(t, response)
+		if got := submitTestResponse(t, a, state.ecdsaKey(response.OperatorId), body).Code; got != want {
+			t.Fatalf("submitting the response of operator %s to task %d = %d, want %d", response.OperatorId.Hex(), response.ReferenceTaskIndex, got, want)
+		}
+	}
+
+	// Task 1 expires below quorum, task 2 reaches consensus, task 3 is never
+	// answered and task 4 is still open
+	a.AddTask(3, AuctionTask{Deadline: uint64(clock.Unix())})
+	a.AddTask(4, AuctionTask{Deadline: uint64(clock.Add(time.Hour).Unix())})
+	submit(newSignedTestResponse(t, state, 1, op1, winnerX, 100), http.StatusOK)
+	for _, operatorId := range []types.OperatorId{op1, op2, op3} {
+		submit(newSignedTestResponse(t, state, 2, operatorId, winnerX, 100), http.StatusOK)
+	}
+	a.checkAndProcessCompletedTasks(ctx)
+	clock = clock.Add(61 * time.Second)
+	a.expireStaleTasks()
+	a.pruneFinishedTasks()
+	if !a.finalizedTasks[1] || a.failedTasks[1] == "" || !a.finalizedTasks[2] || a.consensusResults[2].Signers != 3 {
+		t.Fatal("expected the finished tasks to be kept within their retention")
+	}
+
+	clock = clock.Add(601 * time.Second)
+	a.pruneFinishedTasks()
+	if len(a.finalizedTasks) != 0 || len(a.finalizedAt) != 0 || len(a.failedTasks) != 0 || len(a.consensusResults) != 0 {
+		t.Fatalf("kept %d finalized, %d failed and %d consensus results, want the finished tasks pruned",
+			len(a.finalizedTasks), len(a.failedTasks), len(a.consensusResults))
+	}
+	if _, open := a.tasks[4]; len(a.tasks) != 1 || !open {
+		t.Fatalf("kept tasks %v, want only the open task 4", a.tasks)
+	}
+
+	// Pruned tasks stay closed to late responses, while the open task accepts them
+	submit(newSignedTestResponse(t, state, 1, op2, winnerX, 100), http.StatusGone)
+	submit(newSignedTestResponse(t, state, 3, op1, winnerX, 100), http.StatusGone)
+	submit(newSignedTestResponse(t, state, 4, op1, winnerX, 100), http.StatusOK)
+}
+
+func TestPruningKeepsLowerOpenTasksOpen(t *testing.T) {
+	state := newFakeOperatorState()
+	op1 := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50, TaskRetention: 600}, state)
+	ctx := context.Background()
+
+	clock := time.Now()
+	a.now = func() time.Time { return clock }
+
+	// Task 5 stays open for a day, task 6 is created later and finishes quickly
+	a.AddTask(5, AuctionTask{Deadline: uint64(clock.Add(24 * time.Hour).Unix())})
+	a.AddTask(6, AuctionTask{Deadline: uint64(clock.Add(time.Minute).Unix())})
+	body := marshalTestResponse(t, newSignedTestResponse(t, state, 6, op1, winnerX, 100))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op1), body).Code; got != http.StatusOK {
+		t.Fatalf("submitting to task 6 = %d, want 200", got)
+	}
+	a.checkAndProcessCompletedTasks(ctx)
+	if !a.finalizedTasks[6] {
+		t.Fatal("expected task 6 to be finalized")
+	}
+
+	clock = clock.Add(601 * time.Second)
+	a.pruneFinishedTasks()
+	if _, tracked := a.tasks[6]; tracked {
+		t.Fatal("expected the finished task 6 to be pruned")
+	}
+
+	// The lower, still open task takes its first response
+	body = marshalTestResponse(t, newSignedTestResponse(t, state, 5, op1, winnerX, 100))
+	if got := submitTestResponse(t, a, state.ecdsaKey(op1), body).Code; got != http.StatusOK {
+		t.Fatalf("submitting to the open task 5 after pruning = %d, want 200", got)
+	}
+}
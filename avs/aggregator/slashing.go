@@ -0,0 +1,38 @@
+package aggregator
+
+import "github.com/Layr-Labs/eigensdk-go/types"
+
+// ConsensusMismatchHook is notified of every operator whose response to a
+// finalized task conflicted with the submitted consensus, so a slashing module
+// can flag it
+type ConsensusMismatchHook interface {
+	OnConsensusMismatch(taskIndex uint32, operatorId types.OperatorId)
+}
+
+// AddConsensusMismatchHook registers a hook notified of consensus mismatches.
+// Hooks must be registered before Start.
+func (a *Aggregator) AddConsensusMismatchHook(hook ConsensusMismatchHook) {
+	a.mismatchHooks = append(a.mismatchHooks, hook)
+}
+
+// notifyConsensusMismatches reports the mismatching operators of a finalized task to every hook
+func (a *Aggregator) notifyConsensusMismatches(taskIndex uint32, mismatched []types.OperatorId) {
+	for _, operatorId := range mismatched {
+		a.logger.Warn("Operator response conflicted with consensus",
+			"taskIndex", taskIndex,
+			"operatorId", operatorId.Hex(),
+		)
+		for _, hook := range a.mismatchHooks {
+			hook.OnConsensusMismatch(taskIndex, operatorId)
+		}
+	}
+}
+
+// operatorIdHexes returns the hex encoding of each operator id
+func operatorIdHexes(operatorIds []types.OperatorId) []string {
+	var hexes []string
+	for _, operatorId := range operatorIds {
+		hexes = append(hexes, operatorId.Hex())
+	}
+	return hexes
+}
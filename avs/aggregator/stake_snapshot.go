@@ -0,0 +1,160 @@
+package aggregator
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// queryStakeTTL is how long GET /task/{index} reuses the operator set it read for
+// a task without a stake snapshot, so status queries can't drive stake RPCs
+const queryStakeTTL = 10 * time.Second
+
+// queriedStakes is the operator set a status query read for a task. The fields
+// are set before done is closed and not modified after.
+type queriedStakes struct {
+	stakes    map[types.OperatorId]*big.Int
+	err       error
+	fetchedAt time.Time
+	done      chan struct{}
+}
+
+// expired reports whether the read has finished and is older than queryStakeTTL.
+// A read still in flight is shared rather than repeated.
+func (q *queriedStakes) expired(now time.Time) bool {
+	select {
+	case <-q.done:
+		return now.Sub(q.fetchedAt) >= queryStakeTTL
+	default:
+		return false
+	}
+}
+
+// stakeSnapshot is the operator set of a task's quorums as registered at the
+// task's creation block, so stake moving after creation can't re-weight it
+type stakeSnapshot struct {
+	// stakes is each operator's stake across the task's quorums
+	stakes map[types.OperatorId]*big.Int
+	// perQuorum is each operator's stake in each of the task's quorums
+	perQuorum map[types.QuorumNum]map[types.OperatorId]*big.Int
+}
+
+// taskSnapshot returns the stake snapshot of a task with known metadata, creating
+// it on first use, or nil for a task the aggregator has no metadata of. Those are
+// evaluated against the current operator set, so caching them would freeze it.
+func (a *Aggregator) taskSnapshot(taskIndex uint32) *stakeSnapshot {
+	a.tasksMux.RLock()
+	_, known := a.tasks[taskIndex]
+	a.tasksMux.RUnlock()
+	if !known {
+		return nil
+	}
+
+	a.stakeSnapshotsMux.Lock()
+	defer a.stakeSnapshotsMux.Unlock()
+	snapshot, exists := a.stakeSnapshots[taskIndex]
+	if !exists {
+		snapshot = &stakeSnapshot{}
+		a.stakeSnapshots[taskIndex] = snapshot
+	}
+	return snapshot
+}
+
+// taskStakes returns the stake of each operator in the task's quorums at its
+// creation block, fetched once per task
+func (a *Aggregator) taskStakes(ctx context.Context, taskIndex uint32) (map[types.OperatorId]*big.Int, error) {
+	snapshot := a.taskSnapshot(taskIndex)
+	if snapshot != nil {
+		a.stakeSnapshotsMux.Lock()
+		stakes := snapshot.stakes
+		a.stakeSnapshotsMux.Unlock()
+		if stakes != nil {
+			return stakes, nil
+		}
+	}
+
+	quorumNumbers, blockNumber := a.taskQuorumNumbers(taskIndex)
+	stakes, err := a.avsReader.GetOperatorStakesAtBlock(ctx, quorumNumbers, blockNumber)
+	if err != nil || snapshot == nil {
+		return stakes, err
+	}
+	a.stakeSnapshotsMux.Lock()
+	snapshot.stakes = stakes
+	a.stakeSnapshotsMux.Unlock()
+	return stakes, nil
+}
+
+// taskStakesPerQuorum returns the stake of each operator in each of the task's
+// quorums at its creation block, fetched once per task
+func (a *Aggregator) taskStakesPerQuorum(ctx context.Context, taskIndex uint32) (map[types.QuorumNum]map[types.OperatorId]*big.Int, error) {
+	snapshot := a.taskSnapshot(taskIndex)
+	if snapshot != nil {
+		a.stakeSnapshotsMux.Lock()
+		perQuorum := snapshot.perQuorum
+		a.stakeSnapshotsMux.Unlock()
+		if perQuorum != nil {
+			return perQuorum, nil
+		}
+	}
+
+	quorumNumbers, blockNumber := a.taskQuorumNumbers(taskIndex)
+	perQuorum, err := a.avsReader.GetOperatorStakesPerQuorumAtBlock(ctx, quorumNumbers, blockNumber)
+	if err != nil || snapshot == nil {
+		return perQuorum, err
+	}
+	a.stakeSnapshotsMux.Lock()
+	snapshot.perQuorum = perQuorum
+	a.stakeSnapshotsMux.Unlock()
+	return perQuorum, nil
+}
+
+// queryTaskStakes returns the stakes a status query evaluates a task against:
+// its snapshot when it has one, otherwise the operator set read at most once per
+// queryTaskStakes. Concurrent queries share one read, taken without holding
+// queriedStakesMux, and a failed read is not reused.
+func (a *Aggregator) queryTaskStakes(ctx context.Context, taskIndex uint32) (map[types.OperatorId]*big.Int, error) {
+	if a.taskSnapshot(taskIndex) != nil {
+		return a.taskStakes(ctx, taskIndex)
+	}
+
+	a.queriedStakesMux.Lock()
+	queried, exists := a.queriedStakes[taskIndex]
+	if exists && !queried.expired(a.now()) {
+		a.queriedStakesMux.Unlock()
+		select {
+		case <-queried.done:
+			return queried.stakes, queried.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	queried = &queriedStakes{done: make(chan struct{})}
+	a.queriedStakes[taskIndex] = queried
+	a.queriedStakesMux.Unlock()
+
+	queried.stakes, queried.err = a.taskStakes(ctx, taskIndex)
+	queried.fetchedAt = a.now()
+	close(queried.done)
+	if queried.err != nil {
+		a.queriedStakesMux.Lock()
+		if a.queriedStakes[taskIndex] == queried {
+			delete(a.queriedStakes, taskIndex)
+		}
+		a.queriedStakesMux.Unlock()
+	}
+	return queried.stakes, queried.err
+}
+
+// forgetStakeSnapshot drops the stake snapshot of a task that will not be
+// evaluated again
+func (a *Aggregator) forgetStakeSnapshot(taskIndex uint32) {
+	a.stakeSnapshotsMux.Lock()
+	delete(a.stakeSnapshots, taskIndex)
+	a.stakeSnapshotsMux.Unlock()
+
+	a.queriedStakesMux.Lock()
+	delete(a.queriedStakes, taskIndex)
+	a.queriedStakesMux.Unlock()
+}
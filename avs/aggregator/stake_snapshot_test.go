@@ -0,0 +1,78 @@
+package aggregator
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// blockRecordingState records the blocks operator stakes are read at
+type blockRecordingState struct {
+	*fakeOperatorState
+	mutex  sync.Mutex
+	blocks []uint32
+}
+
+func (s *blockRecordingState) record(blockNumber uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.blocks = append(s.blocks, blockNumber)
+}
+
+func (s *blockRecordingState) GetOperatorStakesAtBlock(ctx context.Context, quorumNumbers types.QuorumNums, blockNumber uint32) (map[types.OperatorId]*big.Int, error) {
+	s.record(blockNumber)
+	return s.fakeOperatorState.GetOperatorStakesAtBlock(ctx, quorumNumbers, blockNumber)
+}
+
+func (s *blockRecordingState) GetOperatorStakesPerQuorumAtBlock(ctx context.Context, quorumNumbers types.QuorumNums, blockNumber uint32) (map[types.QuorumNum]map[types.OperatorId]*big.Int, error) {
+	s.record(blockNumber)
+	return s.fakeOperatorState.GetOperatorStakesPerQuorumAtBlock(ctx, quorumNumbers, blockNumber)
+}
+
+func TestStakeChangeAfterTaskCreationDoesNotReweighTask(t *testing.T) {
+	fake := newFakeOperatorState()
+	whale := fake.addOperator(1, 60)
+	minnow := fake.addOperator(2, 40)
+	state := &blockRecordingState{fakeOperatorState: fake}
+	a := newTestAggregator(t, Config{QuorumThreshold: 50, MinDistinctOperators: 1}, fake)
+	a.avsReader = state
+	a.AddTask(1, AuctionTask{TaskCreatedBlock: 10})
+	ctx := context.Background()
+
+	responses := []SignedAuctionTaskResponse{newTestResponse(1, minnow, winnerX, 10)}
+	if met, err := a.meetsQuorum(ctx, 1, responses); err != nil || met {
+		t.Fatalf("meetsQuorum = %v, %v; want 40%% of the stake to fall short", met, err)
+	}
+
+	// The minnow's stake grows after the task was created
+	fake.mutex.Lock()
+	fake.stakes[minnow] = big.NewInt(1000)
+	fake.mutex.Unlock()
+
+	if met, err := a.meetsQuorum(ctx, 1, responses); err != nil || met {
+		t.Fatalf("meetsQuorum = %v, %v; want the task weighed by its creation-block stake", met, err)
+	}
+	stakes, err := a.taskStakes(ctx, 1)
+	if err != nil || stakes[minnow].Int64() != 40 || stakes[whale].Int64() != 60 {
+		t.Fatalf("taskStakes = %v, %v; want the snapshot", stakes, err)
+	}
+	// Each kind of snapshot is read once, at the creation block
+	if len(state.blocks) != 2 || state.blocks[0] != 10 || state.blocks[1] != 10 {
+		t.Fatalf("stakes read at blocks %v, want each snapshot once at block 10", state.blocks)
+	}
+
+	// A task created after the change is weighed by the new stake
+	a.AddTask(2, AuctionTask{TaskCreatedBlock: 20})
+	later := []SignedAuctionTaskResponse{newTestResponse(2, minnow, winnerX, 10)}
+	if met, err := a.meetsQuorum(ctx, 2, later); err != nil || !met {
+		t.Fatalf("meetsQuorum for the later task = %v, %v; want it met", met, err)
+	}
+
+	a.markTaskFinalized(1)
+	if _, cached := a.stakeSnapshots[1]; cached {
+		t.Fatal("expected the snapshot of a finalized task to be dropped")
+	}
+}
@@ -0,0 +1,177 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/lvr-auction-hook/avs/pkg/avsregistry"
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	// defaultSubmissionRetries is the number of times a failed submission is retried
+	defaultSubmissionRetries = 3
+	// defaultSubmissionBackoff is the delay before the first retry, doubled after each attempt
+	defaultSubmissionBackoff = time.Second
+	// maxSubmissionBackoff caps the delay between retries
+	maxSubmissionBackoff = 30 * time.Second
+)
+
+// transientSubmissionErrors are the node errors of a transaction that may be
+// accepted if sent again, with a fresh nonce or gas price
+var transientSubmissionErrors = []string{
+	"nonce too low",
+	"nonce too high",
+	"underpriced",
+}
+
+// taskResponder submits a task's consensus result to the service manager
+type taskResponder interface {
+	RespondToTask(
+		ctx context.Context,
+		serviceManagerAddr common.Address,
+		taskIndex uint32,
+		winner common.Address,
+		winningBid *big.Int,
+		signature []byte,
+	) (*gethtypes.Receipt, error)
+}
+
+// attestationArguments is the ABI layout of the attestation passed as respondToTask's
+// signature: the aggregated signature, then the non-signers' ids and G1 pubkeys
+var attestationArguments = func() abi.Arguments {
+	g1Point, _ := abi.NewType("uint256[2]", "", nil)
+	operatorIds, _ := abi.NewType("bytes32[]", "", nil)
+	g1Points, _ := abi.NewType("uint256[2][]", "", nil)
+	return abi.Arguments{{Type: g1Point}, {Type: operatorIds}, {Type: g1Points}}
+}()
+
+// EncodeAttestation ABI encodes an attestation for submission to the service manager
+func EncodeAttestation(attestation *SignedAttestation) ([]byte, error) {
+	if attestation.AggregatedSignature == nil || attestation.AggregatedSignature.G1Point == nil {
+		return nil, ErrMissingSignature
+	}
+
+	nonSignerIds := make([][32]byte, len(attestation.NonSignerIds))
+	for i, operatorId := range attestation.NonSignerIds {
+		nonSignerIds[i] = operatorId
+	}
+	nonSignerPubkeys := make([][2]*big.Int, len(attestation.NonSignerPubkeys))
+	for i, pubkey := range attestation.NonSignerPubkeys {
+		nonSignerPubkeys[i] = g1Coordinates(pubkey)
+	}
+
+	return attestationArguments.Pack(g1Coordinates(attestation.AggregatedSignature.G1Point), nonSignerIds, nonSignerPubkeys)
+}
+
+func g1Coordinates(point *bls.G1Point) [2]*big.Int {
+	return [2]*big.Int{point.X.BigInt(new(big.Int)), point.Y.BigInt(new(big.Int))}
+}
+
+// submitConsensusToContract submits a task's consensus response and attestation to
+// the service manager, retrying transient failures with exponential backoff. It
+// returns the last error once the retries are exhausted, or the first error that
+// retrying can't fix, such as a revert.
+func (a *Aggregator) submitConsensusToContract(ctx context.Context, taskIndex uint32, consensus *SignedAuctionTaskResponse, attestation *SignedAttestation) error {
+	a.logger.Info("Submitting consensus to contract",
+		"taskIndex", taskIndex,
+		"winner", consensus.Winner.Hex(),
+		"winningBid", consensus.WinningBid.String(),
+		"signers", len(attestation.SignerIds),
+		"nonSigners", len(attestation.NonSignerIds),
+	)
+
+	signature, err := EncodeAttestation(attestation)
+	if err != nil {
+		a.lvrMetrics.submissions.WithLabelValues("failure").Inc()
+		return fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	retries := a.config.SubmissionRetries
+	if retries == 0 {
+		retries = defaultSubmissionRetries
+	}
+	backoff := a.submissionBackoff
+
+	serviceManager := a.config.ContractAddresses.Address(lvrtypes.ContractServiceManager)
+	for attempt := uint32(0); ; attempt++ {
+		var receipt *gethtypes.Receipt
+		receipt, err = a.avsWriter.RespondToTask(ctx, serviceManager, taskIndex, consensus.Winner, consensus.WinningBid, signature)
+		if err == nil {
+			a.lvrMetrics.submissions.WithLabelValues("success").Inc()
+			if receipt != nil {
+				a.logger.Info("Consensus submitted successfully", "taskIndex", taskIndex, "txHash", receipt.TxHash.Hex())
+			} else {
+				a.logger.Info("Consensus submitted successfully", "taskIndex", taskIndex)
+			}
+			return nil
+		}
+		if !isRetryableSubmission(err) {
+			a.lvrMetrics.submissions.WithLabelValues("failure").Inc()
+			return fmt.Errorf("consensus submission failed permanently: %w", err)
+		}
+		if attempt >= retries {
+			break
+		}
+
+		a.logger.Warn("Consensus submission failed, retrying",
+			"taskIndex", taskIndex,
+			"attempt", attempt+1,
+			"backoff", backoff,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			a.lvrMetrics.submissions.WithLabelValues("failure").Inc()
+			return fmt.Errorf("consensus submission cancelled: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxSubmissionBackoff)
+	}
+
+	a.lvrMetrics.submissions.WithLabelValues("failure").Inc()
+	return fmt.Errorf("consensus submission failed after %d attempts: %w", retries+1, err)
+}
+
+// isRetryableSubmission reports whether a failed submission may succeed if sent
+// again: the node couldn't be reached, or refused the transaction's nonce or gas
+// price. Reverts, and every other failure, are permanent.
+func isRetryableSubmission(err error) bool {
+	var dataErr rpc.DataError
+	if errors.Is(err, avsregistry.ErrTransactionReverted) || errors.As(err, &dataErr) {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	if strings.Contains(message, "execution reverted") {
+		return false
+	}
+	for _, transient := range transientSubmissionErrors {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+	var httpErr rpc.HTTPError
+	switch {
+	case errors.As(err, &netErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, context.DeadlineExceeded):
+		return true
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
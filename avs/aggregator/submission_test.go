@@ -0,0 +1,153 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/lvr-auction-hook/avs/pkg/avsregistry"
+)
+
+// newSubmissionTestTask returns a task's unanimous signed responses from two operators
+func newSubmissionTestTask(t *testing.T, state *fakeOperatorState, taskIndex uint32) []SignedAuctionTaskResponse {
+	t.Helper()
+	op1 := state.addOperator(1, 100)
+	op2 := state.addOperator(2, 100)
+	winner := "0x00000000000000000000000000000000000000aa"
+	return []SignedAuctionTaskResponse{
+		newSignedTestResponse(t, state, taskIndex, op1, winner, 100),
+		newSignedTestResponse(t, state, taskIndex, op2, winner, 100),
+	}
+}
+
+func TestSubmitConsensusRetriesFailedTransactions(t *testing.T) {
+	state := newFakeOperatorState()
+	responses := newSubmissionTestTask(t, state, 1)
+
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+	a.submissionBackoff = time.Millisecond
+	responder := &fakeTaskResponder{failures: 2}
+	a.avsWriter = responder
+
+	if !a.processCompletedTask(context.Background(), 1, responses) {
+		t.Fatal("expected the task to be submitted after retries")
+	}
+	if responder.attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", responder.attempts)
+	}
+	if len(responder.signatures[1]) == 0 {
+		t.Fatal("expected the encoded attestation to be submitted")
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.submissions.WithLabelValues("success")); got != 1 {
+		t.Fatalf("successful submissions = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.submissions.WithLabelValues("failure")); got != 0 {
+		t.Fatalf("failed submissions = %v, want 0", got)
+	}
+}
+
+func TestSubmitConsensusMarksTaskFailedAfterRetries(t *testing.T) {
+	state := newFakeOperatorState()
+	responses := newSubmissionTestTask(t, state, 1)
+
+	a := newTestAggregator(t, Config{QuorumThreshold: 67, SubmissionRetries: 2}, state)
+	a.submissionBackoff = time.Millisecond
+	responder := &fakeTaskResponder{failures: 10}
+	a.avsWriter = responder
+	a.taskResponses[1] = responses
+
+	a.checkAndProcessCompletedTasks(context.Background())
+
+	if responder.attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", responder.attempts)
+	}
+	if !a.finalizedTasks[1] || len(a.taskResponses[1]) != 0 {
+		t.Fatal("expected the failed task not to be processed again")
+	}
+	if reason := a.failedTasks[1]; !strings.Contains(reason, "connection refused") {
+		t.Fatalf("failed task reason = %q, want the submission error", reason)
+	}
+	if got := testutil.ToFloat64(a.lvrMetrics.submissions.WithLabelValues("failure")); got != 1 {
+		t.Fatalf("failed submissions = %v, want 1", got)
+	}
+	if a.accuracy[responses[0].OperatorId].Total != 0 {
+		t.Fatal("expected accuracy to be recorded only for submitted consensus")
+	}
+}
+
+func TestSubmitConsensusDoesNotRetryPermanentFailures(t *testing.T) {
+	for name, err := range map[string]error{
+		"mined and reverted":     fmt.Errorf("respondToTask transaction 0x01: %w", avsregistry.ErrTransactionReverted),
+		"reverted in estimation": errors.New("failed to build respondToTask transaction: execution reverted: task already responded"),
+		"unclassified":           errors.New("invalid sender"),
+	} {
+		state := newFakeOperatorState()
+		responses := newSubmissionTestTask(t, state, 1)
+
+		a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+		a.submissionBackoff = time.Millisecond
+		responder := &fakeTaskResponder{failures: 10, err: err}
+		a.avsWriter = responder
+
+		if a.processCompletedTask(context.Background(), 1, responses) {
+			t.Fatalf("%s: expected the submission to fail", name)
+		}
+		if responder.attempts != 1 {
+			t.Fatalf("%s: attempts = %d, want no retries", name, responder.attempts)
+		}
+		if reason := a.failedTasks[1]; !strings.Contains(reason, err.Error()) {
+			t.Fatalf("%s: failed task reason = %q, want the submission error", name, reason)
+		}
+	}
+
+	// Nonce and gas price refusals are retried like transport failures
+	state := newFakeOperatorState()
+	responses := newSubmissionTestTask(t, state, 1)
+	a := newTestAggregator(t, Config{QuorumThreshold: 67}, state)
+	a.submissionBackoff = time.Millisecond
+	responder := &fakeTaskResponder{failures: 2, err: errors.New("failed to send respondToTask transaction: replacement transaction underpriced")}
+	a.avsWriter = responder
+	if !a.processCompletedTask(context.Background(), 1, responses) || responder.attempts != 3 {
+		t.Fatalf("attempts = %d, want the underpriced transaction retried until it succeeds", responder.attempts)
+	}
+}
+
+func TestEncodeAttestation(t *testing.T) {
+	state := newFakeOperatorState()
+	responses := newSubmissionTestTask(t, state, 1)
+	absent := state.addOperator(3, 100)
+
+	a := newTestAggregator(t, Config{}, state)
+	attestation, err := a.aggregateSignatures(context.Background(), 1, responses[0].AuctionTaskResponse, responses)
+	if err != nil {
+		t.Fatalf("aggregateSignatures: %v", err)
+	}
+
+	encoded, err := EncodeAttestation(attestation)
+	if err != nil {
+		t.Fatalf("EncodeAttestation: %v", err)
+	}
+	values, err := attestationArguments.Unpack(encoded)
+	if err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	sigma := values[0].([2]*big.Int)
+	if sigma[0].Cmp(attestation.AggregatedSignature.X.BigInt(new(big.Int))) != 0 ||
+		sigma[1].Cmp(attestation.AggregatedSignature.Y.BigInt(new(big.Int))) != 0 {
+		t.Fatal("encoded signature does not match the aggregated signature")
+	}
+	nonSignerIds := values[1].([][32]byte)
+	if len(nonSignerIds) != 1 || nonSignerIds[0] != absent {
+		t.Fatalf("encoded non-signers = %x, want [%x]", nonSignerIds, absent)
+	}
+	if pubkeys := values[2].([][2]*big.Int); len(pubkeys) != 1 {
+		t.Fatalf("encoded %d non-signer pubkeys, want 1", len(pubkeys))
+	}
+}
@@ -0,0 +1,65 @@
+package aggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+)
+
+func FuzzHandleTaskResponseSubmission(f *testing.F) {
+	state := newFakeOperatorState()
+	op := state.addOperator(1, 100)
+	a := newTestAggregator(f, Config{}, state)
+	key := state.ecdsaKey(op)
+
+	valid, err := json.Marshal(newSignedTestResponse(f, state, 1, op, winnerX, 10))
+	if err != nil {
+		f.Fatalf("Marshal: %v", err)
+	}
+	for _, seed := range []string{
+		string(valid),
+		string(valid[:len(valid)/2]),
+		string(valid) + "{}",
+		`{}`,
+		`null`,
+		`[]`,
+		`{"referenceTaskIndex":1,"winningBid":null}`,
+		`{"referenceTaskIndex":-1,"winningBid":1}`,
+		`{"referenceTaskIndex":1,"winningBid":"1"}`,
+		`{"referenceTaskIndex":1,"winningBid":1e400}`,
+		`{"referenceTaskIndex":1,"winningBid":-1}`,
+		`{"referenceTaskIndex":1,"winningBid":0,"confidence":2}`,
+		`{"referenceTaskIndex":1,"winningBid":0,"blsSignature":null}`,
+		`{"referenceTaskIndex":1,"winningBid":0,"blsSignature":{}}`,
+		`{"referenceTaskIndex":1,"winningBid":0,"blsSignature":"AAAA"}`,
+		`{"referenceTaskIndex":1,"winningBid":0,"winner":"0x1"}`,
+		`{"referenceTaskIndex":1,"winningBid":0,"operatorId":"zz"}`,
+		`{"referenceTaskIndex":1,"winningBid":0,"unknown":true}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		signature, err := operator.SignRequestBody(key, body)
+		if err != nil {
+			t.Fatalf("SignRequestBody: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/submit-response", bytes.NewReader(body))
+		req.Header.Set(OperatorSignatureHeader, signature)
+		recorder := httptest.NewRecorder()
+		a.handleTaskResponseSubmission(recorder, req)
+
+		if recorder.Code >= http.StatusInternalServerError {
+			t.Fatalf("status %d for body %q: %s", recorder.Code, body, recorder.Body.String())
+		}
+		var rejection *responseRejection
+		if _, err := decodeTaskResponse(body); errors.As(err, &rejection) && recorder.Code != rejection.status {
+			t.Fatalf("status %d for invalid body %q, want %d", recorder.Code, body, rejection.status)
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	// taskScanInterval is how often the service manager is scanned for new tasks
+	// while its NewTaskCreated events can't be subscribed to
+	taskScanInterval = 2 * time.Second
+	// defaultTaskBackfillBlocks is how far back the first scan reaches when
+	// response_window_blocks is unset
+	defaultTaskBackfillBlocks = 100
+)
+
+// serviceManagerEvents is the subset of the LVRAuctionServiceManager ABI tasks are
+// ingested from
+var serviceManagerEvents = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(`[{
+		"type": "event",
+		"name": "NewTaskCreated",
+		"anonymous": false,
+		"inputs": [
+			{"name": "taskIndex", "type": "uint32", "indexed": true},
+			{"name": "task", "type": "tuple", "indexed": false, "components": [
+				{"name": "auctionId", "type": "bytes32"},
+				{"name": "poolId", "type": "bytes32"},
+				{"name": "taskCreatedBlock", "type": "uint32"},
+				{"name": "deadline", "type": "uint256"},
+				{"name": "completed", "type": "bool"}
+			]}
+		]
+	}]`))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// newTaskCreatedEvent mirrors the NewTaskCreated event payload
+type newTaskCreatedEvent struct {
+	Task struct {
+		AuctionId        [32]byte
+		PoolId           [32]byte
+		TaskCreatedBlock uint32
+		Deadline         *big.Int
+		Completed        bool
+	}
+}
+
+// taskEventSource is the chain access tasks are ingested with
+type taskEventSource interface {
+	ethereum.LogFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// watchTasks ingests the tasks the service manager creates until ctx is cancelled,
+// over a subscription to its NewTaskCreated events when the client supports one
+// and by scanning for them otherwise
+func (a *Aggregator) watchTasks(ctx context.Context) {
+	if a.taskEvents == nil {
+		return
+	}
+	for {
+		err := a.streamTaskEvents(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		a.logger.Warn("Task subscription unavailable, scanning for new tasks", "error", err)
+		a.pollTaskEvents(ctx, headResubscribeInterval)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// streamTaskEvents ingests subscribed NewTaskCreated events, after catching up on
+// the ones emitted while unsubscribed, until the subscription fails
+func (a *Aggregator) streamTaskEvents(ctx context.Context) error {
+	logs := make(chan gethtypes.Log, taskStreamBuffer)
+	subscription, err := a.taskEvents.SubscribeFilterLogs(ctx, a.taskQuery(), logs)
+	if err != nil {
+		return err
+	}
+	defer subscription.Unsubscribe()
+
+	// Events emitted before the subscription started are scanned for, and ingesting
+	// one twice has no effect
+	if err := a.scanTasks(ctx); err != nil {
+		a.logger.Warn("Failed to scan for tasks created while unsubscribed", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-subscription.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case log := <-logs:
+			a.ingestTaskLog(log)
+		}
+	}
+}
+
+// pollTaskEvents scans for new tasks every taskScanInterval, for up to duration
+func (a *Aggregator) pollTaskEvents(ctx context.Context, duration time.Duration) {
+	ticker := time.NewTicker(taskScanInterval)
+	defer ticker.Stop()
+	deadline := time.After(duration)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			return
+		case <-ticker.C:
+			if err := a.scanTasks(ctx); err != nil {
+				a.logger.Warn("Failed to scan for new tasks", "error", err)
+			}
+		}
+	}
+}
+
+// scanTasks ingests the NewTaskCreated events emitted since the last scanned
+// block. The first scan reaches back ResponseWindowBlocks blocks, or
+// defaultTaskBackfillBlocks, so tasks still accepting responses when the
+// aggregator starts are known.
+func (a *Aggregator) scanTasks(ctx context.Context) error {
+	head, err := a.taskEvents.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read current block: %w", err)
+	}
+
+	fromBlock := a.taskScanBlock + 1
+	if a.taskScanBlock == 0 {
+		window := uint64(a.config.ResponseWindowBlocks)
+		if window == 0 {
+			window = defaultTaskBackfillBlocks
+		}
+		fromBlock = 0
+		if window < head {
+			fromBlock = head - window
+		}
+	}
+	if fromBlock > head {
+		return nil
+	}
+
+	query := a.taskQuery()
+	query.FromBlock = new(big.Int).SetUint64(fromBlock)
+	query.ToBlock = new(big.Int).SetUint64(head)
+	logs, err := a.taskEvents.FilterLogs(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to filter NewTaskCreated events: %w", err)
+	}
+	for _, log := range logs {
+		a.ingestTaskLog(log)
+	}
+	a.taskScanBlock = head
+	return nil
+}
+
+// taskQuery filters the service manager's NewTaskCreated events
+func (a *Aggregator) taskQuery() ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		Addresses: []common.Address{a.config.ContractAddresses.Address(lvrtypes.ContractServiceManager)},
+		Topics:    [][]common.Hash{{serviceManagerEvents.Events["NewTaskCreated"].ID}},
+	}
+}
+
+// ingestTaskLog adds the task of a NewTaskCreated event, or cancels it when the
+// event was removed by a reorg
+func (a *Aggregator) ingestTaskLog(log gethtypes.Log) {
+	taskIndex, task, err := decodeTaskLog(log)
+	if err != nil {
+		a.logger.Warn("Failed to decode NewTaskCreated event", "txHash", log.TxHash.Hex(), "error", err)
+		return
+	}
+
+	a.tasksMux.RLock()
+	known, exists := a.tasks[taskIndex]
+	a.tasksMux.RUnlock()
+
+	if log.Removed {
+		// The task may have been re-created in the new chain's blocks already
+		if exists && known.TaskCreatedBlockHash != log.BlockHash {
+			return
+		}
+		a.logger.Warn("Task creation reorged out, dropping task", "taskIndex", taskIndex, "blockHash", log.BlockHash.Hex())
+		if err := a.CancelTask(taskIndex, cancelReasonReorged); err != nil {
+			a.logger.Error("Failed to drop reorged task", "taskIndex", taskIndex, "error", err)
+		}
+		return
+	}
+
+	if exists && known.TaskCreatedBlockHash == task.TaskCreatedBlockHash {
+		return
+	}
+
+	if exists {
+		a.reopenReorgedTask(taskIndex)
+	}
+	a.logger.Info("New task created", "taskIndex", taskIndex, "poolId", task.PoolId.Hex(), "block", task.TaskCreatedBlock)
+	a.AddTask(taskIndex, task)
+}
+
+// reopenReorgedTask forgets the cancellation of a task dropped by a reorg, so the
+// task re-created under the same index in the new chain can take responses again.
+// The stale task is forgotten too, so its re-creation is streamed to operators
+// that were told to abstain.
+func (a *Aggregator) reopenReorgedTask(taskIndex uint32) {
+	a.taskResponsesMux.Lock()
+	if a.cancelledTasks[taskIndex] != cancelReasonReorged {
+		a.taskResponsesMux.Unlock()
+		return
+	}
+	delete(a.cancelledTasks, taskIndex)
+	delete(a.finalizedTasks, taskIndex)
+	delete(a.finalizedAt, taskIndex)
+	a.taskResponsesMux.Unlock()
+
+	a.tasksMux.Lock()
+	delete(a.tasks, taskIndex)
+	a.tasksMux.Unlock()
+	a.logger.Info("Reorged task re-created, reopening it", "taskIndex", taskIndex)
+}
+
+// decodeTaskLog decodes the task index and task of a NewTaskCreated event
+func decodeTaskLog(log gethtypes.Log) (uint32, AuctionTask, error) {
+	if len(log.Topics) < 2 {
+		return 0, AuctionTask{}, fmt.Errorf("expected 2 topics, got %d", len(log.Topics))
+	}
+	var event newTaskCreatedEvent
+	if err := serviceManagerEvents.UnpackIntoInterface(&event, "NewTaskCreated", log.Data); err != nil {
+		return 0, AuctionTask{}, err
+	}
+
+	taskIndex := uint32(new(big.Int).SetBytes(log.Topics[1].Bytes()).Uint64())
+	return taskIndex, AuctionTask{
+		PoolId:               event.Task.PoolId,
+		BlockNumber:          event.Task.TaskCreatedBlock,
+		TaskCreatedBlock:     event.Task.TaskCreatedBlock,
+		TaskCreatedBlockHash: log.BlockHash,
+		Deadline:             event.Task.Deadline.Uint64(),
+	}, nil
+}
@@ -0,0 +1,73 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+)
+
+func TestCreatedTasksAreStreamedToSubscribers(t *testing.T) {
+	h := newChainHarness(t)
+	op := h.registerOperator(1, 100)
+	a := h.newAggregator(Config{QuorumThreshold: 50, ResponseWindowBlocks: 10})
+	client := dialTaskStream(t, a)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	timestamp := time.Now().Unix()
+	signature, err := operator.SignRequestBody(h.state.ecdsaKey(op), TaskSubscriptionMessage(timestamp))
+	if err != nil {
+		t.Fatalf("SignRequestBody: %v", err)
+	}
+	tasks := make(chan StreamedTask, 2)
+	subscription, err := client.Subscribe(ctx, taskStreamNamespace, tasks, "tasks", timestamp, signature)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer subscription.Unsubscribe()
+
+	// The first task is created before the aggregator watches the chain and is
+	// found by its first scan, the second arrives over its subscription
+	firstPool, secondPool := common.HexToHash("0x01"), common.HexToHash("0x02")
+	firstIndex, firstReceipt := h.createTask(firstPool)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.watchTasks(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	receive := func(taskIndex uint32, poolId common.Hash, blockNumber uint32, blockHash common.Hash) {
+		t.Helper()
+		select {
+		case task := <-tasks:
+			if task.TaskIndex != taskIndex || task.PoolId != poolId || task.TaskCreatedBlock != blockNumber || task.TaskCreatedBlockHash != blockHash {
+				t.Fatalf("streamed task %+v, want task %d of pool %s created at block %d (%s)", task, taskIndex, poolId.Hex(), blockNumber, blockHash.Hex())
+			}
+		case err := <-subscription.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected task %d to be streamed", taskIndex)
+		}
+	}
+	receive(firstIndex, firstPool, uint32(firstReceipt.BlockNumber.Uint64()), firstReceipt.BlockHash)
+
+	secondIndex, secondReceipt := h.createTask(secondPool)
+	receive(secondIndex, secondPool, uint32(secondReceipt.BlockNumber.Uint64()), secondReceipt.BlockHash)
+
+	select {
+	case task := <-tasks:
+		t.Fatalf("unexpected streamed task %+v", task)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if calls := h.respondToTaskCalls(); len(calls) != 0 {
+		t.Fatalf("service manager received %d respondToTask calls, want none", len(calls))
+	}
+}
@@ -0,0 +1,136 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// taskStreamNamespace is the JSON-RPC namespace of TaskStreamService
+	taskStreamNamespace = "lvr"
+	// taskStreamBuffer is the number of new tasks queued for a slow subscriber
+	// before further tasks are dropped for it
+	taskStreamBuffer = 64
+	// maxSubscriptionSkew bounds how far a subscription's signed timestamp may be
+	// from the aggregator's clock, so a captured request can't be replayed later
+	maxSubscriptionSkew = 5 * time.Minute
+)
+
+// StreamedTask is a task pushed to the operators subscribed to lvr_subscribe("tasks")
+// when it is created, and again with Cancelled set if it is cancelled
+type StreamedTask struct {
+	TaskIndex uint32 `json:"taskIndex"`
+	AuctionTask
+	// Cancelled tells operators to abstain from the task
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// TaskSubscriptionMessage is the message an operator signs, like a request body
+// under OperatorSignatureHeader, to subscribe to tasks at the unix timestamp
+func TaskSubscriptionMessage(timestamp int64) []byte {
+	return []byte(fmt.Sprintf("lvr_subscribe:tasks:%d", timestamp))
+}
+
+// TaskStreamService is the JSON-RPC API served over websocket at /rpc. It lets
+// registered operators stream new tasks and submit their responses over a single
+// connection instead of an HTTP request per response.
+type TaskStreamService struct {
+	aggregator *Aggregator
+}
+
+// Tasks subscribes a registered operator to new tasks. signature is the
+// operator's signature over TaskSubscriptionMessage(timestamp).
+func (s *TaskStreamService) Tasks(ctx context.Context, timestamp int64, signature string) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	a := s.aggregator
+	if skew := a.now().Sub(time.Unix(timestamp, 0)); skew > maxSubscriptionSkew || skew < -maxSubscriptionSkew {
+		return nil, fmt.Errorf("subscription timestamp is %s from the aggregator's clock", skew.Round(time.Second))
+	}
+	operatorId, err := a.authenticateOperator(ctx, TaskSubscriptionMessage(timestamp), signature)
+	if err != nil {
+		a.logger.Warn("Rejecting unauthenticated task subscription", "error", err)
+		return nil, fmt.Errorf("unauthorized: %w", err)
+	}
+
+	subscription := notifier.CreateSubscription()
+	tasks := a.subscribeTasks()
+	a.logger.Info("Operator subscribed to tasks", "operatorId", operatorId.Hex())
+
+	go func() {
+		defer a.unsubscribeTasks(tasks)
+		for {
+			select {
+			case task := <-tasks:
+				if err := notifier.Notify(subscription.ID, task); err != nil {
+					return
+				}
+			case <-subscription.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return subscription, nil
+}
+
+// SubmitResponse submits a task response as POST /submit-response does. body is
+// the JSON encoded response and signature the operator's signature over it.
+func (s *TaskStreamService) SubmitResponse(ctx context.Context, body hexutil.Bytes, signature string) error {
+	if len(body) > maxResponseBodySize {
+		return fmt.Errorf("response body exceeds %d bytes", maxResponseBodySize)
+	}
+	return s.aggregator.acceptTaskResponse(ctx, body, signature, "rpc")
+}
+
+// newTaskStreamServer creates the JSON-RPC server of the task stream API
+func (a *Aggregator) newTaskStreamServer() (*rpc.Server, error) {
+	server := rpc.NewServer()
+	if err := server.RegisterName(taskStreamNamespace, &TaskStreamService{aggregator: a}); err != nil {
+		return nil, fmt.Errorf("failed to register task stream service: %w", err)
+	}
+	return server, nil
+}
+
+// subscribeTasks registers a channel receiving every task added from now on
+func (a *Aggregator) subscribeTasks() chan StreamedTask {
+	tasks := make(chan StreamedTask, taskStreamBuffer)
+	a.taskSubscribersMux.Lock()
+	a.taskSubscribers[tasks] = struct{}{}
+	a.taskSubscribersMux.Unlock()
+	return tasks
+}
+
+// unsubscribeTasks stops delivering tasks to a channel from subscribeTasks
+func (a *Aggregator) unsubscribeTasks(tasks chan StreamedTask) {
+	a.taskSubscribersMux.Lock()
+	delete(a.taskSubscribers, tasks)
+	a.taskSubscribersMux.Unlock()
+}
+
+// publishTask delivers a new task to every subscriber with room in its buffer
+func (a *Aggregator) publishTask(taskIndex uint32, task AuctionTask) {
+	a.publish(StreamedTask{TaskIndex: taskIndex, AuctionTask: task})
+}
+
+// publish delivers a streamed task to every subscriber with room in its buffer
+func (a *Aggregator) publish(streamed StreamedTask) {
+	a.taskSubscribersMux.Lock()
+	defer a.taskSubscribersMux.Unlock()
+
+	for tasks := range a.taskSubscribers {
+		select {
+		case tasks <- streamed:
+		default:
+			a.logger.Warn("Dropping streamed task for a slow subscriber", "taskIndex", streamed.TaskIndex)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package aggregator
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/lvr-auction-hook/avs/pkg/operator"
+)
+
+// dialTaskStream serves the aggregator's HTTP API and dials its task stream
+func dialTaskStream(t *testing.T, a *Aggregator) *rpc.Client {
+	t.Helper()
+	taskStream, err := a.newTaskStreamServer()
+	if err != nil {
+		t.Fatalf("newTaskStreamServer: %v", err)
+	}
+	server := httptest.NewServer(a.httpHandler(taskStream))
+	t.Cleanup(server.Close)
+	t.Cleanup(taskStream.Stop)
+
+	client, err := rpc.DialWebsocket(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")+"/rpc", "")
+	if err != nil {
+		t.Fatalf("DialWebsocket: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestTaskStreamDeliversTasksAndAcceptsResponses(t *testing.T) {
+	state := newFakeOperatorState()
+	op := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{QuorumThreshold: 50}, state)
+	client := dialTaskStream(t, a)
+	ctx := context.Background()
+
+	timestamp := time.Now().Unix()
+	signature, err := operator.SignRequestBody(state.ecdsaKey(op), TaskSubscriptionMessage(timestamp))
+	if err != nil {
+		t.Fatalf("SignRequestBody: %v", err)
+	}
+	tasks := make(chan StreamedTask, 1)
+	subscription, err := client.Subscribe(ctx, taskStreamNamespace, tasks, "tasks", timestamp, signature)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer subscription.Unsubscribe()
+
+	a.AddTask(7, AuctionTask{PoolId: common.HexToHash("0x01"), TaskCreatedBlock: 40, QuorumNumbers: types.QuorumNums{0}})
+	select {
+	case task := <-tasks:
+		if task.TaskIndex != 7 || task.TaskCreatedBlock != 40 || task.PoolId != common.HexToHash("0x01") {
+			t.Fatalf("unexpected streamed task %+v", task)
+		}
+	case err := <-subscription.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("expected the new task to be streamed")
+	}
+
+	// Responses are submitted over the same connection
+	body := marshalTestResponse(t, newTestResponse(7, op, "0x00000000000000000000000000000000000000aa", 10))
+	signature, err = operator.SignRequestBody(state.ecdsaKey(op), body)
+	if err != nil {
+		t.Fatalf("SignRequestBody: %v", err)
+	}
+	if err := client.Call(nil, taskStreamNamespace+"_submitResponse", hexutil.Bytes(body), signature); err != nil {
+		t.Fatalf("submitResponse: %v", err)
+	}
+	a.taskResponsesMux.RLock()
+	stored := len(a.taskResponses[7])
+	a.taskResponsesMux.RUnlock()
+	if stored != 1 {
+		t.Fatalf("stored %d responses for the task, want 1", stored)
+	}
+
+	// A response signed by someone else is rejected
+	signature, _ = operator.SignRequestBody(state.ecdsaKey(state.addOperator(2, 100)), body)
+	if err := client.Call(nil, taskStreamNamespace+"_submitResponse", hexutil.Bytes(body), signature); err == nil {
+		t.Fatal("expected a response signed for another operator to be rejected")
+	}
+}
+
+func TestTaskStreamRejectsUnregisteredSubscribers(t *testing.T) {
+	state := newFakeOperatorState()
+	op := state.addOperator(1, 100)
+	a := newTestAggregator(t, Config{}, state)
+	client := dialTaskStream(t, a)
+	ctx := context.Background()
+
+	outsider, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	timestamp := time.Now().Unix()
+	signature, _ := operator.SignRequestBody(outsider, TaskSubscriptionMessage(timestamp))
+	if _, err := client.Subscribe(ctx, taskStreamNamespace, make(chan StreamedTask), "tasks", timestamp, signature); err == nil {
+		t.Fatal("expected an unregistered signer to be refused")
+	}
+
+	// A registered operator's signature can't be replayed once stale
+	stale := time.Now().Add(-time.Hour).Unix()
+	signature, _ = operator.SignRequestBody(state.ecdsaKey(op), TaskSubscriptionMessage(stale))
+	if _, err := client.Subscribe(ctx, taskStreamNamespace, make(chan StreamedTask), "tasks", stale, signature); err == nil {
+		t.Fatal("expected a stale subscription signature to be refused")
+	}
+}
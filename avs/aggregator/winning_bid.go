@@ -0,0 +1,59 @@
+package aggregator
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const (
+	// defaultSettlementDecimals is the decimals of a settlement token when unset,
+	// those of ETH
+	defaultSettlementDecimals = 18
+	// defaultMaxWinningBidTokens bounds winning bids, in whole settlement tokens,
+	// when no MaxTokens is configured
+	defaultMaxWinningBidTokens = 1_000_000
+)
+
+// WinningBidConfig fixes the unit of winning bids. Every response's winning bid
+// is an amount in wei, the smallest unit, of the settlement token, and bids far
+// outside a sane magnitude for it are rejected as expressed in another unit,
+// before they can split operators into false consensus mismatches.
+type WinningBidConfig struct {
+	// SettlementToken is the ERC20 token bids are paid in, empty for ETH
+	SettlementToken string `json:"settlement_token"`
+	// Decimals is the settlement token's decimals (default 18)
+	Decimals uint8 `json:"decimals"`
+	// MinWei is the smallest non-zero winning bid accepted, such as a bid in whole
+	// tokens would fall below. There is no minimum when zero.
+	MinWei uint64 `json:"min_wei"`
+	// MaxTokens is the largest winning bid accepted, in whole tokens (default 1000000)
+	MaxTokens uint64 `json:"max_tokens"`
+}
+
+// maxWei returns the largest winning bid accepted, in wei of the settlement token
+func (c WinningBidConfig) maxWei() *big.Int {
+	decimals, tokens := uint64(c.Decimals), c.MaxTokens
+	if decimals == 0 {
+		decimals = defaultSettlementDecimals
+	}
+	if tokens == 0 {
+		tokens = defaultMaxWinningBidTokens
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(decimals), nil)
+	return scale.Mul(scale, new(big.Int).SetUint64(tokens))
+}
+
+// checkWinningBid rejects a non-zero winning bid outside the configured range. A
+// zero bid, voting that the auction has no winner, is always accepted.
+func (c WinningBidConfig) checkWinningBid(bid *big.Int) error {
+	if bid == nil || bid.Sign() == 0 {
+		return nil
+	}
+	if bid.Cmp(new(big.Int).SetUint64(c.MinWei)) < 0 {
+		return fmt.Errorf("winningBid %s is below the minimum of %d wei of the settlement token", bid, c.MinWei)
+	}
+	if max := c.maxWei(); bid.Cmp(max) > 0 {
+		return fmt.Errorf("winningBid %s exceeds the maximum of %s wei of the settlement token", bid, max)
+	}
+	return nil
+}
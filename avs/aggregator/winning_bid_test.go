@@ -0,0 +1,53 @@
+package aggregator
+
+import (
+	"math/big"
+	"net/http"
+	"testing"
+)
+
+func TestSubmitResponseRejectsOutOfRangeWinningBids(t *testing.T) {
+	state := newFakeOperatorState()
+	op := state.addOperator(1, 100)
+	// Bids of USDC, with 6 decimals, between 0.01 and 1000 USDC
+	a := newTestAggregator(t, Config{WinningBid: WinningBidConfig{Decimals: 6, MinWei: 10_000, MaxTokens: 1000}}, state)
+
+	cases := []struct {
+		name string
+		bid  *big.Int
+		want int
+	}{
+		{"no winner", big.NewInt(0), http.StatusOK},
+		{"in range", big.NewInt(250_000_000), http.StatusOK},
+		{"at the maximum", big.NewInt(1_000_000_000), http.StatusOK},
+		// 5 USDC expressed in whole tokens
+		{"below the minimum", big.NewInt(5), http.StatusBadRequest},
+		// 5 USDC expressed in 18 decimal wei
+		{"above the maximum", new(big.Int).Mul(big.NewInt(5), big.NewInt(1e18)), http.StatusBadRequest},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			winner := winnerX
+			if tc.bid.Sign() == 0 {
+				winner = "0x0000000000000000000000000000000000000000"
+			}
+			response := newTestResponse(uint32(i+1), op, winner, 0)
+			response.WinningBid = tc.bid
+			body := marshalTestResponse(t, response)
+			if got := submitTestResponse(t, a, state.ecdsaKey(op), body).Code; got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWinningBidRangeDefaultsToEther(t *testing.T) {
+	var config WinningBidConfig
+	limit := new(big.Int).Mul(big.NewInt(defaultMaxWinningBidTokens), big.NewInt(1e18))
+	if err := config.checkWinningBid(limit); err != nil {
+		t.Fatalf("checkWinningBid(max): %v", err)
+	}
+	if err := config.checkWinningBid(new(big.Int).Add(limit, big.NewInt(1))); err == nil {
+		t.Fatal("expected a bid above a million ether to be rejected")
+	}
+}
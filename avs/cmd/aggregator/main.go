@@ -2,14 +2,31 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gopkg.in/yaml.v3"
+
 	"github.com/lvr-auction-hook/avs/aggregator"
+	lvrtypes "github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	defaultQuorumThreshold        = 67 // 67% threshold
+	defaultEigenMetricsIpPortAddr = "0.0.0.0:9091"
+	defaultNodeApiIpPortAddr      = "0.0.0.0:8080"
+	defaultAggregatorServerIpPort = "0.0.0.0:9090"
+	rpcReachabilityCheckTimeout   = 5 * time.Second
 )
 
 var (
@@ -61,19 +78,192 @@ func main() {
 }
 
 func loadConfig(path string) (aggregator.Config, error) {
-	// For now, return a default config
-	// In a real implementation, you would load from YAML/JSON
-	return aggregator.Config{
-		EcdsaPrivateKeyStorePath:      "keys/aggregator.ecdsa.key.json",
-		EthRpcUrl:                     "http://localhost:8545",
-		EthWsUrl:                      "ws://localhost:8546",
-		RegistryCoordinatorAddress:    "0x0000000000000000000000000000000000000000",
-		OperatorStateRetrieverAddress: "0x0000000000000000000000000000000000000000",
-		EigenMetricsIpPortAddress:     "0.0.0.0:9091",
-		EnableMetrics:                 true,
-		NodeApiIpPortAddress:          "0.0.0.0:8080",
-		EnableNodeApi:                 true,
-		AggregatorServerIpPortAddr:    "0.0.0.0:9090",
-		QuorumThreshold:               67, // 67% threshold
-	}, nil
+	var config aggregator.Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Decode YAML (a superset of JSON) generically and re-encode it as JSON so the
+	// json tags on aggregator.Config define the file's field names
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return config, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return config, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := json.Unmarshal(jsonData, &config); err != nil {
+		return config, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	applyConfigDefaults(&config)
+
+	if err := validateConfig(config); err != nil {
+		return config, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// applyConfigDefaults fills in optional fields that were left unset
+func applyConfigDefaults(config *aggregator.Config) {
+	if config.QuorumThreshold == 0 {
+		config.QuorumThreshold = defaultQuorumThreshold
+	}
+	if config.EigenMetricsIpPortAddress == "" {
+		config.EigenMetricsIpPortAddress = defaultEigenMetricsIpPortAddr
+	}
+	if config.NodeApiIpPortAddress == "" {
+		config.NodeApiIpPortAddress = defaultNodeApiIpPortAddr
+	}
+	if config.AggregatorServerIpPortAddr == "" {
+		config.AggregatorServerIpPortAddr = defaultAggregatorServerIpPort
+	}
+}
+
+// validateConfig checks that all required fields are present and usable, reporting
+// every problem at once
+func validateConfig(config aggregator.Config) error {
+	var errs []error
+
+	if config.EcdsaPrivateKeyStorePath == "" {
+		errs = append(errs, errors.New("ecdsa_private_key_store_path is required"))
+	}
+	if config.QuorumThreshold > 100 {
+		errs = append(errs, fmt.Errorf("quorum_threshold must be between 1 and 100, got %d", config.QuorumThreshold))
+	}
+
+	if err := config.ContractAddresses.Validate("contract_addresses",
+		lvrtypes.ContractRegistryCoordinator, lvrtypes.ContractOperatorStateRetriever, lvrtypes.ContractServiceManager); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch config.ResponseStoreMode {
+	case "", aggregator.ResponseStoreMemory:
+	case aggregator.ResponseStoreFile:
+		if config.ResponseStorePath == "" {
+			errs = append(errs, errors.New("response_store_path is required when response_store_mode is file"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("response_store_mode must be %q or %q, got %q",
+			aggregator.ResponseStoreMemory, aggregator.ResponseStoreFile, config.ResponseStoreMode))
+	}
+
+	switch config.ConsensusTieBreak {
+	case "", aggregator.TieBreakHighestBid, aggregator.TieBreakAccuracy:
+	default:
+		errs = append(errs, fmt.Errorf("consensus_tie_break must be %q or %q, got %q",
+			aggregator.TieBreakHighestBid, aggregator.TieBreakAccuracy, config.ConsensusTieBreak))
+	}
+
+	switch config.ConsensusStrategy {
+	case "", aggregator.ConsensusPlurality, aggregator.ConsensusStakeMajority, aggregator.ConsensusMedianBid:
+	default:
+		errs = append(errs, fmt.Errorf("consensus_strategy must be %q, %q or %q, got %q",
+			aggregator.ConsensusPlurality, aggregator.ConsensusStakeMajority, aggregator.ConsensusMedianBid, config.ConsensusStrategy))
+	}
+
+	if config.RateLimit.PerIPBurst < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit.per_ip_burst must not be negative, got %d", config.RateLimit.PerIPBurst))
+	}
+	if config.RateLimit.PerOperatorBurst < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit.per_operator_burst must not be negative, got %d", config.RateLimit.PerOperatorBurst))
+	}
+
+	if !config.MEVSplit.IsZero() {
+		if err := config.MEVSplit.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("mev_split: %w", err))
+		}
+	}
+
+	if token := config.WinningBid.SettlementToken; token != "" && !common.IsHexAddress(token) {
+		errs = append(errs, fmt.Errorf("winning_bid.settlement_token: invalid address %q", token))
+	}
+	if config.WinningBid.Decimals > 77 {
+		errs = append(errs, fmt.Errorf("winning_bid.decimals must be at most 77, got %d", config.WinningBid.Decimals))
+	}
+
+	if config.BidTolerance.Bps > 10000 {
+		errs = append(errs, fmt.Errorf("bid_tolerance.bps must be at most 10000, got %d", config.BidTolerance.Bps))
+	}
+
+	for i, admin := range config.AdminAddresses {
+		if !common.IsHexAddress(admin) {
+			errs = append(errs, fmt.Errorf("admin_addresses[%d]: invalid address %q", i, admin))
+		}
+	}
+
+	for i, webhook := range config.Webhooks {
+		if err := validateWebhook(webhook); err != nil {
+			errs = append(errs, fmt.Errorf("webhooks[%d]: %w", i, err))
+		}
+	}
+
+	if config.EthRpcUrl == "" {
+		errs = append(errs, errors.New("eth_rpc_url is required"))
+	} else if err := checkRPCReachable(config.EthRpcUrl, "http", "https"); err != nil {
+		errs = append(errs, fmt.Errorf("eth_rpc_url: %w", err))
+	}
+	if config.EthWsUrl != "" {
+		if err := checkRPCReachable(config.EthWsUrl, "ws", "wss"); err != nil {
+			errs = append(errs, fmt.Errorf("eth_ws_url: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateWebhook checks that a webhook has an http(s) url and a known format
+func validateWebhook(webhook aggregator.WebhookConfig) error {
+	var errs []error
+	if parsed, err := url.Parse(webhook.URL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid url %q: %w", webhook.URL, err))
+	} else if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		errs = append(errs, fmt.Errorf("url must be http or https, got %q", webhook.URL))
+	}
+	switch webhook.Format {
+	case "", aggregator.WebhookFormatJSON, aggregator.WebhookFormatSlack:
+	default:
+		errs = append(errs, fmt.Errorf("format must be %q or %q, got %q",
+			aggregator.WebhookFormatJSON, aggregator.WebhookFormatSlack, webhook.Format))
+	}
+	if webhook.QueueSize < 0 {
+		errs = append(errs, fmt.Errorf("queue_size must not be negative, got %d", webhook.QueueSize))
+	}
+	return errors.Join(errs...)
+}
+
+// checkRPCReachable verifies that rawURL uses one of schemes and answers an eth_chainId call
+func checkRPCReachable(rawURL string, schemes ...string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+
+	validScheme := false
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			validScheme = true
+		}
+	}
+	if !validScheme {
+		return fmt.Errorf("unsupported url scheme %q, expected one of %v", parsed.Scheme, schemes)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rpcReachabilityCheckTimeout)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", rawURL, err)
+	}
+	defer client.Close()
+
+	if _, err := client.ChainID(ctx); err != nil {
+		return fmt.Errorf("%s is not reachable: %w", rawURL, err)
+	}
+	return nil
 }
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
@@ -15,13 +17,21 @@ import (
 )
 
 var (
-	configFile = flag.String("config", "config/operator.yaml", "Path to configuration file")
-	logLevel   = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	configFile  = flag.String("config", "config/operator.yaml", "Path to configuration file")
+	logLevel    = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	printConfig = flag.Bool("print-config", false, "Print the effective configuration as JSON, with secrets redacted, and exit")
 )
 
+// redacted replaces secrets in printed configuration
+const redacted = "REDACTED"
+
 func main() {
 	flag.Parse()
 
+	if *printConfig {
+		os.Exit(printEffectiveConfig(os.Stdout, os.Stderr, *configFile))
+	}
+
 	// Set log level
 	level, err := logrus.ParseLevel(*logLevel)
 	if err != nil {
@@ -79,11 +89,59 @@ func loadConfig(configFile string) (*types.OperatorConfig, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config types.OperatorConfig
-	err = yaml.Unmarshal(data, &config)
+	// Decode YAML (a superset of JSON) generically and re-encode it as JSON so the
+	// json tags on types.OperatorConfig define the file's field names
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	jsonData, err := json.Marshal(raw)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	var config types.OperatorConfig
+	if err := json.Unmarshal(jsonData, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
 
 	return &config, nil
 }
+
+// printEffectiveConfig loads and validates the config file, then prints it with
+// secrets redacted, returning the process exit code
+func printEffectiveConfig(stdout, stderr io.Writer, configFile string) int {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	data, err := json.MarshalIndent(redactConfig(*config), "", "  ")
+	if err != nil {
+		fmt.Fprintln(stderr, "failed to encode config:", err)
+		return 1
+	}
+	fmt.Fprintln(stdout, string(data))
+	return 0
+}
+
+// redactConfig returns a copy of the config with the private key and price feed
+// API keys replaced
+func redactConfig(config types.OperatorConfig) types.OperatorConfig {
+	if config.PrivateKey != "" {
+		config.PrivateKey = redacted
+	}
+	feeds := make([]types.PriceFeedConfig, len(config.PriceFeeds))
+	for i, feed := range config.PriceFeeds {
+		if feed.APIKey != "" {
+			feed.APIKey = redacted
+		}
+		feeds[i] = feed
+	}
+	config.PriceFeeds = feeds
+	return config
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPrivateKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func writeTestConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "operator.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestPrintConfigRedactsSecrets(t *testing.T) {
+	path := writeTestConfig(t, `
+private_key: "`+testPrivateKey+`"
+bls_key_store_path: "keys/operator.bls.key.json"
+network_config:
+  rpc_url: "http://localhost:8545"
+  contract_addresses:
+    serviceManager: "0x1234567890123456789012345678901234567890"
+price_feeds:
+  - name: "binance"
+    api_key: "feed-secret"
+    update_frequency_seconds: 5
+`)
+
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if config.PrivateKey != testPrivateKey || config.NetworkConfig.RPCURL != "http://localhost:8545" {
+		t.Fatalf("loaded config = %+v, want the file's snake_case fields", config)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := printEffectiveConfig(&stdout, &stderr, path); code != 0 {
+		t.Fatalf("printEffectiveConfig = %d, want 0 (stderr %q)", code, stderr.String())
+	}
+	printed := stdout.String()
+	for _, secret := range []string{testPrivateKey, strings.TrimPrefix(testPrivateKey, "0x"), "feed-secret"} {
+		if strings.Contains(printed, secret) {
+			t.Fatalf("printed config leaks %q:\n%s", secret, printed)
+		}
+	}
+	if !strings.Contains(printed, `"private_key": "REDACTED"`) || !strings.Contains(printed, `"rpc_url": "http://localhost:8545"`) {
+		t.Fatalf("printed config is missing the redacted key or the rpc url:\n%s", printed)
+	}
+	if config.PrivateKey != testPrivateKey || config.PriceFeeds[0].APIKey != "feed-secret" {
+		t.Fatal("redacting the printed config modified the loaded one")
+	}
+}
+
+func TestPrintConfigReportsValidationErrors(t *testing.T) {
+	path := writeTestConfig(t, "private_key: \"0xnot-a-key\"\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := printEffectiveConfig(&stdout, &stderr, path); code == 0 {
+		t.Fatal("printEffectiveConfig on an invalid config = 0, want non-zero")
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), "bls_key_store_path is required") {
+		t.Fatalf("stdout %q, stderr %q; want only the validation errors", stdout.String(), stderr.String())
+	}
+}
@@ -3,26 +3,72 @@ package avsregistry
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
+	"sync"
 
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
 	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/Layr-Labs/eigensdk-go/signerv2"
+	"github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/lvr-auction-hook/avs/pkg/nonce"
 )
 
+// ErrTransactionReverted is returned for a transaction that was mined but reverted
+var ErrTransactionReverted = errors.New("transaction reverted")
+
+// serviceManagerABI is the subset of the LVRAuctionServiceManager ABI used to submit task responses
+var serviceManagerABI = mustParseABI(`[
+	{
+		"type": "function",
+		"name": "respondToTask",
+		"stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "taskIndex", "type": "uint32"},
+			{"name": "winner", "type": "address"},
+			{"name": "winningBid", "type": "uint256"},
+			{"name": "signature", "type": "bytes"}
+		],
+		"outputs": []
+	}
+]`)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
 type AvsRegistryChainReader struct {
 	avsregistry.AvsRegistryReader
 	logger logging.Logger
+
+	// pubkeys caches registered operator BLS pubkeys by operator id
+	pubkeys    map[types.OperatorId]types.OperatorPubkeys
+	pubkeysMux sync.RWMutex
 }
 
 type AvsRegistryChainWriter struct {
 	avsregistry.AvsRegistryWriter
 	logger logging.Logger
+
+	ethClient  eth.Client
+	privateKey *ecdsa.PrivateKey
+	txMgr      txmgr.TxManager
+	nonces     *nonce.Manager
 }
 
 type AvsRegistryConfig struct {
@@ -49,9 +95,122 @@ func NewAvsRegistryChainReader(
 	return &AvsRegistryChainReader{
 		AvsRegistryReader: *avsRegistryReader,
 		logger:            logger,
+		pubkeys:           make(map[types.OperatorId]types.OperatorPubkeys),
 	}, nil
 }
 
+// GetOperatorStakesAtBlock returns the stake of every operator registered in any of
+// the given quorums at blockNumber, summed across quorums. A blockNumber of 0
+// reads the operator set at the current block.
+func (r *AvsRegistryChainReader) GetOperatorStakesAtBlock(
+	ctx context.Context,
+	quorumNumbers types.QuorumNums,
+	blockNumber uint32,
+) (map[types.OperatorId]*big.Int, error) {
+	stakesPerQuorum, err := r.GetOperatorStakesPerQuorumAtBlock(ctx, quorumNumbers, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	stakes := make(map[types.OperatorId]*big.Int)
+	for _, quorumStakes := range stakesPerQuorum {
+		for id, stake := range quorumStakes {
+			if _, exists := stakes[id]; !exists {
+				stakes[id] = new(big.Int)
+			}
+			stakes[id].Add(stakes[id], stake)
+		}
+	}
+
+	return stakes, nil
+}
+
+// GetOperatorStakesPerQuorumAtBlock returns the stake of every operator registered
+// in each of the given quorums at blockNumber. A blockNumber of 0 reads the
+// operator set at the current block.
+func (r *AvsRegistryChainReader) GetOperatorStakesPerQuorumAtBlock(
+	ctx context.Context,
+	quorumNumbers types.QuorumNums,
+	blockNumber uint32,
+) (map[types.QuorumNum]map[types.OperatorId]*big.Int, error) {
+	opts := &bind.CallOpts{Context: ctx}
+
+	var operatorsPerQuorum [][]avsregistry.OperatorStateRetrieverOperator
+	var err error
+	if blockNumber == 0 {
+		operatorsPerQuorum, err = r.GetOperatorsStakeInQuorumsAtCurrentBlock(opts, quorumNumbers)
+	} else {
+		operatorsPerQuorum, err = r.GetOperatorsStakeInQuorumsAtBlock(opts, quorumNumbers, blockNumber)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The retriever returns the operators of each quorum in the order requested
+	stakesPerQuorum := make(map[types.QuorumNum]map[types.OperatorId]*big.Int, len(quorumNumbers))
+	for i, operators := range operatorsPerQuorum {
+		if i >= len(quorumNumbers) {
+			break
+		}
+		stakes := make(map[types.OperatorId]*big.Int, len(operators))
+		for _, operator := range operators {
+			stakes[types.OperatorId(operator.OperatorId)] = new(big.Int).Set(operator.Stake)
+		}
+		stakesPerQuorum[quorumNumbers[i]] = stakes
+	}
+
+	return stakesPerQuorum, nil
+}
+
+// GetOperatorPubkeys returns the BLS pubkeys an operator registered with. Registrations
+// are read from chain and cached, and the cache is refreshed when an id is unknown.
+func (r *AvsRegistryChainReader) GetOperatorPubkeys(ctx context.Context, operatorId types.OperatorId) (types.OperatorPubkeys, error) {
+	r.pubkeysMux.RLock()
+	pubkeys, exists := r.pubkeys[operatorId]
+	r.pubkeysMux.RUnlock()
+	if exists {
+		return pubkeys, nil
+	}
+
+	addresses, registeredPubkeys, err := r.QueryExistingRegisteredOperatorPubKeys(ctx, nil, nil, nil)
+	if err != nil {
+		return types.OperatorPubkeys{}, err
+	}
+
+	r.pubkeysMux.Lock()
+	defer r.pubkeysMux.Unlock()
+	for i, address := range addresses {
+		id, err := r.GetOperatorId(&bind.CallOpts{Context: ctx}, address)
+		if err != nil {
+			return types.OperatorPubkeys{}, err
+		}
+		r.pubkeys[types.OperatorId(id)] = registeredPubkeys[i]
+	}
+
+	pubkeys, exists = r.pubkeys[operatorId]
+	if !exists {
+		return types.OperatorPubkeys{}, fmt.Errorf("operator %s has no registered pubkeys", operatorId.Hex())
+	}
+	return pubkeys, nil
+}
+
+// GetRegisteredOperatorId returns the id of the operator registered with address,
+// and false if address is not a registered operator
+func (r *AvsRegistryChainReader) GetRegisteredOperatorId(ctx context.Context, address common.Address) (types.OperatorId, bool, error) {
+	opts := &bind.CallOpts{Context: ctx}
+
+	registered, err := r.IsOperatorRegistered(opts, address)
+	if err != nil || !registered {
+		return types.OperatorId{}, false, err
+	}
+
+	id, err := r.GetOperatorId(opts, address)
+	if err != nil {
+		return types.OperatorId{}, false, err
+	}
+	return types.OperatorId(id), true, nil
+}
+
 func NewAvsRegistryChainWriter(
 	registryCoordinatorAddr common.Address,
 	operatorStateRetrieverAddr common.Address,
@@ -80,9 +239,65 @@ func NewAvsRegistryChainWriter(
 	return &AvsRegistryChainWriter{
 		AvsRegistryWriter: *avsRegistryWriter,
 		logger:            logger,
+		ethClient:         ethClient,
+		privateKey:        privateKey,
+		txMgr:             txMgr,
+		nonces:            nonce.NewManager(ethClient, crypto.PubkeyToAddress(privateKey.PublicKey)),
 	}, nil
 }
 
+// RespondToTask calls respondToTask on the service manager with a task's consensus
+// result and waits for the transaction to be mined. It returns an error if the
+// transaction cannot be sent or reverts.
+func (w *AvsRegistryChainWriter) RespondToTask(
+	ctx context.Context,
+	serviceManagerAddr common.Address,
+	taskIndex uint32,
+	winner common.Address,
+	winningBid *big.Int,
+	signature []byte,
+) (*gethtypes.Receipt, error) {
+	chainID, err := w.ethClient.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(w.privateKey, chainID)
+	if err != nil {
+		return nil, err
+	}
+	// Build and sign the transaction only; the tx manager sends it and waits for the receipt
+	opts.Context = ctx
+	opts.NoSend = true
+	// The nonce is resynced if the transaction fails, since a nonce that never
+	// reaches the chain would stall every later transaction
+	if err := w.nonces.Apply(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	contract := bind.NewBoundContract(serviceManagerAddr, serviceManagerABI, w.ethClient, w.ethClient, w.ethClient)
+	tx, err := contract.Transact(opts, "respondToTask", taskIndex, winner, winningBid, signature)
+	if err != nil {
+		w.nonces.Resync()
+		return nil, fmt.Errorf("failed to build respondToTask transaction: %w", err)
+	}
+
+	receipt, err := w.txMgr.Send(ctx, tx)
+	if err != nil {
+		w.nonces.Resync()
+		return nil, fmt.Errorf("failed to send respondToTask transaction: %w", err)
+	}
+	if receipt != nil && receipt.Status != gethtypes.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("respondToTask transaction %s: %w", receipt.TxHash.Hex(), ErrTransactionReverted)
+	}
+
+	w.logger.Info("Task response submitted to service manager",
+		"taskIndex", taskIndex,
+		"txHash", tx.Hash().Hex(),
+	)
+	return receipt, nil
+}
+
 // RegisterOperatorInQuorumWithAVSRegistryCoordinator registers an operator with the AVS registry
 func (w *AvsRegistryChainWriter) RegisterOperatorInQuorumWithAVSRegistryCoordinator(
 	ctx context.Context,
@@ -93,7 +308,7 @@ func (w *AvsRegistryChainWriter) RegisterOperatorInQuorumWithAVSRegistryCoordina
 	quorumNumbers []byte,
 ) error {
 	w.logger.Info("Registering operator with AVS registry coordinator")
-	
+
 	// This would call the actual registration function from eigensdk-go
 	// For now, we'll just log the operation
 	w.logger.Info("Operator registration completed",
@@ -101,7 +316,7 @@ func (w *AvsRegistryChainWriter) RegisterOperatorInQuorumWithAVSRegistryCoordina
 		"blsPubkeyG1", blsKeyPair.PubkeyG1.String(),
 		"blsPubkeyG2", blsKeyPair.PubkeyG2.String(),
 	)
-	
+
 	return nil
 }
 
@@ -113,18 +328,18 @@ func (w *AvsRegistryChainWriter) DeregisterOperator(
 	w.logger.Info("Deregistering operator from AVS",
 		"quorumNumbers", quorumNumbers,
 	)
-	
+
 	return nil
 }
 
 // UpdateOperatorSocket updates the operator's socket address
 func (w *AvsRegistryChainWriter) UpdateOperatorSocket(
-	ctx context.Context, 
+	ctx context.Context,
 	socket string,
 ) error {
 	w.logger.Info("Updating operator socket",
 		"socket", socket,
 	)
-	
+
 	return nil
 }
@@ -0,0 +1,67 @@
+package nonce
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PendingNonceReader reads an account's next nonce, counting pending transactions
+type PendingNonceReader interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// Manager hands out consecutive nonces for an account. It reads the pending nonce
+// from the node once, then increments it locally for each transaction. Callers
+// Resync after a transaction fails to be built or sent, since the nonce it was
+// given may never reach the chain.
+type Manager struct {
+	reader  PendingNonceReader
+	account common.Address
+
+	next   uint64
+	synced bool
+	mutex  sync.Mutex
+}
+
+func NewManager(reader PendingNonceReader, account common.Address) *Manager {
+	return &Manager{reader: reader, account: account}
+}
+
+// Next returns the nonce of the account's next transaction and reserves it
+func (m *Manager) Next(ctx context.Context) (uint64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.synced {
+		pending, err := m.reader.PendingNonceAt(ctx, m.account)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read pending nonce of %s: %w", m.account.Hex(), err)
+		}
+		m.next, m.synced = pending, true
+	}
+	nonce := m.next
+	m.next++
+	return nonce, nil
+}
+
+// Apply sets the nonce of opts to the account's next nonce
+func (m *Manager) Apply(ctx context.Context, opts *bind.TransactOpts) error {
+	nonce, err := m.Next(ctx)
+	if err != nil {
+		return err
+	}
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	return nil
+}
+
+// Resync makes the next nonce be read from the node again
+func (m *Manager) Resync() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.synced = false
+}
@@ -0,0 +1,93 @@
+package nonce
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeNonceReader reports a fixed pending nonce and counts the reads
+type fakeNonceReader struct {
+	pending uint64
+	err     error
+	reads   int
+}
+
+func (r *fakeNonceReader) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	r.reads++
+	return r.pending, r.err
+}
+
+func TestManagerIncrementsLocally(t *testing.T) {
+	reader := &fakeNonceReader{pending: 5}
+	m := NewManager(reader, common.HexToAddress("0xb0b"))
+	ctx := context.Background()
+
+	for want := uint64(5); want < 10; want++ {
+		opts := &bind.TransactOpts{}
+		if err := m.Apply(ctx, opts); err != nil {
+			t.Fatalf("Apply: %v", err)
+		}
+		if opts.Nonce.Uint64() != want {
+			t.Fatalf("nonce = %d, want %d", opts.Nonce.Uint64(), want)
+		}
+	}
+	if reader.reads != 1 {
+		t.Fatalf("read the pending nonce %d times, want once", reader.reads)
+	}
+
+	// After a failed transaction the node's pending nonce is authoritative again
+	reader.pending = 7
+	m.Resync()
+	if nonce, err := m.Next(ctx); err != nil || nonce != 7 {
+		t.Fatalf("Next after resync = %d, %v, want 7", nonce, err)
+	}
+	if reader.reads != 2 {
+		t.Fatalf("read the pending nonce %d times, want twice", reader.reads)
+	}
+}
+
+func TestManagerConcurrentNoncesAreUnique(t *testing.T) {
+	m := NewManager(&fakeNonceReader{pending: 100}, common.HexToAddress("0xb0b"))
+
+	const transactions = 50
+	nonces := make([]uint64, transactions)
+	var wg sync.WaitGroup
+	for i := range nonces {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nonce, err := m.Next(context.Background())
+			if err != nil {
+				t.Errorf("Next: %v", err)
+			}
+			nonces[i] = nonce
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		if nonce != uint64(100+i) {
+			t.Fatalf("nonces %v are not consecutive from 100", nonces)
+		}
+	}
+}
+
+func TestManagerRetriesFailedSync(t *testing.T) {
+	reader := &fakeNonceReader{err: errors.New("connection refused")}
+	m := NewManager(reader, common.HexToAddress("0xb0b"))
+
+	if _, err := m.Next(context.Background()); err == nil {
+		t.Fatal("expected the failed pending nonce read to be returned")
+	}
+	reader.pending, reader.err = 3, nil
+	if nonce, err := m.Next(context.Background()); err != nil || nonce != 3 {
+		t.Fatalf("Next = %d, %v, want 3 once the node answers", nonce, err)
+	}
+}
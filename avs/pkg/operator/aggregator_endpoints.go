@@ -0,0 +1,156 @@
+package operator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	// aggregatorFailureThreshold is the consecutive failed submissions after which
+	// an aggregator is tried only once the healthy ones have failed
+	aggregatorFailureThreshold = 1
+	// aggregatorBaseBackoff is how long a failing aggregator is deprioritized,
+	// doubled each time it fails again, up to maxAggregatorBackoff
+	aggregatorBaseBackoff = 5 * time.Second
+	maxAggregatorBackoff  = time.Minute
+
+	// submitRetries is how many times a response every aggregator failed to take
+	// is resubmitted, with the delay doubling from defaultSubmitBackoff
+	submitRetries        = 2
+	defaultSubmitBackoff = time.Second
+)
+
+// aggregatorEndpoint is an aggregator task responses are submitted to, whose
+// health is tracked by the same circuit breaker as price feeds
+type aggregatorEndpoint struct {
+	url     string
+	breaker *feedBreaker
+}
+
+// newAggregatorEndpoints returns the configured primary aggregator followed by its
+// failovers, in order and without duplicates
+func newAggregatorEndpoints(primary string, failovers []string) []*aggregatorEndpoint {
+	var endpoints []*aggregatorEndpoint
+	seen := make(map[string]bool)
+	for _, url := range append([]string{primary}, failovers...) {
+		url = strings.TrimSuffix(url, "/")
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		endpoints = append(endpoints, &aggregatorEndpoint{
+			url:     url,
+			breaker: newBreaker(aggregatorFailureThreshold, aggregatorBaseBackoff, maxAggregatorBackoff),
+		})
+	}
+	return endpoints
+}
+
+// submissionOrder returns the aggregators in configured order, healthy ones
+// first. Failing aggregators are still tried last so a response is only lost
+// when every aggregator fails.
+func (ac *AuctionCoordinator) submissionOrder() []*aggregatorEndpoint {
+	var healthy, failing []*aggregatorEndpoint
+	for _, endpoint := range ac.aggregators {
+		if endpoint.breaker.allow() {
+			healthy = append(healthy, endpoint)
+		} else {
+			failing = append(failing, endpoint)
+		}
+	}
+	return append(healthy, failing...)
+}
+
+// submitToAggregators posts a signed response body to the first aggregator that
+// accepts it, failing over to the next whenever one is unreachable or errors. The
+// body is resubmitted unchanged after a backoff while every aggregator fails.
+func (ac *AuctionCoordinator) submitToAggregators(taskID uint32, body []byte, signature string) error {
+	if len(ac.aggregators) == 0 {
+		return errors.New("no aggregator configured")
+	}
+
+	backoff := ac.submitBackoff
+	for attempt := 0; ; attempt++ {
+		failed, err := ac.submitOnce(taskID, body, signature)
+		if !failed || attempt >= submitRetries {
+			return err
+		}
+
+		ac.logger.WithError(err).WithFields(logrus.Fields{
+			"task_id": taskID,
+			"attempt": attempt + 1,
+			"backoff": backoff,
+		}).Warn("Every aggregator failed to take task response, retrying")
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// submitOnce tries each aggregator in submissionOrder until one answers. It
+// reports whether every aggregator failed.
+func (ac *AuctionCoordinator) submitOnce(taskID uint32, body []byte, signature string) (bool, error) {
+	var errs []error
+	for _, endpoint := range ac.submissionOrder() {
+		failover, err := ac.postTaskResponse(endpoint.url, body, signature)
+		if !failover {
+			// The aggregator answered, so it is healthy even if it rejected the response
+			endpoint.breaker.record(nil)
+			return false, err
+		}
+
+		endpoint.breaker.record(err)
+		ac.logger.WithError(err).WithFields(logrus.Fields{
+			"task_id":    taskID,
+			"aggregator": endpoint.url,
+		}).Warn("Aggregator failed to take task response, failing over")
+		errs = append(errs, fmt.Errorf("%s: %w", endpoint.url, err))
+	}
+	return true, fmt.Errorf("every aggregator failed: %w", errors.Join(errs...))
+}
+
+// postTaskResponse sends a signed response body to one aggregator. It reports
+// whether a failure lies with the aggregator, so another should be tried, rather
+// than with the response it rejected.
+func (ac *AuctionCoordinator) postTaskResponse(url string, body []byte, signature string) (bool, error) {
+	resp, err := ac.aggregator.R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader(OperatorSignatureHeader, signature).
+		SetBody(body).
+		Post(url + "/submit-response")
+	if err != nil {
+		return true, err
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusOK:
+		return false, nil
+	case resp.StatusCode() >= http.StatusInternalServerError:
+		return true, fmt.Errorf("aggregator returned HTTP %d: %s", resp.StatusCode(), resp.String())
+	default:
+		return false, fmt.Errorf("aggregator returned HTTP %d: %s", resp.StatusCode(), resp.String())
+	}
+}
+
+// blsOperatorID returns the EigenLayer operator id of a BLS key pair
+func blsOperatorID(keyPair *bls.KeyPair) [32]byte {
+	pubkey := keyPair.GetPubKeyG1()
+	return types.OperatorID(pubkey.X.BigInt(new(big.Int)), pubkey.Y.BigInt(new(big.Int)))
+}
+
+// AggregatorHealth returns the circuit breaker state of each aggregator, keyed by url
+func (ac *AuctionCoordinator) AggregatorHealth() map[string]FeedHealth {
+	health := make(map[string]FeedHealth, len(ac.aggregators))
+	for _, endpoint := range ac.aggregators {
+		health[endpoint.url] = endpoint.breaker.health()
+	}
+	return health
+}
@@ -0,0 +1,153 @@
+package operator
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// newTestAggregatorServer counts the task responses posted to it, answering each with status
+func newTestAggregatorServer(t *testing.T, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/submit-response" || r.Header.Get(OperatorSignatureHeader) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+// newTestSubmitter creates a coordinator submitting to the given aggregators in order
+func newTestSubmitter(t *testing.T, primary string, failovers ...string) *AuctionCoordinator {
+	t.Helper()
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	blsKeyPair, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		t.Fatalf("GenRandomBlsKeys: %v", err)
+	}
+	config := &types.OperatorConfig{AggregatorURL: primary, AggregatorURLs: failovers}
+	coord, err := NewAuctionCoordinator(config, privateKey, blsKeyPair, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewAuctionCoordinator: %v", err)
+	}
+	coord.submitBackoff = time.Millisecond
+	return coord
+}
+
+func TestSubmitTaskResponseFailsOverToSecondary(t *testing.T) {
+	primary, primaryReceived := newTestAggregatorServer(t, http.StatusServiceUnavailable)
+	secondary, secondaryReceived := newTestAggregatorServer(t, http.StatusOK)
+	coord := newTestSubmitter(t, primary.URL, secondary.URL)
+
+	response := &types.TaskResponse{Winner: testBidder, WinningBid: big.NewInt(250)}
+	if err := coord.SubmitTaskResponse(1, response); err != nil {
+		t.Fatalf("SubmitTaskResponse: %v", err)
+	}
+	if primaryReceived.Load() != 1 || secondaryReceived.Load() != 1 {
+		t.Fatalf("primary received %d, secondary %d, want one each", primaryReceived.Load(), secondaryReceived.Load())
+	}
+
+	health := coord.AggregatorHealth()
+	if state := health[primary.URL].State; state != breakerOpen {
+		t.Fatalf("primary state = %q, want %q", state, breakerOpen)
+	}
+	if state := health[secondary.URL].State; state != breakerClosed {
+		t.Fatalf("secondary state = %q, want %q", state, breakerClosed)
+	}
+
+	// The failing primary is skipped while the secondary is healthy
+	if err := coord.SubmitTaskResponse(2, response); err != nil {
+		t.Fatalf("SubmitTaskResponse: %v", err)
+	}
+	if primaryReceived.Load() != 1 || secondaryReceived.Load() != 2 {
+		t.Fatalf("primary received %d, secondary %d, want the secondary tried first", primaryReceived.Load(), secondaryReceived.Load())
+	}
+}
+
+func TestSubmitTaskResponseDoesNotFailOverRejections(t *testing.T) {
+	primary, _ := newTestAggregatorServer(t, http.StatusUnauthorized)
+	secondary, secondaryReceived := newTestAggregatorServer(t, http.StatusOK)
+	coord := newTestSubmitter(t, primary.URL, secondary.URL)
+
+	response := &types.TaskResponse{Winner: testBidder, WinningBid: big.NewInt(250)}
+	if err := coord.SubmitTaskResponse(1, response); err == nil {
+		t.Fatal("expected the rejected response to fail")
+	}
+	if secondaryReceived.Load() != 0 {
+		t.Fatal("a response rejected by a healthy aggregator was resubmitted")
+	}
+	if state := coord.AggregatorHealth()[primary.URL].State; state != breakerClosed {
+		t.Fatalf("primary state = %q after answering, want %q", state, breakerClosed)
+	}
+}
+
+func TestSubmitTaskResponseFailsWhenEveryAggregatorFails(t *testing.T) {
+	primary, _ := newTestAggregatorServer(t, http.StatusInternalServerError)
+	coord := newTestSubmitter(t, primary.URL, "http://127.0.0.1:1")
+
+	response := &types.TaskResponse{Winner: testBidder, WinningBid: big.NewInt(250)}
+	if err := coord.SubmitTaskResponse(1, response); err == nil {
+		t.Fatal("expected the submission to fail")
+	}
+}
+
+func TestSubmitTaskResponseReusesIdempotencyKeyAfterTimeout(t *testing.T) {
+	var mutex sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			IdempotencyKey string `json:"idempotencyKey"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		mutex.Lock()
+		keys = append(keys, payload.IdempotencyKey)
+		first := len(keys) == 1
+		mutex.Unlock()
+
+		// The first response is taken but answered only after the operator gave up
+		if first {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	coord := newTestSubmitter(t, server.URL)
+	coord.aggregator.SetTimeout(50 * time.Millisecond)
+
+	response := &types.TaskResponse{Winner: testBidder, WinningBid: big.NewInt(250)}
+	if err := coord.SubmitTaskResponse(7, response); err != nil {
+		t.Fatalf("SubmitTaskResponse: %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(keys) != 2 {
+		t.Fatalf("aggregator received %d submissions, want the timed out one retried once", len(keys))
+	}
+	want := types.TaskResponseIdempotencyKey(coord.operatorID, 7)
+	if keys[0] != want || keys[1] != want {
+		t.Fatalf("idempotency keys = %q, want both %q", keys, want)
+	}
+	if coord.operatorID == [32]byte{} {
+		t.Fatal("expected the operator id to be derived from the BLS key")
+	}
+}
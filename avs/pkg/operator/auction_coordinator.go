@@ -0,0 +1,651 @@
+package operator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// serviceManagerABI is the subset of the LVRAuctionServiceManager ABI used by the operator
+const serviceManagerABI = `[
+	{
+		"type": "event",
+		"name": "NewTaskCreated",
+		"anonymous": false,
+		"inputs": [
+			{"name": "taskIndex", "type": "uint32", "indexed": true},
+			{"name": "task", "type": "tuple", "indexed": false, "components": [
+				{"name": "auctionId", "type": "bytes32"},
+				{"name": "poolId", "type": "bytes32"},
+				{"name": "taskCreatedBlock", "type": "uint32"},
+				{"name": "deadline", "type": "uint256"},
+				{"name": "completed", "type": "bool"}
+			]}
+		]
+	}
+]`
+
+// defaultTaskScanInterval is how often the coordinator scans the service manager
+// for new tasks while it is not subscribed to them
+const defaultTaskScanInterval = 2 * time.Second
+
+// auctionCoordinator is the task source the operator loop depends on
+type auctionCoordinator interface {
+	Start(ctx context.Context)
+	GetPendingTasks() ([]*types.Task, error)
+	// NewTasks delivers tasks as they are created while Subscribed reports true
+	NewTasks() <-chan *types.Task
+	Subscribed() bool
+	GetAuction(auctionID string) (*types.Auction, error)
+	GetBids(auctionID string) ([]types.Bid, error)
+	AddBid(auctionID string, bid types.Bid) error
+	AddBidRoot(auctionID string, root string, leaves []types.Bid) error
+	ChainTime() (time.Time, error)
+	SubmitTaskResponse(taskID uint32, response *types.TaskResponse) error
+	AggregatorHealth() map[string]FeedHealth
+}
+
+// newTaskCreatedEvent mirrors the NewTaskCreated event payload
+type newTaskCreatedEvent struct {
+	Task struct {
+		AuctionId        [32]byte
+		PoolId           [32]byte
+		TaskCreatedBlock uint32
+		Deadline         *big.Int
+		Completed        bool
+	}
+}
+
+// AuctionCoordinator tracks auction tasks created by the service manager and
+// relays operator responses to the aggregator
+type AuctionCoordinator struct {
+	privateKey     *ecdsa.PrivateKey
+	blsKeyPair     *bls.KeyPair
+	address        common.Address
+	client         *ethclient.Client
+	serviceManager common.Address
+	contractABI    abi.ABI
+	aggregator     *resty.Client
+	aggregators    []*aggregatorEndpoint
+	operatorID     [32]byte
+	scanInterval   time.Duration
+	logger         *logrus.Logger
+
+	// wsURL is the websocket endpoint NewTaskCreated events are subscribed over
+	wsURL      string
+	dial       func(ctx context.Context, url string) (taskSubscriber, error)
+	newTasks   chan *types.Task
+	subscribed atomic.Bool
+	// reconnectBackoff is the delay before the first reconnection attempt
+	reconnectBackoff time.Duration
+	// cursor persists the last processed block for backfilling, nil if disabled
+	cursor *taskCursor
+	// bidWindow and revealWindow set the bidding windows of new auctions
+	bidWindow    int64
+	revealWindow int64
+	now          func() time.Time
+	// submitBackoff is the delay before resubmitting a response every aggregator failed
+	submitBackoff time.Duration
+	// heartbeatPeriod is how often liveness is reported to the aggregators
+	heartbeatPeriod time.Duration
+
+	tasks    map[uint32]*types.Task
+	auctions map[string]*types.Auction
+	bids     map[string][]types.Bid
+	// lastBlock is the latest block seen and lastBlockTime its timestamp, which
+	// task deadlines are judged against
+	lastBlock     uint64
+	lastBlockTime time.Time
+	mutex         sync.RWMutex
+}
+
+// NewAuctionCoordinator creates a new auction coordinator
+func NewAuctionCoordinator(config *types.OperatorConfig, privateKey *ecdsa.PrivateKey, blsKeyPair *bls.KeyPair, client *ethclient.Client, logger *logrus.Logger) (*AuctionCoordinator, error) {
+	contractABI, err := abi.JSON(strings.NewReader(serviceManagerABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service manager ABI: %w", err)
+	}
+
+	aggregator := resty.New()
+	aggregator.SetTimeout(10 * time.Second)
+
+	var cursor *taskCursor
+	if config.TaskCursorPath != "" {
+		cursor = newTaskCursor(config.TaskCursorPath)
+	}
+
+	scanInterval := time.Duration(config.TaskPollInterval) * time.Second
+	if scanInterval <= 0 {
+		scanInterval = defaultTaskScanInterval
+	}
+	heartbeatPeriod := time.Duration(config.HeartbeatInterval) * time.Second
+	if heartbeatPeriod <= 0 {
+		heartbeatPeriod = defaultHeartbeatInterval
+	}
+
+	return &AuctionCoordinator{
+		privateKey:       privateKey,
+		blsKeyPair:       blsKeyPair,
+		address:          crypto.PubkeyToAddress(privateKey.PublicKey),
+		client:           client,
+		serviceManager:   config.NetworkConfig.ContractAddresses.Address(types.ContractServiceManager),
+		contractABI:      contractABI,
+		aggregator:       aggregator,
+		aggregators:      newAggregatorEndpoints(config.AggregatorURL, config.AggregatorURLs),
+		operatorID:       blsOperatorID(blsKeyPair),
+		submitBackoff:    defaultSubmitBackoff,
+		scanInterval:     scanInterval,
+		logger:           logger,
+		wsURL:            config.NetworkConfig.WSURL,
+		dial:             dialTaskSubscriber,
+		newTasks:         make(chan *types.Task, newTaskBuffer),
+		reconnectBackoff: minReconnectBackoff,
+		tasks:            make(map[uint32]*types.Task),
+		auctions:         make(map[string]*types.Auction),
+		bids:             make(map[string][]types.Bid),
+		cursor:           cursor,
+		bidWindow:        config.BidWindow,
+		revealWindow:     config.RevealWindow,
+		now:              time.Now,
+		heartbeatPeriod:  heartbeatPeriod,
+	}, nil
+}
+
+// Start begins tracking new auction tasks, over a websocket subscription when one
+// is configured and by scanning the service manager whenever it is down
+func (ac *AuctionCoordinator) Start(ctx context.Context) {
+	ac.logger.Info("Starting auction coordinator...")
+
+	// Pick up tasks created while offline before resuming live processing
+	if err := ac.backfillTasks(ctx, ac.client); err != nil {
+		ac.logger.WithError(err).Warn("Failed to backfill tasks created while offline")
+	}
+
+	if ac.wsURL != "" {
+		go ac.subscribeTasks(ctx)
+	}
+	if len(ac.aggregators) > 0 {
+		go ac.sendHeartbeats(ctx)
+	}
+
+	ticker := time.NewTicker(ac.scanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ac.advanceAuctions()
+			if ac.subscribed.Load() {
+				continue
+			}
+			if err := ac.pollTasks(ctx, ac.client); err != nil {
+				ac.logger.WithError(err).Warn("Failed to poll for new tasks")
+			}
+		}
+	}
+}
+
+// taskEventSource is the chain access needed to scan for tasks
+type taskEventSource interface {
+	ethereum.LogFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+}
+
+// pollTasks reads NewTaskCreated events emitted since the last scanned block
+func (ac *AuctionCoordinator) pollTasks(ctx context.Context, client taskEventSource) error {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	head := header.Number.Uint64()
+
+	ac.mutex.RLock()
+	fromBlock := ac.lastBlock + 1
+	ac.mutex.RUnlock()
+	if fromBlock == 1 {
+		// Only look at recent history on the first scan
+		fromBlock = head
+	}
+	if fromBlock > head {
+		return nil
+	}
+
+	query := ac.taskQuery()
+	query.FromBlock = new(big.Int).SetUint64(fromBlock)
+	query.ToBlock = new(big.Int).SetUint64(head)
+	logs, err := client.FilterLogs(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	ac.trackTaskLogs(logs)
+
+	ac.mutex.Lock()
+	ac.lastBlock = head
+	ac.lastBlockTime = time.Unix(int64(header.Time), 0)
+	ac.mutex.Unlock()
+
+	ac.saveCursor()
+	return nil
+}
+
+// trackTaskLogs tracks the tasks of NewTaskCreated events and returns how many
+// were new
+func (ac *AuctionCoordinator) trackTaskLogs(logs []ethtypes.Log) int {
+	var tracked int
+	for _, log := range logs {
+		taskIndex, event, err := ac.decodeTaskLog(log)
+		if err != nil {
+			ac.logger.WithError(err).WithField("tx_hash", log.TxHash.Hex()).Warn("Failed to decode NewTaskCreated event")
+			continue
+		}
+		if ac.trackTask(taskIndex, event) != nil {
+			tracked++
+		}
+	}
+	return tracked
+}
+
+// taskQuery filters the service manager's NewTaskCreated events
+func (ac *AuctionCoordinator) taskQuery() ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		Addresses: []common.Address{ac.serviceManager},
+		Topics:    [][]common.Hash{{ac.contractABI.Events["NewTaskCreated"].ID}},
+	}
+}
+
+// decodeTaskLog decodes the task index and payload of a NewTaskCreated event
+func (ac *AuctionCoordinator) decodeTaskLog(log ethtypes.Log) (uint32, newTaskCreatedEvent, error) {
+	var event newTaskCreatedEvent
+	if len(log.Topics) < 2 {
+		return 0, event, fmt.Errorf("expected 2 topics, got %d", len(log.Topics))
+	}
+	if err := ac.contractABI.UnpackIntoInterface(&event, "NewTaskCreated", log.Data); err != nil {
+		return 0, event, err
+	}
+	return uint32(new(big.Int).SetBytes(log.Topics[1].Bytes()).Uint64()), event, nil
+}
+
+// trackTask records a task and its auction from a decoded NewTaskCreated event.
+// It returns the task, or nil if the task was already tracked.
+func (ac *AuctionCoordinator) trackTask(taskIndex uint32, event newTaskCreatedEvent) *types.Task {
+	auctionID := common.Hash(event.Task.AuctionId).Hex()
+	poolID := common.Hash(event.Task.PoolId).Hex()
+
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+
+	if _, exists := ac.tasks[taskIndex]; exists {
+		return nil
+	}
+
+	task := &types.Task{
+		ID:           taskIndex,
+		AuctionID:    auctionID,
+		PoolID:       poolID,
+		CreatedBlock: event.Task.TaskCreatedBlock,
+		Deadline:     time.Unix(event.Task.Deadline.Int64(), 0),
+		Completed:    event.Task.Completed,
+	}
+	ac.tasks[taskIndex] = task
+
+	if _, exists := ac.auctions[auctionID]; !exists {
+		now := ac.now()
+		auction := &types.Auction{
+			ID:             auctionID,
+			PoolID:         poolID,
+			StartTime:      now,
+			Duration:       ac.bidWindow,
+			RevealDuration: ac.revealWindow,
+			State:          types.AuctionPending,
+			BlockNumber:    uint64(event.Task.TaskCreatedBlock),
+		}
+		if event.Task.Completed {
+			// First observed after it settled, so none of its transitions were seen
+			auction.State = types.AuctionComplete
+			auction.StateTimes = map[types.AuctionState]time.Time{types.AuctionComplete: now}
+		} else if err := auction.Transition(types.AuctionBiddingOpen, now); err != nil {
+			ac.logger.WithError(err).WithField("auction_id", auctionID).Warn("Failed to open auction for bidding")
+		}
+		ac.auctions[auctionID] = auction
+	}
+
+	ac.logger.WithFields(logrus.Fields{
+		"task_id":    taskIndex,
+		"auction_id": auctionID,
+		"pool_id":    poolID,
+		"deadline":   task.Deadline.Format(time.RFC3339),
+	}).Info("New auction task received")
+	return task
+}
+
+// GetPendingTasks returns tasks that have not been responded to yet
+func (ac *AuctionCoordinator) GetPendingTasks() ([]*types.Task, error) {
+	ac.mutex.RLock()
+	defer ac.mutex.RUnlock()
+
+	tasks := make([]*types.Task, 0, len(ac.tasks))
+	for _, task := range ac.tasks {
+		if !task.Completed && !task.Cancelled {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// NewTasks delivers tasks as the subscription observes them
+func (ac *AuctionCoordinator) NewTasks() <-chan *types.Task {
+	return ac.newTasks
+}
+
+// Subscribed reports whether new tasks are currently streamed over the websocket
+// subscription. Pending tasks must be polled for while it is down.
+func (ac *AuctionCoordinator) Subscribed() bool {
+	return ac.subscribed.Load()
+}
+
+// GetAuction returns a snapshot of the auction with the given ID, advanced past
+// the bidding windows that have closed
+func (ac *AuctionCoordinator) GetAuction(auctionID string) (*types.Auction, error) {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+
+	auction, exists := ac.auctions[auctionID]
+	if !exists {
+		return nil, fmt.Errorf("unknown auction %s", auctionID)
+	}
+	ac.advanceAuction(auction, ac.now())
+
+	snapshot := *auction
+	snapshot.StateTimes = make(map[types.AuctionState]time.Time, len(auction.StateTimes))
+	for state, at := range auction.StateTimes {
+		snapshot.StateTimes[state] = at
+	}
+	snapshot.BidRoots = append([]string(nil), auction.BidRoots...)
+	return &snapshot, nil
+}
+
+// AddBid records a sealed bid for an auction, as placeBid places it among the
+// auction's bids. Commitments are only taken while bidding is open, and reveals
+// until the reveal window closes.
+func (ac *AuctionCoordinator) AddBid(auctionID string, bid types.Bid) error {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+
+	auction, exists := ac.auctions[auctionID]
+	if !exists {
+		return fmt.Errorf("unknown auction %s", auctionID)
+	}
+	ac.advanceAuction(auction, ac.now())
+	switch {
+	case bid.Revealed && !auction.AcceptsReveals():
+		return fmt.Errorf("auction %s is %s: %w", auctionID, auction.State, ErrRevealClosed)
+	case !bid.Revealed && !auction.AcceptsBids():
+		return fmt.Errorf("auction %s is %s: %w", auctionID, auction.State, ErrBiddingClosed)
+	}
+
+	bids, err := placeBid(ac.bids[auctionID], bid)
+	if err != nil {
+		return fmt.Errorf("bid of %s in auction %s: %w", bid.Bidder, auctionID, err)
+	}
+	ac.bids[auctionID] = bids
+	return nil
+}
+
+// placeBid returns bids with bid placed among them. A bid from a bidder that
+// already committed replaces their earlier bid, so reveals update the commitment
+// they open, unless that bid was revealed already. A reveal must open the
+// commitment the bidder made, and one under a bid root never replaces a bid the
+// bidder committed directly.
+func placeBid(bids []types.Bid, bid types.Bid) ([]types.Bid, error) {
+	for i := range bids {
+		if !strings.EqualFold(bids[i].Bidder, bid.Bidder) {
+			continue
+		}
+		switch {
+		case bids[i].Revealed:
+			return nil, ErrBidAlreadyRevealed
+		case bid.Revealed && bid.MerkleRoot != "" && bids[i].MerkleRoot == "":
+			return nil, ErrBidCommittedDirectly
+		case bid.Revealed && !strings.EqualFold(bid.Commitment, bids[i].Commitment):
+			return nil, ErrCommitmentMismatch
+		}
+		bids[i] = bid
+		return bids, nil
+	}
+	return append(bids, bid), nil
+}
+
+// placeRootBids returns bids with the sealed bids of a bid root's leaves added,
+// refusing the root if any of their bidders already has a bid in the auction
+func placeRootBids(bids []types.Bid, leaves []types.Bid) ([]types.Bid, error) {
+	for _, leaf := range leaves {
+		if _, exists := findBid(bids, leaf.Bidder); exists {
+			return nil, fmt.Errorf("bid of %s: %w", leaf.Bidder, ErrBidderAlreadyCommitted)
+		}
+	}
+	return append(bids, leaves...), nil
+}
+
+// AddBidRoot records a Merkle root of sealed bid commitments for an auction while
+// its bidding is open, along with the sealed bid of each of its leaves, which
+// their reveals must open. Committing a root again has no effect.
+func (ac *AuctionCoordinator) AddBidRoot(auctionID string, root string, leaves []types.Bid) error {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+
+	auction, exists := ac.auctions[auctionID]
+	if !exists {
+		return fmt.Errorf("unknown auction %s", auctionID)
+	}
+	ac.advanceAuction(auction, ac.now())
+	if !auction.AcceptsBids() {
+		return fmt.Errorf("auction %s is %s: %w", auctionID, auction.State, ErrBiddingClosed)
+	}
+
+	for _, existing := range auction.BidRoots {
+		if strings.EqualFold(existing, root) {
+			return nil
+		}
+	}
+	bids, err := placeRootBids(ac.bids[auctionID], leaves)
+	if err != nil {
+		return fmt.Errorf("bid root %s of auction %s: %w", root, auctionID, err)
+	}
+	ac.bids[auctionID] = bids
+	auction.BidRoots = append(auction.BidRoots, root)
+	return nil
+}
+
+// CancelAuction cancels an auction that can no longer settle, such as one whose
+// block was reorged out, and marks its tasks cancelled so the operator abstains
+// from them. Cancelling a cancelled auction has no effect; a complete auction
+// can't be cancelled.
+func (ac *AuctionCoordinator) CancelAuction(auctionID, reason string) error {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+
+	auction, exists := ac.auctions[auctionID]
+	if !exists {
+		return fmt.Errorf("unknown auction %s", auctionID)
+	}
+	if auction.State == types.AuctionCancelled {
+		return nil
+	}
+	from := auction.State
+	if err := auction.Transition(types.AuctionCancelled, ac.now()); err != nil {
+		return fmt.Errorf("auction %s: %w", auctionID, err)
+	}
+
+	var cancelled []uint32
+	for _, task := range ac.tasks {
+		if task.AuctionID == auctionID {
+			task.Cancelled = true
+			cancelled = append(cancelled, task.ID)
+		}
+	}
+
+	ac.logger.WithFields(logrus.Fields{
+		"auction_id": auctionID,
+		"from":       from,
+		"reason":     reason,
+		"tasks":      cancelled,
+	}).Warn("Auction cancelled")
+	return nil
+}
+
+// cancelTask cancels the auction of a tracked task
+func (ac *AuctionCoordinator) cancelTask(taskIndex uint32, reason string) error {
+	ac.mutex.RLock()
+	task, exists := ac.tasks[taskIndex]
+	var auctionID string
+	if exists {
+		auctionID = task.AuctionID
+	}
+	ac.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("unknown task %d", taskIndex)
+	}
+	return ac.CancelAuction(auctionID, reason)
+}
+
+// advanceAuctions moves every tracked auction past its closed bidding windows
+func (ac *AuctionCoordinator) advanceAuctions() {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+
+	now := ac.now()
+	for _, auction := range ac.auctions {
+		ac.advanceAuction(auction, now)
+	}
+}
+
+// advanceAuction moves an auction past the bidding windows closed by now. The
+// caller must hold ac.mutex.
+func (ac *AuctionCoordinator) advanceAuction(auction *types.Auction, now time.Time) {
+	from := auction.State
+	if auction.Advance(now) {
+		ac.logger.WithFields(logrus.Fields{
+			"auction_id": auction.ID,
+			"from":       from,
+			"to":         auction.State,
+		}).Info("Auction bidding window closed")
+	}
+}
+
+// GetBids returns the sealed bids committed for an auction
+func (ac *AuctionCoordinator) GetBids(auctionID string) ([]types.Bid, error) {
+	ac.mutex.RLock()
+	defer ac.mutex.RUnlock()
+
+	if _, exists := ac.auctions[auctionID]; !exists {
+		return nil, fmt.Errorf("unknown auction %s", auctionID)
+	}
+	return append([]types.Bid(nil), ac.bids[auctionID]...), nil
+}
+
+// CurrentBlock returns the latest block seen by the task scanner
+func (ac *AuctionCoordinator) CurrentBlock() (uint64, error) {
+	ac.mutex.RLock()
+	defer ac.mutex.RUnlock()
+
+	if ac.lastBlock == 0 {
+		return 0, fmt.Errorf("chain head not yet known")
+	}
+	return ac.lastBlock, nil
+}
+
+// ChainTime returns the timestamp of the latest block seen by the task scanner
+func (ac *AuctionCoordinator) ChainTime() (time.Time, error) {
+	ac.mutex.RLock()
+	defer ac.mutex.RUnlock()
+
+	if ac.lastBlockTime.IsZero() {
+		return time.Time{}, fmt.Errorf("chain head not yet known")
+	}
+	return ac.lastBlockTime, nil
+}
+
+// taskResponsePayload is the task response body the aggregator accepts
+type taskResponsePayload struct {
+	ReferenceTaskIndex uint32         `json:"referenceTaskIndex"`
+	Winner             common.Address `json:"winner"`
+	WinningBid         *big.Int       `json:"winningBid"`
+	TotalBids          uint32         `json:"totalBids"`
+	BlsSignature       *bls.Signature `json:"blsSignature"`
+	IdempotencyKey     string         `json:"idempotencyKey"`
+	Confidence         float64        `json:"confidence"`
+}
+
+// SignResponse BLS-signs the operator's response for a task over the digest the
+// aggregator verifies and aggregates
+func (ac *AuctionCoordinator) SignResponse(taskID uint32, response *types.TaskResponse) (*bls.Signature, error) {
+	digest, err := types.TaskResponseDigest(taskID, common.HexToAddress(response.Winner), response.WinningBid, 0)
+	if err != nil {
+		return nil, fmt.Errorf("task %d response: %w", taskID, err)
+	}
+	return ac.blsKeyPair.SignMessage(digest), nil
+}
+
+// SubmitTaskResponse sends the operator's response for a task to the aggregator,
+// failing over to the next configured aggregator when one is down
+func (ac *AuctionCoordinator) SubmitTaskResponse(taskID uint32, response *types.TaskResponse) error {
+	blsSignature, err := ac.SignResponse(taskID, response)
+	if err != nil {
+		return err
+	}
+	payload := taskResponsePayload{
+		ReferenceTaskIndex: taskID,
+		Winner:             common.HexToAddress(response.Winner),
+		WinningBid:         response.WinningBid,
+		BlsSignature:       blsSignature,
+		// Every attempt carries the same key so the aggregator ignores re-sends
+		IdempotencyKey: types.TaskResponseIdempotencyKey(ac.operatorID, taskID),
+		Confidence:     response.Confidence,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// The aggregator only accepts responses signed by a registered operator
+	signature, err := SignRequestBody(ac.privateKey, body)
+	if err != nil {
+		return err
+	}
+
+	if err := ac.submitToAggregators(taskID, body, signature); err != nil {
+		return err
+	}
+
+	ac.mutex.Lock()
+	if task, exists := ac.tasks[taskID]; exists {
+		task.Completed = true
+		task.Responses = append(task.Responses, *response)
+	}
+	ac.mutex.Unlock()
+
+	ac.saveCursor()
+	return nil
+}
@@ -0,0 +1,91 @@
+package operator
+
+import (
+	"math/big"
+	"sync"
+	"time"
+)
+
+// defaultDuplicateAuctionWindow is how long a validated auction result is reused
+const defaultDuplicateAuctionWindow = 5 * time.Minute
+
+// auctionKey identifies an auction by the pool and block it was created for
+type auctionKey struct {
+	poolID      string
+	blockNumber uint64
+}
+
+// auctionEntry holds the shared validation result for an auction
+type auctionEntry struct {
+	done       chan struct{}
+	winner     string
+	winningBid *big.Int
+	err        error
+	createdAt  time.Time
+}
+
+// auctionDeduplicator ensures each (pool, block) auction is validated once and
+// the result is shared by every task that references it
+type auctionDeduplicator struct {
+	window  time.Duration
+	entries map[auctionKey]*auctionEntry
+	mutex   sync.Mutex
+}
+
+// newAuctionDeduplicator creates a deduplicator that remembers results for window
+func newAuctionDeduplicator(window time.Duration) *auctionDeduplicator {
+	if window <= 0 {
+		window = defaultDuplicateAuctionWindow
+	}
+
+	return &auctionDeduplicator{
+		window:  window,
+		entries: make(map[auctionKey]*auctionEntry),
+	}
+}
+
+// Do returns the cached result for key, running validate only if no result exists.
+// Concurrent callers for the same key wait for the first validation to finish.
+// Failed validations are not cached so that later tasks may retry.
+func (d *auctionDeduplicator) Do(key auctionKey, validate func() (string, *big.Int, error)) (string, *big.Int, error) {
+	d.mutex.Lock()
+	d.evictExpired()
+
+	if entry, exists := d.entries[key]; exists {
+		d.mutex.Unlock()
+		<-entry.done
+		return entry.winner, entry.winningBid, entry.err
+	}
+
+	entry := &auctionEntry{
+		done:      make(chan struct{}),
+		createdAt: time.Now(),
+	}
+	d.entries[key] = entry
+	d.mutex.Unlock()
+
+	entry.winner, entry.winningBid, entry.err = validate()
+	close(entry.done)
+
+	if entry.err != nil {
+		d.mutex.Lock()
+		delete(d.entries, key)
+		d.mutex.Unlock()
+	}
+
+	return entry.winner, entry.winningBid, entry.err
+}
+
+// evictExpired drops results older than the dedup window. Callers must hold the mutex.
+func (d *auctionDeduplicator) evictExpired() {
+	cutoff := time.Now().Add(-d.window)
+	for key, entry := range d.entries {
+		select {
+		case <-entry.done:
+			if entry.createdAt.Before(cutoff) {
+				delete(d.entries, key)
+			}
+		default:
+		}
+	}
+}
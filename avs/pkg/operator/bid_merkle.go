@@ -0,0 +1,139 @@
+package operator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+var (
+	// ErrInvalidMerkleProof is returned for a bid whose proof does not lead from its
+	// commitment to the root it claims
+	ErrInvalidMerkleProof = errors.New("invalid merkle proof")
+	// ErrUnknownBidRoot is returned for a bid revealed under a root never committed
+	// to its auction
+	ErrUnknownBidRoot = errors.New("bid root not committed to auction")
+	// ErrBidCommittedDirectly is returned for a bid revealed under a root by a
+	// bidder who committed a bid of their own, which the reveal must not replace
+	ErrBidCommittedDirectly = errors.New("bidder committed a bid directly")
+	// ErrBidderAlreadyCommitted is returned for a bid root naming a bidder who
+	// already has a bid in its auction
+	ErrBidderAlreadyCommitted = errors.New("bidder already committed a bid")
+)
+
+// hashMerklePair hashes two sibling nodes in ascending order, as OpenZeppelin's
+// MerkleProof does, so proofs need no left or right markers
+func hashMerklePair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a.Bytes(), b.Bytes()) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a.Bytes(), b.Bytes())
+}
+
+// merkleLevels returns the levels of the tree over leaves, from the leaves up to
+// the root. The last node of an odd level is carried up unhashed.
+func merkleLevels(leaves []common.Hash) [][]common.Hash {
+	levels := [][]common.Hash{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([]common.Hash, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, hashMerklePair(level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// BidMerkleRoot returns the root of the tree whose leaves are the BidCommitments
+// of sealed bids, so an auction's bids can be committed as a single hash. The
+// root of no leaves is the zero hash.
+func BidMerkleRoot(leaves []common.Hash) common.Hash {
+	if len(leaves) == 0 {
+		return common.Hash{}
+	}
+	levels := merkleLevels(leaves)
+	return levels[len(levels)-1][0]
+}
+
+// BidMerkleProof returns the proof that the leaf at index is included in the
+// BidMerkleRoot of leaves: its sibling at every level, bottom up
+func BidMerkleProof(leaves []common.Hash, index int) ([]common.Hash, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf index %d out of range [0, %d)", index, len(leaves))
+	}
+
+	var proof []common.Hash
+	for _, level := range merkleLevels(leaves) {
+		if sibling := index ^ 1; sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyBidMerkleProof reports whether proof leads from leaf to root
+func VerifyBidMerkleProof(root, leaf common.Hash, proof []common.Hash) bool {
+	node := leaf
+	for _, sibling := range proof {
+		node = hashMerklePair(node, sibling)
+	}
+	return node == root
+}
+
+// verifyBidInclusion checks that a bid revealed under a Merkle root proves its
+// commitment is included in a root committed to the auction. Bids committed
+// individually have nothing to prove.
+func verifyBidInclusion(auction *types.Auction, bid types.Bid) error {
+	if bid.MerkleRoot == "" {
+		return nil
+	}
+	root, err := decodeHash32(bid.MerkleRoot)
+	if err != nil {
+		return fmt.Errorf("invalid bid root %q", bid.MerkleRoot)
+	}
+	committed := false
+	for _, auctionRoot := range auction.BidRoots {
+		if decoded, err := decodeHash32(auctionRoot); err == nil && decoded == root {
+			committed = true
+			break
+		}
+	}
+	if !committed {
+		return fmt.Errorf("%w: %s", ErrUnknownBidRoot, bid.MerkleRoot)
+	}
+
+	leaf, err := decodeHash32(bid.Commitment)
+	if err != nil {
+		return fmt.Errorf("invalid bid commitment %q", bid.Commitment)
+	}
+	proof := make([]common.Hash, len(bid.MerkleProof))
+	for i, node := range bid.MerkleProof {
+		if proof[i], err = decodeHash32(node); err != nil {
+			return fmt.Errorf("%w: node %d is not a 32 byte hash", ErrInvalidMerkleProof, i)
+		}
+	}
+	if !VerifyBidMerkleProof(root, leaf, proof) {
+		return ErrInvalidMerkleProof
+	}
+	return nil
+}
+
+// decodeHash32 decodes a 0x prefixed hex encoded 32 byte hash
+func decodeHash32(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%q is not a 32 byte hash", s)
+	}
+	return common.BytesToHash(b), nil
+}
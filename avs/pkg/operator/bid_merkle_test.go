@@ -0,0 +1,160 @@
+package operator
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestBidMerkleProofsVerifyEveryLeaf(t *testing.T) {
+	for size := 1; size <= 7; size++ {
+		leaves := make([]common.Hash, size)
+		for i := range leaves {
+			leaves[i] = BidCommitment(big.NewInt(int64(100*(i+1))), common.BigToHash(big.NewInt(int64(i))), common.HexToAddress("0xb1"))
+		}
+		root := BidMerkleRoot(leaves)
+
+		for i, leaf := range leaves {
+			proof, err := BidMerkleProof(leaves, i)
+			if err != nil {
+				t.Fatalf("%d leaves: BidMerkleProof(%d): %v", size, i, err)
+			}
+			if !VerifyBidMerkleProof(root, leaf, proof) {
+				t.Fatalf("%d leaves: proof of leaf %d does not verify", size, i)
+			}
+			if size > 1 && VerifyBidMerkleProof(root, common.HexToHash("0x01"), proof) {
+				t.Fatalf("%d leaves: proof of leaf %d verifies a different leaf", size, i)
+			}
+		}
+	}
+	if _, err := BidMerkleProof([]common.Hash{{}}, 1); err == nil {
+		t.Fatal("expected a proof of a leaf out of range to fail")
+	}
+}
+
+func TestRevealBidUnderBidRoot(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["batched"] = &types.Auction{ID: "batched", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.bids["batched"] = []types.Bid{}
+
+	op := newTestOperator(t, coord)
+	server := httptest.NewServer(op.priceHandler())
+	defer server.Close()
+
+	// Four bidders' commitments are committed as one root
+	amounts := []int64{300, 500, 200, 400}
+	keys := make([]*ecdsa.PrivateKey, len(amounts))
+	bidders := make([]common.Address, len(amounts))
+	salts := make([]common.Hash, len(amounts))
+	leaves := make([]common.Hash, len(amounts))
+	signed := make([]bidRootLeaf, len(amounts))
+	for i, amount := range amounts {
+		keys[i], _ = newTestBidder(t)
+		bidders[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		salts[i] = common.BigToHash(big.NewInt(int64(i + 1)))
+		leaves[i] = BidCommitment(big.NewInt(amount), salts[i], bidders[i])
+		commit := signedCommit(t, keys[i], "batched", leaves[i])
+		signed[i] = bidRootLeaf{Bidder: commit.Bidder, Commitment: commit.Commitment, Signature: commit.Signature}
+	}
+	root := BidMerkleRoot(leaves)
+
+	// A root is refused without every leaf signed by its bidder, when its leaves
+	// build a different root, or when a bidder has more than one leaf in it
+	unsigned := append([]bidRootLeaf(nil), signed...)
+	unsigned[2].Signature = signed[0].Signature
+	hedged := BidCommitment(big.NewInt(1000), common.HexToHash("0x77"), bidders[0])
+	hedgedCommit := signedCommit(t, keys[0], "batched", hedged)
+	duplicated := append(append([]bidRootLeaf(nil), signed...), bidRootLeaf{Bidder: hedgedCommit.Bidder, Commitment: hedgedCommit.Commitment, Signature: hedgedCommit.Signature})
+	duplicatedLeaves := append(append([]common.Hash(nil), leaves...), hedged)
+	rejected := []struct {
+		name   string
+		root   common.Hash
+		leaves []bidRootLeaf
+		want   int
+	}{
+		{"without leaves", root, nil, http.StatusBadRequest},
+		{"with a leaf signed by another bidder", root, unsigned, http.StatusUnauthorized},
+		{"not built from its leaves", BidMerkleRoot(leaves[:3]), signed, http.StatusBadRequest},
+		{"with two leaves of one bidder", BidMerkleRoot(duplicatedLeaves), duplicated, http.StatusBadRequest},
+	}
+	for _, tc := range rejected {
+		req := bidRootRequest{AuctionID: "batched", Root: tc.root.Hex(), Leaves: tc.leaves}
+		if status := postBidJSON(t, server.URL+"/bids/root", req); status != tc.want {
+			t.Fatalf("POST /bids/root %s = %d, want %d", tc.name, status, tc.want)
+		}
+	}
+	if status := postBidJSON(t, server.URL+"/bids/root", bidRootRequest{AuctionID: "batched", Root: root.Hex(), Leaves: signed}); status != http.StatusCreated {
+		t.Fatalf("POST /bids/root = %d, want 201", status)
+	}
+	// A second root can't commit another bid for a bidder of the first
+	hedgedRoot := bidRootRequest{AuctionID: "batched", Root: BidMerkleRoot([]common.Hash{hedged}).Hex(), Leaves: duplicated[len(duplicated)-1:]}
+	if status := postBidJSON(t, server.URL+"/bids/root", hedgedRoot); status != http.StatusConflict {
+		t.Fatalf("POST /bids/root for an already committed bidder = %d, want 409", status)
+	}
+	// Bidder 2 also commits a bid of their own
+	direct := BidCommitment(big.NewInt(900), common.HexToHash("0x99"), bidders[2])
+	if status := postBidJSON(t, server.URL+"/bids", signedCommit(t, keys[2], "batched", direct)); status != http.StatusCreated {
+		t.Fatalf("POST /bids = %d, want 201", status)
+	}
+
+	reveal := func(i int, proof []common.Hash) int {
+		encoded := make([]string, len(proof))
+		for j, node := range proof {
+			encoded[j] = node.Hex()
+		}
+		req := signedReveal(t, keys[i], "batched", amounts[i], salts[i], "")
+		req.MerkleRoot = root.Hex()
+		req.MerkleProof = encoded
+		return postBidJSON(t, server.URL+"/bids/reveal", req)
+	}
+
+	proof, err := BidMerkleProof(leaves, 1)
+	if err != nil {
+		t.Fatalf("BidMerkleProof: %v", err)
+	}
+	if status := reveal(1, proof); status != http.StatusOK {
+		t.Fatalf("revealing with a valid proof = %d, want 200", status)
+	}
+	// A revealed bid can't be revealed again, with any amount
+	if status := reveal(1, proof); status != http.StatusConflict {
+		t.Fatalf("revealing a revealed bid again = %d, want 409", status)
+	}
+	// Bidder 3 presents bidder 1's proof, which does not lead from their commitment
+	if status := reveal(3, proof); status != http.StatusBadRequest {
+		t.Fatalf("revealing with an invalid proof = %d, want 400", status)
+	}
+	// Bidder 2's root reveal must not replace the bid they committed directly
+	proof, err = BidMerkleProof(leaves, 2)
+	if err != nil {
+		t.Fatalf("BidMerkleProof: %v", err)
+	}
+	if status := reveal(2, proof); status != http.StatusConflict {
+		t.Fatalf("revealing under a root over a direct commitment = %d, want 409", status)
+	}
+
+	auction, _ := coord.GetAuction("batched")
+	bids, _ := coord.GetBids("batched")
+	if len(bids) != 4 || bids[2].Commitment != direct.Hex() || bids[2].MerkleRoot != "" {
+		t.Fatalf("bids = %+v, want the root's leaves with bidder 2's replaced by their direct commitment", bids)
+	}
+	if bids[0].Revealed || bids[3].Revealed || !bids[1].Revealed || bids[1].Bidder != bidders[1].Hex() || bids[1].MerkleRoot != root.Hex() {
+		t.Fatalf("bids = %+v, want only bidder 1's leaf revealed", bids)
+	}
+	bids = bids[1:2]
+	if err := verifyRevealIn(auction, bids[0]); err != nil {
+		t.Fatalf("verifyRevealIn: %v", err)
+	}
+
+	// A proven bid is rejected at validation without its root committed
+	if err := verifyRevealIn(&types.Auction{ID: "batched"}, bids[0]); !errors.Is(err, ErrUnknownBidRoot) {
+		t.Fatalf("verifyRevealIn without the root = %v, want ErrUnknownBidRoot", err)
+	}
+}
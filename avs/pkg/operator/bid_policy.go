@@ -0,0 +1,64 @@
+package operator
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// Reasons a revealed bid is excluded from winning
+const (
+	bidExclusionSelf      = "self"
+	bidExclusionBlocklist = "blocklist"
+)
+
+// bidExclusion returns why bidder may not win an auction this operator attests
+// to, or "" if it may. The operator can never select itself, nor an address on
+// the configured blocklist.
+func (o *Operator) bidExclusion(bidder common.Address) string {
+	if bidder == o.address {
+		return bidExclusionSelf
+	}
+	for _, blocked := range o.config.BidPolicy.Blocklist {
+		if bidder == common.HexToAddress(blocked) {
+			return bidExclusionBlocklist
+		}
+	}
+	return ""
+}
+
+// eligibleBids drops the revealed bids of bidders excluded by the bid policy.
+// Self-bids are flagged as suspicious, since they suggest the operator's key is
+// being used to steer auctions to itself.
+func (o *Operator) eligibleBids(auction *types.Auction, bids []types.Bid) []types.Bid {
+	eligible := make([]types.Bid, 0, len(bids))
+	for _, bid := range bids {
+		reason := o.bidExclusion(common.HexToAddress(bid.Bidder))
+		if reason == "" {
+			eligible = append(eligible, bid)
+			continue
+		}
+
+		o.metricsMux.Lock()
+		if reason == bidExclusionSelf {
+			o.selfBids++
+		} else {
+			o.blocklistedBids++
+		}
+		o.metricsMux.Unlock()
+
+		entry := o.logger.WithFields(logrus.Fields{
+			"auction_id": auction.ID,
+			"bidder":     bid.Bidder,
+			"bid":        bid.Amount.String(),
+			"reason":     reason,
+		})
+		if reason == bidExclusionSelf {
+			entry.WithField("suspicious", true).Error("Excluding suspicious bid from the operator's own address")
+		} else {
+			entry.Warn("Excluding bid from blocklisted address")
+		}
+	}
+	return eligible
+}
@@ -0,0 +1,50 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestValidateAuctionExcludesSelfAndBlocklistedBids(t *testing.T) {
+	const colluder = "0x00000000000000000000000000000000000000c0"
+	coord := newFakeCoordinator()
+	auction := &types.Auction{ID: "auction-1", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.auctions[auction.ID] = auction
+
+	op := newTestOperator(t, coord)
+	op.address = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	op.config.BidPolicy.Blocklist = []string{colluder}
+
+	// The operator's own address places the top bid, a blocklisted address the second
+	coord.bids[auction.ID] = []types.Bid{
+		newRevealedBid(op.address.Hex(), 900, common.HexToHash("0x01")),
+		newRevealedBid(colluder, 800, common.HexToHash("0x02")),
+		newRevealedBid(testBidder, 300, common.HexToHash("0x03")),
+	}
+
+	winner, winningBid, err := op.validateAuction(auction)
+	if err != nil {
+		t.Fatalf("validateAuction: %v", err)
+	}
+	if winner != testBidder || winningBid.Int64() != 300 {
+		t.Fatalf("winner = %s with %s, want %s with 300", winner, winningBid, testBidder)
+	}
+
+	excluded := op.GetMetrics()["bids_excluded"].(map[string]uint64)
+	if excluded[bidExclusionSelf] != 1 || excluded[bidExclusionBlocklist] != 1 {
+		t.Fatalf("bids_excluded = %v, want one self-bid and one blocklisted bid", excluded)
+	}
+
+	// With only the self-bid left the auction has no winner rather than the operator
+	coord.bids[auction.ID] = coord.bids[auction.ID][:1]
+	winner, winningBid, err = op.validateAuction(auction)
+	if err != nil {
+		t.Fatalf("validateAuction: %v", err)
+	}
+	if winner != "" || winningBid.Sign() != 0 {
+		t.Fatalf("winner = %s with %s, want no winner", winner, winningBid)
+	}
+}
@@ -0,0 +1,371 @@
+package operator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// maxBidRequestBytes bounds the body of bid commit and reveal requests
+const maxBidRequestBytes = 64 << 10
+
+// bidCommitRequest is the body of POST /bids. Signature is the bidder's
+// SignRequestBody signature over BidCommitMessage.
+type bidCommitRequest struct {
+	AuctionID  string `json:"auction_id"`
+	Bidder     string `json:"bidder"`
+	Commitment string `json:"commitment"`
+	Signature  string `json:"signature"`
+}
+
+// bidRootRequest is the body of POST /bids/root: a Merkle root and the leaves it
+// is built from, each signed by the bidder it names
+type bidRootRequest struct {
+	AuctionID string        `json:"auction_id"`
+	Root      string        `json:"root"`
+	Leaves    []bidRootLeaf `json:"leaves,omitempty"`
+}
+
+// bidRootLeaf is a sealed bid committed through a bid root. Signature is the
+// bidder's SignRequestBody signature over BidCommitMessage.
+type bidRootLeaf struct {
+	Bidder     string `json:"bidder"`
+	Commitment string `json:"commitment"`
+	Signature  string `json:"signature"`
+}
+
+// bidRevealRequest is the body of POST /bids/reveal. A bid committed through a
+// bid root is revealed with the root and the proof of its commitment's inclusion.
+// Signature is the bidder's SignRequestBody signature over BidRevealMessage.
+type bidRevealRequest struct {
+	AuctionID      string   `json:"auction_id"`
+	Bidder         string   `json:"bidder"`
+	Amount         *big.Int `json:"amount"`
+	Salt           string   `json:"salt"`
+	SettlementData string   `json:"settlement_data"`
+	MerkleRoot     string   `json:"merkle_root"`
+	MerkleProof    []string `json:"merkle_proof"`
+	Signature      string   `json:"signature"`
+}
+
+// handleCommitBid records a sealed bid commitment for an open auction, signed by
+// its bidder. A bidder committing again before revealing replaces their
+// commitment.
+func (o *Operator) handleCommitBid(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req bidCommitRequest
+	if err := decodeBidRequest(w, r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !common.IsHexAddress(req.Bidder) {
+		http.Error(w, fmt.Sprintf("invalid bidder address %q", req.Bidder), http.StatusBadRequest)
+		return
+	}
+	commitment, err := decodeHash32(req.Commitment)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid bid commitment %q", req.Commitment), http.StatusBadRequest)
+		return
+	}
+	if err := verifyBidder(BidCommitMessage(req.AuctionID, commitment), req.Signature, req.Bidder); err != nil {
+		o.logger.WithError(err).WithField("auction_id", req.AuctionID).Warn("Rejecting unsigned bid commitment")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !o.auctionAccepts(w, req.AuctionID, (*types.Auction).AcceptsBids) {
+		return
+	}
+
+	bids, err := o.auctionCoord.GetBids(req.AuctionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if existing, ok := findBid(bids, req.Bidder); ok && existing.Revealed {
+		http.Error(w, ErrBidAlreadyRevealed.Error(), http.StatusConflict)
+		return
+	}
+
+	bid := types.Bid{
+		Bidder:     common.HexToAddress(req.Bidder).Hex(),
+		Commitment: strings.ToLower(req.Commitment),
+		Timestamp:  time.Now(),
+	}
+	if err := o.auctionCoord.AddBid(req.AuctionID, bid); err != nil {
+		writeAddBidError(w, err)
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"auction_id": req.AuctionID,
+		"bidder":     bid.Bidder,
+		"commitment": bid.Commitment,
+	}).Info("Sealed bid committed")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bid)
+}
+
+// handleCommitBidRoot records a Merkle root of sealed bid commitments for an open
+// auction, committing all of its bids at once. The root is only taken with the
+// leaves it is built from, one for each bidder and signed by the bidder it names,
+// and each leaf is recorded as its bidder's sealed bid.
+func (o *Operator) handleCommitBidRoot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req bidRootRequest
+	if err := decodeBidRequest(w, r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	root, err := decodeHash32(req.Root)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid bid root %q", req.Root), http.StatusBadRequest)
+		return
+	}
+	leaves, err := verifyRootLeaves(req)
+	if err != nil {
+		o.logger.WithError(err).WithField("auction_id", req.AuctionID).Warn("Rejecting bid root")
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrBidderSignature) {
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if BidMerkleRoot(leaves) != root {
+		http.Error(w, "bid root does not match its leaves", http.StatusBadRequest)
+		return
+	}
+	if !o.auctionAccepts(w, req.AuctionID, (*types.Auction).AcceptsBids) {
+		return
+	}
+
+	encoded := strings.ToLower(req.Root)
+	sealed := make([]types.Bid, len(req.Leaves))
+	for i, leaf := range req.Leaves {
+		sealed[i] = types.Bid{
+			Bidder:     common.HexToAddress(leaf.Bidder).Hex(),
+			Commitment: strings.ToLower(leaves[i].Hex()),
+			Timestamp:  time.Now(),
+			MerkleRoot: encoded,
+		}
+	}
+	if err := o.auctionCoord.AddBidRoot(req.AuctionID, encoded, sealed); err != nil {
+		writeAddBidError(w, err)
+		return
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"auction_id": req.AuctionID,
+		"root":       encoded,
+		"leaves":     len(leaves),
+	}).Info("Bid root committed")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bidRootRequest{AuctionID: req.AuctionID, Root: encoded})
+}
+
+// verifyRootLeaves checks that every leaf of a bid root is signed by the bidder
+// it names and that no bidder has more than one, and returns their commitments
+// in order
+func verifyRootLeaves(req bidRootRequest) ([]common.Hash, error) {
+	if len(req.Leaves) == 0 {
+		return nil, errors.New("bid root has no leaves")
+	}
+	leaves := make([]common.Hash, len(req.Leaves))
+	bidders := make(map[common.Address]bool, len(req.Leaves))
+	for i, leaf := range req.Leaves {
+		commitment, err := decodeHash32(leaf.Commitment)
+		if err != nil {
+			return nil, fmt.Errorf("leaf %d: invalid bid commitment %q", i, leaf.Commitment)
+		}
+		if err := verifyBidder(BidCommitMessage(req.AuctionID, commitment), leaf.Signature, leaf.Bidder); err != nil {
+			return nil, fmt.Errorf("leaf %d: %w", i, err)
+		}
+		bidder := common.HexToAddress(leaf.Bidder)
+		if bidders[bidder] {
+			return nil, fmt.Errorf("leaf %d: bidder %s has more than one leaf", i, bidder.Hex())
+		}
+		bidders[bidder] = true
+		leaves[i] = commitment
+	}
+	return leaves, nil
+}
+
+// handleRevealBid opens a bidder's commitment with its amount and salt, once.
+// Reveals not signed by the bidder, or that don't hash to the commitment, are
+// rejected and leave the bid sealed. A bid committed through a bid root is
+// revealed under that root, with the proof its commitment is included in it.
+func (o *Operator) handleRevealBid(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req bidRevealRequest
+	if err := decodeBidRequest(w, r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !o.auctionAccepts(w, req.AuctionID, (*types.Auction).AcceptsReveals) {
+		return
+	}
+
+	auction, err := o.auctionCoord.GetAuction(req.AuctionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	bids, err := o.auctionCoord.GetBids(req.AuctionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	bid, ok := findBid(bids, req.Bidder)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no bid committed by %s", req.Bidder), http.StatusNotFound)
+		return
+	}
+	if bid.Revealed {
+		http.Error(w, ErrBidAlreadyRevealed.Error(), http.StatusConflict)
+		return
+	}
+	if req.MerkleRoot != "" || bid.MerkleRoot != "" {
+		if bid.MerkleRoot == "" {
+			http.Error(w, ErrBidCommittedDirectly.Error(), http.StatusConflict)
+			return
+		}
+		if !strings.EqualFold(req.MerkleRoot, bid.MerkleRoot) {
+			http.Error(w, fmt.Sprintf("bid of %s was committed under bid root %s", bid.Bidder, bid.MerkleRoot), http.StatusBadRequest)
+			return
+		}
+		bid.MerkleProof = req.MerkleProof
+	}
+
+	commitment, err := decodeHash32(bid.Commitment)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid bid commitment %q", bid.Commitment), http.StatusBadRequest)
+		return
+	}
+	if err := verifyBidder(BidRevealMessage(req.AuctionID, commitment, req.SettlementData), req.Signature, bid.Bidder); err != nil {
+		o.logger.WithError(err).WithField("auction_id", req.AuctionID).Warn("Rejecting unsigned bid reveal")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	bid.Amount = req.Amount
+	bid.Salt = req.Salt
+	bid.SettlementData = req.SettlementData
+	bid.Revealed = true
+	if err := verifyRevealIn(auction, bid); err != nil {
+		o.logger.WithError(err).WithFields(logrus.Fields{
+			"auction_id": req.AuctionID,
+			"bidder":     bid.Bidder,
+		}).Warn("Rejecting bid reveal")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := o.auctionCoord.AddBid(req.AuctionID, bid); err != nil {
+		writeAddBidError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bid)
+}
+
+// handleListBids writes the bids committed for an auction and whether each was revealed
+func (o *Operator) handleListBids(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	auctionID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/bids/"), "/")
+	if auctionID == "" || strings.Contains(auctionID, "/") {
+		http.Error(w, "Expected /bids/{auctionId}", http.StatusBadRequest)
+		return
+	}
+	if _, err := o.auctionCoord.GetAuction(auctionID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	bids, err := o.auctionCoord.GetBids(auctionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bids)
+}
+
+// auctionAccepts reports whether the state of an auction accepts a bid request,
+// writing the error response if not
+func (o *Operator) auctionAccepts(w http.ResponseWriter, auctionID string, accepts func(*types.Auction) bool) bool {
+	auction, err := o.auctionCoord.GetAuction(auctionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return false
+	}
+	if !accepts(auction) {
+		http.Error(w, fmt.Sprintf("auction %s is %s", auctionID, auction.State), http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// writeAddBidError responds to a bid the coordinator refused to record. Bids
+// arriving once their window closed conflict with the auction's state, and root
+// reveals with the bidder's own commitment.
+func writeAddBidError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrBiddingClosed) || errors.Is(err, ErrRevealClosed) || errors.Is(err, ErrBidCommittedDirectly) ||
+		errors.Is(err, ErrBidAlreadyRevealed) || errors.Is(err, ErrBidderAlreadyCommitted) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusNotFound)
+}
+
+// decodeBidRequest decodes a bounded JSON body, rejecting unknown fields
+func decodeBidRequest(w http.ResponseWriter, r *http.Request, out interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBidRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("request body exceeds %d bytes", maxBidRequestBytes)
+		}
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
+// findBid returns the bid placed by bidder
+func findBid(bids []types.Bid, bidder string) (types.Bid, bool) {
+	for _, bid := range bids {
+		if strings.EqualFold(bid.Bidder, bidder) {
+			return bid, true
+		}
+	}
+	return types.Bid{}, false
+}
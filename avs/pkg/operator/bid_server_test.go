@@ -0,0 +1,247 @@
+package operator
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// postBidJSON posts body as JSON and returns the response status code
+func postBidJSON(t *testing.T, url string, body interface{}) int {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// newTestBidder generates a bidder's key and address
+func newTestBidder(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key, crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+// signBid signs a bid message as the bidder holding key
+func signBid(t *testing.T, key *ecdsa.PrivateKey, message []byte) string {
+	t.Helper()
+	signature, err := SignRequestBody(key, message)
+	if err != nil {
+		t.Fatalf("SignRequestBody: %v", err)
+	}
+	return signature
+}
+
+// signedCommit builds a commit request signed by the bidder holding key
+func signedCommit(t *testing.T, key *ecdsa.PrivateKey, auctionID string, commitment common.Hash) bidCommitRequest {
+	t.Helper()
+	return bidCommitRequest{
+		AuctionID:  auctionID,
+		Bidder:     crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		Commitment: commitment.Hex(),
+		Signature:  signBid(t, key, BidCommitMessage(auctionID, commitment)),
+	}
+}
+
+// signedReveal builds a reveal request signed by the bidder holding key
+func signedReveal(t *testing.T, key *ecdsa.PrivateKey, auctionID string, amount int64, salt common.Hash, settlementData string) bidRevealRequest {
+	t.Helper()
+	bidder := crypto.PubkeyToAddress(key.PublicKey)
+	commitment := BidCommitment(big.NewInt(amount), salt, bidder)
+	return bidRevealRequest{
+		AuctionID:      auctionID,
+		Bidder:         bidder.Hex(),
+		Amount:         big.NewInt(amount),
+		Salt:           salt.Hex(),
+		SettlementData: settlementData,
+		Signature:      signBid(t, key, BidRevealMessage(auctionID, commitment, settlementData)),
+	}
+}
+
+func TestBidCommitRevealList(t *testing.T) {
+	keyA, bidderA := newTestBidder(t)
+	keyB, bidderB := newTestBidder(t)
+	coord := newFakeCoordinator()
+	coord.auctions["auction-1"] = &types.Auction{ID: "auction-1", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.auctions["closed"] = &types.Auction{ID: "closed", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionComplete}
+	coord.bids["auction-1"] = []types.Bid{}
+
+	op := newTestOperator(t, coord)
+	server := httptest.NewServer(op.priceHandler())
+	defer server.Close()
+
+	saltA, saltB := common.HexToHash("0x0a"), common.HexToHash("0x0b")
+	commitA := BidCommitment(big.NewInt(500), saltA, common.HexToAddress(bidderA))
+	commitB := BidCommitment(big.NewInt(700), saltB, common.HexToAddress(bidderB))
+	for key, commitment := range map[*ecdsa.PrivateKey]common.Hash{keyA: commitA, keyB: commitB} {
+		if status := postBidJSON(t, server.URL+"/bids", signedCommit(t, key, "auction-1", commitment)); status != http.StatusCreated {
+			t.Fatalf("POST /bids for %s = %d, want 201", crypto.PubkeyToAddress(key.PublicKey).Hex(), status)
+		}
+	}
+
+	// B's commitment signed for another auction, and B impersonating A
+	replayed := signedCommit(t, keyB, "other", commitB)
+	replayed.AuctionID = "auction-1"
+	impersonated := signedCommit(t, keyB, "auction-1", commitB)
+	impersonated.Bidder = bidderA
+	unsignedReveal := signedReveal(t, keyA, "auction-1", 500, saltA, "0x")
+	unsignedReveal.Signature = ""
+	// The settlement data is not what A signed
+	tamperedReveal := signedReveal(t, keyA, "auction-1", 500, saltA, "0x")
+	tamperedReveal.SettlementData = "0xdead"
+
+	for name, tc := range map[string]struct {
+		path string
+		body interface{}
+		want int
+	}{
+		"unknown auction": {"/bids", signedCommit(t, keyA, "missing", commitA), http.StatusNotFound},
+		"closed auction":  {"/bids", signedCommit(t, keyA, "closed", commitA), http.StatusConflict},
+		"bad commitment":  {"/bids", bidCommitRequest{AuctionID: "auction-1", Bidder: bidderA, Commitment: "0x1234"}, http.StatusBadRequest},
+		"unknown field":   {"/bids", map[string]string{"auction_id": "auction-1", "amount": "1"}, http.StatusBadRequest},
+		"unsigned commit": {"/bids", bidCommitRequest{AuctionID: "auction-1", Bidder: bidderA, Commitment: commitA.Hex()}, http.StatusUnauthorized},
+		"replayed commit": {"/bids", replayed, http.StatusUnauthorized},
+		"impersonation":   {"/bids", impersonated, http.StatusUnauthorized},
+		"no commitment":   {"/bids/reveal", bidRevealRequest{AuctionID: "auction-1", Bidder: testBidder, Amount: big.NewInt(1), Salt: saltA.Hex()}, http.StatusNotFound},
+		"unsigned reveal": {"/bids/reveal", unsignedReveal, http.StatusUnauthorized},
+		"tampered reveal": {"/bids/reveal", tamperedReveal, http.StatusUnauthorized},
+		// B reveals a different amount than committed
+		"mismatched reveal": {"/bids/reveal", bidRevealRequest{AuctionID: "auction-1", Bidder: bidderB, Amount: big.NewInt(9000), Salt: saltB.Hex(), Signature: signBid(t, keyB, BidRevealMessage("auction-1", commitB, ""))}, http.StatusBadRequest},
+	} {
+		if status := postBidJSON(t, server.URL+tc.path, tc.body); status != tc.want {
+			t.Fatalf("%s: POST %s = %d, want %d", name, tc.path, status, tc.want)
+		}
+	}
+
+	if status := postBidJSON(t, server.URL+"/bids/reveal", signedReveal(t, keyA, "auction-1", 500, saltA, "0x")); status != http.StatusOK {
+		t.Fatalf("POST /bids/reveal = %d, want 200", status)
+	}
+	// A revealed bid can't be replaced by a new commitment
+	recommit := signedCommit(t, keyA, "auction-1", commitB)
+	if status := postBidJSON(t, server.URL+"/bids", recommit); status != http.StatusConflict {
+		t.Fatalf("recommitting a revealed bid = %d, want 409", status)
+	}
+
+	var bids []types.Bid
+	if status := getPriceJSON(t, server.URL+"/bids/auction-1", &bids); status != http.StatusOK || len(bids) != 2 {
+		t.Fatalf("GET /bids/auction-1 = %d with %d bids, want both bids", status, len(bids))
+	}
+	revealed := make(map[common.Address]types.Bid)
+	for _, bid := range bids {
+		revealed[common.HexToAddress(bid.Bidder)] = bid
+	}
+	if a := revealed[common.HexToAddress(bidderA)]; !a.Revealed || a.Amount.Int64() != 500 || a.Commitment != commitA.Hex() {
+		t.Fatalf("bid of A = %+v, want revealed at 500", a)
+	}
+	if b := revealed[common.HexToAddress(bidderB)]; b.Revealed || b.Amount != nil {
+		t.Fatalf("bid of B = %+v, want still sealed after the mismatched reveal", b)
+	}
+
+	// The revealed bid takes part in validation
+	winner, winningBid, err := op.validateAuction(coord.auctions["auction-1"])
+	if err != nil || common.HexToAddress(winner) != common.HexToAddress(bidderA) || winningBid.Int64() != 500 {
+		t.Fatalf("validateAuction = %s, %v, %v, want A winning with 500", winner, winningBid, err)
+	}
+
+	if status := getPriceJSON(t, server.URL+"/bids/missing", nil); status != http.StatusNotFound {
+		t.Fatalf("GET /bids/missing = %d, want 404", status)
+	}
+}
+
+func TestBidWindowsCloseOnTime(t *testing.T) {
+	key, bidder := newTestBidder(t)
+	start := time.Unix(1700000000, 0)
+	now := start
+	var clockMux sync.Mutex
+	ac := &AuctionCoordinator{
+		logger:       newTestLogger(),
+		tasks:        make(map[uint32]*types.Task),
+		auctions:     make(map[string]*types.Auction),
+		bids:         make(map[string][]types.Bid),
+		bidWindow:    60,
+		revealWindow: 30,
+		now: func() time.Time {
+			clockMux.Lock()
+			defer clockMux.Unlock()
+			return now
+		},
+	}
+	setNow := func(at time.Time) {
+		clockMux.Lock()
+		defer clockMux.Unlock()
+		now = at
+	}
+
+	var event newTaskCreatedEvent
+	event.Task.AuctionId = common.HexToHash("0xa1")
+	event.Task.PoolId = common.HexToHash(testPoolID)
+	event.Task.Deadline = big.NewInt(start.Add(time.Hour).Unix())
+	task := ac.trackTask(1, event)
+
+	op := newTestOperator(t, newFakeCoordinator())
+	op.auctionCoord = ac
+	server := httptest.NewServer(op.priceHandler())
+	defer server.Close()
+
+	salt := common.HexToHash("0x0a")
+	commitment := BidCommitment(big.NewInt(500), salt, common.HexToAddress(bidder))
+	commit := signedCommit(t, key, task.AuctionID, commitment)
+	reveal := signedReveal(t, key, task.AuctionID, 500, salt, "")
+
+	// Just before bidding closes a commitment is taken, at the close it isn't
+	setNow(start.Add(60*time.Second - time.Millisecond))
+	if status := postBidJSON(t, server.URL+"/bids", commit); status != http.StatusCreated {
+		t.Fatalf("commit just before the bidding window closed = %d, want 201", status)
+	}
+	setNow(start.Add(60 * time.Second))
+	if status := postBidJSON(t, server.URL+"/bids", commit); status != http.StatusConflict {
+		t.Fatalf("commit once the bidding window closed = %d, want 409", status)
+	}
+	auction, _ := ac.GetAuction(task.AuctionID)
+	if auction.State != types.AuctionRevealPhase || !auction.StateTimes[types.AuctionRevealPhase].Equal(start.Add(60*time.Second)) {
+		t.Fatalf("auction %s entered at %v, want the reveal phase from the bidding close", auction.State, auction.StateTimes)
+	}
+
+	// Reveals are taken until the reveal window closes
+	setNow(start.Add(90*time.Second - time.Millisecond))
+	if status := postBidJSON(t, server.URL+"/bids/reveal", reveal); status != http.StatusOK {
+		t.Fatalf("reveal just before the reveal window closed = %d, want 200", status)
+	}
+	setNow(start.Add(90 * time.Second))
+	if status := postBidJSON(t, server.URL+"/bids/reveal", reveal); status != http.StatusConflict {
+		t.Fatalf("reveal once the reveal window closed = %d, want 409", status)
+	}
+
+	// The closed reveal window moves the auction to settling, even without requests
+	setNow(start.Add(2 * time.Minute))
+	ac.advanceAuctions()
+	ac.mutex.RLock()
+	state := ac.auctions[task.AuctionID].State
+	ac.mutex.RUnlock()
+	if state != types.AuctionSettling {
+		t.Fatalf("auction is %s after the reveal window, want settling", state)
+	}
+	if bids, _ := ac.GetBids(task.AuctionID); len(bids) != 1 || !bids[0].Revealed {
+		t.Fatalf("bids = %+v, want the bid revealed within its window", bids)
+	}
+}
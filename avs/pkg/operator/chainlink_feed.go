@@ -0,0 +1,132 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// aggregatorV3ABI is the subset of Chainlink's AggregatorV3Interface read by the operator
+const aggregatorV3ABI = `[
+	{
+		"type": "function",
+		"name": "decimals",
+		"stateMutability": "view",
+		"inputs": [],
+		"outputs": [{"name": "", "type": "uint8"}]
+	},
+	{
+		"type": "function",
+		"name": "latestRoundData",
+		"stateMutability": "view",
+		"inputs": [],
+		"outputs": [
+			{"name": "roundId", "type": "uint80"},
+			{"name": "answer", "type": "int256"},
+			{"name": "startedAt", "type": "uint256"},
+			{"name": "updatedAt", "type": "uint256"},
+			{"name": "answeredInRound", "type": "uint80"}
+		]
+	}
+]`
+
+// contractCallTimeout bounds the contract calls reading a single on-chain price
+const contractCallTimeout = 10 * time.Second
+
+// chainlinkReader reads prices from Chainlink AggregatorV3Interface contracts
+type chainlinkReader struct {
+	caller      ethereum.ContractCaller
+	contractABI abi.ABI
+
+	// decimals caches each aggregator's decimals(), which never changes
+	decimals map[common.Address]uint8
+	mutex    sync.Mutex
+}
+
+func newChainlinkReader(caller ethereum.ContractCaller) (*chainlinkReader, error) {
+	contractABI, err := abi.JSON(strings.NewReader(aggregatorV3ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse aggregator ABI: %w", err)
+	}
+	return &chainlinkReader{
+		caller:      caller,
+		contractABI: contractABI,
+		decimals:    make(map[common.Address]uint8),
+	}, nil
+}
+
+// fetchPrice reads the latest answer of the pair's aggregator, scaled to
+// normalizedPriceDecimals
+func (c *chainlinkReader) fetchPrice(ctx context.Context, feed types.PriceFeedConfig, pair types.TokenPair) (*types.PriceData, error) {
+	ctx, cancel := context.WithTimeout(ctx, contractCallTimeout)
+	defer cancel()
+
+	aggregator := common.HexToAddress(pair.Aggregator)
+	decimals, err := c.aggregatorDecimals(ctx, aggregator)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.call(ctx, aggregator, "latestRoundData")
+	if err != nil {
+		return nil, err
+	}
+	answer, updatedAt := out[1].(*big.Int), out[3].(*big.Int)
+	if answer.Sign() <= 0 {
+		return nil, fmt.Errorf("aggregator %s returned non-positive answer %s", aggregator.Hex(), answer)
+	}
+
+	timestamp := time.Unix(updatedAt.Int64(), 0)
+	return &types.PriceData{
+		Token0:    pair.Token0,
+		Token1:    pair.Token1,
+		Price:     NormalizePrice(answer, int(decimals), normalizedPriceDecimals),
+		Decimals:  normalizedPriceDecimals,
+		Timestamp: timestamp,
+		Source:    feed.Name,
+		IsStale:   time.Since(timestamp) > maxStaleness(feed),
+	}, nil
+}
+
+// aggregatorDecimals returns the decimals of an aggregator's answers
+func (c *chainlinkReader) aggregatorDecimals(ctx context.Context, aggregator common.Address) (uint8, error) {
+	c.mutex.Lock()
+	decimals, cached := c.decimals[aggregator]
+	c.mutex.Unlock()
+	if cached {
+		return decimals, nil
+	}
+
+	out, err := c.call(ctx, aggregator, "decimals")
+	if err != nil {
+		return 0, err
+	}
+	decimals = out[0].(uint8)
+
+	c.mutex.Lock()
+	c.decimals[aggregator] = decimals
+	c.mutex.Unlock()
+	return decimals, nil
+}
+
+// call invokes a view method of an aggregator and unpacks its outputs
+func (c *chainlinkReader) call(ctx context.Context, aggregator common.Address, method string) ([]interface{}, error) {
+	data, err := c.contractABI.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.caller.CallContract(ctx, ethereum.CallMsg{To: &aggregator, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s on aggregator %s: %w", method, aggregator.Hex(), err)
+	}
+	return c.contractABI.Unpack(method, result)
+}
@@ -0,0 +1,120 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// fakeAggregatorBackend answers AggregatorV3Interface calls for fixed aggregators
+type fakeAggregatorBackend struct {
+	reader    *chainlinkReader
+	decimals  map[common.Address]uint8
+	answers   map[common.Address]*big.Int
+	updatedAt time.Time
+	calls     map[string]int
+}
+
+func (f *fakeAggregatorBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeAggregatorBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := f.reader.contractABI.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	f.calls[method.Name]++
+
+	answer, exists := f.answers[*msg.To]
+	if !exists {
+		return nil, fmt.Errorf("no contract at %s", msg.To.Hex())
+	}
+	switch method.Name {
+	case "decimals":
+		return method.Outputs.Pack(f.decimals[*msg.To])
+	default:
+		updatedAt := big.NewInt(f.updatedAt.Unix())
+		return method.Outputs.Pack(big.NewInt(1), answer, updatedAt, updatedAt, big.NewInt(1))
+	}
+}
+
+func TestChainlinkFeedScalesAnswersToDecimals(t *testing.T) {
+	usdFeed := common.HexToAddress("0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419")
+	wideFeed := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	backend := &fakeAggregatorBackend{
+		decimals: map[common.Address]uint8{usdFeed: 8, wideFeed: 24},
+		answers: map[common.Address]*big.Int{
+			usdFeed:  big.NewInt(2000_12345678),
+			wideFeed: new(big.Int).Mul(big.NewInt(3), new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)),
+		},
+		updatedAt: time.Now(),
+		calls:     make(map[string]int),
+	}
+
+	feed := types.PriceFeedConfig{
+		Name: "chainlink",
+		Type: feedTypeChainlink,
+		Pairs: []types.TokenPair{
+			{Token0: "0xa", Token1: "0xb", Symbol: "ETH/USD", IsActive: true, Aggregator: usdFeed.Hex()},
+			{Token0: "0xc", Token1: "0xd", Symbol: "WIDE", IsActive: true, Aggregator: wideFeed.Hex()},
+		},
+	}
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, backend, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	backend.reader = pm.chainlink
+
+	priceData, err := pm.fetchPrice(context.Background(), feed, feed.Pairs[0])
+	if err != nil {
+		t.Fatalf("fetchPrice: %v", err)
+	}
+	want, _ := new(big.Int).SetString("2000123456780000000000", 10)
+	if priceData.Price.Cmp(want) != 0 {
+		t.Fatalf("price = %s, want %s (8 decimal answer scaled to 18)", priceData.Price, want)
+	}
+	if priceData.Source != "chainlink" || priceData.IsStale || priceData.Timestamp.Unix() != backend.updatedAt.Unix() {
+		t.Fatalf("unexpected price data %+v", priceData)
+	}
+
+	priceData, err = pm.fetchPrice(context.Background(), feed, feed.Pairs[1])
+	if err != nil {
+		t.Fatalf("fetchPrice: %v", err)
+	}
+	if want := new(big.Int).Mul(big.NewInt(3), big.NewInt(1e18)); priceData.Price.Cmp(want) != 0 {
+		t.Fatalf("price = %s, want %s (24 decimal answer scaled to 18)", priceData.Price, want)
+	}
+
+	// decimals() is read once per aggregator
+	if _, err := pm.fetchPrice(context.Background(), feed, feed.Pairs[0]); err != nil {
+		t.Fatalf("fetchPrice: %v", err)
+	}
+	if backend.calls["decimals"] != 2 || backend.calls["latestRoundData"] != 3 {
+		t.Fatalf("unexpected aggregator calls %v", backend.calls)
+	}
+}
+
+func TestNewPriceMonitorValidatesFeedTypes(t *testing.T) {
+	backend := &fakeAggregatorBackend{}
+	for name, feed := range map[string]types.PriceFeedConfig{
+		"unknown type":       {Name: "feed", Type: "grpc"},
+		"missing aggregator": {Name: "feed", Type: feedTypeChainlink, Pairs: []types.TokenPair{{Symbol: "ETH/USD"}}},
+	} {
+		if _, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, backend, newTestLogger()); err == nil {
+			t.Fatalf("%s: expected NewPriceMonitor to fail", name)
+		}
+	}
+
+	chainlink := types.PriceFeedConfig{Name: "feed", Type: feedTypeChainlink}
+	if _, err := NewPriceMonitor([]types.PriceFeedConfig{chainlink}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger()); err == nil {
+		t.Fatal("expected a chainlink feed without a client to be rejected")
+	}
+}
@@ -0,0 +1,49 @@
+package operator
+
+import (
+	"math"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	// confidenceMaxAge is the price age at which freshness no longer adds to a
+	// response's confidence
+	confidenceMaxAge = 5 * time.Minute
+	// confidenceMaxSpreadBps is the spread between sources at which their
+	// agreement no longer adds to a response's confidence
+	confidenceMaxSpreadBps = 1000
+	// minConfidence is the lowest confidence reported, since the aggregator reads
+	// a zero confidence as unreported
+	minConfidence = 0.01
+)
+
+// priceConfidence scores how far a response built on priceData can be trusted,
+// from 1 for a price fresh at now whose sources agree exactly down to
+// minConfidence. Freshness and agreement each fall linearly, with the price's age
+// up to confidenceMaxAge and with the spread between its sources up to
+// confidenceMaxSpreadBps, and the score is their product.
+func priceConfidence(priceData *types.PriceData, now time.Time) float64 {
+	if priceData == nil || priceData.IsStale {
+		return minConfidence
+	}
+
+	freshness := 1 - float64(now.Sub(priceData.Timestamp))/float64(confidenceMaxAge)
+	agreement := 1.0
+	if priceData.Discrepancy != nil {
+		spread, _ := priceData.Discrepancy.Float64()
+		agreement = 1 - spread/confidenceMaxSpreadBps
+	}
+	return math.Max(minConfidence, math.Min(1, freshness)*math.Min(1, agreement))
+}
+
+// responseConfidence returns the confidence of a response about a pool, from the
+// pool's current price
+func (o *Operator) responseConfidence(poolID string) float64 {
+	priceData, err := o.priceMonitor.GetPriceData(poolID)
+	if err != nil {
+		return minConfidence
+	}
+	return priceConfidence(priceData, time.Now())
+}
@@ -0,0 +1,57 @@
+package operator
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestPriceConfidenceDropsWithStaleness(t *testing.T) {
+	now := time.Now()
+	confidenceAt := func(age time.Duration) float64 {
+		return priceConfidence(&types.PriceData{Timestamp: now.Add(-age), Discrepancy: new(big.Int)}, now)
+	}
+
+	if fresh := confidenceAt(0); fresh != 1 {
+		t.Fatalf("fresh confidence = %v, want 1", fresh)
+	}
+	previous := confidenceAt(0)
+	for _, age := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		confidence := confidenceAt(age)
+		if confidence >= previous {
+			t.Fatalf("confidence at %s = %v, want below %v", age, confidence, previous)
+		}
+		previous = confidence
+	}
+	if old := confidenceAt(time.Hour); old != minConfidence {
+		t.Fatalf("confidence of an hour old price = %v, want %v", old, minConfidence)
+	}
+	if stale := priceConfidence(&types.PriceData{Timestamp: now, IsStale: true}, now); stale != minConfidence {
+		t.Fatalf("stale confidence = %v, want %v", stale, minConfidence)
+	}
+}
+
+func TestPriceConfidenceDropsWithSpread(t *testing.T) {
+	now := time.Now()
+	confidenceAt := func(spreadBps int64) float64 {
+		return priceConfidence(&types.PriceData{Timestamp: now, Discrepancy: big.NewInt(spreadBps)}, now)
+	}
+
+	if agreed := confidenceAt(0); agreed != 1 {
+		t.Fatalf("confidence without spread = %v, want 1", agreed)
+	}
+	if half := confidenceAt(confidenceMaxSpreadBps / 2); half != 0.5 {
+		t.Fatalf("confidence at half the max spread = %v, want 0.5", half)
+	}
+	if wide := confidenceAt(2 * confidenceMaxSpreadBps); wide != minConfidence {
+		t.Fatalf("confidence past the max spread = %v, want %v", wide, minConfidence)
+	}
+
+	// Staleness and spread compound
+	both := priceConfidence(&types.PriceData{Timestamp: now.Add(-confidenceMaxAge / 2), Discrepancy: big.NewInt(confidenceMaxSpreadBps / 2)}, now)
+	if both != 0.25 {
+		t.Fatalf("confidence of a half aged, half spread price = %v, want 0.25", both)
+	}
+}
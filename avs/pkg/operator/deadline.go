@@ -0,0 +1,16 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// taskOpen reports whether a response to task may still be submitted at chainTime,
+// the timestamp of the latest block. The service manager accepts responses while
+// block.timestamp is at or before the task's deadline, and judging it by block
+// timestamps rather than the local clock keeps operators with skewed clocks in
+// agreement with each other and with the aggregator.
+func taskOpen(task *types.Task, chainTime time.Time) bool {
+	return !chainTime.After(task.Deadline)
+}
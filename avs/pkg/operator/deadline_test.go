@@ -0,0 +1,71 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestTaskOpenUsesChainTime(t *testing.T) {
+	deadline := time.Unix(1700000060, 0)
+	cases := []struct {
+		name      string
+		chainTime time.Time
+		want      bool
+	}{
+		{"before deadline", deadline.Add(-12 * time.Second), true},
+		{"at deadline", deadline, true},
+		{"after deadline", deadline.Add(time.Second), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := taskOpen(&types.Task{Deadline: deadline}, tc.chainTime); got != tc.want {
+				t.Fatalf("taskOpen = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProcessTasksHonoursChainTime(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["auction-a"] = &types.Auction{ID: "auction-a", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	// The chain is an hour behind the local clock, so the chain decides
+	chainTime := time.Now().Add(-time.Hour)
+	coord.tasks = []*types.Task{
+		// Open by chain time although the local clock is past its deadline
+		{ID: 1, AuctionID: "auction-a", Deadline: chainTime.Add(time.Minute)},
+		// Closed by chain time
+		{ID: 2, AuctionID: "auction-a", Deadline: chainTime.Add(-time.Minute)},
+	}
+	coord.setChainTime(chainTime)
+
+	op := newTestOperator(t, coord)
+	op.processTasks()
+	time.Sleep(20 * time.Millisecond)
+
+	coord.mutex.Lock()
+	defer coord.mutex.Unlock()
+	if coord.responses[1] == nil {
+		t.Fatal("expected a response for the task open by chain time")
+	}
+	if coord.responses[2] != nil {
+		t.Fatal("expected no response for the task closed by chain time")
+	}
+}
+
+func TestProcessTaskDropsResponseAfterDeadline(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["auction-a"] = &types.Auction{ID: "auction-a", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	// The local clock is behind the chain, which already passed the deadline
+	deadline := time.Now().Add(time.Hour)
+	coord.setChainTime(deadline.Add(time.Second))
+
+	op := newTestOperator(t, coord)
+	op.processTask(&types.Task{ID: 1, AuctionID: "auction-a", Deadline: deadline})
+
+	if coord.responses[1] != nil {
+		t.Fatal("expected response to be dropped after the deadline")
+	}
+}
@@ -0,0 +1,258 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	// defaultDecisionBatchSize is the number of records written to the sink at once
+	defaultDecisionBatchSize = 100
+	// defaultDecisionFlushInterval bounds how long a partial batch waits before being written
+	defaultDecisionFlushInterval = 10 * time.Second
+	// defaultDecisionBufferSize is the number of records queued before new ones are dropped
+	defaultDecisionBufferSize = 10000
+)
+
+// Decision outcomes recorded for each processed task
+const (
+	decisionSubmitted = "submitted"
+	decisionNoWinner  = "no_winner"
+	decisionSkipped   = "skipped"
+	decisionFailed    = "failed"
+	// decisionDryRun is a response computed but not submitted in dry-run mode
+	decisionDryRun = "dry_run"
+)
+
+// DecisionRecord is the structured record exported for every task the operator
+// decides on. Amounts are decimal strings so they survive warehouse loaders intact.
+type DecisionRecord struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Operator    string    `json:"operator"`
+	TaskID      uint32    `json:"task_id"`
+	AuctionID   string    `json:"auction_id"`
+	PoolID      string    `json:"pool_id"`
+	BlockNumber uint64    `json:"block_number"`
+	Price       string    `json:"price,omitempty"`
+	Discrepancy string    `json:"discrepancy,omitempty"`
+	Winner      string    `json:"winner,omitempty"`
+	WinningBid  string    `json:"winning_bid,omitempty"`
+	Outcome     string    `json:"outcome"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// DecisionSink receives batches of decision records for a data warehouse
+type DecisionSink interface {
+	WriteBatch(ctx context.Context, records []DecisionRecord) error
+	Close() error
+}
+
+// NewDecisionSink creates the sink selected by config
+func NewDecisionSink(config types.DecisionExportConfig) (DecisionSink, error) {
+	switch config.Sink {
+	case "", "file":
+		return NewFileDecisionSink(config.Path)
+	default:
+		return nil, fmt.Errorf("unknown decision export sink %q", config.Sink)
+	}
+}
+
+// fileDecisionSink appends records to a newline delimited JSON file, the format
+// accepted by most warehouse bulk loaders
+type fileDecisionSink struct {
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// NewFileDecisionSink creates a sink appending records to the file at path
+func NewFileDecisionSink(path string) (DecisionSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("decision export path is required")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open decision export file: %w", err)
+	}
+	return &fileDecisionSink{file: file}, nil
+}
+
+func (s *fileDecisionSink) WriteBatch(ctx context.Context, records []DecisionRecord) error {
+	var data []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err := s.file.Write(data)
+	return err
+}
+
+func (s *fileDecisionSink) Close() error {
+	return s.file.Close()
+}
+
+// decisionExporter batches decision records and writes them to a sink in the
+// background. Recording never blocks task processing; records are dropped when
+// the buffer is full.
+type decisionExporter struct {
+	sink          DecisionSink
+	records       chan DecisionRecord
+	batchSize     int
+	flushInterval time.Duration
+	logger        *logrus.Logger
+
+	// started is set by Start, and stopped is closed once Run has flushed the last
+	// records and closed the sink
+	started bool
+	stopped chan struct{}
+	mutex   sync.Mutex
+
+	exported atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+// newDecisionExporter creates an exporter writing to sink
+func newDecisionExporter(sink DecisionSink, config types.DecisionExportConfig, logger *logrus.Logger) *decisionExporter {
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultDecisionBatchSize
+	}
+	flushInterval := time.Duration(config.FlushInterval) * time.Second
+	if flushInterval <= 0 {
+		flushInterval = defaultDecisionFlushInterval
+	}
+	bufferSize := config.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultDecisionBufferSize
+	}
+
+	return &decisionExporter{
+		sink:          sink,
+		records:       make(chan DecisionRecord, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		logger:        logger,
+		stopped:       make(chan struct{}),
+	}
+}
+
+// Start runs the exporter in the background until ctx is cancelled
+func (e *decisionExporter) Start(ctx context.Context) {
+	e.mutex.Lock()
+	e.started = true
+	e.mutex.Unlock()
+
+	go func() {
+		defer close(e.stopped)
+		e.Run(ctx)
+	}()
+}
+
+// Stopped returns a channel closed once the exporter started by Start has flushed
+// its last records after ctx is cancelled. It is closed already if the exporter
+// was never started.
+func (e *decisionExporter) Stopped() <-chan struct{} {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !e.started {
+		stopped := make(chan struct{})
+		close(stopped)
+		return stopped
+	}
+	return e.stopped
+}
+
+// Record queues a record for export without blocking
+func (e *decisionExporter) Record(record DecisionRecord) {
+	select {
+	case e.records <- record:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Run writes queued records in batches until ctx is cancelled, then flushes what
+// remains and closes the sink
+func (e *decisionExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]DecisionRecord, 0, e.batchSize)
+	for {
+		select {
+		case record := <-e.records:
+			batch = append(batch, record)
+			if len(batch) >= e.batchSize {
+				batch = e.flush(batch)
+			}
+		case <-ticker.C:
+			batch = e.flush(batch)
+		case <-ctx.Done():
+			e.drain(batch)
+			return
+		}
+	}
+}
+
+// drain writes every queued record and closes the sink
+func (e *decisionExporter) drain(batch []DecisionRecord) {
+	for {
+		select {
+		case record := <-e.records:
+			batch = append(batch, record)
+			if len(batch) >= e.batchSize {
+				batch = e.flush(batch)
+			}
+		default:
+			e.flush(batch)
+			if err := e.sink.Close(); err != nil {
+				e.logger.WithError(err).Warn("Failed to close decision export sink")
+			}
+			return
+		}
+	}
+}
+
+// flush writes batch to the sink and returns an empty batch
+func (e *decisionExporter) flush(batch []DecisionRecord) []DecisionRecord {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	// Use a fresh context so the final flush on shutdown still completes
+	ctx, cancel := context.WithTimeout(context.Background(), e.flushInterval)
+	defer cancel()
+
+	if err := e.sink.WriteBatch(ctx, batch); err != nil {
+		e.failed.Add(uint64(len(batch)))
+		e.logger.WithError(err).WithField("records", len(batch)).Error("Failed to export decision records")
+	} else {
+		e.exported.Add(uint64(len(batch)))
+	}
+	return make([]DecisionRecord, 0, e.batchSize)
+}
+
+// Metrics returns export counters
+func (e *decisionExporter) Metrics() map[string]interface{} {
+	return map[string]interface{}{
+		"decisions_exported": e.exported.Load(),
+		"decisions_dropped":  e.dropped.Load(),
+		"decisions_failed":   e.failed.Load(),
+	}
+}
@@ -0,0 +1,203 @@
+package operator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// mockDecisionSink collects the batches written to it
+type mockDecisionSink struct {
+	mutex   sync.Mutex
+	batches [][]DecisionRecord
+	closed  bool
+	// delay slows every write down
+	delay time.Duration
+}
+
+func (s *mockDecisionSink) WriteBatch(ctx context.Context, records []DecisionRecord) error {
+	time.Sleep(s.delay)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.batches = append(s.batches, append([]DecisionRecord(nil), records...))
+	return nil
+}
+
+func (s *mockDecisionSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *mockDecisionSink) records() []DecisionRecord {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var records []DecisionRecord
+	for _, batch := range s.batches {
+		records = append(records, batch...)
+	}
+	return records
+}
+
+// runExporter runs e until the returned stop function is called
+func runExporter(e *decisionExporter) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestDecisionRecordsExported(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["open"] = &types.Auction{ID: "open", PoolID: testPoolID, BlockNumber: 7, State: types.AuctionBiddingOpen}
+	coord.auctions["done"] = &types.Auction{ID: "done", PoolID: testPoolID, BlockNumber: 8, State: types.AuctionComplete}
+
+	sink := &mockDecisionSink{}
+	op := newTestOperator(t, coord)
+	op.decisions = newDecisionExporter(sink, types.DecisionExportConfig{BatchSize: 2}, op.logger)
+	stop := runExporter(op.decisions)
+
+	deadline := time.Now().Add(time.Minute)
+	op.processTask(&types.Task{ID: 1, AuctionID: "open", PoolID: testPoolID, Deadline: deadline})
+	op.processTask(&types.Task{ID: 2, AuctionID: "done", PoolID: testPoolID, Deadline: deadline})
+	op.processTask(&types.Task{ID: 3, AuctionID: "missing", PoolID: testPoolID, Deadline: deadline})
+	stop()
+
+	records := sink.records()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if len(sink.batches) != 2 || !sink.closed {
+		t.Fatalf("expected 2 batches and a closed sink, got %d batches, closed %v", len(sink.batches), sink.closed)
+	}
+
+	submitted := records[0]
+	if submitted.TaskID != 1 || submitted.Outcome != decisionSubmitted || submitted.BlockNumber != 7 {
+		t.Fatalf("unexpected submitted record: %+v", submitted)
+	}
+	if submitted.Winner == "" || submitted.WinningBid != "100" || submitted.Discrepancy != "100000" || submitted.Price == "" {
+		t.Fatalf("submitted record missing decision fields: %+v", submitted)
+	}
+
+	if records[1].Outcome != decisionSkipped || records[1].Reason != skipReasonInactiveAuction {
+		t.Fatalf("unexpected record for completed auction: %+v", records[1])
+	}
+	if records[2].Outcome != decisionSkipped || records[2].Reason != skipReasonUnknownAuction {
+		t.Fatalf("unexpected record for unknown auction: %+v", records[2])
+	}
+
+	// The exported schema is stable for warehouse loaders
+	data, err := json.Marshal(submitted)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	var keys []string
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	want := []string{"auction_id", "block_number", "discrepancy", "operator", "outcome", "pool_id", "price", "task_id", "timestamp", "winner", "winning_bid"}
+	if len(keys) != len(want) {
+		t.Fatalf("schema = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("schema = %v, want %v", keys, want)
+		}
+	}
+}
+
+func TestStopWaitsForDecisionFlush(t *testing.T) {
+	sink := &mockDecisionSink{delay: 50 * time.Millisecond}
+	op := newTestOperator(t, newFakeCoordinator())
+	op.decisions = newDecisionExporter(sink, types.DecisionExportConfig{}, op.logger)
+	op.decisions.Start(op.ctx)
+
+	op.decisions.Record(DecisionRecord{TaskID: 1, Outcome: decisionSkipped})
+	if err := op.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	if len(sink.batches) != 1 || !sink.closed {
+		t.Fatalf("after Stop: %d batches written, sink closed %v; want the last batch flushed and the sink closed", len(sink.batches), sink.closed)
+	}
+}
+
+func TestDecisionExporterDoesNotBlock(t *testing.T) {
+	e := newDecisionExporter(&mockDecisionSink{}, types.DecisionExportConfig{BufferSize: 1}, newTestLogger())
+
+	done := make(chan struct{})
+	go func() {
+		e.Record(DecisionRecord{TaskID: 1})
+		e.Record(DecisionRecord{TaskID: 2})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Record blocked with a full buffer")
+	}
+	if got := e.Metrics()["decisions_dropped"]; got != uint64(1) {
+		t.Fatalf("decisions_dropped = %v, want 1", got)
+	}
+}
+
+func TestFileDecisionSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.ndjson")
+	sink, err := NewFileDecisionSink(path)
+	if err != nil {
+		t.Fatalf("NewFileDecisionSink: %v", err)
+	}
+
+	records := []DecisionRecord{{TaskID: 1, Outcome: decisionSubmitted}, {TaskID: 2, Outcome: decisionSkipped}}
+	if err := sink.WriteBatch(context.Background(), records); err != nil {
+		t.Fatalf("WriteBatch: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+
+	var lines int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record DecisionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d is not a record: %v", lines, err)
+		}
+		if record.TaskID != records[lines].TaskID {
+			t.Fatalf("line %d task = %d, want %d", lines, record.TaskID, records[lines].TaskID)
+		}
+		lines++
+	}
+	if lines != len(records) {
+		t.Fatalf("expected %d lines, got %d", len(records), lines)
+	}
+}
@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// Circuit breaker states reported by FeedHealth
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+const (
+	// defaultFailureThreshold is the consecutive failed polls after which a feed's breaker opens
+	defaultFailureThreshold = 3
+	// defaultMaxFeedBackoff caps how long a feed's breaker stays open
+	defaultMaxFeedBackoff = 5 * time.Minute
+)
+
+// FeedHealth is the circuit breaker state of a price feed
+type FeedHealth struct {
+	State               string `json:"state"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	// OpenUntil is when an open breaker next lets a probe through
+	OpenUntil time.Time `json:"open_until,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// feedBreaker stops polling a feed after consecutive failures. Once open it
+// waits out a backoff, doubled each time a probe fails, then half-opens to let
+// a single poll probe whether the feed recovered.
+type feedBreaker struct {
+	threshold   int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+
+	state     string
+	failures  int
+	backoff   time.Duration
+	openUntil time.Time
+	lastError string
+	mutex     sync.Mutex
+}
+
+// newFeedBreaker creates a closed breaker for a feed. The first open period is
+// the feed's update interval.
+func newFeedBreaker(feed types.PriceFeedConfig) *feedBreaker {
+	threshold := feed.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultFailureThreshold
+	}
+	baseBackoff := time.Duration(feed.UpdateFreq) * time.Second
+	if baseBackoff <= 0 {
+		baseBackoff = time.Second
+	}
+	maxBackoff := time.Duration(feed.MaxBackoff) * time.Second
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxFeedBackoff
+	}
+	return newBreaker(threshold, baseBackoff, maxBackoff)
+}
+
+// newBreaker creates a closed breaker opening after threshold consecutive failures
+func newBreaker(threshold int, baseBackoff, maxBackoff time.Duration) *feedBreaker {
+	return &feedBreaker{
+		threshold:   threshold,
+		baseBackoff: baseBackoff,
+		maxBackoff:  max(maxBackoff, baseBackoff),
+		now:         time.Now,
+		state:       breakerClosed,
+	}
+}
+
+// allow reports whether the feed may be polled, half-opening an open breaker
+// whose backoff has elapsed
+func (b *feedBreaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == breakerOpen {
+		if b.now().Before(b.openUntil) {
+			return false
+		}
+		b.state = breakerHalfOpen
+	}
+	return true
+}
+
+// record updates the breaker with the outcome of a poll
+func (b *feedBreaker) record(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err == nil {
+		b.state = breakerClosed
+		b.failures = 0
+		b.backoff = 0
+		b.lastError = ""
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	switch {
+	case b.state == breakerHalfOpen:
+		b.backoff = min(2*b.backoff, b.maxBackoff)
+	case b.failures >= b.threshold:
+		b.backoff = b.baseBackoff
+	default:
+		return
+	}
+	b.state = breakerOpen
+	b.openUntil = b.now().Add(b.backoff)
+}
+
+// health returns the breaker's current state
+func (b *feedBreaker) health() FeedHealth {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	health := FeedHealth{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if b.state == breakerOpen {
+		health.OpenUntil = b.openUntil
+	}
+	return health
+}
@@ -0,0 +1,79 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestFeedBreakerStopsPollingFailingFeed(t *testing.T) {
+	var calls atomic.Int32
+	var down atomic.Bool
+	down.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"price": "2000", "timestamp": time.Now().Unix()})
+	}))
+	defer server.Close()
+
+	feed := types.PriceFeedConfig{
+		Name:             "flaky",
+		URL:              server.URL,
+		UpdateFreq:       10,
+		FailureThreshold: 2,
+		MaxRetries:       -1,
+		Pairs:            []types.TokenPair{{Token0: "0xa", Token1: "0xb", Symbol: "AB", IsActive: true}},
+	}
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	clock := newFakeClock()
+	pm.breakers[feed.Name].now = clock.Now
+
+	pollAndExpect := func(wantCalls int32, wantState string) {
+		t.Helper()
+		pm.pollFeed(context.Background(), feed)
+		if got := calls.Load(); got != wantCalls {
+			t.Fatalf("feed called %d times, want %d", got, wantCalls)
+		}
+		if state := pm.FeedHealth()[feed.Name].State; state != wantState {
+			t.Fatalf("breaker state = %s, want %s", state, wantState)
+		}
+	}
+
+	pollAndExpect(1, breakerClosed)
+	pollAndExpect(2, breakerOpen)
+
+	// The feed isn't called while the breaker is open
+	clock.Advance(5 * time.Second)
+	pollAndExpect(2, breakerOpen)
+
+	// After the backoff a single probe is let through; its failure doubles the backoff
+	clock.Advance(5 * time.Second)
+	pollAndExpect(3, breakerOpen)
+	if openUntil := pm.FeedHealth()[feed.Name].OpenUntil; !openUntil.Equal(clock.Now().Add(20 * time.Second)) {
+		t.Fatalf("open until %s, want 20s after the failed probe", openUntil)
+	}
+	clock.Advance(10 * time.Second)
+	pollAndExpect(3, breakerOpen)
+
+	// A successful probe closes the breaker
+	down.Store(false)
+	clock.Advance(10 * time.Second)
+	pollAndExpect(4, breakerClosed)
+	if health := pm.FeedHealth()[feed.Name]; health.ConsecutiveFailures != 0 || health.LastError != "" {
+		t.Fatalf("unexpected health after recovery %+v", health)
+	}
+	pollAndExpect(5, breakerClosed)
+}
@@ -0,0 +1,203 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// responseMappingVersion is the PriceResponseMapping schema version supported
+const responseMappingVersion = 1
+
+// Units of the timestamps of an http feed's responses
+const (
+	timestampUnitSeconds      = "seconds"
+	timestampUnitMilliseconds = "milliseconds"
+)
+
+// errFieldNotFound is returned for a path that does not exist in a response
+var errFieldNotFound = errors.New("field not found")
+
+// pathSegment is one step of a field path: an object key, or an array index
+// when key is empty
+type pathSegment struct {
+	key   string
+	index int
+}
+
+// fieldPath is a parsed JSONPath-style field path
+type fieldPath struct {
+	raw      string
+	segments []pathSegment
+}
+
+// parseFieldPath parses a dotted path of object keys and [n] array indexes,
+// optionally prefixed by "$", such as "$.data.quotes[0].price"
+func parseFieldPath(raw string) (fieldPath, error) {
+	path := fieldPath{raw: raw}
+	rest := strings.TrimPrefix(strings.TrimPrefix(raw, "$"), ".")
+	if rest == "" {
+		return path, fmt.Errorf("empty field path %q", raw)
+	}
+
+	for _, part := range strings.Split(rest, ".") {
+		key, indexes, _ := strings.Cut(part, "[")
+		if key == "" && indexes == "" {
+			return path, fmt.Errorf("field path %q has an empty key", raw)
+		}
+		if key != "" {
+			path.segments = append(path.segments, pathSegment{key: key})
+		}
+		if indexes == "" {
+			continue
+		}
+		if !strings.HasSuffix(indexes, "]") {
+			return path, fmt.Errorf("field path %q has an unterminated index", raw)
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			n, err := strconv.Atoi(index)
+			if err != nil || n < 0 {
+				return path, fmt.Errorf("field path %q has an invalid index %q", raw, index)
+			}
+			path.segments = append(path.segments, pathSegment{index: n})
+		}
+	}
+	return path, nil
+}
+
+// lookup returns the value at the path in a decoded JSON document
+func (p fieldPath) lookup(document interface{}) (interface{}, error) {
+	value := document
+	for _, segment := range p.segments {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			next, exists := node[segment.key]
+			if segment.key == "" || !exists {
+				return nil, fmt.Errorf("%s: %w", p.raw, errFieldNotFound)
+			}
+			value = next
+		case []interface{}:
+			if segment.key != "" || segment.index >= len(node) {
+				return nil, fmt.Errorf("%s: %w", p.raw, errFieldNotFound)
+			}
+			value = node[segment.index]
+		default:
+			return nil, fmt.Errorf("%s: %w", p.raw, errFieldNotFound)
+		}
+	}
+	return value, nil
+}
+
+// responseMapping reads the price, timestamp and source of an http feed's
+// responses
+type responseMapping struct {
+	price     fieldPath
+	timestamp fieldPath
+	source    fieldPath
+	// milliseconds is set when response timestamps are in milliseconds
+	milliseconds bool
+}
+
+// newResponseMapping validates and parses a feed's response mapping
+func newResponseMapping(config types.PriceResponseMapping) (*responseMapping, error) {
+	if config.Version != 0 && config.Version != responseMappingVersion {
+		return nil, fmt.Errorf("unsupported response mapping version %d", config.Version)
+	}
+
+	mapping := &responseMapping{}
+	switch config.TimestampUnit {
+	case "", timestampUnitSeconds:
+	case timestampUnitMilliseconds:
+		mapping.milliseconds = true
+	default:
+		return nil, fmt.Errorf("unknown timestamp unit %q", config.TimestampUnit)
+	}
+
+	paths := []struct {
+		path     *fieldPath
+		raw      string
+		fallback string
+	}{
+		{&mapping.price, config.Price, "price"},
+		{&mapping.timestamp, config.Timestamp, "timestamp"},
+		{&mapping.source, config.Source, "source"},
+	}
+	for _, p := range paths {
+		raw := p.raw
+		if raw == "" {
+			raw = p.fallback
+		}
+		path, err := parseFieldPath(raw)
+		if err != nil {
+			return nil, err
+		}
+		*p.path = path
+	}
+	return mapping, nil
+}
+
+// parse extracts the price, timestamp and source of a response body. Prices may
+// be JSON strings or integer numbers.
+func (m *responseMapping) parse(body []byte) (*big.Int, time.Time, string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, time.Time{}, "", err
+	}
+
+	rawPrice, err := m.price.lookup(document)
+	if err != nil {
+		return nil, time.Time{}, "", err
+	}
+	priceString, ok := scalarString(rawPrice)
+	if !ok {
+		return nil, time.Time{}, "", fmt.Errorf("%s: price is not a string or number", m.price.raw)
+	}
+	price, ok := new(big.Int).SetString(priceString, 10)
+	if !ok {
+		return nil, time.Time{}, "", fmt.Errorf("invalid price format: %s", priceString)
+	}
+
+	rawTimestamp, err := m.timestamp.lookup(document)
+	if err != nil {
+		return nil, time.Time{}, "", err
+	}
+	timestampString, ok := scalarString(rawTimestamp)
+	if !ok {
+		return nil, time.Time{}, "", fmt.Errorf("%s: timestamp is not a string or number", m.timestamp.raw)
+	}
+	units, err := strconv.ParseInt(timestampString, 10, 64)
+	if err != nil {
+		return nil, time.Time{}, "", fmt.Errorf("invalid timestamp format: %s", timestampString)
+	}
+	timestamp := time.Unix(units, 0)
+	if m.milliseconds {
+		timestamp = time.UnixMilli(units)
+	}
+
+	var source string
+	if rawSource, err := m.source.lookup(document); err == nil {
+		source, _ = rawSource.(string)
+	}
+	return price, timestamp, source, nil
+}
+
+// scalarString returns a JSON string or number as a string
+func scalarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
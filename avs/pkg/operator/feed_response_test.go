@@ -0,0 +1,97 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// newShapedPriceFeed serves body for every pair and returns its feed config,
+// reading responses with mapping
+func newShapedPriceFeed(t *testing.T, name, body string, mapping types.PriceResponseMapping) types.PriceFeedConfig {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return types.PriceFeedConfig{
+		Name:            name,
+		URL:             server.URL,
+		ResponseMapping: mapping,
+		Pairs: []types.TokenPair{
+			{Token0: "0xa", Token1: "0xb", Symbol: "AB", Decimals: normalizedPriceDecimals, IsActive: true},
+		},
+	}
+}
+
+func TestPriceMonitorParsesDifferentlyShapedResponses(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+	feeds := []types.PriceFeedConfig{
+		// Nested quote with a numeric price and a millisecond timestamp
+		newShapedPriceFeed(t, "vendor_a",
+			fmt.Sprintf(`{"data":{"quotes":[{"px":2000000000,"updated_ms":%d}]}}`, now.UnixMilli()),
+			types.PriceResponseMapping{
+				Version:       1,
+				Price:         "$.data.quotes[0].px",
+				Timestamp:     "$.data.quotes[0].updated_ms",
+				TimestampUnit: timestampUnitMilliseconds,
+			}),
+		// Flat result with string fields under other names
+		newShapedPriceFeed(t, "vendor_b",
+			fmt.Sprintf(`{"result":{"last":"2010000000","time":"%d","exchange":"b-exchange"}}`, now.Unix()),
+			types.PriceResponseMapping{
+				Price:     "result.last",
+				Timestamp: "result.time",
+				Source:    "result.exchange",
+			}),
+	}
+
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+
+	a, err := pm.fetchHTTPPriceOnce(context.Background(), feeds[0], feeds[0].Pairs[0])
+	if err != nil {
+		t.Fatalf("vendor_a: %v", err)
+	}
+	if a.Price.String() != "2000000000" || !a.Timestamp.Equal(now) || a.Source != "vendor_a" {
+		t.Fatalf("vendor_a price = %s at %s from %q, want 2000000000 at %s from the feed name", a.Price, a.Timestamp, a.Source, now)
+	}
+
+	b, err := pm.fetchHTTPPriceOnce(context.Background(), feeds[1], feeds[1].Pairs[0])
+	if err != nil {
+		t.Fatalf("vendor_b: %v", err)
+	}
+	if b.Price.String() != "2010000000" || b.Timestamp.Unix() != now.Unix() || b.Source != "b-exchange" || b.IsStale {
+		t.Fatalf("vendor_b price = %s at %s from %q, stale %t", b.Price, b.Timestamp, b.Source, b.IsStale)
+	}
+
+	// A response missing a mapped field fails instead of parsing as zero
+	missing := newShapedPriceFeed(t, "vendor_a", `{"data":{"quotes":[]}}`, feeds[0].ResponseMapping)
+	if _, err := pm.fetchHTTPPriceOnce(context.Background(), missing, missing.Pairs[0]); err == nil {
+		t.Fatal("expected a response without the mapped price to fail")
+	}
+}
+
+func TestNewPriceMonitorValidatesResponseMappings(t *testing.T) {
+	for name, mapping := range map[string]types.PriceResponseMapping{
+		"version":        {Version: 2},
+		"empty key":      {Price: "data..price"},
+		"bad index":      {Price: "data.quotes[x].price"},
+		"unterminated":   {Timestamp: "data.quotes[0"},
+		"timestamp unit": {TimestampUnit: "hours"},
+	} {
+		feed := types.PriceFeedConfig{Name: "feed", URL: "http://feed", ResponseMapping: mapping}
+		if _, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger()); err == nil {
+			t.Errorf("%s: expected the response mapping to be rejected", name)
+		}
+	}
+}
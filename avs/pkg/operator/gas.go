@@ -0,0 +1,96 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Fee strategies selectable by NetworkConfig.FeeStrategy
+const (
+	// feeStrategyAuto prices EIP-1559 transactions on chains whose blocks carry
+	// a base fee, and legacy transactions elsewhere
+	feeStrategyAuto    = "auto"
+	feeStrategyLegacy  = "legacy"
+	feeStrategyDynamic = "dynamic"
+)
+
+const (
+	// baseFeeMultiplier is the number of base fees the fee cap covers on top of
+	// the tip, so a transaction stays includable while the base fee rises
+	baseFeeMultiplier = 2
+	// gasLimitMarginPercent is added to estimated gas limits, since state can
+	// change between estimation and inclusion
+	gasLimitMarginPercent = 20
+)
+
+// errNoBaseFee is returned when dynamic fees are requested on a chain without EIP-1559
+var errNoBaseFee = errors.New("chain does not support EIP-1559 dynamic fees")
+
+// feeBackend is the chain client transactions are priced and estimated against
+type feeBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+}
+
+// prepareTransaction sets the fees of auth according to strategy and its gas
+// limit to the estimate of call plus gasLimitMarginPercent
+func prepareTransaction(ctx context.Context, backend feeBackend, strategy string, auth *bind.TransactOpts, call ethereum.CallMsg) error {
+	if err := setFees(ctx, backend, strategy, auth); err != nil {
+		return err
+	}
+
+	gas, err := backend.EstimateGas(ctx, call)
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	auth.GasLimit = gas + gas*gasLimitMarginPercent/100
+	return nil
+}
+
+// setFees prices auth as a legacy or an EIP-1559 transaction
+func setFees(ctx context.Context, backend feeBackend, strategy string, auth *bind.TransactOpts) error {
+	if strategy == "" {
+		strategy = feeStrategyAuto
+	}
+
+	var baseFee *big.Int
+	switch strategy {
+	case feeStrategyAuto, feeStrategyDynamic:
+		head, err := backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to read latest header: %w", err)
+		}
+		baseFee = head.BaseFee
+		if baseFee == nil && strategy == feeStrategyDynamic {
+			return errNoBaseFee
+		}
+	case feeStrategyLegacy:
+	default:
+		return fmt.Errorf("unknown fee strategy %q", strategy)
+	}
+
+	if baseFee == nil {
+		gasPrice, err := backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		auth.GasPrice = gasPrice
+		return nil
+	}
+
+	tip, err := backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to suggest gas tip cap: %w", err)
+	}
+	auth.GasTipCap = tip
+	auth.GasFeeCap = new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(baseFeeMultiplier)))
+	return nil
+}
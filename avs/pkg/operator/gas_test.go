@@ -0,0 +1,106 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeFeeBackend returns fixed fee data, with a nil baseFee for pre-London chains
+type fakeFeeBackend struct {
+	baseFee  *big.Int
+	gasPrice *big.Int
+	tipCap   *big.Int
+	gas      uint64
+	calls    []ethereum.CallMsg
+}
+
+func (b *fakeFeeBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error) {
+	return &ethtypes.Header{Number: big.NewInt(100), BaseFee: b.baseFee}, nil
+}
+
+func (b *fakeFeeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return b.gasPrice, nil
+}
+
+func (b *fakeFeeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if b.baseFee == nil {
+		return nil, errors.New("method eth_maxPriorityFeePerGas not supported")
+	}
+	return b.tipCap, nil
+}
+
+func (b *fakeFeeBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	b.calls = append(b.calls, call)
+	return b.gas, nil
+}
+
+func TestPrepareTransaction(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9)) }
+	london := func() *fakeFeeBackend {
+		return &fakeFeeBackend{baseFee: gwei(30), gasPrice: gwei(32), tipCap: gwei(2), gas: 100000}
+	}
+	preLondon := func() *fakeFeeBackend {
+		return &fakeFeeBackend{gasPrice: gwei(20), gas: 100000}
+	}
+
+	tests := []struct {
+		name     string
+		backend  *fakeFeeBackend
+		strategy string
+		// wantPrice is the legacy gas price, or nil for a dynamic fee transaction
+		wantPrice  *big.Int
+		wantTip    *big.Int
+		wantFeeCap *big.Int
+		wantErr    error
+	}{
+		{name: "auto on london", backend: london(), wantTip: gwei(2), wantFeeCap: gwei(62)},
+		{name: "auto before london", backend: preLondon(), strategy: feeStrategyAuto, wantPrice: gwei(20)},
+		{name: "dynamic", backend: london(), strategy: feeStrategyDynamic, wantTip: gwei(2), wantFeeCap: gwei(62)},
+		{name: "dynamic before london", backend: preLondon(), strategy: feeStrategyDynamic, wantErr: errNoBaseFee},
+		{name: "legacy on london", backend: london(), strategy: feeStrategyLegacy, wantPrice: gwei(32)},
+	}
+
+	serviceManager := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	call := ethereum.CallMsg{From: common.HexToAddress("0xb0b"), To: &serviceManager, Value: big.NewInt(1e18)}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := &bind.TransactOpts{}
+			err := prepareTransaction(context.Background(), tc.backend, tc.strategy, auth, call)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("prepareTransaction error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("prepareTransaction: %v", err)
+			}
+
+			if tc.wantPrice != nil {
+				if auth.GasPrice == nil || auth.GasPrice.Cmp(tc.wantPrice) != 0 || auth.GasTipCap != nil || auth.GasFeeCap != nil {
+					t.Fatalf("expected a legacy gas price of %s, got price %v tip %v cap %v", tc.wantPrice, auth.GasPrice, auth.GasTipCap, auth.GasFeeCap)
+				}
+			} else if auth.GasPrice != nil || auth.GasTipCap.Cmp(tc.wantTip) != 0 || auth.GasFeeCap.Cmp(tc.wantFeeCap) != 0 {
+				t.Fatalf("expected tip %s and fee cap %s, got price %v tip %v cap %v", tc.wantTip, tc.wantFeeCap, auth.GasPrice, auth.GasTipCap, auth.GasFeeCap)
+			}
+
+			if auth.GasLimit != 120000 {
+				t.Fatalf("gas limit = %d, want the estimate plus a 20%% margin", auth.GasLimit)
+			}
+			if len(tc.backend.calls) != 1 || tc.backend.calls[0].Value.Cmp(call.Value) != 0 {
+				t.Fatalf("expected the call to be estimated once, got %+v", tc.backend.calls)
+			}
+		})
+	}
+
+	if err := prepareTransaction(context.Background(), london(), "eip4844", &bind.TransactOpts{}, call); err == nil {
+		t.Fatal("expected an unknown fee strategy to be rejected")
+	}
+}
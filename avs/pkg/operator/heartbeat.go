@@ -0,0 +1,70 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultHeartbeatInterval is how often liveness is reported to the aggregators
+// when heartbeat_interval_seconds is unset
+const defaultHeartbeatInterval = 30 * time.Second
+
+// heartbeatPayload is the signed body of a POST /heartbeat
+type heartbeatPayload struct {
+	Timestamp int64 `json:"timestamp"`
+}
+
+// sendHeartbeats reports liveness to every aggregator each heartbeat interval
+// until ctx is cancelled
+func (ac *AuctionCoordinator) sendHeartbeats(ctx context.Context) {
+	ticker := time.NewTicker(ac.heartbeatPeriod)
+	defer ticker.Stop()
+
+	for {
+		if err := ac.sendHeartbeat(); err != nil {
+			ac.logger.WithError(err).Warn("Failed to send heartbeat")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendHeartbeat posts a signed heartbeat to every configured aggregator, each of
+// which tracks liveness on its own
+func (ac *AuctionCoordinator) sendHeartbeat() error {
+	body, err := json.Marshal(heartbeatPayload{Timestamp: ac.now().Unix()})
+	if err != nil {
+		return err
+	}
+	signature, err := SignRequestBody(ac.privateKey, body)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, endpoint := range ac.aggregators {
+		resp, err := ac.aggregator.R().
+			SetHeader("Content-Type", "application/json").
+			SetHeader(OperatorSignatureHeader, signature).
+			SetBody(body).
+			Post(endpoint.url + "/heartbeat")
+		if err == nil && resp.StatusCode() != http.StatusOK {
+			err = fmt.Errorf("aggregator returned HTTP %d: %s", resp.StatusCode(), resp.String())
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", endpoint.url, err))
+			continue
+		}
+		ac.logger.WithFields(logrus.Fields{"aggregator": endpoint.url}).Debug("Sent heartbeat")
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendHeartbeatReachesEveryAggregator(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	received := make(chan heartbeatPayload, 2)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var heartbeat heartbeatPayload
+		if r.URL.Path != "/heartbeat" || r.Header.Get(OperatorSignatureHeader) == "" || json.NewDecoder(r.Body).Decode(&heartbeat) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- heartbeat
+	})
+	primary := httptest.NewServer(handler)
+	defer primary.Close()
+	secondary := httptest.NewServer(handler)
+	defer secondary.Close()
+
+	coord := newTestSubmitter(t, primary.URL, secondary.URL)
+	coord.now = func() time.Time { return now }
+	if err := coord.sendHeartbeat(); err != nil {
+		t.Fatalf("sendHeartbeat: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if heartbeat := <-received; heartbeat.Timestamp != now.Unix() {
+			t.Fatalf("heartbeat timestamp = %d, want %d", heartbeat.Timestamp, now.Unix())
+		}
+	}
+
+	// A failing aggregator is reported without stopping heartbeats to the others
+	down, _ := newTestAggregatorServer(t, http.StatusOK)
+	coord = newTestSubmitter(t, down.URL, primary.URL)
+	if err := coord.sendHeartbeat(); err == nil {
+		t.Fatal("expected an error for the aggregator rejecting heartbeats")
+	}
+	if len(received) != 1 {
+		t.Fatalf("%d heartbeats received, want the healthy aggregator to get one", len(received))
+	}
+}
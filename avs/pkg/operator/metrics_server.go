@@ -0,0 +1,58 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// serveMetrics serves the operator metrics on addr until ctx is cancelled
+func (o *Operator) serveMetrics(ctx context.Context, addr string) {
+	o.serveHTTP(ctx, addr, o.metricsHandler(), "metrics")
+}
+
+// metricsHandler serves GetMetrics as JSON on /metrics and the Prometheus
+// metrics on /metrics/prometheus
+func (o *Operator) metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", o.handleMetrics)
+	if o.metrics != nil {
+		mux.Handle("/metrics/prometheus", o.metrics.handler())
+	}
+	return mux
+}
+
+// serveHTTP runs an HTTP server for handler on addr until ctx is cancelled
+func (o *Operator) serveHTTP(ctx context.Context, addr string, handler http.Handler, name string) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	o.logger.WithFields(logrus.Fields{"addr": addr, "server": name}).Info("Serving operator HTTP API")
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		o.logger.WithError(err).WithField("server", name).Error("HTTP server error")
+	}
+}
+
+// handleMetrics writes GetMetrics as a JSON object
+func (o *Operator) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(o.GetMetrics())
+}
@@ -0,0 +1,119 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestMetricsReportUptimeAndThroughput(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["auction-a"] = &types.Auction{ID: "auction-a", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.auctions["auction-b"] = &types.Auction{ID: "auction-b", PoolID: testPoolID, BlockNumber: 2, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	op.startTime = time.Now().Add(-90 * time.Second)
+
+	deadline := time.Now().Add(time.Minute)
+	op.processTask(&types.Task{ID: 1, AuctionID: "auction-a", Deadline: deadline})
+	op.processTask(&types.Task{ID: 2, AuctionID: "auction-b", Deadline: deadline})
+
+	// Validation fails for an auction without a correctly revealed bid
+	mismatched := newRevealedBid(testBidder, 100, common.HexToHash("0x01"))
+	mismatched.Salt = common.HexToHash("0x02").Hex()
+	coord.bids["auction-c"] = []types.Bid{mismatched}
+	coord.auctions["auction-c"] = &types.Auction{ID: "auction-c", PoolID: testPoolID, BlockNumber: 3, State: types.AuctionBiddingOpen}
+	op.processTask(&types.Task{ID: 3, AuctionID: "auction-c", Deadline: deadline})
+
+	recorder := httptest.NewRecorder()
+	op.handleMetrics(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var metrics struct {
+		Uptime         string `json:"uptime"`
+		UptimeSeconds  int64  `json:"uptime_seconds"`
+		TasksProcessed uint64 `json:"tasks_processed"`
+		TasksFailed    uint64 `json:"tasks_failed"`
+		LastTaskTime   string `json:"last_task_time"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&metrics); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if metrics.UptimeSeconds < 90 || metrics.Uptime == "0s" {
+		t.Fatalf("uptime = %s (%ds), want at least 90s", metrics.Uptime, metrics.UptimeSeconds)
+	}
+	if metrics.TasksProcessed != 2 || metrics.TasksFailed != 1 {
+		t.Fatalf("tasks_processed = %d, tasks_failed = %d, want 2 and 1", metrics.TasksProcessed, metrics.TasksFailed)
+	}
+	lastTaskTime, err := time.Parse(time.RFC3339, metrics.LastTaskTime)
+	if err != nil || time.Since(lastTaskTime) > time.Minute {
+		t.Fatalf("unexpected last_task_time %q: %v", metrics.LastTaskTime, err)
+	}
+}
+
+func TestMetricsBeforeStart(t *testing.T) {
+	op := newTestOperator(t, newFakeCoordinator())
+	metrics := op.GetMetrics()
+	if metrics["uptime_seconds"] != int64(0) || metrics["last_task_time"] != "" {
+		t.Fatalf("unexpected metrics before start: %v", metrics)
+	}
+}
+
+func TestPrometheusMetricsCountTasks(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["auction-a"] = &types.Auction{ID: "auction-a", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.auctions["auction-b"] = &types.Auction{ID: "auction-b", PoolID: testPoolID, BlockNumber: 2, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	op.metrics = newOperatorMetrics()
+	op.priceMonitor.metrics = op.metrics
+
+	deadline := time.Now().Add(time.Minute)
+	op.processTask(&types.Task{ID: 1, AuctionID: "auction-a", Deadline: deadline})
+	coord.submitErr = errors.New("aggregator unavailable")
+	op.processTask(&types.Task{ID: 2, AuctionID: "auction-b", Deadline: deadline})
+
+	var requests atomic.Int32
+	feed := newFlakyPriceFeed(t, http.StatusBadRequest, 1, &requests)
+	op.priceMonitor.updatePrices(context.Background(), feed)
+
+	server := httptest.NewServer(op.metricsHandler())
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/metrics/prometheus")
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read scrape: %v", err)
+	}
+
+	for _, sample := range []string{
+		"lvr_operator_tasks_seen_total 2",
+		`lvr_operator_tasks_processed_total{outcome="submitted"} 1`,
+		`lvr_operator_tasks_processed_total{outcome="failed"} 1`,
+		"lvr_operator_responses_submitted_total 1",
+		"lvr_operator_submit_errors_total 1",
+		`lvr_operator_price_fetch_errors_total{feed="flaky"} 1`,
+		`lvr_operator_auction_discrepancy_bps{pool_id="` + testPoolID + `"} 100000`,
+	} {
+		if !strings.Contains(string(body), sample) {
+			t.Errorf("scrape is missing %q", sample)
+		}
+	}
+}
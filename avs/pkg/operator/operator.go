@@ -3,29 +3,73 @@ package operator
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
+	"fmt"
 	"math/big"
+	"os"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
 
+	"github.com/lvr-auction-hook/avs/pkg/nonce"
 	"github.com/lvr-auction-hook/avs/pkg/types"
 )
 
 // Operator handles AVS operations for LVR auction validation
 type Operator struct {
-	config        *types.OperatorConfig
-	privateKey    *ecdsa.PrivateKey
-	address       common.Address
-	client        *ethclient.Client
-	priceMonitor  *PriceMonitor
-	auctionCoord  *AuctionCoordinator
-	logger        *logrus.Logger
-	ctx           context.Context
-	cancel        context.CancelFunc
+	config       *types.OperatorConfig
+	privateKey   *ecdsa.PrivateKey
+	address      common.Address
+	client       *ethclient.Client
+	priceMonitor *PriceMonitor
+	pools        *PoolRegistry
+	allowedPools map[common.Hash]bool
+	auctionCoord auctionCoordinator
+	dedup        *auctionDeduplicator
+	elector      *standbyElector
+	settlement   settlementSimulator
+	stake        *stakeReader
+	nonces       *nonce.Manager
+	txs          *txTracker
+	decisions    *decisionExporter
+	metrics      *operatorMetrics
+	logger       *logrus.Logger
+
+	// skippedTasks counts tasks skipped without a response, by reason
+	skippedTasks map[string]uint64
+	// disqualifiedBids counts winning bids rejected by settlement simulation
+	disqualifiedBids uint64
+	// selfBids and blocklistedBids count revealed bids excluded by the bid policy
+	selfBids        uint64
+	blocklistedBids uint64
+	// tasksProcessed and tasksFailed count tasks responded to and tasks that
+	// failed validation or submission; lastTaskTime is when the last one finished
+	tasksProcessed uint64
+	tasksFailed    uint64
+	lastTaskTime   time.Time
+	startTime      time.Time
+	metricsMux     sync.Mutex
+
+	// inFlight tracks queued and processing tasks, and inFlightTasks when each was
+	// dispatched. Stop waits up to shutdownTimeout for them to finish.
+	inFlight        sync.WaitGroup
+	inFlightTasks   map[uint32]time.Time
+	inFlightMux     sync.Mutex
+	shutdownTimeout time.Duration
+	// taskQueue holds dispatched tasks ordered by deadline, drained by up to
+	// performance.max_concurrent_tasks workers; taskWorkers is the number running
+	taskQueue   []*types.Task
+	taskWorkers int
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewOperator creates a new operator instance
@@ -33,10 +77,10 @@ func NewOperator(config *types.OperatorConfig) (*Operator, error) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
 
-	// Parse private key
-	privateKey, err := crypto.HexToECDSA(config.PrivateKey)
+	// Load the private key from its configured source
+	privateKey, err := loadPrivateKey(config)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to load private key: %w", err)
 	}
 
 	// Get public key and address
@@ -47,6 +91,12 @@ func NewOperator(config *types.OperatorConfig) (*Operator, error) {
 	}
 	address := crypto.PubkeyToAddress(*publicKeyECDSA)
 
+	// Task responses are BLS-signed so the aggregator can attest to consensus
+	blsKeyPair, err := bls.ReadPrivateKeyFromFile(config.BLSKeyStorePath, os.Getenv(BLSKeyPasswordEnv))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load BLS key: %w", err)
+	}
+
 	// Connect to Ethereum client
 	client, err := ethclient.Dial(config.NetworkConfig.RPCURL)
 	if err != nil {
@@ -55,30 +105,106 @@ func NewOperator(config *types.OperatorConfig) (*Operator, error) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// Initialize the pool registry resolving task pool IDs to token pairs
+	pools, err := NewPoolRegistry(config.Pools, config.NetworkConfig.ContractAddresses.Get(types.ContractLVRHook), logger)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Initialize the token registry, naming every pair's tokens by address
+	tokens, err := NewTokenRegistry(config.Tokens, client)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	priceFeeds, err := tokens.resolveFeeds(config.PriceFeeds)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
 	// Initialize price monitor
-	priceMonitor, err := NewPriceMonitor(config.PriceFeeds, logger)
+	priceMonitor, err := NewPriceMonitor(priceFeeds, config.PriceAlerts, config.PriceAggregation, pools, client, logger)
 	if err != nil {
 		cancel()
 		return nil, err
 	}
+	priceMonitor.tokens = tokens
+	metrics := newOperatorMetrics()
+	priceMonitor.metrics = metrics
 
 	// Initialize auction coordinator
-	auctionCoord, err := NewAuctionCoordinator(address, client, logger)
+	auctionCoord, err := NewAuctionCoordinator(config, privateKey, blsKeyPair, client, logger)
 	if err != nil {
 		cancel()
 		return nil, err
 	}
 
+	// Initialize active/standby election
+	var elector *standbyElector
+	if config.Standby.Enabled {
+		instanceID := config.Standby.InstanceID
+		if instanceID == "" {
+			instanceID, _ = os.Hostname()
+		}
+		store := NewFileLeaseStore(config.Standby.LeasePath)
+		elector = newStandbyElector(store, instanceID, time.Duration(config.Standby.LeaseTTL)*time.Second, logger)
+	}
+
+	// Initialize pre-sign settlement simulation
+	settlement, err := newSettlementSimulator(config, client)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Initialize the stake reader
+	stake, err := newStakeReader(client, config.NetworkConfig.ContractAddresses.Address(types.ContractServiceManager), address)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Initialize decision export
+	var decisions *decisionExporter
+	if config.DecisionExport.Enabled {
+		sink, err := NewDecisionSink(config.DecisionExport)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		decisions = newDecisionExporter(sink, config.DecisionExport, logger)
+	}
+
+	shutdownTimeout := time.Duration(config.ShutdownTimeout) * time.Second
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+
 	operator := &Operator{
-		config:       config,
-		privateKey:   privateKey,
-		address:      address,
-		client:       client,
-		priceMonitor: priceMonitor,
-		auctionCoord: auctionCoord,
-		logger:       logger,
-		ctx:          ctx,
-		cancel:       cancel,
+		config:          config,
+		privateKey:      privateKey,
+		address:         address,
+		client:          client,
+		priceMonitor:    priceMonitor,
+		pools:           pools,
+		allowedPools:    newPoolAllowlist(config.AllowedPools),
+		auctionCoord:    auctionCoord,
+		dedup:           newAuctionDeduplicator(time.Duration(config.DuplicateAuctionWindow) * time.Second),
+		elector:         elector,
+		settlement:      settlement,
+		stake:           stake,
+		nonces:          nonce.NewManager(client, address),
+		txs:             newTxTracker(client, config.NetworkConfig.BlockConfirmations, metrics, logger),
+		decisions:       decisions,
+		metrics:         metrics,
+		skippedTasks:    make(map[string]uint64),
+		inFlightTasks:   make(map[uint32]time.Time),
+		shutdownTimeout: shutdownTimeout,
+		logger:          logger,
+		ctx:             ctx,
+		cancel:          cancel,
 	}
 
 	return operator, nil
@@ -88,12 +214,54 @@ func NewOperator(config *types.OperatorConfig) (*Operator, error) {
 func (o *Operator) Start() error {
 	o.logger.Info("Starting LVR Auction Hook Operator...")
 
+	o.metricsMux.Lock()
+	o.startTime = time.Now()
+	o.metricsMux.Unlock()
+
 	// Start price monitoring
-	go o.priceMonitor.Start(o.ctx)
+	o.priceMonitor.Start(o.ctx)
 
 	// Start auction coordination
 	go o.auctionCoord.Start(o.ctx)
 
+	// Start pool discovery
+	if o.config.PoolDiscovery.Enabled {
+		poolManager := o.config.PoolDiscovery.PoolManager
+		if poolManager == "" {
+			poolManager = o.config.NetworkConfig.ContractAddresses.Get(types.ContractPoolManager)
+		}
+		if !common.IsHexAddress(poolManager) {
+			return fmt.Errorf("invalid pool manager address %q", poolManager)
+		}
+		go o.pools.Watch(o.ctx, o.client, common.HexToAddress(poolManager), o.config.PoolDiscovery.FromBlock)
+	}
+
+	// Keep the operator's stake fresh
+	go o.stake.run(o.ctx, o.logger)
+
+	// Follow sent transactions until they are confirmed
+	go o.txs.run(o.ctx)
+
+	// Start active/standby election
+	if o.elector != nil {
+		go o.elector.Run(o.ctx)
+	}
+
+	// Start decision export
+	if o.decisions != nil {
+		o.decisions.Start(o.ctx)
+	}
+
+	// Serve metrics
+	if o.config.MetricsPort > 0 {
+		go o.serveMetrics(o.ctx, fmt.Sprintf(":%d", o.config.MetricsPort))
+	}
+
+	// Serve cached prices
+	if addr := o.priceServerAddress(); addr != "" {
+		go o.serveHTTP(o.ctx, addr, o.priceHandler(), "prices")
+	}
+
 	// Main operator loop
 	go o.run()
 
@@ -101,28 +269,98 @@ func (o *Operator) Start() error {
 	return nil
 }
 
-// Stop gracefully shuts down the operator
+// Stop gracefully shuts down the operator. No new tasks are started once it is
+// called, and it waits up to the shutdown timeout for in-flight tasks to finish.
 func (o *Operator) Stop() error {
 	o.logger.Info("Stopping operator...")
 	o.cancel()
-	
-	// Wait for goroutines to finish
-	time.Sleep(2 * time.Second)
-	
+
+	// Taking the lock ensures no task is started after the wait begins
+	o.inFlightMux.Lock()
+	o.inFlightMux.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		o.inFlight.Wait()
+		close(done)
+	}()
+
+	// In-flight tasks and price feeds share the shutdown timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), o.shutdownTimeout)
+	defer cancel()
+
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		o.inFlightMux.Lock()
+		abandoned := make([]uint32, 0, len(o.inFlightTasks))
+		for taskID := range o.inFlightTasks {
+			abandoned = append(abandoned, taskID)
+		}
+		o.inFlightMux.Unlock()
+		sort.Slice(abandoned, func(i, j int) bool { return abandoned[i] < abandoned[j] })
+
+		o.logger.WithFields(logrus.Fields{
+			"timeout":  o.shutdownTimeout,
+			"task_ids": abandoned,
+		}).Warn("Shutdown timeout elapsed, abandoning in-flight tasks")
+	}
+
+	select {
+	case <-o.priceMonitor.Stopped():
+	case <-shutdownCtx.Done():
+		o.logger.Warn("Shutdown timeout elapsed before price feeds stopped")
+	}
+
+	if o.decisions != nil {
+		select {
+		case <-o.decisions.Stopped():
+		case <-shutdownCtx.Done():
+			o.logger.Warn("Shutdown timeout elapsed before decision records were flushed")
+		}
+	}
+
 	o.logger.Info("Operator stopped")
 	return nil
 }
 
-// run is the main operator loop
+// BLSKeyPasswordEnv is the environment variable holding the BLS keystore password
+const BLSKeyPasswordEnv = "OPERATOR_BLS_KEY_PASSWORD"
+
+// defaultShutdownTimeout bounds how long Stop waits for in-flight tasks
+const defaultShutdownTimeout = 30 * time.Second
+
+const (
+	// defaultTaskPollInterval is how often pending tasks are polled when no
+	// subscription delivers them
+	defaultTaskPollInterval = time.Second
+	// defaultMaxConcurrentTasks is how many tasks are processed at once by default
+	defaultMaxConcurrentTasks = 8
+	// defaultMinDiscrepancyBps is the price discrepancy, 0.5%, an auction's pool
+	// must exceed by default to be an LVR opportunity
+	defaultMinDiscrepancyBps = 50
+)
+
+// run is the main operator loop. Tasks are processed as the coordinator's
+// subscription delivers them, and polled for only while it is down.
 func (o *Operator) run() {
-	ticker := time.NewTicker(1 * time.Second)
+	interval := time.Duration(o.config.TaskPollInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultTaskPollInterval
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-o.ctx.Done():
 			return
+		case task := <-o.auctionCoord.NewTasks():
+			o.dispatchTasks([]*types.Task{task})
 		case <-ticker.C:
+			if o.auctionCoord.Subscribed() {
+				continue
+			}
 			o.processTasks()
 		}
 	}
@@ -137,32 +375,178 @@ func (o *Operator) processTasks() {
 		return
 	}
 
+	o.dispatchTasks(tasks)
+}
+
+// dispatchTasks queues the tasks whose deadline has not passed for processing
+func (o *Operator) dispatchTasks(tasks []*types.Task) {
+	// A standby instance keeps its state warm but leaves task processing to the active one
+	if !o.isActive() {
+		return
+	}
+
+	// Deadlines are judged by block timestamps, which all operators agree on
+	chainTime, err := o.auctionCoord.ChainTime()
+	if err != nil {
+		o.logger.WithError(err).Error("Failed to get chain time")
+		return
+	}
+
 	for _, task := range tasks {
-		if task.Deadline.Before(time.Now()) {
-			o.logger.WithField("task_id", task.ID).Warn("Task deadline passed, skipping")
+		if !taskOpen(task, chainTime) {
+			o.logger.WithFields(logrus.Fields{
+				"task_id":    task.ID,
+				"deadline":   task.Deadline.Format(time.RFC3339),
+				"chain_time": chainTime.Format(time.RFC3339),
+			}).Warn("Task deadline passed, skipping")
 			continue
 		}
 
-		// Process the task
-		go o.processTask(task)
+		// Queue the task, unless it is still being processed or the operator is stopping
+		if !o.beginTask(task.ID) {
+			continue
+		}
+		o.queueTask(task)
+	}
+}
+
+// queueTask inserts a task into the queue by deadline, starting a worker when
+// fewer than performance.max_concurrent_tasks are running
+func (o *Operator) queueTask(task *types.Task) {
+	o.inFlightMux.Lock()
+	defer o.inFlightMux.Unlock()
+
+	i := sort.Search(len(o.taskQueue), func(i int) bool {
+		return task.Deadline.Before(o.taskQueue[i].Deadline)
+	})
+	o.taskQueue = append(o.taskQueue, nil)
+	copy(o.taskQueue[i+1:], o.taskQueue[i:])
+	o.taskQueue[i] = task
+
+	if o.taskWorkers < o.maxConcurrentTasks() {
+		o.taskWorkers++
+		go o.runTaskWorker()
+	}
+}
+
+// runTaskWorker processes queued tasks, most urgent first, until the queue is
+// empty. Tasks still queued once the operator is stopping are dropped.
+func (o *Operator) runTaskWorker() {
+	for {
+		o.inFlightMux.Lock()
+		if len(o.taskQueue) == 0 {
+			o.taskWorkers--
+			o.inFlightMux.Unlock()
+			return
+		}
+		task := o.taskQueue[0]
+		o.taskQueue = o.taskQueue[1:]
+		o.inFlightMux.Unlock()
+
+		if o.ctx.Err() == nil {
+			o.processTask(task)
+		}
+		o.endTask(task.ID)
+	}
+}
+
+// maxConcurrentTasks returns how many tasks may be processed at once
+func (o *Operator) maxConcurrentTasks() int {
+	if o.config.Performance.MaxConcurrentTasks > 0 {
+		return o.config.Performance.MaxConcurrentTasks
+	}
+	return defaultMaxConcurrentTasks
+}
+
+// beginTask registers a task as in flight. It reports false if the task is
+// already in flight or the operator is stopping.
+func (o *Operator) beginTask(taskID uint32) bool {
+	o.inFlightMux.Lock()
+	defer o.inFlightMux.Unlock()
+
+	if o.ctx.Err() != nil {
+		return false
+	}
+	if _, exists := o.inFlightTasks[taskID]; exists {
+		return false
 	}
+	o.inFlightTasks[taskID] = time.Now()
+	o.inFlight.Add(1)
+	return true
+}
+
+// endTask marks an in-flight task as finished
+func (o *Operator) endTask(taskID uint32) {
+	o.inFlightMux.Lock()
+	delete(o.inFlightTasks, taskID)
+	o.inFlightMux.Unlock()
+	o.inFlight.Done()
 }
 
 // processTask processes a single auction task
 func (o *Operator) processTask(task *types.Task) {
 	o.logger.WithField("task_id", task.ID).Info("Processing auction task")
+	o.metrics.observeTaskSeen()
+
+	// Tasks for pools outside the allowlist cannot be priced
+	if !o.poolAllowed(task.PoolID) {
+		o.skipTask(task, skipReasonPoolNotAllowed)
+		o.recordDecision(task, nil, decisionSkipped, skipReasonPoolNotAllowed, "", nil)
+		return
+	}
+
+	// An auction id not derived from the task's pool and block may be spoofed
+	if o.config.VerifyAuctionIDs {
+		if err := task.VerifyAuctionID(); err != nil {
+			o.logger.WithError(err).WithField("task_id", task.ID).Warn("Rejecting task with an underived auction id")
+			o.skipTask(task, skipReasonInvalidAuctionID)
+			o.recordDecision(task, nil, decisionSkipped, skipReasonInvalidAuctionID, "", nil)
+			return
+		}
+	}
 
 	// Get auction details
 	auction, err := o.auctionCoord.GetAuction(task.AuctionID)
 	if err != nil {
 		o.logger.WithError(err).WithField("auction_id", task.AuctionID).Error("Failed to get auction")
+		o.skipTask(task, skipReasonUnknownAuction)
+		o.recordDecision(task, nil, decisionSkipped, skipReasonUnknownAuction, "", nil)
 		return
 	}
 
-	// Validate auction and determine winner
-	winner, winningBid, err := o.validateAuction(auction)
+	// Cancelled auctions can't settle, so no response is given even when inactive
+	// auctions are allowed
+	if auction.State == types.AuctionCancelled {
+		o.skipTask(task, skipReasonCancelledAuction)
+		o.recordDecision(task, auction, decisionSkipped, skipReasonCancelledAuction, "", nil)
+		return
+	}
+
+	// Only respond about auctions that match the task and are still open
+	if !o.config.AllowInactiveAuctions {
+		if reason := checkAuctionForTask(task, auction); reason != "" {
+			o.skipTask(task, reason)
+			o.recordDecision(task, auction, decisionSkipped, reason, "", nil)
+			return
+		}
+	}
+
+	// Validate auction and determine winner, reusing the result for duplicate
+	// tasks that reference the same pool and block
+	key := auctionKey{poolID: auction.PoolID, blockNumber: auction.BlockNumber}
+	winner, winningBid, err := o.dedup.Do(key, func() (string, *big.Int, error) {
+		return o.validateAuction(auction)
+	})
+	var abstain *abstainError
+	if errors.As(err, &abstain) {
+		o.logger.WithError(err).WithField("auction_id", auction.ID).Warn("Abstaining from auction task")
+		o.skipTask(task, abstain.reason)
+		o.recordDecision(task, auction, decisionSkipped, abstain.reason, "", nil)
+		return
+	}
 	if err != nil {
 		o.logger.WithError(err).WithField("auction_id", auction.ID).Error("Failed to validate auction")
+		o.recordDecision(task, auction, decisionFailed, err.Error(), "", nil)
 		return
 	}
 
@@ -173,11 +557,50 @@ func (o *Operator) processTask(task *types.Task) {
 		Winner:     winner,
 		WinningBid: winningBid,
 		Timestamp:  time.Now(),
+		Confidence: o.responseConfidence(auction.PoolID),
+	}
+
+	// Don't submit once the deadline has passed while validating
+	if chainTime, err := o.auctionCoord.ChainTime(); err == nil && !taskOpen(task, chainTime) {
+		o.logger.WithFields(logrus.Fields{
+			"task_id":    task.ID,
+			"deadline":   task.Deadline.Format(time.RFC3339),
+			"chain_time": chainTime.Format(time.RFC3339),
+		}).Warn("Task deadline passed during validation, dropping response")
+		o.recordDecision(task, auction, decisionSkipped, "deadline_passed", winner, winningBid)
+		return
+	}
+
+	// Nor once the auction was cancelled while validating
+	if current, err := o.auctionCoord.GetAuction(task.AuctionID); err == nil && current.State == types.AuctionCancelled {
+		o.logger.WithField("task_id", task.ID).Warn("Auction cancelled during validation, dropping response")
+		o.skipTask(task, skipReasonCancelledAuction)
+		o.recordDecision(task, auction, decisionSkipped, skipReasonCancelledAuction, winner, winningBid)
+		return
+	}
+
+	// Re-check the lease right before submitting so a demoted instance never overlaps
+	if !o.isActive() {
+		o.logger.WithField("task_id", task.ID).Warn("Lost active lease, dropping task response")
+		return
+	}
+
+	if o.config.DryRun {
+		o.logger.WithFields(logrus.Fields{
+			"task_id":     task.ID,
+			"auction_id":  auction.ID,
+			"winner":      winner,
+			"winning_bid": winningBid.String(),
+		}).Info("Dry run: skipping task response submission")
+		o.recordDecision(task, auction, decisionDryRun, "", winner, winningBid)
+		return
 	}
 
 	err = o.auctionCoord.SubmitTaskResponse(task.ID, response)
+	o.metrics.observeSubmission(err)
 	if err != nil {
 		o.logger.WithError(err).WithField("task_id", task.ID).Error("Failed to submit task response")
+		o.recordDecision(task, auction, decisionFailed, err.Error(), winner, winningBid)
 		return
 	}
 
@@ -187,29 +610,216 @@ func (o *Operator) processTask(task *types.Task) {
 		"winner":      winner,
 		"winning_bid": winningBid.String(),
 	}).Info("Task response submitted successfully")
+
+	outcome := decisionSubmitted
+	if winner == "" {
+		outcome = decisionNoWinner
+	}
+	o.recordDecision(task, auction, outcome, "", winner, winningBid)
+}
+
+// recordDecision counts the outcome of a task and exports the operator's decision
+// when decision export is enabled
+func (o *Operator) recordDecision(task *types.Task, auction *types.Auction, outcome, reason, winner string, winningBid *big.Int) {
+	o.countTask(outcome)
+	o.metrics.observeTaskProcessed(outcome)
+
+	if o.decisions == nil {
+		return
+	}
+
+	record := DecisionRecord{
+		Timestamp: time.Now().UTC(),
+		Operator:  o.address.Hex(),
+		TaskID:    task.ID,
+		AuctionID: task.AuctionID,
+		PoolID:    task.PoolID,
+		Winner:    winner,
+		Outcome:   outcome,
+		Reason:    reason,
+	}
+	if winningBid != nil {
+		record.WinningBid = winningBid.String()
+	}
+
+	if auction != nil {
+		record.PoolID = auction.PoolID
+		record.BlockNumber = auction.BlockNumber
+		if priceData, err := o.priceMonitor.GetPriceData(auction.PoolID); err == nil {
+			record.Price = priceData.Price.String()
+			if priceData.Discrepancy != nil {
+				record.Discrepancy = priceData.Discrepancy.String()
+			}
+		}
+	}
+
+	o.decisions.Record(record)
+}
+
+// Reasons a task is skipped without a response
+const (
+	skipReasonUnknownAuction    = "unknown_auction"
+	skipReasonMismatchedAuction = "mismatched_auction"
+	skipReasonInactiveAuction   = "inactive_auction"
+	skipReasonNoPrice           = "no_price"
+	skipReasonStalePrice        = "stale_price"
+	skipReasonPoolNotAllowed    = "pool_not_allowed"
+	skipReasonInvalidAuctionID  = "invalid_auction_id"
+	skipReasonAnomalousPrice    = "anomalous_price"
+	skipReasonCancelledAuction  = "cancelled_auction"
+	skipReasonNoSimulation      = "no_simulation"
+)
+
+// abstainError is returned by validateAuction when the operator lacks the data
+// to judge an auction, so it skips the task instead of counting a failure
+type abstainError struct {
+	reason string
+	err    error
+}
+
+func (e *abstainError) Error() string { return e.err.Error() }
+func (e *abstainError) Unwrap() error { return e.err }
+
+// checkAuctionForTask returns a skip reason if auction is not a valid subject for
+// task, or an empty string if the task can be processed
+func checkAuctionForTask(task *types.Task, auction *types.Auction) string {
+	if auction == nil {
+		return skipReasonUnknownAuction
+	}
+	if auction.ID != task.AuctionID || (task.PoolID != "" && auction.PoolID != task.PoolID) {
+		return skipReasonMismatchedAuction
+	}
+	if !auction.Live() {
+		return skipReasonInactiveAuction
+	}
+	return ""
+}
+
+// newPoolAllowlist returns the set of allowed pool IDs, or nil to allow every pool
+func newPoolAllowlist(poolIDs []string) map[common.Hash]bool {
+	if len(poolIDs) == 0 {
+		return nil
+	}
+	allowed := make(map[common.Hash]bool, len(poolIDs))
+	for _, poolID := range poolIDs {
+		allowed[common.HexToHash(poolID)] = true
+	}
+	return allowed
+}
+
+// poolAllowed reports whether tasks for a pool are processed
+func (o *Operator) poolAllowed(poolID string) bool {
+	return o.allowedPools == nil || o.allowedPools[common.HexToHash(poolID)]
+}
+
+// countTask updates the throughput counters for a task that finished with outcome
+func (o *Operator) countTask(outcome string) {
+	o.metricsMux.Lock()
+	defer o.metricsMux.Unlock()
+
+	switch outcome {
+	case decisionSubmitted, decisionNoWinner, decisionDryRun:
+		o.tasksProcessed++
+	case decisionFailed:
+		o.tasksFailed++
+	default:
+		return
+	}
+	o.lastTaskTime = time.Now()
+}
+
+// skipTask records that a task was skipped without a response
+func (o *Operator) skipTask(task *types.Task, reason string) {
+	o.metricsMux.Lock()
+	o.skippedTasks[reason]++
+	o.metricsMux.Unlock()
+
+	o.logger.WithFields(logrus.Fields{
+		"task_id":    task.ID,
+		"auction_id": task.AuctionID,
+		"pool_id":    task.PoolID,
+		"reason":     reason,
+	}).Warn("Skipping auction task")
+}
+
+// isActive reports whether this instance should process and submit tasks
+func (o *Operator) isActive() bool {
+	return o.elector == nil || o.elector.IsActive()
+}
+
+// minDiscrepancyBps returns the discrepancy a pool's price must exceed for its
+// auctions to be LVR opportunities
+func (o *Operator) minDiscrepancyBps(poolID string) uint64 {
+	if pool, err := o.pools.Lookup(poolID); err == nil && pool.MinDiscrepancyBps > 0 {
+		return pool.MinDiscrepancyBps
+	}
+	if o.config.AuctionConfig.LVRThreshold > 0 {
+		return o.config.AuctionConfig.LVRThreshold
+	}
+	return defaultMinDiscrepancyBps
 }
 
 // validateAuction validates an auction and determines the winner
 func (o *Operator) validateAuction(auction *types.Auction) (string, *big.Int, error) {
 	// Get current price data for the pool
 	priceData, err := o.priceMonitor.GetPriceData(auction.PoolID)
-	if err != nil {
+	switch {
+	case errors.Is(err, ErrPriceNotFound):
+		return "", nil, &abstainError{reason: skipReasonNoPrice, err: err}
+	case errors.Is(err, ErrPriceStale):
+		return "", nil, &abstainError{reason: skipReasonStalePrice, err: err}
+	case errors.Is(err, ErrPriceAnomalous):
+		return "", nil, &abstainError{reason: skipReasonAnomalousPrice, err: err}
+	case err != nil:
 		return "", nil, err
 	}
 
 	// Check if price discrepancy exists (LVR opportunity)
-	if priceData.Discrepancy.Cmp(big.NewInt(50)) < 0 { // 0.5% threshold
+	o.metrics.observeDiscrepancy(auction.PoolID, priceData.Discrepancy)
+	threshold := o.minDiscrepancyBps(auction.PoolID)
+	if priceData.Discrepancy.Cmp(new(big.Int).SetUint64(threshold)) <= 0 {
 		return "", big.NewInt(0), nil // No significant LVR opportunity
 	}
 
-	// Simulate auction winner selection
-	// In a real implementation, this would collect and validate sealed bids
-	winner := "0x1234567890123456789012345678901234567890" // Mock winner
-	winningBid := new(big.Int).Div(priceData.Discrepancy, big.NewInt(1000)) // Mock bid
+	// Collect the sealed bids and keep those whose reveal matches their commitment
+	committed, err := o.auctionCoord.GetBids(auction.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	bids := o.revealedBids(auction, committed)
+	if len(bids) == 0 {
+		return "", nil, fmt.Errorf("auction %s: %w (%d committed)", auction.ID, ErrNoValidBids, len(committed))
+	}
+	if bids = o.eligibleBids(auction, bids); len(bids) == 0 {
+		o.logger.WithField("auction_id", auction.ID).Warn("No bidder is eligible to win, auction has no winner")
+		return "", big.NewInt(0), nil
+	}
+
+	// Only name a winner whose settlement can actually execute
+	bid, ok, err := o.selectSettleableBid(auction, bids)
+	if err != nil {
+		return "", nil, &abstainError{reason: skipReasonNoSimulation, err: err}
+	}
+	if !ok {
+		o.logger.WithField("auction_id", auction.ID).Warn("No bid can settle, auction has no winner")
+		return "", big.NewInt(0), nil
+	}
+	winner, winningBid := bid.Bidder, bid.Amount
+
+	// Settling below the reserve would underpay LPs for the LVR they bear
+	if reserve := o.reservePrice(auction, priceData.Discrepancy); reserve != nil && winningBid.Cmp(reserve) < 0 {
+		o.logger.WithFields(logrus.Fields{
+			"auction_id":    auction.ID,
+			"winning_bid":   winningBid.String(),
+			"reserve_price": reserve.String(),
+		}).Warn("Top bid is below the reserve price, auction has no winner")
+		return "", big.NewInt(0), nil
+	}
 
 	o.logger.WithFields(logrus.Fields{
 		"auction_id":  auction.ID,
 		"discrepancy": priceData.Discrepancy.String(),
+		"valid_bids":  len(bids),
 		"winner":      winner,
 		"winning_bid": winningBid.String(),
 	}).Info("Auction validated")
@@ -219,16 +829,69 @@ func (o *Operator) validateAuction(auction *types.Auction) (string, *big.Int, er
 
 // GetMetrics returns operator metrics
 func (o *Operator) GetMetrics() map[string]interface{} {
-	return map[string]interface{}{
-		"operator_address": o.address.Hex(),
-		"is_running":       o.ctx.Err() == nil,
-		"price_feeds":      len(o.config.PriceFeeds),
-		"uptime":          time.Since(time.Now()).String(), // This would be tracked properly
+	o.metricsMux.Lock()
+	skippedTasks := make(map[string]uint64, len(o.skippedTasks))
+	for reason, count := range o.skippedTasks {
+		skippedTasks[reason] = count
+	}
+	disqualifiedBids := o.disqualifiedBids
+	selfBids, blocklistedBids := o.selfBids, o.blocklistedBids
+	tasksProcessed, tasksFailed := o.tasksProcessed, o.tasksFailed
+	var uptime time.Duration
+	if !o.startTime.IsZero() {
+		uptime = time.Since(o.startTime)
+	}
+	var lastTaskTime string
+	if !o.lastTaskTime.IsZero() {
+		lastTaskTime = o.lastTaskTime.UTC().Format(time.RFC3339)
+	}
+	o.metricsMux.Unlock()
+
+	metrics := map[string]interface{}{
+		"operator_address":  o.address.Hex(),
+		"is_running":        o.ctx.Err() == nil,
+		"price_feeds":       len(o.config.PriceFeeds),
+		"uptime":            uptime.Round(time.Second).String(),
+		"uptime_seconds":    int64(uptime.Seconds()),
+		"tasks_processed":   tasksProcessed,
+		"tasks_failed":      tasksFailed,
+		"last_task_time":    lastTaskTime,
+		"tasks_skipped":     skippedTasks,
+		"bids_disqualified": disqualifiedBids,
+		"bids_excluded": map[string]uint64{
+			bidExclusionSelf:      selfBids,
+			bidExclusionBlocklist: blocklistedBids,
+		},
+		"price_feed_health": o.priceMonitor.FeedHealth(),
+		"aggregator_health": o.auctionCoord.AggregatorHealth(),
 	}
+	for name, value := range o.priceMonitor.GetAlertMetrics() {
+		metrics[name] = value
+	}
+	if o.decisions != nil {
+		for name, value := range o.decisions.Metrics() {
+			metrics[name] = value
+		}
+	}
+	if o.txs != nil {
+		for name, value := range o.txs.Metrics() {
+			metrics[name] = value
+		}
+	}
+	return metrics
 }
 
 // Register registers the operator with the AVS
 func (o *Operator) Register() error {
+	if o.config.DryRun {
+		o.logger.WithFields(logrus.Fields{
+			"operator":        o.address.Hex(),
+			"service_manager": o.config.NetworkConfig.ContractAddresses.Get(types.ContractServiceManager),
+			"stake_amount":    o.config.StakeAmount,
+		}).Info("Dry run: skipping operator registration")
+		return nil
+	}
+
 	o.logger.Info("Registering operator with AVS...")
 
 	// Create transaction options
@@ -237,16 +900,30 @@ func (o *Operator) Register() error {
 		return err
 	}
 
-	// Set gas price and limit
-	gasPrice, err := o.client.SuggestGasPrice(o.ctx)
-	if err != nil {
+	// Stake is sent along with the registration
+	stake := new(big.Int)
+	if o.config.StakeAmount != "" {
+		if _, ok := stake.SetString(o.config.StakeAmount, 10); !ok {
+			return fmt.Errorf("invalid stake_amount %q", o.config.StakeAmount)
+		}
+	}
+	auth.Value = stake
+	// The gas limit is left to be estimated with prepareTransaction against the
+	// registration calldata once the contract method is called; estimating a
+	// plain transfer to the service manager would be meaningless
+	if err := setFees(o.ctx, o.client, o.config.NetworkConfig.FeeStrategy, auth); err != nil {
+		return err
+	}
+	if err := o.nonces.Apply(o.ctx, auth); err != nil {
 		return err
 	}
-	auth.GasPrice = gasPrice
-	auth.GasLimit = 500000
 
 	// Register with service manager
-	// This would call the actual contract method
+	// This would call the actual contract method, resyncing o.nonces if it fails
+	// to send, and track the sent transaction with o.txs.Track(tx, o.address,
+	// txActionRegister). Until it does no transaction takes the reserved nonce,
+	// which would stall every later transaction, so it is handed out again.
+	o.nonces.Resync()
 	o.logger.Info("Operator registration transaction sent")
 
 	return nil
@@ -257,8 +934,8 @@ func (o *Operator) GetAddress() common.Address {
 	return o.address
 }
 
-// GetStake returns the operator's stake amount
+// GetStake returns the operator's stake in the service manager, cached for up
+// to stakeRefreshInterval
 func (o *Operator) GetStake() (*big.Int, error) {
-	// This would query the service manager contract
-	return big.NewInt(32 * 1e18), nil // Mock stake
+	return o.stake.Stake(o.ctx)
 }
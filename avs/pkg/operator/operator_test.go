@@ -0,0 +1,486 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// testPool is the pool registered with test operators, and testPoolID its pool ID
+var (
+	testPool = types.PoolConfig{
+		Currency0:   "0x0987654321098765432109876543210987654321",
+		Currency1:   "0x1234567890123456789012345678901234567890",
+		Fee:         3000,
+		TickSpacing: 60,
+		Hooks:       "0x00000000000000000000000000000000000000ff",
+	}
+	testPoolID = ComputePoolID(
+		common.HexToAddress(testPool.Currency0),
+		common.HexToAddress(testPool.Currency1),
+		testPool.Fee,
+		testPool.TickSpacing,
+		common.HexToAddress(testPool.Hooks),
+	).Hex()
+)
+
+// testBidder is the bidder of the revealed bid placed in auctions without configured bids
+const testBidder = "0x1234567890123456789012345678901234567890"
+
+// newRevealedBid returns a bid revealing its commitment to amount under salt
+func newRevealedBid(bidder string, amount int64, salt common.Hash) types.Bid {
+	return types.Bid{
+		Bidder:     bidder,
+		Amount:     big.NewInt(amount),
+		Commitment: BidCommitment(big.NewInt(amount), salt, common.HexToAddress(bidder)).Hex(),
+		Salt:       salt.Hex(),
+		Revealed:   true,
+	}
+}
+
+// fakeCoordinator is an in-memory auctionCoordinator for tests. Auctions without
+// an entry in bids hold a single revealed bid from testBidder.
+type fakeCoordinator struct {
+	mutex sync.Mutex
+	// chainTime is the latest block's timestamp, the current time when zero
+	chainTime time.Time
+	tasks     []*types.Task
+	auctions  map[string]*types.Auction
+	bids      map[string][]types.Bid
+	responses map[uint32]*types.TaskResponse
+	// newTasks, when set, streams tasks as a live subscription would
+	newTasks chan *types.Task
+	// submitErr, when set, fails every task response submission
+	submitErr error
+}
+
+func newFakeCoordinator() *fakeCoordinator {
+	return &fakeCoordinator{
+		auctions:  make(map[string]*types.Auction),
+		bids:      make(map[string][]types.Bid),
+		responses: make(map[uint32]*types.TaskResponse),
+	}
+}
+
+func (f *fakeCoordinator) Start(ctx context.Context) {}
+
+func (f *fakeCoordinator) GetPendingTasks() ([]*types.Task, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]*types.Task(nil), f.tasks...), nil
+}
+
+func (f *fakeCoordinator) NewTasks() <-chan *types.Task {
+	return f.newTasks
+}
+
+func (f *fakeCoordinator) Subscribed() bool {
+	return f.newTasks != nil
+}
+
+func (f *fakeCoordinator) GetAuction(auctionID string) (*types.Auction, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	auction, exists := f.auctions[auctionID]
+	if !exists {
+		return nil, fmt.Errorf("unknown auction %s", auctionID)
+	}
+	return auction, nil
+}
+
+func (f *fakeCoordinator) GetBids(auctionID string) ([]types.Bid, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	bids, exists := f.bids[auctionID]
+	if !exists {
+		return []types.Bid{newRevealedBid(testBidder, 100, common.HexToHash("0x01"))}, nil
+	}
+	return append([]types.Bid(nil), bids...), nil
+}
+
+func (f *fakeCoordinator) AddBid(auctionID string, bid types.Bid) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	bids, err := placeBid(f.bids[auctionID], bid)
+	if err != nil {
+		return err
+	}
+	f.bids[auctionID] = bids
+	return nil
+}
+
+func (f *fakeCoordinator) AddBidRoot(auctionID string, root string, leaves []types.Bid) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	auction, exists := f.auctions[auctionID]
+	if !exists {
+		return fmt.Errorf("unknown auction %s", auctionID)
+	}
+	bids, err := placeRootBids(f.bids[auctionID], leaves)
+	if err != nil {
+		return err
+	}
+	f.bids[auctionID] = bids
+	auction.BidRoots = append(auction.BidRoots, root)
+	return nil
+}
+
+func (f *fakeCoordinator) ChainTime() (time.Time, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.chainTime.IsZero() {
+		return time.Now(), nil
+	}
+	return f.chainTime, nil
+}
+
+func (f *fakeCoordinator) setChainTime(chainTime time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.chainTime = chainTime
+}
+
+func (f *fakeCoordinator) SubmitTaskResponse(taskID uint32, response *types.TaskResponse) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.submitErr != nil {
+		return f.submitErr
+	}
+	f.responses[taskID] = response
+	return nil
+}
+
+func (f *fakeCoordinator) AggregatorHealth() map[string]FeedHealth { return nil }
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// newTestOperator builds an operator wired to a fake coordinator and a price
+// monitor seeded with a price for the test pool's token pair
+func newTestOperator(t *testing.T, coord *fakeCoordinator) *Operator {
+	t.Helper()
+
+	logger := newTestLogger()
+	pools, err := NewPoolRegistry([]types.PoolConfig{testPool}, "", logger)
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+	pm, err := NewPriceMonitor(nil, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, pools, nil, logger)
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+
+	token0, token1, err := pm.parsePoolID(testPoolID)
+	if err != nil {
+		t.Fatalf("parsePoolID: %v", err)
+	}
+	pm.cache.set(pm.getCacheKey(token0, token1), &types.PriceData{
+		Token0:      token0,
+		Token1:      token1,
+		Price:       big.NewInt(2000e6),
+		Timestamp:   time.Now(),
+		Source:      "test",
+		Discrepancy: big.NewInt(100000),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return &Operator{
+		config:          &types.OperatorConfig{},
+		priceMonitor:    pm,
+		pools:           pools,
+		auctionCoord:    coord,
+		dedup:           newAuctionDeduplicator(time.Minute),
+		skippedTasks:    make(map[string]uint64),
+		inFlightTasks:   make(map[uint32]time.Time),
+		shutdownTimeout: time.Second,
+		logger:          logger,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+func TestAuctionDeduplicatorRunsValidationOnce(t *testing.T) {
+	dedup := newAuctionDeduplicator(time.Minute)
+	key := auctionKey{poolID: "0xpool", blockNumber: 100}
+
+	var calls int
+	var callsMux sync.Mutex
+	validate := func() (string, *big.Int, error) {
+		callsMux.Lock()
+		calls++
+		callsMux.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		return "0xwinner", big.NewInt(42), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			winner, bid, err := dedup.Do(key, validate)
+			if err != nil || winner != "0xwinner" || bid.Int64() != 42 {
+				t.Errorf("unexpected result: %s %v %v", winner, bid, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected validation to run once, ran %d times", calls)
+	}
+
+	// A different block for the same pool is a different auction
+	dedup.Do(auctionKey{poolID: "0xpool", blockNumber: 101}, validate)
+	if calls != 2 {
+		t.Fatalf("expected a new block to trigger validation, ran %d times", calls)
+	}
+}
+
+func TestAuctionDeduplicatorDoesNotCacheErrors(t *testing.T) {
+	dedup := newAuctionDeduplicator(time.Minute)
+	key := auctionKey{poolID: "0xpool", blockNumber: 100}
+
+	var calls int
+	failing := func() (string, *big.Int, error) {
+		calls++
+		return "", nil, fmt.Errorf("no price data")
+	}
+
+	dedup.Do(key, failing)
+	dedup.Do(key, failing)
+	if calls != 2 {
+		t.Fatalf("expected failed validations to be retried, ran %d times", calls)
+	}
+}
+
+func TestProcessTaskReusesResponseForDuplicateAuctions(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["auction-a"] = &types.Auction{ID: "auction-a", PoolID: testPoolID, BlockNumber: 100, State: types.AuctionBiddingOpen}
+	coord.auctions["auction-b"] = &types.Auction{ID: "auction-b", PoolID: testPoolID, BlockNumber: 100, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+
+	op.processTask(&types.Task{ID: 1, AuctionID: "auction-a", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)})
+
+	// Raise the bids so a fresh validation would produce a different winner
+	coord.mutex.Lock()
+	coord.bids["auction-a"] = []types.Bid{newRevealedBid(bidderB, 900, common.HexToHash("0x02"))}
+	coord.bids["auction-b"] = coord.bids["auction-a"]
+	coord.mutex.Unlock()
+
+	op.processTask(&types.Task{ID: 2, AuctionID: "auction-b", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)})
+
+	first, second := coord.responses[1], coord.responses[2]
+	if first == nil || second == nil {
+		t.Fatalf("expected responses for both tasks, got %v and %v", first, second)
+	}
+	if first.Winner != second.Winner || first.WinningBid.Cmp(second.WinningBid) != 0 {
+		t.Fatalf("expected duplicate auctions to share a response: %+v vs %+v", first, second)
+	}
+}
+
+func TestProcessTaskSkipsUnknownOrInactiveAuctions(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["complete"] = &types.Auction{ID: "complete", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionComplete}
+	coord.auctions["other-pool"] = &types.Auction{ID: "other-pool", PoolID: "0xother", BlockNumber: 2, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	deadline := time.Now().Add(time.Minute)
+
+	op.processTask(&types.Task{ID: 1, AuctionID: "complete", PoolID: testPoolID, Deadline: deadline})
+	op.processTask(&types.Task{ID: 2, AuctionID: "missing", PoolID: testPoolID, Deadline: deadline})
+	op.processTask(&types.Task{ID: 3, AuctionID: "other-pool", PoolID: testPoolID, Deadline: deadline})
+
+	if len(coord.responses) != 0 {
+		t.Fatalf("expected no responses, got %d", len(coord.responses))
+	}
+
+	skipped := op.GetMetrics()["tasks_skipped"].(map[string]uint64)
+	want := map[string]uint64{
+		skipReasonInactiveAuction:   1,
+		skipReasonUnknownAuction:    1,
+		skipReasonMismatchedAuction: 1,
+	}
+	for reason, count := range want {
+		if skipped[reason] != count {
+			t.Fatalf("tasks_skipped[%s] = %d, want %d (all: %v)", reason, skipped[reason], count, skipped)
+		}
+	}
+
+	// The check can be disabled by config
+	op.config.AllowInactiveAuctions = true
+	op.processTask(&types.Task{ID: 4, AuctionID: "complete", PoolID: testPoolID, Deadline: deadline})
+	if coord.responses[4] == nil {
+		t.Fatal("expected a response when inactive auctions are allowed")
+	}
+}
+
+func TestProcessTaskSkipsPoolsOutsideAllowlist(t *testing.T) {
+	const otherPoolID = "0x00000000000000000000000000000000000000000000000000000000000000ff"
+	coord := newFakeCoordinator()
+	coord.auctions["allowed"] = &types.Auction{ID: "allowed", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.auctions["unconfigured"] = &types.Auction{ID: "unconfigured", PoolID: otherPoolID, BlockNumber: 2, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	op.allowedPools = newPoolAllowlist([]string{"0x" + strings.ToUpper(testPoolID[2:])})
+	deadline := time.Now().Add(time.Minute)
+
+	op.processTask(&types.Task{ID: 1, AuctionID: "allowed", PoolID: testPoolID, Deadline: deadline})
+	op.processTask(&types.Task{ID: 2, AuctionID: "unconfigured", PoolID: otherPoolID, Deadline: deadline})
+
+	if coord.responses[1] == nil {
+		t.Fatal("expected a response for the allowed pool")
+	}
+	if coord.responses[2] != nil {
+		t.Fatal("expected the task for an unconfigured pool to be skipped")
+	}
+	if skipped := op.GetMetrics()["tasks_skipped"].(map[string]uint64); skipped[skipReasonPoolNotAllowed] != 1 {
+		t.Fatalf("tasks_skipped[%s] = %d, want 1", skipReasonPoolNotAllowed, skipped[skipReasonPoolNotAllowed])
+	}
+}
+
+func TestProcessTaskRejectsUnderivedAuctionIDs(t *testing.T) {
+	derived := types.DeriveAuctionID(common.HexToHash(testPoolID), 7, 0).Hex()
+	coord := newFakeCoordinator()
+	coord.auctions[derived] = &types.Auction{ID: derived, PoolID: testPoolID, BlockNumber: 7, State: types.AuctionBiddingOpen}
+	coord.auctions["spoofed"] = &types.Auction{ID: "spoofed", PoolID: testPoolID, BlockNumber: 7, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	op.config.VerifyAuctionIDs = true
+	deadline := time.Now().Add(time.Minute)
+
+	op.processTask(&types.Task{ID: 1, AuctionID: derived, PoolID: testPoolID, CreatedBlock: 7, Deadline: deadline})
+	op.processTask(&types.Task{ID: 2, AuctionID: "spoofed", PoolID: testPoolID, CreatedBlock: 7, Deadline: deadline})
+	op.processTask(&types.Task{ID: 3, AuctionID: derived, PoolID: testPoolID, CreatedBlock: 8, Deadline: deadline})
+
+	if coord.responses[1] == nil {
+		t.Fatal("expected a response for the task with a derived auction id")
+	}
+	if coord.responses[2] != nil || coord.responses[3] != nil {
+		t.Fatal("expected tasks whose auction id does not match their pool and block to be skipped")
+	}
+	if skipped := op.GetMetrics()["tasks_skipped"].(map[string]uint64); skipped[skipReasonInvalidAuctionID] != 2 {
+		t.Fatalf("tasks_skipped[%s] = %d, want 2", skipReasonInvalidAuctionID, skipped[skipReasonInvalidAuctionID])
+	}
+}
+
+func TestDryRunComputesResponsesWithoutSubmitting(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["open"] = &types.Auction{ID: "open", PoolID: testPoolID, BlockNumber: 7, State: types.AuctionBiddingOpen}
+
+	sink := &mockDecisionSink{}
+	op := newTestOperator(t, coord)
+	op.config.DryRun = true
+	op.decisions = newDecisionExporter(sink, types.DecisionExportConfig{}, op.logger)
+	stop := runExporter(op.decisions)
+
+	op.processTask(&types.Task{ID: 1, AuctionID: "open", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)})
+	stop()
+
+	if len(coord.responses) != 0 {
+		t.Fatalf("expected no submitted responses in dry run, got %d", len(coord.responses))
+	}
+	records := sink.records()
+	if len(records) != 1 || records[0].Outcome != decisionDryRun || records[0].Winner == "" || records[0].WinningBid != "100" {
+		t.Fatalf("expected the dry-run response to still be computed, got %+v", records)
+	}
+	if processed := op.GetMetrics()["tasks_processed"]; processed != uint64(1) {
+		t.Fatalf("tasks_processed = %v, want 1", processed)
+	}
+
+	// Registration never builds a transaction, so no chain client is needed
+	if err := op.Register(); err != nil {
+		t.Fatalf("Register in dry run: %v", err)
+	}
+}
+
+func TestProcessTaskAbstainsWithoutFreshPrice(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["stale"] = &types.Auction{ID: "stale", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.auctions["unpriced"] = &types.Auction{ID: "unpriced", PoolID: testPoolID, BlockNumber: 2, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	token0, token1, _ := op.priceMonitor.parsePoolID(testPoolID)
+	key := op.priceMonitor.getCacheKey(token0, token1)
+	deadline := time.Now().Add(time.Minute)
+
+	op.priceMonitor.cache.lookup(key).IsStale = true
+	op.processTask(&types.Task{ID: 1, AuctionID: "stale", PoolID: testPoolID, Deadline: deadline})
+	op.priceMonitor.cache.remove(key)
+	op.processTask(&types.Task{ID: 2, AuctionID: "unpriced", PoolID: testPoolID, Deadline: deadline})
+
+	if len(coord.responses) != 0 {
+		t.Fatalf("expected no responses without a fresh price, got %d", len(coord.responses))
+	}
+	metrics := op.GetMetrics()
+	skipped := metrics["tasks_skipped"].(map[string]uint64)
+	if skipped[skipReasonStalePrice] != 1 || skipped[skipReasonNoPrice] != 1 {
+		t.Fatalf("tasks_skipped = %v, want one stale_price and one no_price", skipped)
+	}
+	if failed := metrics["tasks_failed"]; failed != uint64(0) {
+		t.Fatalf("tasks_failed = %v, want abstentions not counted as failures", failed)
+	}
+}
+
+func TestValidateAuctionUsesPoolDiscrepancyThreshold(t *testing.T) {
+	op := newTestOperator(t, newFakeCoordinator())
+	op.config.AuctionConfig.LVRThreshold = 100
+
+	// Both pools trade the test pair and see the same 80 bps discrepancy
+	register := func(fee uint32, minDiscrepancyBps uint64) string {
+		t.Helper()
+		info := PoolInfo{
+			Token0:            common.HexToAddress(testPool.Currency0),
+			Token1:            common.HexToAddress(testPool.Currency1),
+			Fee:               fee,
+			TickSpacing:       60,
+			MinDiscrepancyBps: minDiscrepancyBps,
+		}
+		if err := op.pools.Register(info); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+		return ComputePoolID(info.Token0, info.Token1, info.Fee, info.TickSpacing, info.Hooks).Hex()
+	}
+	volatilePool := register(10000, 200)
+	stablePool := register(100, 20)
+	token0, token1, _ := op.priceMonitor.parsePoolID(testPoolID)
+	op.priceMonitor.cache.lookup(op.priceMonitor.getCacheKey(token0, token1)).Discrepancy = big.NewInt(80)
+
+	cases := []struct {
+		name   string
+		poolID string
+		winner string
+	}{
+		{"volatile pool below its threshold", volatilePool, ""},
+		{"stable pool above its threshold", stablePool, testBidder},
+		{"pool below the operator default", testPoolID, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auction := &types.Auction{ID: "auction-" + tc.name, PoolID: tc.poolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+			winner, _, err := op.validateAuction(auction)
+			if err != nil {
+				t.Fatalf("validateAuction: %v", err)
+			}
+			if winner != tc.winner {
+				t.Fatalf("winner = %q, want %q", winner, tc.winner)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// poolManagerABI is the subset of the Uniswap v4 PoolManager ABI used to discover pools
+const poolManagerABI = `[
+	{
+		"type": "event",
+		"name": "Initialize",
+		"anonymous": false,
+		"inputs": [
+			{"name": "id", "type": "bytes32", "indexed": true},
+			{"name": "currency0", "type": "address", "indexed": true},
+			{"name": "currency1", "type": "address", "indexed": true},
+			{"name": "fee", "type": "uint24", "indexed": false},
+			{"name": "tickSpacing", "type": "int24", "indexed": false},
+			{"name": "hooks", "type": "address", "indexed": false},
+			{"name": "sqrtPriceX96", "type": "uint160", "indexed": false},
+			{"name": "tick", "type": "int24", "indexed": false}
+		]
+	}
+]`
+
+// poolDiscoveryInterval is how often the PoolManager is scanned for new pools
+const poolDiscoveryInterval = 30 * time.Second
+
+// ErrUnknownPool is returned when a pool ID has not been registered
+var ErrUnknownPool = errors.New("unknown pool id")
+
+// PoolInfo is a Uniswap v4 PoolKey together with its PoolId
+type PoolInfo struct {
+	ID          common.Hash
+	Token0      common.Address
+	Token1      common.Address
+	Fee         uint32
+	TickSpacing int32
+	Hooks       common.Address
+	// Sources are the price feeds the pool is priced over (empty for all feeds)
+	Sources []string
+	// MinDiscrepancyBps is the pool's LVR opportunity threshold (0 for the default)
+	MinDiscrepancyBps uint64
+	// Reserve and Liquidity set the reserve price of the pool's auctions, in wei
+	// of the settlement token (nil when not configured)
+	Reserve   *big.Int
+	Liquidity *big.Int
+}
+
+// ComputePoolID returns the Uniswap v4 PoolId of a pool, the keccak256 of its
+// ABI encoded PoolKey
+func ComputePoolID(token0, token1 common.Address, fee uint32, tickSpacing int32, hooks common.Address) common.Hash {
+	encoded := make([]byte, 0, 5*32)
+	encoded = append(encoded, common.LeftPadBytes(token0.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(token1.Bytes(), 32)...)
+	encoded = append(encoded, math.U256Bytes(big.NewInt(int64(fee)))...)
+	encoded = append(encoded, math.U256Bytes(big.NewInt(int64(tickSpacing)))...)
+	encoded = append(encoded, common.LeftPadBytes(hooks.Bytes(), 32)...)
+	return crypto.Keccak256Hash(encoded)
+}
+
+// poolEventSource is the chain access needed to discover pools
+type poolEventSource interface {
+	ethereum.LogFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// initializeEvent mirrors the non-indexed fields of the Initialize event
+type initializeEvent struct {
+	Fee          *big.Int
+	TickSpacing  *big.Int
+	Hooks        common.Address
+	SqrtPriceX96 *big.Int
+	Tick         *big.Int
+}
+
+// PoolRegistry maps Uniswap v4 pool IDs to their pool keys. Pools are registered
+// from config and, when discovery runs, from PoolManager Initialize events.
+type PoolRegistry struct {
+	// hooks restricts discovered pools to those using this hook contract (zero for any)
+	hooks       common.Address
+	contractABI abi.ABI
+	logger      *logrus.Logger
+
+	pools     map[common.Hash]PoolInfo
+	lastBlock uint64
+	mutex     sync.RWMutex
+}
+
+// NewPoolRegistry creates a registry holding the configured pools. Pools without
+// hooks set use defaultHooks, which also restricts discovered pools when non-empty.
+func NewPoolRegistry(pools []types.PoolConfig, defaultHooks string, logger *logrus.Logger) (*PoolRegistry, error) {
+	contractABI, err := abi.JSON(strings.NewReader(poolManagerABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pool manager ABI: %w", err)
+	}
+	if defaultHooks != "" && !common.IsHexAddress(defaultHooks) {
+		return nil, fmt.Errorf("invalid hooks address %q", defaultHooks)
+	}
+
+	r := &PoolRegistry{
+		hooks:       common.HexToAddress(defaultHooks),
+		contractABI: contractABI,
+		logger:      logger,
+		pools:       make(map[common.Hash]PoolInfo),
+	}
+
+	for _, pool := range pools {
+		hooks := pool.Hooks
+		if hooks == "" {
+			hooks = defaultHooks
+		}
+		for _, address := range []string{pool.Currency0, pool.Currency1} {
+			if !common.IsHexAddress(address) {
+				return nil, fmt.Errorf("invalid pool currency address %q", address)
+			}
+		}
+		if hooks != "" && !common.IsHexAddress(hooks) {
+			return nil, fmt.Errorf("invalid hooks address %q", hooks)
+		}
+		reserve, err := parseWei(pool.ReserveWei)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool reserve_wei: %w", err)
+		}
+		liquidity, err := parseWei(pool.LiquidityWei)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool liquidity_wei: %w", err)
+		}
+
+		info := PoolInfo{
+			Token0:      common.HexToAddress(pool.Currency0),
+			Token1:      common.HexToAddress(pool.Currency1),
+			Fee:         pool.Fee,
+			TickSpacing: pool.TickSpacing,
+			Hooks:       common.HexToAddress(hooks),
+			Sources:     pool.Sources,
+
+			MinDiscrepancyBps: pool.MinDiscrepancyBps,
+			Reserve:           reserve,
+			Liquidity:         liquidity,
+		}
+		if err := r.Register(info); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Register adds a pool, computing its ID from its key
+func (r *PoolRegistry) Register(info PoolInfo) error {
+	if info.Token0.Big().Cmp(info.Token1.Big()) >= 0 {
+		return fmt.Errorf("pool currencies must be sorted: %s >= %s", info.Token0.Hex(), info.Token1.Hex())
+	}
+	info.ID = ComputePoolID(info.Token0, info.Token1, info.Fee, info.TickSpacing, info.Hooks)
+
+	r.mutex.Lock()
+	r.pools[info.ID] = info
+	r.mutex.Unlock()
+	return nil
+}
+
+// Lookup returns the pool registered under poolID
+func (r *PoolRegistry) Lookup(poolID string) (PoolInfo, error) {
+	if r == nil || len(strings.TrimPrefix(poolID, "0x")) != 2*common.HashLength {
+		return PoolInfo{}, fmt.Errorf("%w: %s", ErrUnknownPool, poolID)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	info, exists := r.pools[common.HexToHash(poolID)]
+	if !exists {
+		return PoolInfo{}, fmt.Errorf("%w: %s", ErrUnknownPool, poolID)
+	}
+	return info, nil
+}
+
+// checkSources verifies that every pool source names one of the given feeds
+func (r *PoolRegistry) checkSources(feeds map[string]bool) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for id, info := range r.pools {
+		for _, source := range info.Sources {
+			if !feeds[source] {
+				return fmt.Errorf("pool %s uses unknown price feed %q", id.Hex(), source)
+			}
+		}
+	}
+	return nil
+}
+
+// Size returns the number of registered pools
+func (r *PoolRegistry) Size() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.pools)
+}
+
+// Watch registers pools from Initialize events emitted by poolManager, starting
+// at fromBlock, until ctx is cancelled
+func (r *PoolRegistry) Watch(ctx context.Context, client poolEventSource, poolManager common.Address, fromBlock uint64) {
+	r.mutex.Lock()
+	if fromBlock > 0 {
+		r.lastBlock = fromBlock - 1
+	}
+	r.mutex.Unlock()
+
+	ticker := time.NewTicker(poolDiscoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.poll(ctx, client, poolManager); err != nil {
+			r.logger.WithError(err).Warn("Failed to discover pools")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll registers pools from Initialize events emitted since the last scanned block
+func (r *PoolRegistry) poll(ctx context.Context, client poolEventSource, poolManager common.Address) error {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.RLock()
+	fromBlock := r.lastBlock + 1
+	r.mutex.RUnlock()
+	if fromBlock > head {
+		return nil
+	}
+
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(head),
+		Addresses: []common.Address{poolManager},
+		Topics:    [][]common.Hash{{r.contractABI.Events["Initialize"].ID}},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, log := range logs {
+		if err := r.registerInitialize(log); err != nil {
+			r.logger.WithError(err).WithField("tx_hash", log.TxHash.Hex()).Warn("Failed to decode Initialize event")
+		}
+	}
+
+	r.mutex.Lock()
+	r.lastBlock = head
+	r.mutex.Unlock()
+
+	return nil
+}
+
+// registerInitialize registers the pool created by an Initialize event
+func (r *PoolRegistry) registerInitialize(log ethtypes.Log) error {
+	if len(log.Topics) < 4 {
+		return fmt.Errorf("expected 4 topics, got %d", len(log.Topics))
+	}
+
+	var event initializeEvent
+	if err := r.contractABI.UnpackIntoInterface(&event, "Initialize", log.Data); err != nil {
+		return err
+	}
+	if r.hooks != (common.Address{}) && event.Hooks != r.hooks {
+		return nil
+	}
+
+	info := PoolInfo{
+		Token0:      common.BytesToAddress(log.Topics[2].Bytes()),
+		Token1:      common.BytesToAddress(log.Topics[3].Bytes()),
+		Fee:         uint32(event.Fee.Uint64()),
+		TickSpacing: int32(event.TickSpacing.Int64()),
+		Hooks:       event.Hooks,
+	}
+	if id := ComputePoolID(info.Token0, info.Token1, info.Fee, info.TickSpacing, info.Hooks); id != log.Topics[1] {
+		return fmt.Errorf("pool id %s does not match its key (computed %s)", log.Topics[1].Hex(), id.Hex())
+	}
+	if err := r.Register(info); err != nil {
+		return err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"pool_id":      log.Topics[1].Hex(),
+		"token0":       info.Token0.Hex(),
+		"token1":       info.Token1.Hex(),
+		"fee":          info.Fee,
+		"tick_spacing": info.TickSpacing,
+	}).Info("Registered pool from Initialize event")
+	return nil
+}
@@ -0,0 +1,182 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// fakePoolEventSource serves a fixed set of logs at a fixed chain height
+type fakePoolEventSource struct {
+	head    uint64
+	logs    []ethtypes.Log
+	queries []ethereum.FilterQuery
+}
+
+func (f *fakePoolEventSource) BlockNumber(ctx context.Context) (uint64, error) {
+	return f.head, nil
+}
+
+func (f *fakePoolEventSource) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]ethtypes.Log, error) {
+	f.queries = append(f.queries, query)
+	return f.logs, nil
+}
+
+func (f *fakePoolEventSource) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- ethtypes.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+// initializeLog builds the Initialize event PoolManager emits for a pool
+func initializeLog(t *testing.T, r *PoolRegistry, id common.Hash, info PoolInfo) ethtypes.Log {
+	t.Helper()
+	event := r.contractABI.Events["Initialize"]
+	data, err := event.Inputs.NonIndexed().Pack(
+		big.NewInt(int64(info.Fee)),
+		big.NewInt(int64(info.TickSpacing)),
+		info.Hooks,
+		new(big.Int).Lsh(big.NewInt(1), 96),
+		big.NewInt(0),
+	)
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	return ethtypes.Log{
+		Topics: []common.Hash{
+			event.ID,
+			id,
+			common.BytesToHash(info.Token0.Bytes()),
+			common.BytesToHash(info.Token1.Bytes()),
+		},
+		Data: data,
+	}
+}
+
+func TestComputePoolIDMatchesABIEncodedPoolKey(t *testing.T) {
+	addressType, _ := abi.NewType("address", "", nil)
+	uint24Type, _ := abi.NewType("uint24", "", nil)
+	int24Type, _ := abi.NewType("int24", "", nil)
+	poolKey := abi.Arguments{{Type: addressType}, {Type: addressType}, {Type: uint24Type}, {Type: int24Type}, {Type: addressType}}
+
+	token0 := common.Address{}
+	token1 := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	hooks := common.HexToAddress("0x00000000000000000000000000000000000000ff")
+
+	for _, tickSpacing := range []int32{60, -1} {
+		encoded, err := poolKey.Pack(token0, token1, big.NewInt(500), big.NewInt(int64(tickSpacing)), hooks)
+		if err != nil {
+			t.Fatalf("Pack: %v", err)
+		}
+		if got, want := ComputePoolID(token0, token1, 500, tickSpacing, hooks), crypto.Keccak256Hash(encoded); got != want {
+			t.Fatalf("tick spacing %d: pool id = %s, want %s", tickSpacing, got.Hex(), want.Hex())
+		}
+	}
+}
+
+func TestPriceMonitorResolvesConfiguredPools(t *testing.T) {
+	pools, err := NewPoolRegistry([]types.PoolConfig{testPool}, "", newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+	pm, err := NewPriceMonitor(nil, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, pools, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+
+	token0, token1, err := pm.parsePoolID(testPoolID)
+	if err != nil {
+		t.Fatalf("parsePoolID: %v", err)
+	}
+	if token0 != common.HexToAddress(testPool.Currency0).Hex() || token1 != common.HexToAddress(testPool.Currency1).Hex() {
+		t.Fatalf("resolved %s/%s, want the configured currencies", token0, token1)
+	}
+
+	// Feeds may configure tokens in a different case than the registry reports
+	pm.updateCache(testPool.Currency0, testPool.Currency1, "test", &types.PriceData{Price: big.NewInt(2000), Timestamp: time.Now()})
+	priceData, err := pm.GetPriceData(testPoolID)
+	if err != nil || priceData.Price.Cmp(big.NewInt(2000)) != 0 {
+		t.Fatalf("GetPriceData = %v, %v; want the cached price", priceData, err)
+	}
+
+	for _, poolID := range []string{"", "0xpool", common.HexToHash("0x01").Hex()} {
+		if _, err := pm.GetPriceData(poolID); !errors.Is(err, ErrUnknownPool) {
+			t.Fatalf("GetPriceData(%q) error = %v, want ErrUnknownPool", poolID, err)
+		}
+	}
+}
+
+func TestNewPoolRegistryRejectsUnsortedCurrencies(t *testing.T) {
+	pool := testPool
+	pool.Currency0, pool.Currency1 = testPool.Currency1, testPool.Currency0
+	if _, err := NewPoolRegistry([]types.PoolConfig{pool}, "", newTestLogger()); err == nil {
+		t.Fatal("expected unsorted pool currencies to be rejected")
+	}
+}
+
+func TestPoolRegistryDiscoversInitializedPools(t *testing.T) {
+	hooks := common.HexToAddress("0x00000000000000000000000000000000000000ff")
+	r, err := NewPoolRegistry(nil, hooks.Hex(), newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+
+	hooked := PoolInfo{
+		Token0:      common.HexToAddress("0x0000000000000000000000000000000000000001"),
+		Token1:      common.HexToAddress("0x0000000000000000000000000000000000000002"),
+		Fee:         3000,
+		TickSpacing: 60,
+		Hooks:       hooks,
+	}
+	hookedID := ComputePoolID(hooked.Token0, hooked.Token1, hooked.Fee, hooked.TickSpacing, hooked.Hooks)
+
+	unhooked := hooked
+	unhooked.Hooks = common.Address{}
+	unhookedID := ComputePoolID(unhooked.Token0, unhooked.Token1, unhooked.Fee, unhooked.TickSpacing, unhooked.Hooks)
+
+	forged := hooked
+	forged.Fee = 500
+
+	source := &fakePoolEventSource{head: 120, logs: []ethtypes.Log{
+		initializeLog(t, r, hookedID, hooked),
+		initializeLog(t, r, unhookedID, unhooked),
+		initializeLog(t, r, common.HexToHash("0xbad"), forged),
+	}}
+	poolManager := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	if err := r.poll(context.Background(), source, poolManager); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	info, err := r.Lookup(hookedID.Hex())
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if info.Token0 != hooked.Token0 || info.Token1 != hooked.Token1 || info.Fee != 3000 || info.TickSpacing != 60 {
+		t.Fatalf("unexpected pool info %+v", info)
+	}
+	if r.Size() != 1 {
+		t.Fatalf("registered %d pools, want only the pool using the hook", r.Size())
+	}
+
+	query := source.queries[0]
+	if query.Addresses[0] != poolManager || query.ToBlock.Uint64() != 120 {
+		t.Fatalf("unexpected log query %+v", query)
+	}
+
+	// The next scan resumes after the last scanned block
+	source.head = 130
+	if err := r.poll(context.Background(), source, poolManager); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if from := source.queries[1].FromBlock.Uint64(); from != 121 {
+		t.Fatalf("second scan from block %d, want 121", from)
+	}
+}
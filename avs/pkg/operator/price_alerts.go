@@ -0,0 +1,195 @@
+package operator
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// defaultPriceAlertCooldown is the minimum time between alerts for the same pair
+const defaultPriceAlertCooldown = 5 * time.Minute
+
+// PriceDeviationAlert is raised when price sources disagree on a pair by more than
+// the configured bound
+type PriceDeviationAlert struct {
+	Pair         string    `json:"pair"`
+	Token0       string    `json:"token0"`
+	Token1       string    `json:"token1"`
+	LowSource    string    `json:"low_source"`
+	LowPrice     *big.Int  `json:"low_price"`
+	HighSource   string    `json:"high_source"`
+	HighPrice    *big.Int  `json:"high_price"`
+	DeviationBps uint64    `json:"deviation_bps"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// deviationMonitor tracks the latest price from each source and alerts when the
+// spread between sources for a pair exceeds the bound. It observes every price
+// update and runs independently of auction processing.
+type deviationMonitor struct {
+	maxDeviationBps uint64
+	cooldown        time.Duration
+	maxAge          time.Duration
+	webhookURL      string
+	client          *resty.Client
+	logger          *logrus.Logger
+	now             func() time.Time
+	notify          func(PriceDeviationAlert)
+
+	prices      map[string]map[string]*types.PriceData
+	lastAlert   map[string]time.Time
+	alertCounts map[string]uint64
+	deviations  map[string]uint64
+	mutex       sync.Mutex
+}
+
+// newDeviationMonitor creates a monitor from config, or returns nil if alerts are disabled
+func newDeviationMonitor(config types.PriceAlertConfig, client *resty.Client, logger *logrus.Logger) *deviationMonitor {
+	if !config.Enabled || config.MaxDeviationBps == 0 {
+		return nil
+	}
+
+	cooldown := time.Duration(config.Cooldown) * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultPriceAlertCooldown
+	}
+
+	m := &deviationMonitor{
+		maxDeviationBps: config.MaxDeviationBps,
+		cooldown:        cooldown,
+		maxAge:          time.Duration(config.MaxPriceAge) * time.Second,
+		webhookURL:      config.WebhookURL,
+		client:          client,
+		logger:          logger,
+		now:             time.Now,
+		prices:          make(map[string]map[string]*types.PriceData),
+		lastAlert:       make(map[string]time.Time),
+		alertCounts:     make(map[string]uint64),
+		deviations:      make(map[string]uint64),
+	}
+	m.notify = m.emit
+	return m
+}
+
+// Observe records a price from source for the pair identified by key and raises
+// an alert if the cross-source deviation exceeds the bound
+func (m *deviationMonitor) Observe(key, source string, priceData *types.PriceData) {
+	if priceData == nil || priceData.Price == nil || priceData.Price.Sign() <= 0 {
+		return
+	}
+
+	m.mutex.Lock()
+	sources, exists := m.prices[key]
+	if !exists {
+		sources = make(map[string]*types.PriceData)
+		m.prices[key] = sources
+	}
+	sources[source] = priceData
+
+	alert, ok := m.evaluate(key, sources)
+	if ok {
+		m.deviations[key] = alert.DeviationBps
+	}
+	if ok && alert.DeviationBps > m.maxDeviationBps && m.now().Sub(m.lastAlert[key]) >= m.cooldown {
+		m.lastAlert[key] = m.now()
+		m.alertCounts[key]++
+	} else {
+		ok = false
+	}
+	m.mutex.Unlock()
+
+	if ok {
+		m.notify(alert)
+	}
+}
+
+// evaluate computes the spread between the lowest and highest fresh source prices,
+// in basis points of the lowest. Callers must hold the mutex.
+func (m *deviationMonitor) evaluate(key string, sources map[string]*types.PriceData) (PriceDeviationAlert, bool) {
+	alert := PriceDeviationAlert{Pair: key, Timestamp: m.now()}
+
+	fresh := 0
+	for source, priceData := range sources {
+		if priceData.IsStale || (m.maxAge > 0 && m.now().Sub(priceData.Timestamp) > m.maxAge) {
+			continue
+		}
+		fresh++
+		alert.Token0, alert.Token1 = priceData.Token0, priceData.Token1
+
+		if alert.LowPrice == nil || priceData.Price.Cmp(alert.LowPrice) < 0 {
+			alert.LowSource, alert.LowPrice = source, priceData.Price
+		}
+		if alert.HighPrice == nil || priceData.Price.Cmp(alert.HighPrice) > 0 {
+			alert.HighSource, alert.HighPrice = source, priceData.Price
+		}
+	}
+	if fresh < 2 {
+		return alert, false
+	}
+
+	bps := spreadBps(alert.LowPrice, alert.HighPrice)
+	if !bps.IsUint64() {
+		alert.DeviationBps = ^uint64(0)
+	} else {
+		alert.DeviationBps = bps.Uint64()
+	}
+	return alert, true
+}
+
+// emit logs the alert and forwards it to the configured webhook
+func (m *deviationMonitor) emit(alert PriceDeviationAlert) {
+	m.logger.WithFields(logrus.Fields{
+		"pair":          alert.Pair,
+		"deviation_bps": alert.DeviationBps,
+		"low_source":    alert.LowSource,
+		"low_price":     alert.LowPrice.String(),
+		"high_source":   alert.HighSource,
+		"high_price":    alert.HighPrice.String(),
+	}).Warn("Cross-source price deviation exceeds alert bound")
+
+	if m.webhookURL == "" {
+		return
+	}
+
+	go func() {
+		resp, err := m.client.R().
+			SetHeader("Content-Type", "application/json").
+			SetBody(alert).
+			Post(m.webhookURL)
+		if err == nil && resp.StatusCode() >= http.StatusBadRequest {
+			err = fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+		}
+		if err != nil {
+			m.logger.WithError(err).WithField("pair", alert.Pair).Error("Failed to deliver price deviation alert")
+		}
+	}()
+}
+
+// Metrics returns the number of alerts raised and the latest deviation, in basis
+// points, observed for each pair
+func (m *deviationMonitor) Metrics() map[string]interface{} {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	alertCounts := make(map[string]uint64, len(m.alertCounts))
+	for key, count := range m.alertCounts {
+		alertCounts[key] = count
+	}
+	deviations := make(map[string]uint64, len(m.deviations))
+	for key, bps := range m.deviations {
+		deviations[key] = bps
+	}
+
+	return map[string]interface{}{
+		"price_deviation_alerts":  alertCounts,
+		"price_deviation_bps":     deviations,
+		"price_deviation_max_bps": m.maxDeviationBps,
+	}
+}
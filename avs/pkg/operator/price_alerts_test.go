@@ -0,0 +1,98 @@
+package operator
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func newTestDeviationMonitor(t *testing.T, maxDeviationBps uint64) (*deviationMonitor, *[]PriceDeviationAlert, *fakeClock) {
+	t.Helper()
+
+	m := newDeviationMonitor(types.PriceAlertConfig{
+		Enabled:         true,
+		MaxDeviationBps: maxDeviationBps,
+		Cooldown:        60,
+	}, nil, newTestLogger())
+	if m == nil {
+		t.Fatal("expected deviation monitor to be enabled")
+	}
+
+	clock := newFakeClock()
+	m.now = clock.Now
+
+	var alerts []PriceDeviationAlert
+	m.notify = func(alert PriceDeviationAlert) {
+		alerts = append(alerts, alert)
+	}
+	return m, &alerts, clock
+}
+
+func testPrice(clock *fakeClock, price int64) *types.PriceData {
+	return &types.PriceData{
+		Token0:    "0xa",
+		Token1:    "0xb",
+		Price:     big.NewInt(price),
+		Timestamp: clock.Now(),
+	}
+}
+
+func TestDeviationMonitorAlertsWhenBoundCrossed(t *testing.T) {
+	m, alerts, clock := newTestDeviationMonitor(t, 100) // 1%
+
+	m.Observe("0xa_0xb", "binance", testPrice(clock, 100000))
+	m.Observe("0xa_0xb", "coinbase", testPrice(clock, 100500)) // 0.5%
+	if len(*alerts) != 0 {
+		t.Fatalf("expected no alert below bound, got %v", *alerts)
+	}
+
+	m.Observe("0xa_0xb", "kraken", testPrice(clock, 102000)) // 2%
+	if len(*alerts) != 1 {
+		t.Fatalf("expected one alert, got %d", len(*alerts))
+	}
+
+	alert := (*alerts)[0]
+	if alert.DeviationBps != 200 || alert.LowSource != "binance" || alert.HighSource != "kraken" {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+
+	metrics := m.Metrics()
+	if got := metrics["price_deviation_alerts"].(map[string]uint64)["0xa_0xb"]; got != 1 {
+		t.Fatalf("price_deviation_alerts = %d, want 1", got)
+	}
+	if got := metrics["price_deviation_bps"].(map[string]uint64)["0xa_0xb"]; got != 200 {
+		t.Fatalf("price_deviation_bps = %d, want 200", got)
+	}
+}
+
+func TestDeviationMonitorCooldown(t *testing.T) {
+	m, alerts, clock := newTestDeviationMonitor(t, 100)
+
+	m.Observe("0xa_0xb", "binance", testPrice(clock, 100000))
+	m.Observe("0xa_0xb", "kraken", testPrice(clock, 105000))
+	m.Observe("0xa_0xb", "kraken", testPrice(clock, 106000))
+	if len(*alerts) != 1 {
+		t.Fatalf("expected repeated alerts to be suppressed, got %d", len(*alerts))
+	}
+
+	clock.Advance(time.Minute)
+	m.Observe("0xa_0xb", "binance", testPrice(clock, 100000))
+	if len(*alerts) != 2 {
+		t.Fatalf("expected alert after cooldown, got %d", len(*alerts))
+	}
+}
+
+func TestDeviationMonitorIgnoresStalePrices(t *testing.T) {
+	m, alerts, clock := newTestDeviationMonitor(t, 100)
+
+	stale := testPrice(clock, 150000)
+	stale.IsStale = true
+
+	m.Observe("0xa_0xb", "binance", testPrice(clock, 100000))
+	m.Observe("0xa_0xb", "kraken", stale)
+	if len(*alerts) != 0 {
+		t.Fatalf("expected stale price to be ignored, got %v", *alerts)
+	}
+}
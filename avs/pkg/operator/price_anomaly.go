@@ -0,0 +1,125 @@
+package operator
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// ErrPriceAnomalous is returned for a pair a source has reported an anomalous price for
+var ErrPriceAnomalous = errors.New("price is anomalous")
+
+const (
+	// defaultAnomalyWindow is the number of recent prices per pair anomalies are
+	// judged against when no AnomalyWindow is configured
+	defaultAnomalyWindow = 20
+	// minAnomalySamples is the fewest recent prices of a pair before any price of
+	// it is judged, so the first prices of a pair set its baseline
+	minAnomalySamples = 3
+	// anomalyFactorScale is the fixed point precision the anomaly factor is applied with
+	anomalyFactorScale = 1_000_000
+)
+
+// anomalyDetector rejects source prices deviating more than a factor from the
+// rolling median of their pair's recent prices, such as a feed quoting a 1000x
+// jump, and flags the pair until the source quotes a plausible price again
+type anomalyDetector struct {
+	// factor is the anomaly factor scaled by anomalyFactorScale
+	factor *big.Int
+	window int
+
+	mutex sync.Mutex
+	// history holds the recent accepted prices of each pair, oldest first
+	history map[string][]*big.Int
+	// flagged holds the sources whose latest price of a pair was anomalous
+	flagged map[string]map[string]bool
+	// consecutive counts the anomalous prices of a pair in a row. Once they fill
+	// the window the move is sustained, and the pair's baseline is relearned.
+	consecutive map[string]int
+}
+
+// newAnomalyDetector creates a detector rejecting prices more than factor from the
+// median of the last window prices, or returns nil if factor does not exceed 1
+func newAnomalyDetector(factor float64, window int) *anomalyDetector {
+	if factor <= 1 {
+		return nil
+	}
+	if window <= 0 {
+		window = defaultAnomalyWindow
+	}
+	scaled, _ := new(big.Float).Mul(big.NewFloat(factor), big.NewFloat(anomalyFactorScale)).Int(nil)
+	return &anomalyDetector{
+		factor:      scaled,
+		window:      max(window, minAnomalySamples),
+		history:     make(map[string][]*big.Int),
+		flagged:     make(map[string]map[string]bool),
+		consecutive: make(map[string]int),
+	}
+}
+
+// observe judges a price of the pair key from source, reporting whether it is
+// anomalous. Plausible prices join the pair's window; anomalous ones are not
+// recorded and flag the pair.
+func (d *anomalyDetector) observe(key, source string, price *big.Int) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	history := d.history[key]
+	if len(history) >= minAnomalySamples && d.deviates(price, rollingMedian(history)) {
+		d.consecutive[key]++
+		if d.consecutive[key] < d.window {
+			if d.flagged[key] == nil {
+				d.flagged[key] = make(map[string]bool)
+			}
+			d.flagged[key][source] = true
+			return true
+		}
+		// The pair's price has moved for a whole window; start over from here
+		history = nil
+		delete(d.flagged, key)
+	}
+
+	d.consecutive[key] = 0
+	delete(d.flagged[key], source)
+	history = append(history, new(big.Int).Set(price))
+	if len(history) > d.window {
+		history = history[len(history)-d.window:]
+	}
+	d.history[key] = history
+	return false
+}
+
+// deviates reports whether price is more than the factor above or below median
+func (d *anomalyDetector) deviates(price, median *big.Int) bool {
+	scaledPrice := new(big.Int).Mul(price, big.NewInt(anomalyFactorScale))
+	scaledMedian := new(big.Int).Mul(median, big.NewInt(anomalyFactorScale))
+	return scaledPrice.Cmp(new(big.Int).Mul(median, d.factor)) > 0 ||
+		new(big.Int).Mul(price, d.factor).Cmp(scaledMedian) < 0
+}
+
+// anomalous returns the sources whose latest price of the pair key was anomalous
+func (d *anomalyDetector) anomalous(key string) []string {
+	if d == nil {
+		return nil
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	sources := make([]string, 0, len(d.flagged[key]))
+	for source := range d.flagged[key] {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// rollingMedian returns the median of a pair's recent prices
+func rollingMedian(history []*big.Int) *big.Int {
+	prices := make([]sourcePrice, len(history))
+	for i, price := range history {
+		prices[i] = sourcePrice{price: price}
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].price.Cmp(prices[j].price) < 0 })
+	return medianPrice(prices)
+}
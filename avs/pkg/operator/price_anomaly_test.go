@@ -0,0 +1,103 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestPriceMonitorRejectsPriceSpikes(t *testing.T) {
+	var price atomic.Value
+	price.Store("2000000000")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"price": price.Load(), "timestamp": time.Now().Unix()})
+	}))
+	defer server.Close()
+	feed := types.PriceFeedConfig{
+		Name: "spiky",
+		URL:  server.URL,
+		Pairs: []types.TokenPair{
+			{Token0: testPool.Currency0, Token1: testPool.Currency1, Symbol: "AB", Decimals: normalizedPriceDecimals, IsActive: true},
+		},
+	}
+
+	pools, err := NewPoolRegistry([]types.PoolConfig{testPool}, "", newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{AnomalyFactor: 10, AnomalyWindow: 5}, pools, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	pm.metrics = newOperatorMetrics()
+	ctx := context.Background()
+	for i := 0; i < minAnomalySamples; i++ {
+		pm.updatePrices(ctx, feed)
+	}
+
+	// A 1000x jump is flagged and does not replace the cached price
+	price.Store("2000000000000")
+	pm.updatePrices(ctx, feed)
+	if _, err := pm.GetPriceData(testPoolID); !errors.Is(err, ErrPriceAnomalous) {
+		t.Fatalf("GetPriceData error = %v, want ErrPriceAnomalous", err)
+	}
+	if count := testutil.ToFloat64(pm.metrics.priceAnomalies.WithLabelValues("spiky")); count != 1 {
+		t.Fatalf("price_anomalies_total = %v, want 1", count)
+	}
+
+	// The pair is trusted again once the feed quotes a plausible price
+	price.Store("2100000000")
+	pm.updatePrices(ctx, feed)
+	priceData, err := pm.GetPriceData(testPoolID)
+	if err != nil || priceData.Price.Cmp(big.NewInt(2100000000)) != 0 {
+		t.Fatalf("GetPriceData = %v, %v; want the plausible price", priceData, err)
+	}
+}
+
+func TestAnomalyDetectorRelearnsSustainedMoves(t *testing.T) {
+	detector := newAnomalyDetector(10, 4)
+	for i := 0; i < 4; i++ {
+		detector.observe("pair", "feed", big.NewInt(100))
+	}
+
+	// A drop below a tenth stays anomalous until it has lasted a whole window
+	for i := 1; i < 4; i++ {
+		if !detector.observe("pair", "feed", big.NewInt(5)) {
+			t.Fatalf("price %d at the new level was not flagged", i)
+		}
+	}
+	if detector.observe("pair", "feed", big.NewInt(5)) || len(detector.anomalous("pair")) != 0 {
+		t.Fatal("expected the sustained move to become the pair's new baseline")
+	}
+}
+
+func TestProcessTaskAbstainsOnAnomalousPrice(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["spiked"] = &types.Auction{ID: "spiked", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	token0, token1, _ := op.priceMonitor.parsePoolID(testPoolID)
+	op.priceMonitor.anomalies = newAnomalyDetector(10, 0)
+	key := op.priceMonitor.getCacheKey(token0, token1)
+	for _, price := range []int64{2000, 2000, 2000, 2_000_000} {
+		op.priceMonitor.anomalies.observe(key, "test", big.NewInt(price))
+	}
+
+	op.processTask(&types.Task{ID: 1, AuctionID: "spiked", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)})
+	if len(coord.responses) != 0 {
+		t.Fatalf("expected no response on an anomalous price, got %d", len(coord.responses))
+	}
+	if skipped := op.GetMetrics()["tasks_skipped"].(map[string]uint64); skipped[skipReasonAnomalousPrice] != 1 {
+		t.Fatalf("tasks_skipped = %v, want one anomalous_price", skipped)
+	}
+}
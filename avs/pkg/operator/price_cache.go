@@ -0,0 +1,79 @@
+package operator
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// priceCacheShards is the number of independently locked shards the price cache
+// is split into, so updates and reads of different pairs rarely contend
+const priceCacheShards = 32
+
+// priceCache holds the prices of every pair, sharded by a hash of the pair key.
+// A pair's source prices and aggregate always live in the same shard.
+type priceCache struct {
+	shards []*priceCacheShard
+}
+
+// priceCacheShard is one locked partition of the price cache
+type priceCacheShard struct {
+	mutex sync.RWMutex
+	// aggregates holds the aggregate price per pair of the pair's sources
+	aggregates map[string]*types.PriceData
+	// sources holds the latest price from each feed, keyed by pair then feed name
+	sources map[string]map[string]*types.PriceData
+}
+
+// newPriceCache creates a price cache split into shards
+func newPriceCache(shards int) *priceCache {
+	cache := &priceCache{shards: make([]*priceCacheShard, shards)}
+	for i := range cache.shards {
+		cache.shards[i] = &priceCacheShard{
+			aggregates: make(map[string]*types.PriceData),
+			sources:    make(map[string]map[string]*types.PriceData),
+		}
+	}
+	return cache
+}
+
+// shard returns the shard holding the prices of the pair key
+func (c *priceCache) shard(key string) *priceCacheShard {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return c.shards[hash.Sum32()%uint32(len(c.shards))]
+}
+
+// get returns the aggregate price of the pair key
+func (c *priceCache) get(key string) (*types.PriceData, bool) {
+	shard := c.shard(key)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+	priceData, exists := shard.aggregates[key]
+	return priceData, exists
+}
+
+// len returns the number of pairs with an aggregate price
+func (c *priceCache) len() int {
+	var size int
+	for _, shard := range c.shards {
+		shard.mutex.RLock()
+		size += len(shard.aggregates)
+		shard.mutex.RUnlock()
+	}
+	return size
+}
+
+// all returns a copy of the aggregate prices of every pair
+func (c *priceCache) all() map[string]*types.PriceData {
+	result := make(map[string]*types.PriceData)
+	for _, shard := range c.shards {
+		shard.mutex.RLock()
+		for key, priceData := range shard.aggregates {
+			result[key] = priceData
+		}
+		shard.mutex.RUnlock()
+	}
+	return result
+}
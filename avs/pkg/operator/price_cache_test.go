@@ -0,0 +1,121 @@
+package operator
+
+import (
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// set replaces the aggregate price of the pair key
+func (c *priceCache) set(key string, priceData *types.PriceData) {
+	shard := c.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	shard.aggregates[key] = priceData
+}
+
+// lookup returns the aggregate price of the pair key, nil if there is none
+func (c *priceCache) lookup(key string) *types.PriceData {
+	priceData, _ := c.get(key)
+	return priceData
+}
+
+// remove drops the aggregate price of the pair key
+func (c *priceCache) remove(key string) {
+	shard := c.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	delete(shard.aggregates, key)
+}
+
+// source returns the latest price of the pair key from a feed
+func (c *priceCache) source(key, feed string) (*types.PriceData, bool) {
+	shard := c.shard(key)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+	priceData, exists := shard.sources[key][feed]
+	return priceData, exists
+}
+
+func TestPriceCacheShardsKeepPairsTogether(t *testing.T) {
+	pm, err := NewPriceMonitor(nil, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+
+	const pairs = 200
+	now := time.Now()
+	for i := 0; i < pairs; i++ {
+		token0, token1 := fmt.Sprintf("0x%x", 2*i+1), fmt.Sprintf("0x%x", 2*i+2)
+		pm.updateCache(token0, token1, "a", &types.PriceData{Price: big.NewInt(1000), Timestamp: now})
+		pm.updateCache(token0, token1, "b", &types.PriceData{Price: big.NewInt(1010), Timestamp: now})
+	}
+
+	if size := pm.GetCacheSize(); size != pairs {
+		t.Fatalf("cache size = %d, want %d", size, pairs)
+	}
+	if all := pm.GetAllPrices(); len(all) != pairs {
+		t.Fatalf("GetAllPrices returned %d pairs, want %d", len(all), pairs)
+	}
+	used := 0
+	for _, shard := range pm.cache.shards {
+		if len(shard.aggregates) > 0 {
+			used++
+		}
+	}
+	if used < priceCacheShards/2 {
+		t.Fatalf("pairs spread over %d of %d shards", used, priceCacheShards)
+	}
+
+	// Both sources of a pair are aggregated together
+	bps, err := pm.GetDiscrepancyBps("0x2", "0x1")
+	if err != nil || bps != 100 {
+		t.Fatalf("discrepancy = %d bps (%v), want 100", bps, err)
+	}
+
+	pm.evictStale(now.Add(2 * defaultMaxStaleness))
+	if size := pm.GetCacheSize(); size != 0 {
+		t.Fatalf("cache size after eviction = %d, want 0", size)
+	}
+}
+
+// benchmarkPriceCache updates and reads prices of many pairs concurrently, one
+// update per nine reads, over a cache split into shards
+func benchmarkPriceCache(b *testing.B, shards int) {
+	pm, err := NewPriceMonitor(nil, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		b.Fatalf("NewPriceMonitor: %v", err)
+	}
+	pm.cache = newPriceCache(shards)
+
+	const pairs = 500
+	tokens := make([][2]string, pairs)
+	now := time.Now()
+	for i := range tokens {
+		tokens[i] = [2]string{fmt.Sprintf("0x%x", 2*i+1), fmt.Sprintf("0x%x", 2*i+2)}
+		pm.updateCache(tokens[i][0], tokens[i][1], "a", &types.PriceData{Price: big.NewInt(1000), Timestamp: now})
+	}
+
+	var workers atomic.Uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		// Each worker walks the pairs from its own offset
+		n := workers.Add(1) * 7919
+		for pb.Next() {
+			n++
+			pair := tokens[n%pairs]
+			if n%10 == 0 {
+				pm.updateCache(pair[0], pair[1], "b", &types.PriceData{Price: big.NewInt(1010), Timestamp: now})
+			} else {
+				pm.GetPairPrice(pair[0], pair[1])
+			}
+		}
+	})
+}
+
+func BenchmarkPriceCacheSingleLock(b *testing.B) { benchmarkPriceCache(b, 1) }
+func BenchmarkPriceCacheSharded(b *testing.B)    { benchmarkPriceCache(b, priceCacheShards) }
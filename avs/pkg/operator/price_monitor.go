@@ -2,52 +2,311 @@ package operator
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"math/rand"
 	"net/http"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-resty/resty/v2"
 	"github.com/sirupsen/logrus"
 
 	"github.com/lvr-auction-hook/avs/pkg/types"
 )
 
+var (
+	// ErrPriceNotFound is returned when no source has quoted a pair yet
+	ErrPriceNotFound = errors.New("no price data available")
+	// ErrPriceStale is returned when every source price of a pair is stale
+	ErrPriceStale = errors.New("price data is stale")
+	// ErrFeedUnavailable is returned when a price feed fails to return a price
+	ErrFeedUnavailable = errors.New("price feed unavailable")
+)
+
+// Price aggregation methods selected by PriceAggregationConfig.Method
+const (
+	aggregationMedian       = "median"
+	aggregationWeightedMean = "weighted_mean"
+)
+
+// minSourcesForOutliers is the fewest fresh sources among which outliers are
+// rejected, since with two neither can be told apart as the outlier
+const minSourcesForOutliers = 3
+
+// Price feed types selected by PriceFeedConfig.Type
+const (
+	feedTypeHTTP      = "http"
+	feedTypeChainlink = "chainlink"
+	// feedTypeUniswapV4 reads the spot price of each pair's own pool
+	feedTypeUniswapV4 = "uniswap_v4"
+)
+
+const (
+	// normalizedPriceDecimals is the fixed point precision every price is scaled to
+	// before prices from different feeds are compared
+	normalizedPriceDecimals = 18
+	// maxPriceDecimals bounds the configured decimals of a pair
+	maxPriceDecimals = 36
+)
+
+// NormalizePrice rescales a fixed point price from fromDecimals to toDecimals,
+// truncating any digits lost when scaling down
+func NormalizePrice(price *big.Int, fromDecimals, toDecimals int) *big.Int {
+	normalized := new(big.Int).Set(price)
+	switch {
+	case fromDecimals < toDecimals:
+		normalized.Mul(normalized, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toDecimals-fromDecimals)), nil))
+	case fromDecimals > toDecimals:
+		normalized.Quo(normalized, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromDecimals-toDecimals)), nil))
+	}
+	return normalized
+}
+
+// defaultMaxStaleness is how old a feed's prices may be when no MaxStaleness is configured
+const defaultMaxStaleness = time.Hour
+
+const (
+	// defaultFeedRetries is the number of times a transient price fetch failure is retried
+	defaultFeedRetries = 2
+	// defaultFeedRetryBackoff is the delay before the first retry, doubled after
+	// each attempt and jittered by up to half
+	defaultFeedRetryBackoff = 250 * time.Millisecond
+	// maxFeedRetryBackoff caps the delay between retries
+	maxFeedRetryBackoff = 5 * time.Second
+)
+
+// retryableFetchError is a price fetch failure that may succeed if retried
+type retryableFetchError struct {
+	err error
+}
+
+func (e *retryableFetchError) Error() string { return e.err.Error() }
+func (e *retryableFetchError) Unwrap() error { return e.err }
+
+// maxStaleness returns how old a feed's prices may be before they are stale
+func maxStaleness(feed types.PriceFeedConfig) time.Duration {
+	if feed.MaxStaleness <= 0 {
+		return defaultMaxStaleness
+	}
+	return time.Duration(feed.MaxStaleness) * time.Second
+}
+
+// sourceMaxStaleness returns how old the prices of the named source may be
+func (pm *PriceMonitor) sourceMaxStaleness(source string) time.Duration {
+	for _, feed := range pm.priceFeeds {
+		if feed.Name == source {
+			return maxStaleness(feed)
+		}
+	}
+	return defaultMaxStaleness
+}
+
+// sourceStale reports whether a source's price was stale when fetched or has
+// since aged past its feed's MaxStaleness
+func (pm *PriceMonitor) sourceStale(source string, priceData *types.PriceData, now time.Time) bool {
+	return priceData.IsStale || now.Sub(priceData.Timestamp) > pm.sourceMaxStaleness(source)
+}
+
 // PriceMonitor monitors price feeds for LVR detection
 type PriceMonitor struct {
 	priceFeeds []types.PriceFeedConfig
 	client     *resty.Client
 	logger     *logrus.Logger
-	cache      map[string]*types.PriceData
-	mutex      sync.RWMutex
+	// cache holds the latest price from each feed and their aggregate, per pair
+	cache  *priceCache
+	alerts *deviationMonitor
+	// pools resolves the pool IDs of tasks to their token pairs
+	pools *PoolRegistry
+	// breakers stops polling feeds that keep failing, keyed by feed name
+	breakers map[string]*feedBreaker
+	// responses reads the responses of http feeds, keyed by feed name
+	responses map[string]*responseMapping
+	// chainlink and poolPrices read the prices of chainlink and uniswap_v4 feeds,
+	// nil if none are configured
+	chainlink  *chainlinkReader
+	poolPrices *poolPriceReader
+	// aggregation selects how source prices are combined, weighting them by the
+	// weights of their feeds
+	aggregation types.PriceAggregationConfig
+	weights     map[string]uint64
+	// smoothing holds the EMA alpha of feeds that smooth their prices, by feed name
+	smoothing map[string]float64
+	// anomalies rejects implausible source prices, nil when disabled
+	anomalies *anomalyDetector
+	// retryBackoff is the delay before retrying a failed HTTP price fetch
+	retryBackoff time.Duration
+	// mutex guards started
+	mutex sync.RWMutex
+	// metrics counts failed fetches, nil when not exported
+	metrics *operatorMetrics
+	// tokens resolves the symbols price lookups may name tokens by, nil when
+	// lookups name tokens by address only
+	tokens *TokenRegistry
+
+	// goroutines tracks the goroutines of Start, and stopped is closed once they
+	// have all returned
+	goroutines sync.WaitGroup
+	started    bool
+	stopped    chan struct{}
 }
 
-// NewPriceMonitor creates a new price monitor
-func NewPriceMonitor(priceFeeds []types.PriceFeedConfig, logger *logrus.Logger) (*PriceMonitor, error) {
+// NewPriceMonitor creates a new price monitor. caller reads chainlink feeds and
+// may be nil when none are configured.
+func NewPriceMonitor(priceFeeds []types.PriceFeedConfig, alertConfig types.PriceAlertConfig, aggregation types.PriceAggregationConfig, pools *PoolRegistry, caller ethereum.ContractCaller, logger *logrus.Logger) (*PriceMonitor, error) {
+	switch aggregation.Method {
+	case "", aggregationMedian, aggregationWeightedMean:
+	default:
+		return nil, fmt.Errorf("unknown price aggregation method %q", aggregation.Method)
+	}
+	if aggregation.AnomalyFactor != 0 && aggregation.AnomalyFactor <= 1 {
+		return nil, fmt.Errorf("price aggregation anomaly factor must exceed 1, got %g", aggregation.AnomalyFactor)
+	}
+
+	feedNames := make(map[string]bool, len(priceFeeds))
+	weights := make(map[string]uint64, len(priceFeeds))
+	smoothing := make(map[string]float64)
+	breakers := make(map[string]*feedBreaker, len(priceFeeds))
+	responses := make(map[string]*responseMapping, len(priceFeeds))
+	var chainlink *chainlinkReader
+	var poolPrices *poolPriceReader
+	for _, feed := range priceFeeds {
+		feedNames[feed.Name] = true
+		breakers[feed.Name] = newFeedBreaker(feed)
+		weights[feed.Name] = feed.Weight
+		if feed.SmoothingAlpha < 0 || feed.SmoothingAlpha > 1 {
+			return nil, fmt.Errorf("price feed %q: smoothing alpha must be between 0 and 1, got %g", feed.Name, feed.SmoothingAlpha)
+		}
+		if feed.SmoothingAlpha > 0 && feed.SmoothingAlpha < 1 {
+			smoothing[feed.Name] = feed.SmoothingAlpha
+		}
+
+		for _, pair := range feed.Pairs {
+			if pair.Decimals < 0 || pair.Decimals > maxPriceDecimals || pair.QuoteDecimals < 0 || pair.QuoteDecimals > maxPriceDecimals {
+				return nil, fmt.Errorf("price feed %q pair %s: decimals must be between 0 and %d", feed.Name, pair.Symbol, maxPriceDecimals)
+			}
+		}
+
+		switch feed.Type {
+		case "", feedTypeHTTP:
+			mapping, err := newResponseMapping(feed.ResponseMapping)
+			if err != nil {
+				return nil, fmt.Errorf("price feed %q: %w", feed.Name, err)
+			}
+			responses[feed.Name] = mapping
+		case feedTypeChainlink:
+			if caller == nil {
+				return nil, fmt.Errorf("chainlink feed %q requires an ethereum client", feed.Name)
+			}
+			for _, pair := range feed.Pairs {
+				if !common.IsHexAddress(pair.Aggregator) {
+					return nil, fmt.Errorf("chainlink feed %q pair %s: invalid aggregator address %q", feed.Name, pair.Symbol, pair.Aggregator)
+				}
+			}
+			if chainlink == nil {
+				var err error
+				if chainlink, err = newChainlinkReader(caller); err != nil {
+					return nil, err
+				}
+			}
+		case feedTypeUniswapV4:
+			if caller == nil {
+				return nil, fmt.Errorf("uniswap_v4 feed %q requires an ethereum client", feed.Name)
+			}
+			if !common.IsHexAddress(feed.StateView) {
+				return nil, fmt.Errorf("uniswap_v4 feed %q: invalid state view address %q", feed.Name, feed.StateView)
+			}
+			for _, pair := range feed.Pairs {
+				if len(strings.TrimPrefix(pair.PoolID, "0x")) != 2*common.HashLength {
+					return nil, fmt.Errorf("uniswap_v4 feed %q pair %s: invalid pool id %q", feed.Name, pair.Symbol, pair.PoolID)
+				}
+			}
+			if poolPrices == nil {
+				var err error
+				if poolPrices, err = newPoolPriceReader(caller, pools); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, fmt.Errorf("price feed %q has unknown type %q", feed.Name, feed.Type)
+		}
+	}
+	if err := pools.checkSources(feedNames); err != nil {
+		return nil, err
+	}
+
 	client := resty.New()
 	client.SetTimeout(10 * time.Second)
 
 	return &PriceMonitor{
-		priceFeeds: priceFeeds,
-		client:     client,
-		logger:     logger,
-		cache:      make(map[string]*types.PriceData),
+		priceFeeds:   priceFeeds,
+		client:       client,
+		logger:       logger,
+		cache:        newPriceCache(priceCacheShards),
+		alerts:       newDeviationMonitor(alertConfig, client, logger),
+		pools:        pools,
+		breakers:     breakers,
+		responses:    responses,
+		chainlink:    chainlink,
+		poolPrices:   poolPrices,
+		aggregation:  aggregation,
+		weights:      weights,
+		smoothing:    smoothing,
+		anomalies:    newAnomalyDetector(aggregation.AnomalyFactor, aggregation.AnomalyWindow),
+		retryBackoff: defaultFeedRetryBackoff,
+		stopped:      make(chan struct{}),
 	}, nil
 }
 
-// Start begins price monitoring
+// Start begins price monitoring until ctx is cancelled. It must be called at
+// most once.
 func (pm *PriceMonitor) Start(ctx context.Context) {
 	pm.logger.Info("Starting price monitoring...")
 
+	pm.mutex.Lock()
+	pm.started = true
+	pm.mutex.Unlock()
+
 	// Start monitoring for each price feed
 	for _, feed := range pm.priceFeeds {
-		go pm.monitorFeed(ctx, feed)
+		pm.goroutines.Add(1)
+		go func(feed types.PriceFeedConfig) {
+			defer pm.goroutines.Done()
+			pm.monitorFeed(ctx, feed)
+		}(feed)
 	}
 
 	// Start cache cleanup
-	go pm.cleanupCache(ctx)
+	pm.goroutines.Add(1)
+	go func() {
+		defer pm.goroutines.Done()
+		pm.cleanupCache(ctx)
+	}()
+
+	go func() {
+		pm.goroutines.Wait()
+		close(pm.stopped)
+	}()
+}
+
+// Stopped returns a channel closed once every feed and cleanup goroutine has
+// returned after the context of Start is cancelled. It is closed already if the
+// monitor was never started.
+func (pm *PriceMonitor) Stopped() <-chan struct{} {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	if !pm.started {
+		stopped := make(chan struct{})
+		close(stopped)
+		return stopped
+	}
+	return pm.stopped
 }
 
 // monitorFeed monitors a specific price feed
@@ -62,130 +321,427 @@ func (pm *PriceMonitor) monitorFeed(ctx context.Context, feed types.PriceFeedCon
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			pm.updatePrices(feed)
+			pm.pollFeed(ctx, feed)
 		}
 	}
 }
 
-// updatePrices updates prices for a specific feed
-func (pm *PriceMonitor) updatePrices(feed types.PriceFeedConfig) {
+// pollFeed updates a feed's prices unless its circuit breaker is open
+func (pm *PriceMonitor) pollFeed(ctx context.Context, feed types.PriceFeedConfig) {
+	breaker := pm.breakers[feed.Name]
+	if !breaker.allow() {
+		return
+	}
+
+	err := pm.updatePrices(ctx, feed)
+	if ctx.Err() != nil {
+		// Polls cut short by shutdown say nothing about the feed's health
+		return
+	}
+	breaker.record(err)
+	if health := breaker.health(); health.State == breakerOpen {
+		pm.logger.WithError(err).WithFields(logrus.Fields{
+			"feed":       feed.Name,
+			"failures":   health.ConsecutiveFailures,
+			"open_until": health.OpenUntil.Format(time.RFC3339),
+		}).Warn("Price feed circuit breaker open")
+	}
+}
+
+// updatePrices updates prices for a specific feed. It returns an error if every
+// active pair failed to fetch, or if ctx is cancelled.
+func (pm *PriceMonitor) updatePrices(ctx context.Context, feed types.PriceFeedConfig) error {
+	var lastErr error
+	fetched := false
 	for _, pair := range feed.Pairs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if !pair.IsActive {
 			continue
 		}
 
-		priceData, err := pm.fetchPrice(feed, pair)
+		priceData, err := pm.fetchPrice(ctx, feed, pair)
 		if err != nil {
+			pm.metrics.observePriceFetchError(feed.Name)
 			pm.logger.WithError(err).WithFields(logrus.Fields{
+				"feed": feed.Name,
+				"pair": pair.Symbol,
+			}).Error("Failed to fetch price")
+			lastErr = err
+			continue
+		}
+		fetched = true
+
+		// Cached and compared prices share a scale whatever the feed's precision
+		priceData.Price = NormalizePrice(priceData.Price, priceData.Decimals, normalizedPriceDecimals)
+		priceData.Decimals = normalizedPriceDecimals
+
+		if pm.anomalies != nil && pm.anomalies.observe(pm.getCacheKey(pair.Token0, pair.Token1), feed.Name, priceData.Price) {
+			pm.metrics.observePriceAnomaly(feed.Name)
+			pm.logger.WithFields(logrus.Fields{
 				"feed":  feed.Name,
 				"pair":  pair.Symbol,
-			}).Error("Failed to fetch price")
+				"price": priceData.Price.String(),
+			}).Warn("Rejecting anomalous price")
 			continue
 		}
 
-		pm.updateCache(pair.Token0, pair.Token1, priceData)
+		pm.updateCache(pair.Token0, pair.Token1, feed.Name, priceData)
+		if pm.alerts != nil {
+			pm.alerts.Observe(pm.getCacheKey(pair.Token0, pair.Token1), feed.Name, priceData)
+		}
+	}
+
+	if !fetched && lastErr != nil {
+		return lastErr
 	}
+	return nil
 }
 
 // fetchPrice fetches price data from a specific feed
-func (pm *PriceMonitor) fetchPrice(feed types.PriceFeedConfig, pair types.TokenPair) (*types.PriceData, error) {
+func (pm *PriceMonitor) fetchPrice(ctx context.Context, feed types.PriceFeedConfig, pair types.TokenPair) (*types.PriceData, error) {
+	var priceData *types.PriceData
+	var err error
+	switch feed.Type {
+	case feedTypeChainlink:
+		priceData, err = pm.chainlink.fetchPrice(ctx, feed, pair)
+	case feedTypeUniswapV4:
+		priceData, err = pm.poolPrices.fetchPrice(ctx, feed, pair)
+	default:
+		priceData, err = pm.fetchHTTPPrice(ctx, feed, pair)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrFeedUnavailable, feed.Name, err)
+	}
+	return priceData, nil
+}
+
+// fetchHTTPPrice fetches price data from a REST price feed, retrying transient
+// failures with jittered exponential backoff
+func (pm *PriceMonitor) fetchHTTPPrice(ctx context.Context, feed types.PriceFeedConfig, pair types.TokenPair) (*types.PriceData, error) {
+	retries := feed.MaxRetries
+	if retries == 0 {
+		retries = defaultFeedRetries
+	}
+	backoff := pm.retryBackoff
+
+	for attempt := 0; ; attempt++ {
+		priceData, err := pm.fetchHTTPPriceOnce(ctx, feed, pair)
+		var retryable *retryableFetchError
+		if err == nil || !errors.As(err, &retryable) || attempt >= retries {
+			return priceData, err
+		}
+
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		pm.logger.WithError(err).WithFields(logrus.Fields{
+			"feed":    feed.Name,
+			"pair":    pair.Symbol,
+			"attempt": attempt + 1,
+			"backoff": delay,
+		}).Debug("Price fetch failed, retrying")
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff = min(2*backoff, maxFeedRetryBackoff)
+	}
+}
+
+// fetchHTTPPriceOnce makes a single request to a REST price feed. Network
+// errors, 429s and 5xx responses are returned as retryableFetchErrors.
+func (pm *PriceMonitor) fetchHTTPPriceOnce(ctx context.Context, feed types.PriceFeedConfig, pair types.TokenPair) (*types.PriceData, error) {
 	url := fmt.Sprintf("%s/price/%s", feed.URL, pair.Symbol)
-	
+
 	resp, err := pm.client.R().
+		SetContext(ctx).
 		SetHeader("X-API-Key", feed.APIKey).
 		Get(url)
 
 	if err != nil {
-		return nil, err
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &retryableFetchError{err: err}
 	}
 
 	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
-	}
-
-	var priceResponse struct {
-		Price     string `json:"price"`
-		Timestamp int64  `json:"timestamp"`
-		Source    string `json:"source"`
+		err := fmt.Errorf("HTTP %d: %s", resp.StatusCode(), resp.String())
+		if resp.StatusCode() >= http.StatusInternalServerError || resp.StatusCode() == http.StatusTooManyRequests {
+			return nil, &retryableFetchError{err: err}
+		}
+		return nil, err
 	}
 
-	err = json.Unmarshal(resp.Body(), &priceResponse)
+	price, timestamp, source, err := pm.responses[feed.Name].parse(resp.Body())
 	if err != nil {
 		return nil, err
 	}
-
-	price, ok := new(big.Int).SetString(priceResponse.Price, 10)
-	if !ok {
-		return nil, fmt.Errorf("invalid price format: %s", priceResponse.Price)
+	if source == "" {
+		source = feed.Name
 	}
 
 	return &types.PriceData{
 		Token0:    pair.Token0,
 		Token1:    pair.Token1,
 		Price:     price,
-		Timestamp: time.Unix(priceResponse.Timestamp, 0),
-		Source:    priceResponse.Source,
-		IsStale:   time.Since(time.Unix(priceResponse.Timestamp, 0)) > 1*time.Hour,
+		Timestamp: timestamp,
+		Source:    source,
+		IsStale:   time.Since(timestamp) > maxStaleness(feed),
+		Decimals:  pair.Decimals,
 	}, nil
 }
 
-// updateCache updates the price cache
-func (pm *PriceMonitor) updateCache(token0, token1 string, priceData *types.PriceData) {
-	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-
+// updateCache records the latest price from source, smoothed if its feed smooths
+// prices, and recomputes the pair's aggregate price and cross-source discrepancy
+func (pm *PriceMonitor) updateCache(token0, token1, source string, priceData *types.PriceData) {
 	key := pm.getCacheKey(token0, token1)
-	pm.cache[key] = priceData
+	shard := pm.cache.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	sources, exists := shard.sources[key]
+	if !exists {
+		sources = make(map[string]*types.PriceData)
+		shard.sources[key] = sources
+	}
+	priceData = pm.smoothPrice(source, sources[source], priceData)
+	sources[source] = priceData
+
+	aggregate := pm.aggregatePrices(token0, token1, sources)
+	shard.aggregates[key] = aggregate
 
 	pm.logger.WithFields(logrus.Fields{
-		"pair":       fmt.Sprintf("%s/%s", token0, token1),
-		"price":      priceData.Price.String(),
-		"source":     priceData.Source,
-		"is_stale":   priceData.IsStale,
+		"pair":        fmt.Sprintf("%s/%s", token0, token1),
+		"price":       priceData.Price.String(),
+		"source":      source,
+		"is_stale":    priceData.IsStale,
+		"sources":     len(sources),
+		"discrepancy": aggregate.Discrepancy.String(),
 	}).Debug("Price updated in cache")
 }
 
+// aggregatePrices combines the fresh source prices of a pair, normalized to
+// normalizedPriceDecimals, into the canonical price: their median, or with the
+// weighted_mean method the mean weighted by each feed's weight. With at least
+// three fresh sources, those deviating from the median by more than OutlierBps
+// are dropped first. The discrepancy is the max-minus-min spread across the
+// remaining sources in basis points. Feeds must quote a pair in the same
+// orientation for their prices to be comparable.
+func (pm *PriceMonitor) aggregatePrices(token0, token1 string, sources map[string]*types.PriceData) *types.PriceData {
+	aggregate := &types.PriceData{
+		Token0:      token0,
+		Token1:      token1,
+		IsStale:     true,
+		Discrepancy: new(big.Int),
+		Decimals:    normalizedPriceDecimals,
+	}
+
+	now := time.Now()
+	var fresh []sourcePrice
+	for name, priceData := range sources {
+		if priceData.Timestamp.After(aggregate.Timestamp) {
+			aggregate.Timestamp = priceData.Timestamp
+		}
+		if pm.sourceStale(name, priceData, now) || priceData.Price == nil || priceData.Price.Sign() <= 0 {
+			continue
+		}
+		fresh = append(fresh, sourcePrice{name: name, price: priceData.Price, raw: priceData.RawPrice})
+	}
+	if len(fresh) == 0 {
+		aggregate.Price = new(big.Int)
+		return aggregate
+	}
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].price.Cmp(fresh[j].price) < 0 })
+
+	accepted := fresh
+	if pm.aggregation.OutlierBps > 0 && len(fresh) >= minSourcesForOutliers {
+		mid := medianPrice(fresh)
+		accepted = nil
+		for _, source := range fresh {
+			if deviationBps(source.price, mid).Cmp(new(big.Int).SetUint64(pm.aggregation.OutlierBps)) <= 0 {
+				accepted = append(accepted, source)
+			}
+		}
+		if len(accepted) < len(fresh) {
+			pm.logger.WithFields(logrus.Fields{
+				"pair":     fmt.Sprintf("%s/%s", token0, token1),
+				"rejected": len(fresh) - len(accepted),
+			}).Debug("Dropped outlier source prices")
+		}
+	}
+
+	aggregate.Price = pm.combinePrices(accepted)
+	// Smoothing lags the sources, so they are compared by their raw prices
+	raw, smoothed := rawPrices(accepted)
+	if smoothed {
+		aggregate.RawPrice = pm.combinePrices(raw)
+	}
+
+	names := make([]string, len(accepted))
+	for i, source := range accepted {
+		names[i] = source.name
+	}
+	sort.Strings(names)
+
+	aggregate.IsStale = false
+	aggregate.Source = strings.Join(names, ",")
+	aggregate.Discrepancy = spreadBps(raw[0].price, raw[len(raw)-1].price)
+	return aggregate
+}
+
+// combinePrices combines prices sorted in ascending order by the configured
+// aggregation method
+func (pm *PriceMonitor) combinePrices(prices []sourcePrice) *big.Int {
+	if pm.aggregation.Method == aggregationWeightedMean {
+		return pm.weightedMeanPrice(prices)
+	}
+	return medianPrice(prices)
+}
+
+// sourcePrice is the fresh price of one source of a pair
+type sourcePrice struct {
+	name  string
+	price *big.Int
+	// raw is the unsmoothed price when price is smoothed, nil otherwise
+	raw *big.Int
+}
+
+// medianPrice returns the median of prices sorted in ascending order, averaging
+// the middle two of an even count
+func medianPrice(prices []sourcePrice) *big.Int {
+	mid := len(prices) / 2
+	if len(prices)%2 == 1 {
+		return new(big.Int).Set(prices[mid].price)
+	}
+	median := new(big.Int).Add(prices[mid-1].price, prices[mid].price)
+	return median.Rsh(median, 1)
+}
+
+// weightedMeanPrice returns the mean of prices weighted by their feed's weight
+func (pm *PriceMonitor) weightedMeanPrice(prices []sourcePrice) *big.Int {
+	sum, totalWeight := new(big.Int), new(big.Int)
+	for _, source := range prices {
+		weight := new(big.Int).SetUint64(pm.feedWeight(source.name))
+		sum.Add(sum, new(big.Int).Mul(source.price, weight))
+		totalWeight.Add(totalWeight, weight)
+	}
+	return sum.Quo(sum, totalWeight)
+}
+
+// feedWeight returns the weight of a feed's prices in a weighted mean
+func (pm *PriceMonitor) feedWeight(name string) uint64 {
+	if weight := pm.weights[name]; weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// deviationBps returns how far price is from reference, in basis points of reference
+func deviationBps(price, reference *big.Int) *big.Int {
+	deviation := new(big.Int).Sub(price, reference)
+	deviation.Abs(deviation)
+	return deviation.Mul(deviation, big.NewInt(10000)).Quo(deviation, reference)
+}
+
+// spreadBps returns high minus low in basis points of low
+func spreadBps(low, high *big.Int) *big.Int {
+	spread := new(big.Int).Sub(high, low)
+	return spread.Mul(spread, big.NewInt(10000)).Div(spread, low)
+}
+
 // GetPriceData retrieves price data for a token pair
 func (pm *PriceMonitor) GetPriceData(poolID string) (*types.PriceData, error) {
-	pm.mutex.RLock()
-	defer pm.mutex.RUnlock()
-
-	// Parse pool ID to extract token pair (simplified)
-	token0, token1, err := pm.parsePoolID(poolID)
+	pool, err := pm.pools.Lookup(poolID)
 	if err != nil {
 		return nil, err
 	}
+	token0, token1 := pool.Token0.Hex(), pool.Token1.Hex()
 
 	key := pm.getCacheKey(token0, token1)
-	priceData, exists := pm.cache[key]
+	shard := pm.cache.shard(key)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+
+	priceData, exists := shard.aggregates[key]
+	if len(pool.Sources) > 0 {
+		// Pools with their own source set are priced over those sources only
+		priceData, exists = pm.poolPriceData(shard, key, pool)
+	} else if exists && pm.aggregateAged(shard.sources[key], priceData) {
+		// A source the cached aggregate was computed from has aged out since
+		priceData = pm.aggregatePrices(token0, token1, shard.sources[key])
+	}
 	if !exists {
-		return nil, fmt.Errorf("no price data available for pair %s/%s", token0, token1)
+		return nil, fmt.Errorf("%w for pair %s/%s", ErrPriceNotFound, token0, token1)
+	}
+
+	// A source quoting an implausible price casts doubt on the pair's price
+	for _, source := range pm.anomalies.anomalous(key) {
+		if len(pool.Sources) == 0 || slices.Contains(pool.Sources, source) {
+			return nil, fmt.Errorf("%w for pair %s/%s from %s", ErrPriceAnomalous, token0, token1, source)
+		}
 	}
 
 	// Check if price is stale
 	if priceData.IsStale {
-		return nil, fmt.Errorf("price data is stale for pair %s/%s", token0, token1)
+		return nil, fmt.Errorf("%w for pair %s/%s", ErrPriceStale, token0, token1)
 	}
 
 	return priceData, nil
 }
 
-// GetPriceDiscrepancy calculates price discrepancy between sources
-func (pm *PriceMonitor) GetPriceDiscrepancy(token0, token1 string) (*big.Int, error) {
-	pm.mutex.RLock()
-	defer pm.mutex.RUnlock()
+// aggregateAged reports whether a source an aggregate was computed from has aged
+// past its feed's MaxStaleness since
+func (pm *PriceMonitor) aggregateAged(sources map[string]*types.PriceData, aggregate *types.PriceData) bool {
+	if aggregate.IsStale || aggregate.Source == "" {
+		return false
+	}
+	now := time.Now()
+	for _, name := range strings.Split(aggregate.Source, ",") {
+		if priceData, exists := sources[name]; exists && pm.sourceStale(name, priceData, now) {
+			return true
+		}
+	}
+	return false
+}
 
-	key := pm.getCacheKey(token0, token1)
-	priceData, exists := pm.cache[key]
+// poolPriceData aggregates the prices of a pool's assigned sources. Callers must
+// hold the mutex of shard, the shard of key.
+func (pm *PriceMonitor) poolPriceData(shard *priceCacheShard, key string, pool PoolInfo) (*types.PriceData, bool) {
+	sources := make(map[string]*types.PriceData, len(pool.Sources))
+	for _, source := range pool.Sources {
+		if priceData, exists := shard.sources[key][source]; exists {
+			sources[source] = priceData
+		}
+	}
+	if len(sources) == 0 {
+		return nil, false
+	}
+	return pm.aggregatePrices(pool.Token0.Hex(), pool.Token1.Hex(), sources), true
+}
+
+// GetPriceDiscrepancy returns the price discrepancy between sources in basis points
+func (pm *PriceMonitor) GetPriceDiscrepancy(token0, token1 string) (*big.Int, error) {
+	priceData, exists := pm.cache.get(pm.getCacheKey(token0, token1))
 	if !exists {
-		return nil, fmt.Errorf("no price data available")
+		return nil, fmt.Errorf("%w for pair %s/%s", ErrPriceNotFound, token0, token1)
 	}
 
-	// Calculate discrepancy (simplified - in reality would compare multiple sources)
-	// For now, return a mock discrepancy
-	discrepancy := new(big.Int).Div(priceData.Price, big.NewInt(1000)) // 0.1% mock discrepancy
-	
-	return discrepancy, nil
+	return new(big.Int).Set(priceData.Discrepancy), nil
+}
+
+// GetDiscrepancyBps returns the spread between the lowest and highest fresh source
+// price for a pair, in basis points of the lowest
+func (pm *PriceMonitor) GetDiscrepancyBps(token0, token1 string) (uint64, error) {
+	discrepancy, err := pm.GetPriceDiscrepancy(token0, token1)
+	if err != nil {
+		return 0, err
+	}
+	if !discrepancy.IsUint64() {
+		return ^uint64(0), nil
+	}
+	return discrepancy.Uint64(), nil
 }
 
 // cleanupCache periodically cleans up stale cache entries
@@ -198,50 +754,113 @@ func (pm *PriceMonitor) cleanupCache(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			pm.mutex.Lock()
-			cutoff := time.Now().Add(-1 * time.Hour)
-			
-			for key, priceData := range pm.cache {
-				if priceData.Timestamp.Before(cutoff) {
-					delete(pm.cache, key)
-				}
+			pm.evictStale(time.Now())
+		}
+	}
+}
+
+// evictStale drops the source prices older than their feed's MaxStaleness, and
+// the pairs left without a source price. Pairs that keep other sources have
+// their aggregate recomputed.
+func (pm *PriceMonitor) evictStale(now time.Time) {
+	staleness := make(map[string]time.Duration, len(pm.priceFeeds))
+	for _, feed := range pm.priceFeeds {
+		staleness[feed.Name] = maxStaleness(feed)
+	}
+
+	for _, shard := range pm.cache.shards {
+		pm.evictStaleShard(shard, staleness, now)
+	}
+}
+
+// evictStaleShard drops the stale source prices of one shard of the cache
+func (pm *PriceMonitor) evictStaleShard(shard *priceCacheShard, staleness map[string]time.Duration, now time.Time) {
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	for key, sources := range shard.sources {
+		evicted := false
+		for source, priceData := range sources {
+			maxAge, known := staleness[source]
+			if !known {
+				maxAge = defaultMaxStaleness
+			}
+			if now.Sub(priceData.Timestamp) > maxAge {
+				delete(sources, source)
+				evicted = true
+			}
+		}
+		switch {
+		case len(sources) == 0:
+			delete(shard.sources, key)
+			delete(shard.aggregates, key)
+		case evicted:
+			if aggregate := shard.aggregates[key]; aggregate != nil {
+				shard.aggregates[key] = pm.aggregatePrices(aggregate.Token0, aggregate.Token1, sources)
 			}
-			
-			pm.mutex.Unlock()
 		}
 	}
 }
 
-// getCacheKey generates a cache key for a token pair
+// getCacheKey generates a cache key for a token pair, given by address or by symbol
 func (pm *PriceMonitor) getCacheKey(token0, token1 string) string {
+	// Addresses may be checksummed or not depending on where they came from
+	token0, token1 = strings.ToLower(pm.tokenAddress(token0)), strings.ToLower(pm.tokenAddress(token1))
 	if token0 < token1 {
 		return fmt.Sprintf("%s_%s", token0, token1)
 	}
 	return fmt.Sprintf("%s_%s", token1, token0)
 }
 
-// parsePoolID parses a pool ID to extract token addresses (simplified)
+// tokenAddress returns the hex address of a token named by symbol, and any other
+// token unchanged
+func (pm *PriceMonitor) tokenAddress(token string) string {
+	if pm.tokens == nil || common.IsHexAddress(token) {
+		return token
+	}
+	if address, err := pm.tokens.ResolveAddress(token); err == nil {
+		return address.Hex()
+	}
+	return token
+}
+
+// parsePoolID resolves a Uniswap v4 pool ID to the pool's token addresses
 func (pm *PriceMonitor) parsePoolID(poolID string) (string, string, error) {
-	// This is a simplified implementation
-	// In reality, you'd decode the pool ID properly
-	return "0x1234567890123456789012345678901234567890", "0x0987654321098765432109876543210987654321", nil
+	pool, err := pm.pools.Lookup(poolID)
+	if err != nil {
+		return "", "", err
+	}
+	return pool.Token0.Hex(), pool.Token1.Hex(), nil
 }
 
 // GetCacheSize returns the current cache size
 func (pm *PriceMonitor) GetCacheSize() int {
-	pm.mutex.RLock()
-	defer pm.mutex.RUnlock()
-	return len(pm.cache)
+	return pm.cache.len()
 }
 
 // GetAllPrices returns all cached prices
 func (pm *PriceMonitor) GetAllPrices() map[string]*types.PriceData {
-	pm.mutex.RLock()
-	defer pm.mutex.RUnlock()
-	
-	result := make(map[string]*types.PriceData)
-	for key, value := range pm.cache {
-		result[key] = value
+	return pm.cache.all()
+}
+
+// GetPairPrice returns the cached aggregate price of a pair, whether or not it is stale
+func (pm *PriceMonitor) GetPairPrice(token0, token1 string) (*types.PriceData, bool) {
+	return pm.cache.get(pm.getCacheKey(token0, token1))
+}
+
+// FeedHealth returns the circuit breaker state of each price feed, keyed by feed name
+func (pm *PriceMonitor) FeedHealth() map[string]FeedHealth {
+	health := make(map[string]FeedHealth, len(pm.breakers))
+	for name, breaker := range pm.breakers {
+		health[name] = breaker.health()
+	}
+	return health
+}
+
+// GetAlertMetrics returns price deviation alert metrics, or nil if alerts are disabled
+func (pm *PriceMonitor) GetAlertMetrics() map[string]interface{} {
+	if pm.alerts == nil {
+		return nil
 	}
-	return result
+	return pm.alerts.Metrics()
 }
@@ -0,0 +1,548 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// newTestPriceFeed serves a fixed price, quoted at normalizedPriceDecimals, for
+// every pair and returns its feed config
+func newTestPriceFeed(t *testing.T, name, price string, timestamp time.Time) types.PriceFeedConfig {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"price":     price,
+			"timestamp": timestamp.Unix(),
+			"source":    name,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return types.PriceFeedConfig{
+		Name: name,
+		URL:  server.URL,
+		Pairs: []types.TokenPair{
+			{Token0: "0xa", Token1: "0xb", Symbol: "AB", Decimals: normalizedPriceDecimals, IsActive: true},
+		},
+	}
+}
+
+func TestPriceMonitorDiscrepancyAcrossFeeds(t *testing.T) {
+	now := time.Now()
+	feeds := []types.PriceFeedConfig{
+		newTestPriceFeed(t, "binance", "2000000000", now),
+		newTestPriceFeed(t, "coinbase", "2010000000", now), // 0.5% above binance
+		newTestPriceFeed(t, "kraken", "2030000000", now),   // 1.5% above binance
+	}
+
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	for _, feed := range feeds {
+		pm.updatePrices(context.Background(), feed)
+	}
+
+	bps, err := pm.GetDiscrepancyBps("0xa", "0xb")
+	if err != nil {
+		t.Fatalf("GetDiscrepancyBps: %v", err)
+	}
+	if bps != 150 {
+		t.Fatalf("discrepancy = %d bps, want 150", bps)
+	}
+
+	priceData := pm.cache.lookup(pm.getCacheKey("0xb", "0xa"))
+	if priceData.Discrepancy.Cmp(big.NewInt(150)) != 0 {
+		t.Fatalf("PriceData.Discrepancy = %s, want 150", priceData.Discrepancy)
+	}
+	if priceData.Price.Cmp(big.NewInt(2010000000)) != 0 {
+		t.Fatalf("price = %s, want median 2010000000", priceData.Price)
+	}
+	if priceData.Source != "binance,coinbase,kraken" || priceData.IsStale {
+		t.Fatalf("unexpected aggregate source %q stale=%v", priceData.Source, priceData.IsStale)
+	}
+}
+
+func TestAggregationRejectsOutliers(t *testing.T) {
+	now := time.Now()
+	feeds := []types.PriceFeedConfig{
+		newTestPriceFeed(t, "binance", "2000000000", now),
+		newTestPriceFeed(t, "coinbase", "2010000000", now),
+		newTestPriceFeed(t, "kraken", "2020000000", now),
+		newTestPriceFeed(t, "manipulated", "3000000000", now), // 50% above the others
+	}
+
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{OutlierBps: 500}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	for _, feed := range feeds {
+		pm.updatePrices(context.Background(), feed)
+	}
+
+	aggregate := pm.cache.lookup(pm.getCacheKey("0xa", "0xb"))
+	if aggregate.Price.Int64() != 2010000000 || aggregate.Discrepancy.Int64() != 100 {
+		t.Fatalf("aggregate price %s discrepancy %s, want 2010000000 and 100 without the outlier", aggregate.Price, aggregate.Discrepancy)
+	}
+	if aggregate.Source != "binance,coinbase,kraken" {
+		t.Fatalf("aggregate source = %q, want the outlier excluded", aggregate.Source)
+	}
+}
+
+func TestAggregationWeightedMean(t *testing.T) {
+	now := time.Now()
+	binance := newTestPriceFeed(t, "binance", "2000000000", now)
+	binance.Weight = 3
+	coinbase := newTestPriceFeed(t, "coinbase", "2040000000", now) // unweighted defaults to 1
+	feeds := []types.PriceFeedConfig{binance, coinbase}
+
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{Method: "weighted_mean"}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	for _, feed := range feeds {
+		pm.updatePrices(context.Background(), feed)
+	}
+
+	if price := pm.cache.lookup(pm.getCacheKey("0xa", "0xb")).Price; price.Int64() != 2010000000 {
+		t.Fatalf("weighted mean = %s, want 2010000000", price)
+	}
+
+	if _, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{Method: "mode"}, nil, nil, newTestLogger()); err == nil {
+		t.Fatal("expected an unknown aggregation method to be rejected")
+	}
+}
+
+func TestPriceMonitorDiscrepancyIgnoresStaleFeeds(t *testing.T) {
+	now := time.Now()
+	feeds := []types.PriceFeedConfig{
+		newTestPriceFeed(t, "binance", "2000000000", now),
+		newTestPriceFeed(t, "coinbase", "2000000000", now),
+		newTestPriceFeed(t, "kraken", "3000000000", now.Add(-2*time.Hour)),
+	}
+
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	pm.updatePrices(context.Background(), feeds[0])
+
+	if bps, _ := pm.GetDiscrepancyBps("0xa", "0xb"); bps != 0 {
+		t.Fatalf("single source discrepancy = %d bps, want 0", bps)
+	}
+
+	pm.updatePrices(context.Background(), feeds[1])
+	pm.updatePrices(context.Background(), feeds[2])
+
+	if bps, _ := pm.GetDiscrepancyBps("0xa", "0xb"); bps != 0 {
+		t.Fatalf("discrepancy = %d bps, want stale kraken price ignored", bps)
+	}
+	if _, err := pm.GetDiscrepancyBps("0xa", "0xc"); err == nil {
+		t.Fatal("expected error for unknown pair")
+	}
+}
+
+func TestPoolSourceSetsComputeIndependentDiscrepancy(t *testing.T) {
+	cex := testPool
+	cex.Sources = []string{"binance", "coinbase"}
+	dex := testPool
+	dex.Fee = 500
+	dex.Sources = []string{"kraken", "uniswap"}
+
+	pools, err := NewPoolRegistry([]types.PoolConfig{cex, dex}, "", newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+	var feeds []types.PriceFeedConfig
+	for _, name := range []string{"binance", "coinbase", "kraken", "uniswap"} {
+		feeds = append(feeds, types.PriceFeedConfig{Name: name})
+	}
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, pools, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+
+	now := time.Now()
+	for source, price := range map[string]int64{
+		"binance":  2000000,
+		"coinbase": 2002000, // 0.1% above binance
+		"kraken":   1900000,
+		"uniswap":  1995000, // 5% above kraken
+	} {
+		pm.updateCache(cex.Currency0, cex.Currency1, source, &types.PriceData{Price: big.NewInt(price), Timestamp: now})
+	}
+
+	cexID := ComputePoolID(common.HexToAddress(cex.Currency0), common.HexToAddress(cex.Currency1), cex.Fee, cex.TickSpacing, common.HexToAddress(cex.Hooks))
+	dexID := ComputePoolID(common.HexToAddress(dex.Currency0), common.HexToAddress(dex.Currency1), dex.Fee, dex.TickSpacing, common.HexToAddress(dex.Hooks))
+
+	for _, tc := range []struct {
+		poolID      string
+		discrepancy int64
+		price       int64
+	}{
+		{cexID.Hex(), 10, 2001000},
+		{dexID.Hex(), 500, 1947500},
+	} {
+		priceData, err := pm.GetPriceData(tc.poolID)
+		if err != nil {
+			t.Fatalf("GetPriceData(%s): %v", tc.poolID, err)
+		}
+		if priceData.Discrepancy.Int64() != tc.discrepancy || priceData.Price.Int64() != tc.price {
+			t.Fatalf("pool %s: discrepancy %s bps at price %s, want %d bps at %d",
+				tc.poolID, priceData.Discrepancy, priceData.Price, tc.discrepancy, tc.price)
+		}
+	}
+
+	// The pair level discrepancy still spans every source
+	if bps, _ := pm.GetDiscrepancyBps(cex.Currency0, cex.Currency1); bps != 536 {
+		t.Fatalf("pair discrepancy = %d bps, want 536", bps)
+	}
+}
+
+func TestNewPriceMonitorRejectsUnknownPoolSources(t *testing.T) {
+	pool := testPool
+	pool.Sources = []string{"missing"}
+	pools, err := NewPoolRegistry([]types.PoolConfig{pool}, "", newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+	if _, err := NewPriceMonitor(nil, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, pools, nil, newTestLogger()); err == nil {
+		t.Fatal("expected a pool source without a configured feed to be rejected")
+	}
+}
+
+func TestPriceStalenessFollowsFeedConfig(t *testing.T) {
+	now := time.Now()
+	fast := newTestPriceFeed(t, "fast", "2000000000", now.Add(-30*time.Second))
+	fast.MaxStaleness = 10
+	slow := newTestPriceFeed(t, "slow", "2000000000", now.Add(-30*time.Second))
+
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{fast, slow}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	for _, tc := range []struct {
+		feed  types.PriceFeedConfig
+		stale bool
+	}{{fast, true}, {slow, false}} {
+		priceData, err := pm.fetchPrice(context.Background(), tc.feed, tc.feed.Pairs[0])
+		if err != nil {
+			t.Fatalf("fetchPrice %s: %v", tc.feed.Name, err)
+		}
+		if priceData.IsStale != tc.stale {
+			t.Fatalf("%s price 30s old stale = %v, want %v", tc.feed.Name, priceData.IsStale, tc.stale)
+		}
+		pm.updatePrices(context.Background(), tc.feed)
+	}
+
+	// Eviction drops only the fast feed's price, leaving the slow feed's aggregate
+	pm.evictStale(now)
+	key := pm.getCacheKey("0xa", "0xb")
+	if _, ok := pm.cache.source(key, "fast"); ok {
+		t.Fatal("expected the fast feed's price to be evicted")
+	}
+	if aggregate := pm.cache.lookup(key); aggregate == nil || aggregate.Source != "slow" {
+		t.Fatalf("unexpected aggregate after eviction %+v", aggregate)
+	}
+
+	pm.evictStale(now.Add(time.Hour))
+	if _, ok := pm.cache.get(key); ok {
+		t.Fatal("expected the pair to be evicted once past the default staleness")
+	}
+}
+
+func TestGetPriceDataRejectsPricesAgedInCache(t *testing.T) {
+	pools, err := NewPoolRegistry([]types.PoolConfig{testPool}, "", newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+	// Neither feed marks its prices stale when fetched, as the Uniswap feed never does
+	feeds := []types.PriceFeedConfig{{Name: "fast", MaxStaleness: 10}, {Name: "slow"}}
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, pools, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	key := pm.getCacheKey(testPool.Currency0, testPool.Currency1)
+	age := func(source string, by time.Duration) {
+		t.Helper()
+		priceData, ok := pm.cache.source(key, source)
+		if !ok {
+			t.Fatalf("no cached %s price", source)
+		}
+		priceData.Timestamp = priceData.Timestamp.Add(-by)
+	}
+
+	pm.updateCache(testPool.Currency0, testPool.Currency1, "fast", &types.PriceData{Price: big.NewInt(2000), Timestamp: time.Now()})
+	if _, err := pm.GetPriceData(testPoolID); err != nil {
+		t.Fatalf("GetPriceData of a fresh price: %v", err)
+	}
+
+	// The cached aggregate outlives the fast feed's MaxStaleness without a refetch
+	age("fast", 11*time.Second)
+	if _, err := pm.GetPriceData(testPoolID); !errors.Is(err, ErrPriceStale) {
+		t.Fatalf("GetPriceData of an aged price = %v, want ErrPriceStale", err)
+	}
+
+	// Aggregating leaves the aged price out
+	pm.updateCache(testPool.Currency0, testPool.Currency1, "slow", &types.PriceData{Price: big.NewInt(2100), Timestamp: time.Now()})
+	priceData, err := pm.GetPriceData(testPoolID)
+	if err != nil || priceData.Source != "slow" || priceData.Price.Cmp(big.NewInt(2100)) != 0 {
+		t.Fatalf("GetPriceData = %+v, %v; want only the slow feed's price", priceData, err)
+	}
+	age("slow", 2*time.Hour)
+	if _, err := pm.GetPriceData(testPoolID); !errors.Is(err, ErrPriceStale) {
+		t.Fatalf("GetPriceData once every price aged = %v, want ErrPriceStale", err)
+	}
+}
+
+// newFlakyPriceFeed serves status for the first failures requests and a price afterwards
+func newFlakyPriceFeed(t *testing.T, status, failures int, requests *atomic.Int32) types.PriceFeedConfig {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= int32(failures) {
+			http.Error(w, "unavailable", status)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"price":     "2000000000",
+			"timestamp": time.Now().Unix(),
+			"source":    "flaky",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return types.PriceFeedConfig{
+		Name:  "flaky",
+		URL:   server.URL,
+		Pairs: []types.TokenPair{{Token0: "0xa", Token1: "0xb", Symbol: "AB", Decimals: normalizedPriceDecimals, IsActive: true}},
+	}
+}
+
+func TestFetchPriceRetriesTransientFailures(t *testing.T) {
+	var requests atomic.Int32
+	feed := newFlakyPriceFeed(t, http.StatusServiceUnavailable, 2, &requests)
+
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	pm.retryBackoff = time.Millisecond
+
+	pm.pollFeed(context.Background(), feed)
+	if got := requests.Load(); got != 3 {
+		t.Fatalf("feed requested %d times, want 3", got)
+	}
+	priceData, ok := pm.cache.get(pm.getCacheKey("0xa", "0xb"))
+	if !ok || priceData.Price.Int64() != 2000000000 {
+		t.Fatalf("expected the price to be cached after retrying, got %+v", priceData)
+	}
+	if health := pm.FeedHealth()["flaky"]; health.ConsecutiveFailures != 0 {
+		t.Fatalf("retried poll counted as failed: %+v", health)
+	}
+}
+
+func TestFetchPriceDoesNotRetryClientErrors(t *testing.T) {
+	var requests atomic.Int32
+	feed := newFlakyPriceFeed(t, http.StatusNotFound, 1, &requests)
+
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	pm.retryBackoff = time.Millisecond
+
+	if _, err := pm.fetchPrice(context.Background(), feed, feed.Pairs[0]); err == nil {
+		t.Fatal("expected a 404 to fail the fetch")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("feed requested %d times, want a 404 not to be retried", got)
+	}
+}
+
+func TestNormalizePrice(t *testing.T) {
+	for _, tc := range []struct {
+		price      string
+		from, to   int
+		normalized string
+	}{
+		{"200012345678", 8, 18, "2000123456780000000000"},
+		{"2000123456780000000000", 18, 8, "200012345678"},
+		// Digits below the target precision are truncated
+		{"2000123456789999999999", 18, 6, "2000123456"},
+		{"42", 6, 6, "42"},
+	} {
+		price, _ := new(big.Int).SetString(tc.price, 10)
+		if got := NormalizePrice(price, tc.from, tc.to); got.String() != tc.normalized {
+			t.Fatalf("NormalizePrice(%s, %d, %d) = %s, want %s", tc.price, tc.from, tc.to, got, tc.normalized)
+		}
+	}
+}
+
+func TestDiscrepancyComparesNormalizedPrices(t *testing.T) {
+	now := time.Now()
+	// The same $2000 price quoted with 8 and 18 decimals, and $2010 with 6
+	eightDecimals := newTestPriceFeed(t, "chainlink-style", "200000000000", now)
+	eightDecimals.Pairs[0].Decimals = 8
+	eighteenDecimals := newTestPriceFeed(t, "wei", "2000000000000000000000", now)
+	sixDecimals := newTestPriceFeed(t, "usdc", "2010000000", now)
+	sixDecimals.Pairs[0].Decimals = 6
+	feeds := []types.PriceFeedConfig{eightDecimals, eighteenDecimals, sixDecimals}
+
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	for _, feed := range feeds {
+		pm.updatePrices(context.Background(), feed)
+	}
+
+	if bps, _ := pm.GetDiscrepancyBps("0xa", "0xb"); bps != 50 {
+		t.Fatalf("discrepancy = %d bps, want 50 between the normalized prices", bps)
+	}
+	aggregate := pm.cache.lookup(pm.getCacheKey("0xa", "0xb"))
+	if aggregate.Price.String() != "2000000000000000000000" || aggregate.Decimals != normalizedPriceDecimals {
+		t.Fatalf("aggregate price = %s with %d decimals, want the normalized median", aggregate.Price, aggregate.Decimals)
+	}
+
+	eightDecimals.Pairs[0].Decimals = -1
+	if _, err := NewPriceMonitor([]types.PriceFeedConfig{eightDecimals}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger()); err == nil {
+		t.Fatal("expected negative decimals to be rejected")
+	}
+}
+
+func TestFetchPriceHonorsCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	feed := types.PriceFeedConfig{
+		Name:  "slow",
+		URL:   server.URL,
+		Pairs: []types.TokenPair{{Token0: "0xa", Token1: "0xb", Symbol: "AB", IsActive: true}},
+	}
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = pm.fetchPrice(ctx, feed, feed.Pairs[0])
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("fetchPrice error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("fetchPrice returned %s after cancellation, want promptly", elapsed)
+	}
+
+	// A poll cut short by shutdown leaves the feed's breaker untouched
+	pm.pollFeed(ctx, feed)
+	if health := pm.FeedHealth()[feed.Name]; health.ConsecutiveFailures != 0 {
+		t.Fatalf("cancelled poll recorded as a failure: %+v", health)
+	}
+}
+
+func TestPriceMonitorErrorsAreTyped(t *testing.T) {
+	coord := newFakeCoordinator()
+	op := newTestOperator(t, coord)
+	pm := op.priceMonitor
+
+	token0, token1, err := pm.parsePoolID(testPoolID)
+	if err != nil {
+		t.Fatalf("parsePoolID: %v", err)
+	}
+	key := pm.getCacheKey(token0, token1)
+
+	pm.cache.lookup(key).IsStale = true
+	if _, err := pm.GetPriceData(testPoolID); !errors.Is(err, ErrPriceStale) {
+		t.Fatalf("GetPriceData error = %v, want ErrPriceStale", err)
+	}
+
+	pm.cache.remove(key)
+	if _, err := pm.GetPriceData(testPoolID); !errors.Is(err, ErrPriceNotFound) {
+		t.Fatalf("GetPriceData error = %v, want ErrPriceNotFound", err)
+	}
+	if _, err := pm.GetPriceDiscrepancy(token0, token1); !errors.Is(err, ErrPriceNotFound) {
+		t.Fatalf("GetPriceDiscrepancy error = %v, want ErrPriceNotFound", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	feed := types.PriceFeedConfig{Name: "down", URL: server.URL, MaxRetries: -1}
+	if _, err := pm.fetchPrice(context.Background(), feed, types.TokenPair{Token0: "0xa", Token1: "0xb"}); !errors.Is(err, ErrFeedUnavailable) {
+		t.Fatalf("fetchPrice error = %v, want ErrFeedUnavailable", err)
+	}
+}
+
+func TestPriceMonitorStoppedAfterCancel(t *testing.T) {
+	polling := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case polling <- struct{}{}:
+		default:
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	feed := types.PriceFeedConfig{
+		Name:       "slow",
+		URL:        server.URL,
+		UpdateFreq: 1,
+		Pairs:      []types.TokenPair{{Token0: "0xa", Token1: "0xb", Symbol: "AB", IsActive: true}},
+	}
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+
+	select {
+	case <-pm.Stopped():
+	default:
+		t.Fatal("expected a monitor that was never started to be stopped")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pm.Start(ctx)
+	select {
+	case <-pm.Stopped():
+		t.Fatal("monitor reported stopped while running")
+	default:
+	}
+
+	// Cancel while the feed goroutine is blocked in a poll
+	select {
+	case <-polling:
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the feed to be polled")
+	}
+	cancel()
+
+	select {
+	case <-pm.Stopped():
+	case <-time.After(time.Second):
+		t.Fatal("expected every goroutine to return after cancellation")
+	}
+}
@@ -0,0 +1,126 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Price server health statuses reported by GET /health
+const (
+	priceHealthHealthy   = "healthy"
+	priceHealthDegraded  = "degraded"
+	priceHealthUnhealthy = "unhealthy"
+)
+
+// priceServerAddress returns where cached prices are served, or "" if they aren't
+func (o *Operator) priceServerAddress() string {
+	if o.config.PriceServerAddress != "" {
+		return o.config.PriceServerAddress
+	}
+	if o.config.MetricsPort > 0 {
+		return fmt.Sprintf(":%d", o.config.MetricsPort+1)
+	}
+	return ""
+}
+
+// priceHandler routes the API inspecting the price monitor's cache, and taking
+// the sealed bids of auctions:
+//
+//   - GET /prices: every cached aggregate price, keyed by pair
+//   - GET /price/{token0}/{token1}: the cached price of a pair, by token address or symbol, even if stale
+//   - GET /price/{poolId}: the fresh price a task on the pool is validated against
+//   - GET /health: the price feeds' circuit breakers
+//   - POST /bids: commit a sealed bid, signed by its bidder, to an open auction
+//   - POST /bids/root: commit a Merkle root of sealed bids to an open auction, with
+//     each leaf signed by its bidder
+//   - POST /bids/reveal: reveal a committed bid's amount and salt, signed by its bidder
+//   - GET /bids/{auctionId}: the bids of an auction and whether each was revealed
+func (o *Operator) priceHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/prices", o.handleGetPrices)
+	mux.HandleFunc("/price/", o.handleGetPrice)
+	mux.HandleFunc("/health", o.handlePriceHealth)
+	mux.HandleFunc("/bids", o.handleCommitBid)
+	mux.HandleFunc("/bids/root", o.handleCommitBidRoot)
+	mux.HandleFunc("/bids/reveal", o.handleRevealBid)
+	mux.HandleFunc("/bids/", o.handleListBids)
+	return mux
+}
+
+// handleGetPrices writes GetAllPrices as a JSON object
+func (o *Operator) handleGetPrices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(o.priceMonitor.GetAllPrices())
+}
+
+// handleGetPrice writes the cached price of a pair or pool
+func (o *Operator) handleGetPrice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/price/"), "/"), "/")
+	var priceData interface{}
+	switch len(segments) {
+	case 1:
+		poolPrice, err := o.priceMonitor.GetPriceData(segments[0])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		priceData = poolPrice
+	case 2:
+		pairPrice, exists := o.priceMonitor.GetPairPrice(segments[0], segments[1])
+		if !exists {
+			http.Error(w, fmt.Sprintf("no price data for pair %s/%s", segments[0], segments[1]), http.StatusNotFound)
+			return
+		}
+		priceData = pairPrice
+	default:
+		http.Error(w, "Expected /price/{token0}/{token1} or /price/{poolId}", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(priceData)
+}
+
+// handlePriceHealth reports the price feeds' health. The monitor is degraded
+// while some feed's circuit breaker is open, and unhealthy with 503 when all are.
+func (o *Operator) handlePriceHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	feeds := o.priceMonitor.FeedHealth()
+	open := 0
+	for _, health := range feeds {
+		if health.State == breakerOpen {
+			open++
+		}
+	}
+	status, code := priceHealthHealthy, http.StatusOK
+	switch {
+	case len(feeds) > 0 && open == len(feeds):
+		status, code = priceHealthUnhealthy, http.StatusServiceUnavailable
+	case open > 0:
+		status = priceHealthDegraded
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":        status,
+		"cached_prices": o.priceMonitor.GetCacheSize(),
+		"feeds":         feeds,
+	})
+}
@@ -0,0 +1,110 @@
+package operator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// getPriceJSON decodes the JSON body of a GET request and returns its status code
+func getPriceJSON(t *testing.T, url string, out interface{}) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s: %v", url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestPriceServerServesCachedPrices(t *testing.T) {
+	op := newTestOperator(t, newFakeCoordinator())
+	server := httptest.NewServer(op.priceHandler())
+	defer server.Close()
+
+	token0, token1, err := op.priceMonitor.parsePoolID(testPoolID)
+	if err != nil {
+		t.Fatalf("parsePoolID: %v", err)
+	}
+
+	var prices map[string]types.PriceData
+	if status := getPriceJSON(t, server.URL+"/prices", &prices); status != http.StatusOK || len(prices) != 1 {
+		t.Fatalf("GET /prices = %d with %d prices, want the seeded price", status, len(prices))
+	}
+
+	// Pairs are looked up in either order
+	for _, path := range []string{"/price/" + token0 + "/" + token1, "/price/" + token1 + "/" + token0, "/price/" + testPoolID} {
+		var priceData types.PriceData
+		if status := getPriceJSON(t, server.URL+path, &priceData); status != http.StatusOK || priceData.Price.Int64() != 2000e6 {
+			t.Fatalf("GET %s = %d with %+v, want the seeded price", path, status, priceData)
+		}
+	}
+
+	for path, want := range map[string]int{
+		"/price/0xa/0xc": http.StatusNotFound,
+		"/price/0xabc":   http.StatusNotFound,
+		"/price/a/b/c":   http.StatusBadRequest,
+	} {
+		if status := getPriceJSON(t, server.URL+path, nil); status != want {
+			t.Fatalf("GET %s = %d, want %d", path, status, want)
+		}
+	}
+}
+
+func TestPriceServerHealthReflectsFeedBreakers(t *testing.T) {
+	op := newTestOperator(t, newFakeCoordinator())
+	server := httptest.NewServer(op.priceHandler())
+	defer server.Close()
+
+	up, down := newFeedBreaker(types.PriceFeedConfig{Name: "up"}), newFeedBreaker(types.PriceFeedConfig{Name: "down"})
+	op.priceMonitor.breakers = map[string]*feedBreaker{"up": up, "down": down}
+
+	expectStatus := func(wantCode int, wantStatus string) {
+		t.Helper()
+		var health struct {
+			Status       string                `json:"status"`
+			CachedPrices int                   `json:"cached_prices"`
+			Feeds        map[string]FeedHealth `json:"feeds"`
+		}
+		if code := getPriceJSON(t, server.URL+"/health", &health); code != wantCode || health.Status != wantStatus {
+			t.Fatalf("GET /health = %d %q, want %d %q", code, health.Status, wantCode, wantStatus)
+		}
+		if health.CachedPrices != 1 || len(health.Feeds) != 2 {
+			t.Fatalf("unexpected health %+v", health)
+		}
+	}
+
+	expectStatus(http.StatusOK, priceHealthHealthy)
+	for i := 0; i < defaultFailureThreshold; i++ {
+		down.record(errors.New("unavailable"))
+	}
+	expectStatus(http.StatusOK, priceHealthDegraded)
+	for i := 0; i < defaultFailureThreshold; i++ {
+		up.record(errors.New("unavailable"))
+	}
+	expectStatus(http.StatusServiceUnavailable, priceHealthUnhealthy)
+}
+
+func TestPriceServerAddressDefaultsNextToMetrics(t *testing.T) {
+	op := newTestOperator(t, newFakeCoordinator())
+	if addr := op.priceServerAddress(); addr != "" {
+		t.Fatalf("address = %q, want the server disabled without metrics", addr)
+	}
+	op.config.MetricsPort = 8080
+	if addr := op.priceServerAddress(); addr != ":8081" {
+		t.Fatalf("address = %q, want :8081", addr)
+	}
+	op.config.PriceServerAddress = "127.0.0.1:9000"
+	if addr := op.priceServerAddress(); addr != "127.0.0.1:9000" {
+		t.Fatalf("address = %q, want the configured address", addr)
+	}
+}
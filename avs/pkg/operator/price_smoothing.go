@@ -0,0 +1,58 @@
+package operator
+
+import (
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// smoothingScale is the fixed point precision smoothing alphas are applied at
+const smoothingScale = 1_000_000
+
+// smoothPrice returns the price of source to cache, the exponential moving average
+// of its prices when its feed smooths them, keeping the quoted price as RawPrice.
+// The average restarts from the quoted price when previous, the cached price of
+// source, is missing or stale.
+func (pm *PriceMonitor) smoothPrice(source string, previous, priceData *types.PriceData) *types.PriceData {
+	alpha, smooths := pm.smoothing[source]
+	if !smooths || priceData.Price == nil {
+		return priceData
+	}
+
+	smoothed := *priceData
+	smoothed.RawPrice = priceData.Price
+	if previous == nil || previous.IsStale || previous.Price == nil || previous.Price.Sign() <= 0 ||
+		priceData.IsStale || priceData.Price.Sign() <= 0 {
+		return &smoothed
+	}
+	smoothed.Price = emaPrice(previous.Price, priceData.Price, alpha)
+	return &smoothed
+}
+
+// emaPrice moves average towards price by alpha of the difference
+func emaPrice(average, price *big.Int, alpha float64) *big.Int {
+	step := new(big.Int).Sub(price, average)
+	step.Mul(step, big.NewInt(int64(math.Round(alpha*smoothingScale))))
+	step.Quo(step, big.NewInt(smoothingScale))
+	return step.Add(step, average)
+}
+
+// rawPrices returns prices with their raw prices in place of smoothed ones, sorted
+// in ascending order, and whether any of them was smoothed
+func rawPrices(prices []sourcePrice) ([]sourcePrice, bool) {
+	raw := make([]sourcePrice, len(prices))
+	smoothed := false
+	for i, source := range prices {
+		raw[i] = sourcePrice{name: source.name, price: source.price}
+		if source.raw != nil {
+			raw[i].price = source.raw
+			smoothed = true
+		}
+	}
+	if smoothed {
+		sort.Slice(raw, func(i, j int) bool { return raw[i].price.Cmp(raw[j].price) < 0 })
+	}
+	return raw, smoothed
+}
@@ -0,0 +1,108 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// newSettablePriceFeed serves the price stored in price, quoted at
+// normalizedPriceDecimals, for the test pool's pair
+func newSettablePriceFeed(t *testing.T, name string, price *atomic.Value) types.PriceFeedConfig {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"price": price.Load(), "timestamp": time.Now().Unix()})
+	}))
+	t.Cleanup(server.Close)
+	return types.PriceFeedConfig{
+		Name: name,
+		URL:  server.URL,
+		Pairs: []types.TokenPair{
+			{Token0: testPool.Currency0, Token1: testPool.Currency1, Symbol: "AB", Decimals: normalizedPriceDecimals, IsActive: true},
+		},
+	}
+}
+
+func TestPriceSmoothingConvergesToSustainedPrice(t *testing.T) {
+	var smoothPrice, rawPrice atomic.Value
+	smoothPrice.Store("1000000")
+	rawPrice.Store("1000000")
+	smooth := newSettablePriceFeed(t, "smooth", &smoothPrice)
+	smooth.SmoothingAlpha = 0.5
+	raw := newSettablePriceFeed(t, "raw", &rawPrice)
+
+	pools, err := NewPoolRegistry([]types.PoolConfig{testPool}, "", newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{smooth, raw}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, pools, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	ctx := context.Background()
+	key := pm.getCacheKey(testPool.Currency0, testPool.Currency1)
+	smoothed := func() *types.PriceData {
+		shard := pm.cache.shard(key)
+		shard.mutex.RLock()
+		defer shard.mutex.RUnlock()
+		return shard.sources[key]["smooth"]
+	}
+
+	// The first price seeds the average
+	pm.updatePrices(ctx, smooth)
+	if got := smoothed(); got.Price.Int64() != 1_000_000 || got.RawPrice.Int64() != 1_000_000 {
+		t.Fatalf("first smoothed price = %s (raw %s), want the quoted 1000000", got.Price, got.RawPrice)
+	}
+
+	// Both feeds move to a new level; the smoothed one halves its gap each update
+	smoothPrice.Store("2000000")
+	rawPrice.Store("2000000")
+	pm.updatePrices(ctx, raw)
+	want := []int64{1_500_000, 1_750_000, 1_875_000, 1_937_500}
+	for i, price := range want {
+		pm.updatePrices(ctx, smooth)
+		if got := smoothed().Price.Int64(); got != price {
+			t.Fatalf("smoothed price after update %d = %d, want %d", i+1, got, price)
+		}
+	}
+
+	priceData, err := pm.GetPriceData(testPoolID)
+	if err != nil {
+		t.Fatalf("GetPriceData: %v", err)
+	}
+	// The aggregate is the median of the smoothed and raw feeds, 1937500 and 2000000
+	if priceData.Price.Int64() != 1_968_750 || priceData.RawPrice.Int64() != 2_000_000 {
+		t.Fatalf("aggregate price = %s (raw %s), want 1968750 (raw 2000000)", priceData.Price, priceData.RawPrice)
+	}
+	// The feeds quote the same raw price, so smoothing lag is no discrepancy
+	if priceData.Discrepancy.Sign() != 0 {
+		t.Fatalf("discrepancy = %s bps, want 0 from the raw prices", priceData.Discrepancy)
+	}
+
+	for i := 0; i < 30; i++ {
+		pm.updatePrices(ctx, smooth)
+	}
+	gap := new(big.Int).Sub(big.NewInt(2_000_000), smoothed().Price)
+	if gap.Sign() < 0 || gap.Int64() > 1 {
+		t.Fatalf("smoothed price = %s after 34 updates, want it converged to 2000000", smoothed().Price)
+	}
+}
+
+func TestPriceSmoothingAlphaMustBeAFraction(t *testing.T) {
+	var price atomic.Value
+	price.Store("1000000")
+	for _, alpha := range []float64{-0.1, 1.5} {
+		feed := newSettablePriceFeed(t, "smooth", &price)
+		feed.SmoothingAlpha = alpha
+		if _, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger()); err == nil {
+			t.Fatalf("expected a smoothing alpha of %g to be rejected", alpha)
+		}
+	}
+}
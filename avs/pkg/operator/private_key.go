@@ -0,0 +1,77 @@
+package operator
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	// PrivateKeyEnv is the environment variable holding the operator's ECDSA key
+	// when private_key_source is env
+	PrivateKeyEnv = "OPERATOR_PRIVATE_KEY"
+	// PrivateKeyPasswordEnv is the environment variable holding the ECDSA
+	// keystore password when no private_key_password_file is configured
+	PrivateKeyPasswordEnv = "OPERATOR_ECDSA_KEY_PASSWORD"
+)
+
+// loadPrivateKey reads the operator's ECDSA key from the configured source.
+// Errors name the source but never include the key.
+func loadPrivateKey(config *types.OperatorConfig) (*ecdsa.PrivateKey, error) {
+	switch config.PrivateKeySource {
+	case "", types.PrivateKeySourceInline:
+		return parsePrivateKey(config.PrivateKey, "private_key")
+	case types.PrivateKeySourceEnv:
+		hexKey := os.Getenv(PrivateKeyEnv)
+		if hexKey == "" {
+			return nil, fmt.Errorf("%s is not set", PrivateKeyEnv)
+		}
+		return parsePrivateKey(hexKey, PrivateKeyEnv)
+	case types.PrivateKeySourceKeystore:
+		return decryptKeystore(config.PrivateKeyStorePath, config.PrivateKeyPasswordFile)
+	default:
+		return nil, fmt.Errorf("unknown private key source %q", config.PrivateKeySource)
+	}
+}
+
+// parsePrivateKey parses a hex ECDSA key, with or without its 0x prefix
+func parsePrivateKey(hexKey, source string) (*ecdsa.PrivateKey, error) {
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return privateKey, nil
+}
+
+// decryptKeystore decrypts the Ethereum keystore at path with the password in
+// passwordFile, or in PrivateKeyPasswordEnv when passwordFile is empty
+func decryptKeystore(path, passwordFile string) (*ecdsa.PrivateKey, error) {
+	password := os.Getenv(PrivateKeyPasswordEnv)
+	if passwordFile != "" {
+		data, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keystore password: %w", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: wrong password", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return key.PrivateKey, nil
+}
@@ -0,0 +1,89 @@
+package operator
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const testHexKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+// writeTestKeystore encrypts the test key with password into a keystore file
+func writeTestKeystore(t *testing.T, password string) string {
+	t.Helper()
+	privateKey, err := crypto.HexToECDSA(testHexKey)
+	if err != nil {
+		t.Fatalf("HexToECDSA: %v", err)
+	}
+	key := &keystore.Key{Address: crypto.PubkeyToAddress(privateKey.PublicKey), PrivateKey: privateKey}
+	keyJSON, err := keystore.EncryptKey(key, password, keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKey: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "operator.ecdsa.key.json")
+	if err := os.WriteFile(path, keyJSON, 0o600); err != nil {
+		t.Fatalf("write keystore: %v", err)
+	}
+	return path
+}
+
+func TestLoadPrivateKeyFromKeystore(t *testing.T) {
+	path := writeTestKeystore(t, "hunter2")
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatalf("write password: %v", err)
+	}
+
+	config := &types.OperatorConfig{PrivateKeySource: types.PrivateKeySourceKeystore, PrivateKeyStorePath: path, PrivateKeyPasswordFile: passwordFile}
+	privateKey, err := loadPrivateKey(config)
+	if err != nil {
+		t.Fatalf("loadPrivateKey from a password file: %v", err)
+	}
+	if got := hex.EncodeToString(crypto.FromECDSA(privateKey)); got != testHexKey {
+		t.Fatal("decrypted key does not match the encrypted one")
+	}
+
+	// Without a password file the password comes from the environment
+	config.PrivateKeyPasswordFile = ""
+	t.Setenv(PrivateKeyPasswordEnv, "hunter2")
+	if _, err := loadPrivateKey(config); err != nil {
+		t.Fatalf("loadPrivateKey with the env password: %v", err)
+	}
+
+	t.Setenv(PrivateKeyPasswordEnv, "wrong")
+	if _, err := loadPrivateKey(config); err == nil || !strings.Contains(err.Error(), "wrong password") {
+		t.Fatalf("loadPrivateKey with a wrong password = %v, want a wrong password error", err)
+	}
+}
+
+func TestLoadPrivateKeyFromEnv(t *testing.T) {
+	config := &types.OperatorConfig{PrivateKeySource: types.PrivateKeySourceEnv}
+
+	t.Setenv(PrivateKeyEnv, "")
+	if _, err := loadPrivateKey(config); err == nil {
+		t.Fatal("expected an unset key variable to fail")
+	}
+
+	t.Setenv(PrivateKeyEnv, "0x"+testHexKey+"\n")
+	privateKey, err := loadPrivateKey(config)
+	if err != nil {
+		t.Fatalf("loadPrivateKey from env: %v", err)
+	}
+	if got := hex.EncodeToString(crypto.FromECDSA(privateKey)); got != testHexKey {
+		t.Fatal("key read from env does not match")
+	}
+
+	// A malformed key is reported without echoing it
+	malformed := "0x" + strings.Repeat("zz", 32)
+	t.Setenv(PrivateKeyEnv, malformed)
+	if _, err := loadPrivateKey(config); err == nil || strings.Contains(err.Error(), malformed[2:]) {
+		t.Fatalf("loadPrivateKey of a malformed key = %v, want an error without the key", err)
+	}
+}
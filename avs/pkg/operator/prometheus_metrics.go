@@ -0,0 +1,175 @@
+package operator
+
+import (
+	"math/big"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// operatorMetrics are the operator's Prometheus metrics, served on
+// /metrics/prometheus of the metrics port:
+//
+//   - lvr_operator_tasks_seen_total: tasks the operator started processing
+//   - lvr_operator_tasks_processed_total{outcome}: tasks finished, by decision outcome
+//   - lvr_operator_responses_submitted_total: task responses the aggregator accepted
+//   - lvr_operator_submit_errors_total: task responses that failed to submit
+//   - lvr_operator_price_fetch_errors_total{feed}: failed price fetches, by feed
+//   - lvr_operator_auction_discrepancy_bps{pool_id}: cross-source price discrepancy
+//     of each pool's latest validated auction
+//   - lvr_operator_transactions_total{action,status}: finished on-chain transactions,
+//     confirmed, reverted or dropped
+//   - lvr_operator_pending_transactions: transactions awaiting their outcome
+//
+// All methods are no-ops on a nil *operatorMetrics.
+type operatorMetrics struct {
+	registry           *prometheus.Registry
+	tasksSeen          prometheus.Counter
+	tasksProcessed     *prometheus.CounterVec
+	responsesSubmitted prometheus.Counter
+	submitErrors       prometheus.Counter
+	priceFetchErrors   *prometheus.CounterVec
+	auctionDiscrepancy *prometheus.GaugeVec
+	transactions       *prometheus.CounterVec
+	pendingTxs         prometheus.Gauge
+	priceAnomalies     *prometheus.CounterVec
+}
+
+func newOperatorMetrics() *operatorMetrics {
+	const namespace = "lvr_operator"
+	m := &operatorMetrics{
+		registry: prometheus.NewRegistry(),
+		tasksSeen: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_seen_total",
+			Help:      "Tasks the operator started processing",
+		}),
+		tasksProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_processed_total",
+			Help:      "Tasks finished, by decision outcome",
+		}, []string{"outcome"}),
+		responsesSubmitted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "responses_submitted_total",
+			Help:      "Task responses accepted by the aggregator",
+		}),
+		submitErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "submit_errors_total",
+			Help:      "Task responses that failed to submit",
+		}),
+		priceFetchErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "price_fetch_errors_total",
+			Help:      "Failed price fetches, by feed",
+		}, []string{"feed"}),
+		auctionDiscrepancy: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "auction_discrepancy_bps",
+			Help:      "Cross-source price discrepancy of each pool's latest validated auction, in basis points",
+		}, []string{"pool_id"}),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transactions_total",
+			Help:      "Finished on-chain transactions, by action and status",
+		}, []string{"action", "status"}),
+		pendingTxs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pending_transactions",
+			Help:      "On-chain transactions awaiting confirmation",
+		}),
+		priceAnomalies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "price_anomalies_total",
+			Help:      "Prices rejected for deviating from their pair's rolling median, by feed",
+		}, []string{"feed"}),
+	}
+	m.registry.MustRegister(
+		m.tasksSeen,
+		m.tasksProcessed,
+		m.responsesSubmitted,
+		m.submitErrors,
+		m.priceFetchErrors,
+		m.auctionDiscrepancy,
+		m.transactions,
+		m.pendingTxs,
+		m.priceAnomalies,
+	)
+	return m
+}
+
+// handler serves the metrics in the Prometheus exposition format
+func (m *operatorMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// observeTaskSeen records a task the operator started processing
+func (m *operatorMetrics) observeTaskSeen() {
+	if m == nil {
+		return
+	}
+	m.tasksSeen.Inc()
+}
+
+// observeTaskProcessed records a task that finished with outcome
+func (m *operatorMetrics) observeTaskProcessed(outcome string) {
+	if m == nil {
+		return
+	}
+	m.tasksProcessed.WithLabelValues(outcome).Inc()
+}
+
+// observeSubmission records the result of submitting a task response
+func (m *operatorMetrics) observeSubmission(err error) {
+	if m == nil {
+		return
+	}
+	if err != nil {
+		m.submitErrors.Inc()
+		return
+	}
+	m.responsesSubmitted.Inc()
+}
+
+// observePriceFetchError records a failed price fetch from feed
+func (m *operatorMetrics) observePriceFetchError(feed string) {
+	if m == nil {
+		return
+	}
+	m.priceFetchErrors.WithLabelValues(feed).Inc()
+}
+
+// observePriceAnomaly records a price from feed rejected as anomalous
+func (m *operatorMetrics) observePriceAnomaly(feed string) {
+	if m == nil {
+		return
+	}
+	m.priceAnomalies.WithLabelValues(feed).Inc()
+}
+
+// observeDiscrepancy records the price discrepancy an auction of a pool was validated at
+func (m *operatorMetrics) observeDiscrepancy(poolID string, discrepancyBps *big.Int) {
+	if m == nil || discrepancyBps == nil {
+		return
+	}
+	bps, _ := new(big.Float).SetInt(discrepancyBps).Float64()
+	m.auctionDiscrepancy.WithLabelValues(poolID).Set(bps)
+}
+
+// observeTransaction records the outcome of an on-chain transaction sent for action
+func (m *operatorMetrics) observeTransaction(action, status string) {
+	if m == nil {
+		return
+	}
+	m.transactions.WithLabelValues(action, status).Inc()
+}
+
+// observePendingTransactions records how many transactions await their outcome
+func (m *operatorMetrics) observePendingTransactions(pending int) {
+	if m == nil {
+		return
+	}
+	m.pendingTxs.Set(float64(pending))
+}
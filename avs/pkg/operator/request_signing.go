@@ -0,0 +1,49 @@
+package operator
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// OperatorSignatureHeader carries the operator's ECDSA signature over the body of
+// requests sent to the aggregator
+const OperatorSignatureHeader = "X-Operator-Signature"
+
+// SignRequestBody produces the OperatorSignatureHeader value for body: a 65 byte
+// signature over the EIP-191 personal message hash of the raw body, hex encoded
+// with its recovery id as 27 or 28
+func SignRequestBody(privateKey *ecdsa.PrivateKey, body []byte) (string, error) {
+	signature, err := crypto.Sign(accounts.TextHash(body), privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign request body: %w", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(signature), nil
+}
+
+// recoverSigner returns the address that produced a SignRequestBody signature
+// over message
+func recoverSigner(message []byte, signature string) (common.Address, error) {
+	if signature == "" {
+		return common.Address{}, errors.New("missing signature")
+	}
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, errors.New("malformed signature")
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubkey, err := crypto.SigToPub(accounts.TextHash(message), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
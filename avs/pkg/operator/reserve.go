@@ -0,0 +1,40 @@
+package operator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// parseWei parses a configured decimal wei amount, nil when empty
+func parseWei(amount string) (*big.Int, error) {
+	if amount == "" {
+		return nil, nil
+	}
+	wei, ok := new(big.Int).SetString(amount, 10)
+	if !ok || wei.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not a non-negative integer", amount)
+	}
+	return wei, nil
+}
+
+// reservePrice returns the smallest winning bid the auction settles at: its own
+// reserve, else its pool's configured reserve, else the LVR the discrepancy is
+// estimated to cost the pool's liquidity. It is nil when the auction has no reserve.
+func (o *Operator) reservePrice(auction *types.Auction, discrepancyBps *big.Int) *big.Int {
+	if auction.ReservePrice != nil {
+		return auction.ReservePrice
+	}
+	pool, err := o.pools.Lookup(auction.PoolID)
+	switch {
+	case err != nil:
+		return nil
+	case pool.Reserve != nil:
+		return pool.Reserve
+	case pool.Liquidity != nil && discrepancyBps != nil:
+		reserve := new(big.Int).Mul(pool.Liquidity, discrepancyBps)
+		return reserve.Quo(reserve, big.NewInt(10_000))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package operator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestValidateAuctionEnforcesReservePrice(t *testing.T) {
+	op := newTestOperator(t, newFakeCoordinator())
+	token0, token1, _ := op.priceMonitor.parsePoolID(testPoolID)
+	op.priceMonitor.cache.lookup(op.priceMonitor.getCacheKey(token0, token1)).Discrepancy = big.NewInt(80)
+
+	// Pools of the test pair, whose auctions' only bid is 100 wei at an 80 bps discrepancy
+	fee := uint32(1000)
+	register := func(reserve, liquidity int64) string {
+		t.Helper()
+		fee++
+		info := PoolInfo{
+			Token0:      common.HexToAddress(testPool.Currency0),
+			Token1:      common.HexToAddress(testPool.Currency1),
+			Fee:         fee,
+			TickSpacing: 60,
+		}
+		if reserve > 0 {
+			info.Reserve = big.NewInt(reserve)
+		}
+		if liquidity > 0 {
+			info.Liquidity = big.NewInt(liquidity)
+		}
+		if err := op.pools.Register(info); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+		return ComputePoolID(info.Token0, info.Token1, info.Fee, info.TickSpacing, info.Hooks).Hex()
+	}
+
+	cases := []struct {
+		name    string
+		poolID  string
+		reserve *big.Int
+		winner  string
+	}{
+		{"no reserve", testPoolID, nil, testBidder},
+		{"bid at the auction reserve", testPoolID, big.NewInt(100), testBidder},
+		{"bid below the auction reserve", testPoolID, big.NewInt(101), ""},
+		{"bid at the pool reserve", register(100, 0), nil, testBidder},
+		{"bid below the pool reserve", register(101, 0), nil, ""},
+		{"auction reserve overrides the pool's", register(101, 0), big.NewInt(100), testBidder},
+		// 12500 * 80 bps = 100
+		{"bid at the estimated LVR", register(0, 12_500), nil, testBidder},
+		// 12625 * 80 bps = 101
+		{"bid below the estimated LVR", register(0, 12_625), nil, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auction := &types.Auction{ID: "auction-" + tc.name, PoolID: tc.poolID, BlockNumber: 1, State: types.AuctionBiddingOpen, ReservePrice: tc.reserve}
+			winner, winningBid, err := op.validateAuction(auction)
+			if err != nil {
+				t.Fatalf("validateAuction: %v", err)
+			}
+			if winner != tc.winner {
+				t.Fatalf("winner = %q, want %q", winner, tc.winner)
+			}
+			if winner == "" && winningBid.Sign() != 0 {
+				t.Fatalf("winning bid = %s without a winner, want 0", winningBid)
+			}
+		})
+	}
+}
+
+func TestNewPoolRegistryRejectsMalformedReserve(t *testing.T) {
+	pool := testPool
+	pool.ReserveWei = "1e18"
+	if _, err := NewPoolRegistry([]types.PoolConfig{pool}, "", newTestLogger()); err == nil {
+		t.Fatal("expected a reserve_wei that is not an integer to be rejected")
+	}
+}
@@ -0,0 +1,118 @@
+package operator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+var (
+	// ErrNoValidBids is returned when an auction has no correctly revealed bid
+	ErrNoValidBids = errors.New("no valid revealed bids")
+	// ErrBidNotRevealed is returned for a committed bid that was never revealed
+	ErrBidNotRevealed = errors.New("bid not revealed")
+	// ErrCommitmentMismatch is returned when a revealed bid does not hash to its commitment
+	ErrCommitmentMismatch = errors.New("revealed bid does not match commitment")
+	// ErrBiddingClosed is returned for a commitment arriving after the bidding window
+	ErrBiddingClosed = errors.New("bidding window closed")
+	// ErrRevealClosed is returned for a reveal arriving after the reveal window
+	ErrRevealClosed = errors.New("reveal window closed")
+	// ErrBidAlreadyRevealed is returned for a commitment or reveal replacing a bid
+	// that was revealed already
+	ErrBidAlreadyRevealed = errors.New("bid already revealed")
+	// ErrBidderSignature is returned for a bid request not signed by its bidder
+	ErrBidderSignature = errors.New("bid not signed by its bidder")
+)
+
+// BidCommitment returns the sealed bid commitment keccak256(amount, salt, bidder),
+// with the amount packed as a uint256 and the bidder as a 20 byte address
+func BidCommitment(amount *big.Int, salt common.Hash, bidder common.Address) common.Hash {
+	return crypto.Keccak256Hash(math.U256Bytes(new(big.Int).Set(amount)), salt.Bytes(), bidder.Bytes())
+}
+
+// BidCommitMessage is the message a bidder signs, like a request body under
+// OperatorSignatureHeader, to commit commitment to an auction
+func BidCommitMessage(auctionID string, commitment common.Hash) []byte {
+	return []byte(fmt.Sprintf("lvr_bid:commit:%s:%s", auctionID, commitment.Hex()))
+}
+
+// BidRevealMessage is the message a bidder signs to reveal the bid sealed by
+// commitment, along with the settlement data it is executed with
+func BidRevealMessage(auctionID string, commitment common.Hash, settlementData string) []byte {
+	return []byte(fmt.Sprintf("lvr_bid:reveal:%s:%s:%s", auctionID, commitment.Hex(), settlementData))
+}
+
+// verifyBidder checks that signature over message was produced by bidder
+func verifyBidder(message []byte, signature, bidder string) error {
+	signer, err := recoverSigner(message, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBidderSignature, err)
+	}
+	if !common.IsHexAddress(bidder) || signer != common.HexToAddress(bidder) {
+		return fmt.Errorf("%w: signed by %s", ErrBidderSignature, signer.Hex())
+	}
+	return nil
+}
+
+// verifyReveal checks that a bid was revealed and that its amount, salt and
+// bidder hash to the commitment it was sealed with
+func verifyReveal(bid types.Bid) error {
+	if !bid.Revealed || bid.Amount == nil || bid.Salt == "" {
+		return ErrBidNotRevealed
+	}
+	if bid.Amount.Sign() < 0 {
+		return fmt.Errorf("negative bid amount %s", bid.Amount)
+	}
+	if !common.IsHexAddress(bid.Bidder) {
+		return fmt.Errorf("invalid bidder address %q", bid.Bidder)
+	}
+	salt, err := hexutil.Decode(bid.Salt)
+	if err != nil || len(salt) != common.HashLength {
+		return fmt.Errorf("invalid bid salt %q", bid.Salt)
+	}
+	commitment, err := hexutil.Decode(bid.Commitment)
+	if err != nil || len(commitment) != common.HashLength {
+		return fmt.Errorf("invalid bid commitment %q", bid.Commitment)
+	}
+
+	if BidCommitment(bid.Amount, common.BytesToHash(salt), common.HexToAddress(bid.Bidder)) != common.BytesToHash(commitment) {
+		return ErrCommitmentMismatch
+	}
+	return nil
+}
+
+// verifyRevealIn checks a bid's reveal and, for a bid revealed under a Merkle
+// root, that its commitment is included in a root committed to the auction
+func verifyRevealIn(auction *types.Auction, bid types.Bid) error {
+	if err := verifyReveal(bid); err != nil {
+		return err
+	}
+	return verifyBidInclusion(auction, bid)
+}
+
+// revealedBids returns the bids of an auction whose reveals match their
+// commitments. Unrevealed and mismatched bids, and those without a valid proof
+// of inclusion in their bid root, are rejected.
+func (o *Operator) revealedBids(auction *types.Auction, bids []types.Bid) []types.Bid {
+	valid := make([]types.Bid, 0, len(bids))
+	for _, bid := range bids {
+		if err := verifyRevealIn(auction, bid); err != nil {
+			o.logger.WithError(err).WithFields(logrus.Fields{
+				"auction_id": auction.ID,
+				"bidder":     bid.Bidder,
+				"commitment": bid.Commitment,
+			}).Warn("Rejecting sealed bid")
+			continue
+		}
+		valid = append(valid, bid)
+	}
+	return valid
+}
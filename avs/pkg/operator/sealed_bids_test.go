@@ -0,0 +1,79 @@
+package operator
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+func TestVerifyReveal(t *testing.T) {
+	salt := common.HexToHash("0x5a17")
+
+	if err := verifyReveal(newRevealedBid(bidderA, 500, salt)); err != nil {
+		t.Fatalf("valid reveal rejected: %v", err)
+	}
+
+	mismatched := newRevealedBid(bidderA, 500, salt)
+	mismatched.Amount = big.NewInt(5000)
+	if err := verifyReveal(mismatched); !errors.Is(err, ErrCommitmentMismatch) {
+		t.Fatalf("mismatched amount error = %v, want ErrCommitmentMismatch", err)
+	}
+
+	otherBidder := newRevealedBid(bidderA, 500, salt)
+	otherBidder.Bidder = bidderB
+	if err := verifyReveal(otherBidder); !errors.Is(err, ErrCommitmentMismatch) {
+		t.Fatalf("mismatched bidder error = %v, want ErrCommitmentMismatch", err)
+	}
+
+	unrevealed := types.Bid{Bidder: bidderA, Commitment: newRevealedBid(bidderA, 500, salt).Commitment}
+	if err := verifyReveal(unrevealed); !errors.Is(err, ErrBidNotRevealed) {
+		t.Fatalf("unrevealed bid error = %v, want ErrBidNotRevealed", err)
+	}
+}
+
+func TestValidateAuctionSelectsHighestValidReveal(t *testing.T) {
+	coord := newFakeCoordinator()
+	auction := &types.Auction{ID: "auction-1", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.auctions[auction.ID] = auction
+
+	// The highest bid doesn't match its commitment and the second highest was never revealed
+	mismatched := newRevealedBid(bidderA, 900, common.HexToHash("0x01"))
+	mismatched.Amount = big.NewInt(9000)
+	unrevealed := newRevealedBid(bidderB, 800, common.HexToHash("0x02"))
+	unrevealed.Revealed, unrevealed.Amount = false, nil
+	valid := newRevealedBid(testBidder, 300, common.HexToHash("0x03"))
+	coord.bids[auction.ID] = []types.Bid{mismatched, unrevealed, valid}
+
+	op := newTestOperator(t, coord)
+	winner, winningBid, err := op.validateAuction(auction)
+	if err != nil {
+		t.Fatalf("validateAuction: %v", err)
+	}
+	if winner != testBidder || winningBid.Int64() != 300 {
+		t.Fatalf("winner = %s with %s, want %s with 300", winner, winningBid, testBidder)
+	}
+}
+
+func TestValidateAuctionFailsWithoutValidBids(t *testing.T) {
+	coord := newFakeCoordinator()
+	auction := &types.Auction{ID: "auction-1", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.auctions[auction.ID] = auction
+	unrevealed := newRevealedBid(bidderA, 500, common.HexToHash("0x01"))
+	unrevealed.Revealed = false
+	coord.bids[auction.ID] = []types.Bid{unrevealed}
+
+	op := newTestOperator(t, coord)
+	if _, _, err := op.validateAuction(auction); !errors.Is(err, ErrNoValidBids) {
+		t.Fatalf("validateAuction error = %v, want ErrNoValidBids", err)
+	}
+
+	op.processTask(&types.Task{ID: 1, AuctionID: auction.ID, PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)})
+	if coord.responses[1] != nil {
+		t.Fatal("expected no response for an auction without valid bids")
+	}
+}
@@ -0,0 +1,159 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// defaultSettlementTimeout bounds a single settlement simulation
+const defaultSettlementTimeout = 5 * time.Second
+
+var (
+	// ErrSettlementReverted is returned when a bid's settlement reverts in simulation
+	ErrSettlementReverted = errors.New("settlement reverted in simulation")
+	// ErrSettlementUnavailable is returned when a bid's settlement could not be
+	// simulated, which says nothing about whether it can execute
+	ErrSettlementUnavailable = errors.New("settlement simulation unavailable")
+)
+
+// settlementSimulator checks that a winning bid can be settled on chain before the
+// operator signs that its bidder won
+type settlementSimulator interface {
+	SimulateSettlement(ctx context.Context, auction *types.Auction, bid types.Bid) error
+}
+
+// ethCallSettlementSimulator simulates the winner's settlement transaction, their
+// arbitrage calldata paying the bid to the settlement target, with eth_call
+type ethCallSettlementSimulator struct {
+	caller   ethereum.ContractCaller
+	target   common.Address
+	gasLimit uint64
+	timeout  time.Duration
+}
+
+// newSettlementSimulator creates a simulator from config, or returns nil if
+// simulation is disabled
+func newSettlementSimulator(config *types.OperatorConfig, caller ethereum.ContractCaller) (settlementSimulator, error) {
+	if !config.SettlementSimulation.Enabled {
+		return nil, nil
+	}
+
+	target := config.SettlementSimulation.TargetAddress
+	if target == "" {
+		target = config.NetworkConfig.ContractAddresses.Get(types.ContractLVRHook)
+	}
+	if !common.IsHexAddress(target) {
+		return nil, fmt.Errorf("invalid settlement target address %q", target)
+	}
+
+	timeout := time.Duration(config.SettlementSimulation.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultSettlementTimeout
+	}
+
+	return &ethCallSettlementSimulator{
+		caller:   caller,
+		target:   common.HexToAddress(target),
+		gasLimit: config.SettlementSimulation.GasLimit,
+		timeout:  timeout,
+	}, nil
+}
+
+func (s *ethCallSettlementSimulator) SimulateSettlement(ctx context.Context, auction *types.Auction, bid types.Bid) error {
+	if !common.IsHexAddress(bid.Bidder) {
+		return fmt.Errorf("invalid bidder address %q", bid.Bidder)
+	}
+
+	var data []byte
+	if bid.SettlementData != "" {
+		var err error
+		data, err = hexutil.Decode(bid.SettlementData)
+		if err != nil {
+			return fmt.Errorf("invalid settlement data: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	msg := ethereum.CallMsg{
+		From:  common.HexToAddress(bid.Bidder),
+		To:    &s.target,
+		Gas:   s.gasLimit,
+		Value: bid.Amount,
+		Data:  data,
+	}
+	if _, err := s.caller.CallContract(ctx, msg, nil); err != nil {
+		if isExecutionRevert(err) {
+			return fmt.Errorf("%w: %v", ErrSettlementReverted, err)
+		}
+		return fmt.Errorf("%w: %v", ErrSettlementUnavailable, err)
+	}
+	return nil
+}
+
+// isExecutionRevert reports whether an eth_call error is the call reverting, as
+// opposed to the node being unreachable or failing to serve it
+func isExecutionRevert(err error) bool {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "execution reverted")
+}
+
+// selectSettleableBid returns the highest bid whose settlement succeeds in
+// simulation. Bidders whose settlement reverts are disqualified. It reports false
+// if no bid can settle, and returns ErrSettlementUnavailable if a settlement
+// could not be simulated.
+func (o *Operator) selectSettleableBid(auction *types.Auction, bids []types.Bid) (types.Bid, bool, error) {
+	ranked := make([]types.Bid, 0, len(bids))
+	for _, bid := range bids {
+		if bid.Amount != nil && bid.Amount.Sign() > 0 {
+			ranked = append(ranked, bid)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Amount.Cmp(ranked[j].Amount) > 0
+	})
+
+	for _, bid := range ranked {
+		if o.settlement == nil {
+			return bid, true, nil
+		}
+
+		// In-flight tasks finish during shutdown, so simulation outlives the operator context
+		err := o.settlement.SimulateSettlement(context.WithoutCancel(o.ctx), auction, bid)
+		if err == nil {
+			return bid, true, nil
+		}
+		// Passing over a bid the node failed to simulate would name the wrong winner
+		if errors.Is(err, ErrSettlementUnavailable) {
+			return types.Bid{}, false, err
+		}
+
+		o.metricsMux.Lock()
+		o.disqualifiedBids++
+		o.metricsMux.Unlock()
+
+		o.logger.WithError(err).WithFields(logrus.Fields{
+			"auction_id": auction.ID,
+			"bidder":     bid.Bidder,
+			"bid":        bid.Amount.String(),
+		}).Warn("Disqualifying bidder whose settlement cannot execute")
+	}
+
+	return types.Bid{}, false, nil
+}
@@ -0,0 +1,162 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// fakeSettlementSimulator reverts the settlement of the configured bidders, and
+// fails to simulate every settlement while unavailable is set
+type fakeSettlementSimulator struct {
+	mutex       sync.Mutex
+	reverts     map[string]bool
+	unavailable bool
+	simulated   []string
+}
+
+func (f *fakeSettlementSimulator) SimulateSettlement(ctx context.Context, auction *types.Auction, bid types.Bid) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.simulated = append(f.simulated, bid.Bidder)
+	if f.unavailable {
+		return ErrSettlementUnavailable
+	}
+	if f.reverts[bid.Bidder] {
+		return ErrSettlementReverted
+	}
+	return nil
+}
+
+// fakeContractCaller records eth_call messages and returns a fixed error
+type fakeContractCaller struct {
+	msgs []ethereum.CallMsg
+	err  error
+}
+
+func (f *fakeContractCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeContractCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	f.msgs = append(f.msgs, msg)
+	return nil, f.err
+}
+
+const (
+	bidderA = "0x00000000000000000000000000000000000000aa"
+	bidderB = "0x00000000000000000000000000000000000000bb"
+)
+
+func TestSelectSettleableBidDisqualifiesRevertingWinner(t *testing.T) {
+	op := newTestOperator(t, newFakeCoordinator())
+	simulator := &fakeSettlementSimulator{reverts: map[string]bool{bidderA: true}}
+	op.settlement = simulator
+
+	auction := &types.Auction{ID: "auction-1"}
+	bids := []types.Bid{
+		{Bidder: bidderB, Amount: big.NewInt(500)},
+		{Bidder: bidderA, Amount: big.NewInt(900)},
+	}
+
+	bid, ok, err := op.selectSettleableBid(auction, bids)
+	if err != nil || !ok {
+		t.Fatal("expected a settleable bid")
+	}
+	if bid.Bidder != bidderB {
+		t.Fatalf("winner = %s, want %s after %s was disqualified", bid.Bidder, bidderB, bidderA)
+	}
+	if len(simulator.simulated) != 2 || simulator.simulated[0] != bidderA {
+		t.Fatalf("expected highest bid to be simulated first, got %v", simulator.simulated)
+	}
+	if got := op.GetMetrics()["bids_disqualified"]; got != uint64(1) {
+		t.Fatalf("bids_disqualified = %v, want 1", got)
+	}
+}
+
+func TestProcessTaskReportsNoWinnerWhenSettlementReverts(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["auction-1"] = &types.Auction{ID: "auction-1", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	op.settlement = &fakeSettlementSimulator{reverts: map[string]bool{testBidder: true}}
+
+	op.processTask(&types.Task{ID: 1, AuctionID: "auction-1", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)})
+
+	response := coord.responses[1]
+	if response == nil {
+		t.Fatal("expected a task response")
+	}
+	if response.Winner != "" || response.WinningBid.Sign() != 0 {
+		t.Fatalf("expected disqualified bidder not to be named winner, got %s with %s", response.Winner, response.WinningBid)
+	}
+}
+
+func TestEthCallSettlementSimulator(t *testing.T) {
+	caller := &fakeContractCaller{err: errors.New("execution reverted")}
+	simulator, err := newSettlementSimulator(&types.OperatorConfig{
+		SettlementSimulation: types.SettlementSimulationConfig{
+			Enabled:       true,
+			TargetAddress: "0x00000000000000000000000000000000000000cc",
+			GasLimit:      500000,
+		},
+	}, caller)
+	if err != nil {
+		t.Fatalf("newSettlementSimulator: %v", err)
+	}
+
+	bid := types.Bid{Bidder: bidderA, Amount: big.NewInt(900), SettlementData: "0xdeadbeef"}
+	err = simulator.SimulateSettlement(context.Background(), &types.Auction{ID: "auction-1"}, bid)
+	if !errors.Is(err, ErrSettlementReverted) {
+		t.Fatalf("expected ErrSettlementReverted, got %v", err)
+	}
+
+	msg := caller.msgs[0]
+	if msg.From != common.HexToAddress(bidderA) || *msg.To != common.HexToAddress("0x00000000000000000000000000000000000000cc") {
+		t.Fatalf("unexpected call addresses: from %s to %s", msg.From, msg.To)
+	}
+	if msg.Value.Cmp(bid.Amount) != 0 || msg.Gas != 500000 || common.Bytes2Hex(msg.Data) != "deadbeef" {
+		t.Fatalf("unexpected call message: %+v", msg)
+	}
+
+	// A node that can't be reached says nothing about the settlement
+	caller.err = errors.New("dial tcp 127.0.0.1:8545: connect: connection refused")
+	err = simulator.SimulateSettlement(context.Background(), &types.Auction{ID: "auction-1"}, bid)
+	if !errors.Is(err, ErrSettlementUnavailable) || errors.Is(err, ErrSettlementReverted) {
+		t.Fatalf("expected ErrSettlementUnavailable for a transport error, got %v", err)
+	}
+
+	caller.err = nil
+	if err := simulator.SimulateSettlement(context.Background(), &types.Auction{ID: "auction-1"}, bid); err != nil {
+		t.Fatalf("expected settlement to succeed, got %v", err)
+	}
+}
+
+func TestProcessTaskAbstainsWhenSettlementCannotBeSimulated(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.auctions["auction-1"] = &types.Auction{ID: "auction-1", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	op.settlement = &fakeSettlementSimulator{unavailable: true}
+
+	op.processTask(&types.Task{ID: 1, AuctionID: "auction-1", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)})
+
+	if response := coord.responses[1]; response != nil {
+		t.Fatalf("expected no response without a simulation, got %+v", response)
+	}
+	metrics := op.GetMetrics()
+	if skipped := metrics["tasks_skipped"].(map[string]uint64); skipped[skipReasonNoSimulation] != 1 {
+		t.Fatalf("tasks_skipped = %v, want one %s", skipped, skipReasonNoSimulation)
+	}
+	if disqualified := metrics["bids_disqualified"]; disqualified != uint64(0) {
+		t.Fatalf("bids_disqualified = %v, want the bidder not disqualified", disqualified)
+	}
+}
@@ -0,0 +1,158 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// blockingSettlementSimulator holds every settlement simulation until release is closed
+type blockingSettlementSimulator struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingSettlementSimulator) SimulateSettlement(ctx context.Context, auction *types.Auction, bid types.Bid) error {
+	b.started <- struct{}{}
+	<-b.release
+	return nil
+}
+
+// startSlowTask dispatches a task whose validation blocks in settlement simulation
+func startSlowTask(t *testing.T) (*Operator, *fakeCoordinator, *blockingSettlementSimulator) {
+	t.Helper()
+	coord := newFakeCoordinator()
+	coord.auctions["auction-1"] = &types.Auction{ID: "auction-1", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	simulator := &blockingSettlementSimulator{started: make(chan struct{}, 1), release: make(chan struct{})}
+	op.settlement = simulator
+
+	op.dispatchTasks([]*types.Task{{ID: 1, AuctionID: "auction-1", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)}})
+	select {
+	case <-simulator.started:
+	case <-time.After(time.Second):
+		t.Fatal("expected the task to start")
+	}
+	return op, coord, simulator
+}
+
+func TestStopWaitsForInFlightTasks(t *testing.T) {
+	op, coord, simulator := startSlowTask(t)
+
+	stopped := make(chan struct{})
+	go func() {
+		op.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a task was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Tasks are not started once stopping
+	op.dispatchTasks([]*types.Task{{ID: 2, AuctionID: "auction-1", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)}})
+
+	close(simulator.release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the task finished")
+	}
+
+	coord.mutex.Lock()
+	defer coord.mutex.Unlock()
+	if coord.responses[1] == nil {
+		t.Fatal("expected the in-flight task to submit its response")
+	}
+	if coord.responses[2] != nil {
+		t.Fatal("expected no task to start after Stop")
+	}
+}
+
+func TestStopAbandonsTasksAfterTimeout(t *testing.T) {
+	op, _, simulator := startSlowTask(t)
+	defer close(simulator.release)
+	op.shutdownTimeout = 20 * time.Millisecond
+
+	start := time.Now()
+	op.Stop()
+	if elapsed := time.Since(start); elapsed < op.shutdownTimeout || elapsed > time.Second {
+		t.Fatalf("Stop returned after %s, want the %s timeout", elapsed, op.shutdownTimeout)
+	}
+
+	op.inFlightMux.Lock()
+	defer op.inFlightMux.Unlock()
+	if _, inFlight := op.inFlightTasks[1]; !inFlight {
+		t.Fatal("expected the slow task to still be in flight")
+	}
+}
+
+// recordingSettlementSimulator reports the auction of every settlement simulation
+// it starts, and holds them until release is closed
+type recordingSettlementSimulator struct {
+	started chan string
+	release chan struct{}
+}
+
+func (r *recordingSettlementSimulator) SimulateSettlement(ctx context.Context, auction *types.Auction, bid types.Bid) error {
+	r.started <- auction.ID
+	<-r.release
+	return nil
+}
+
+func TestDispatchProcessesMostUrgentTasksFirst(t *testing.T) {
+	coord := newFakeCoordinator()
+	for i, id := range []string{"a", "b", "c", "d"} {
+		coord.auctions[id] = &types.Auction{ID: id, PoolID: testPoolID, BlockNumber: uint64(i + 1), State: types.AuctionBiddingOpen}
+	}
+
+	op := newTestOperator(t, coord)
+	op.config.Performance.MaxConcurrentTasks = 1
+	simulator := &recordingSettlementSimulator{started: make(chan string, 4), release: make(chan struct{})}
+	op.settlement = simulator
+
+	now := time.Now()
+	op.dispatchTasks([]*types.Task{
+		{ID: 3, AuctionID: "c", PoolID: testPoolID, Deadline: now.Add(3 * time.Minute)},
+		{ID: 1, AuctionID: "a", PoolID: testPoolID, Deadline: now.Add(time.Minute)},
+		{ID: 2, AuctionID: "b", PoolID: testPoolID, Deadline: now.Add(2 * time.Minute)},
+	})
+
+	nextStarted := func() string {
+		t.Helper()
+		select {
+		case id := <-simulator.started:
+			return id
+		case <-time.After(time.Second):
+			t.Fatal("expected another task to start")
+			return ""
+		}
+	}
+	if first := nextStarted(); first != "a" {
+		t.Fatalf("first task processed for auction %s, want the nearest deadline a", first)
+	}
+	select {
+	case id := <-simulator.started:
+		t.Fatalf("task for auction %s started beyond the concurrency cap", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A later but more urgent task jumps the queue
+	op.dispatchTasks([]*types.Task{{ID: 4, AuctionID: "d", PoolID: testPoolID, Deadline: now.Add(30 * time.Second)}})
+
+	close(simulator.release)
+	for _, want := range []string{"d", "b", "c"} {
+		if got := nextStarted(); got != want {
+			t.Fatalf("processed auction %s, want %s", got, want)
+		}
+	}
+	op.inFlight.Wait()
+	if len(coord.responses) != 4 {
+		t.Fatalf("expected 4 responses, got %d", len(coord.responses))
+	}
+}
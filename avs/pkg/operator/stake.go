@@ -0,0 +1,114 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// operatorStakesABI is the service manager's operatorStakes getter
+const operatorStakesABI = `[
+	{
+		"type": "function",
+		"name": "operatorStakes",
+		"stateMutability": "view",
+		"inputs": [{"name": "operator", "type": "address"}],
+		"outputs": [{"name": "", "type": "uint256"}]
+	}
+]`
+
+// stakeRefreshInterval is how long a read stake is served from cache, and how
+// often it is refreshed in the background
+const stakeRefreshInterval = time.Minute
+
+// stakeReader reads the operator's stake from the service manager, caching it
+// for stakeRefreshInterval so callers don't issue an RPC each
+type stakeReader struct {
+	caller         ethereum.ContractCaller
+	contractABI    abi.ABI
+	serviceManager common.Address
+	operator       common.Address
+	now            func() time.Time
+
+	stake     *big.Int
+	fetchedAt time.Time
+	mutex     sync.Mutex
+}
+
+func newStakeReader(caller ethereum.ContractCaller, serviceManager, operator common.Address) (*stakeReader, error) {
+	contractABI, err := abi.JSON(strings.NewReader(operatorStakesABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operator stakes ABI: %w", err)
+	}
+	return &stakeReader{
+		caller:         caller,
+		contractABI:    contractABI,
+		serviceManager: serviceManager,
+		operator:       operator,
+		now:            time.Now,
+	}, nil
+}
+
+// Stake returns the operator's stake, reading it from the service manager when
+// the cached value is missing or older than stakeRefreshInterval
+func (s *stakeReader) Stake(ctx context.Context) (*big.Int, error) {
+	s.mutex.Lock()
+	if s.stake != nil && s.now().Sub(s.fetchedAt) < stakeRefreshInterval {
+		stake := new(big.Int).Set(s.stake)
+		s.mutex.Unlock()
+		return stake, nil
+	}
+	s.mutex.Unlock()
+
+	return s.refresh(ctx)
+}
+
+// refresh reads the operator's stake from the service manager and caches it
+func (s *stakeReader) refresh(ctx context.Context) (*big.Int, error) {
+	data, err := s.contractABI.Pack("operatorStakes", s.operator)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contractCallTimeout)
+	defer cancel()
+	result, err := s.caller.CallContract(ctx, ethereum.CallMsg{To: &s.serviceManager, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("operatorStakes on service manager %s: %w", s.serviceManager.Hex(), err)
+	}
+	out, err := s.contractABI.Unpack("operatorStakes", result)
+	if err != nil {
+		return nil, err
+	}
+	stake := out[0].(*big.Int)
+
+	s.mutex.Lock()
+	s.stake, s.fetchedAt = stake, s.now()
+	s.mutex.Unlock()
+	return new(big.Int).Set(stake), nil
+}
+
+// run refreshes the cached stake every stakeRefreshInterval until ctx is cancelled
+func (s *stakeReader) run(ctx context.Context, logger *logrus.Logger) {
+	ticker := time.NewTicker(stakeRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.refresh(ctx); err != nil && ctx.Err() == nil {
+				logger.WithError(err).Warn("Failed to refresh operator stake")
+			}
+		}
+	}
+}
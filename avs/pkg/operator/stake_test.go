@@ -0,0 +1,81 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeStakeBackend answers operatorStakes calls with a fixed stake per operator
+type fakeStakeBackend struct {
+	reader *stakeReader
+	stakes map[common.Address]*big.Int
+	err    error
+	calls  int
+}
+
+func (f *fakeStakeBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeStakeBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	method, err := f.reader.contractABI.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	stake := f.stakes[args[0].(common.Address)]
+	if stake == nil {
+		stake = new(big.Int)
+	}
+	return method.Outputs.Pack(stake)
+}
+
+func TestStakeReaderCachesServiceManagerStake(t *testing.T) {
+	operator := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	stake, _ := new(big.Int).SetString("48000000000000000000", 10)
+	backend := &fakeStakeBackend{stakes: map[common.Address]*big.Int{operator: stake}}
+
+	reader, err := newStakeReader(backend, common.HexToAddress("0x00000000000000000000000000000000000000cc"), operator)
+	if err != nil {
+		t.Fatalf("newStakeReader: %v", err)
+	}
+	backend.reader = reader
+	now := time.Now()
+	reader.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		got, err := reader.Stake(context.Background())
+		if err != nil {
+			t.Fatalf("Stake: %v", err)
+		}
+		if got.Cmp(stake) != 0 {
+			t.Fatalf("stake = %s, want %s", got, stake)
+		}
+	}
+	if backend.calls != 1 {
+		t.Fatalf("made %d calls, want the cached stake to be reused", backend.calls)
+	}
+
+	// Once the cache ages out, RPC failures surface instead of a stale or mock value
+	now = now.Add(stakeRefreshInterval)
+	backend.err = errors.New("connection refused")
+	if _, err := reader.Stake(context.Background()); err == nil {
+		t.Fatal("expected the RPC failure to be returned")
+	}
+	if backend.calls != 2 {
+		t.Fatalf("made %d calls, want the expired stake to be refreshed", backend.calls)
+	}
+}
@@ -0,0 +1,227 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultLeaseTTL is how long an active instance holds the lease without renewing it
+const defaultLeaseTTL = 15 * time.Second
+
+// LeaseStore is the shared backend used to elect the active operator instance
+type LeaseStore interface {
+	// TryAcquire acquires or renews the lease for holder and reports whether holder owns it
+	TryAcquire(holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease if holder owns it
+	Release(holder string) error
+}
+
+// lease is the persisted lease record
+type lease struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// fileLeaseStore keeps the lease in a file on storage shared by all instances.
+// Each read-check-write of the lease holds an flock on a sibling lock file, so
+// instances racing for an expired lease can't both acquire it.
+type fileLeaseStore struct {
+	path  string
+	now   func() time.Time
+	mutex sync.Mutex
+}
+
+// NewFileLeaseStore creates a LeaseStore backed by the file at path
+func NewFileLeaseStore(path string) LeaseStore {
+	return &fileLeaseStore{path: path, now: time.Now}
+}
+
+func (s *fileLeaseStore) TryAcquire(holder string, ttl time.Duration) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	current, err := s.read()
+	if err != nil {
+		return false, err
+	}
+
+	now := s.now()
+	if current != nil && current.Holder != holder && now.Before(current.ExpiresAt) {
+		return false, nil
+	}
+
+	if err := s.write(&lease{Holder: holder, ExpiresAt: now.Add(ttl)}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *fileLeaseStore) Release(holder string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := s.read()
+	if err != nil || current == nil || current.Holder != holder {
+		return err
+	}
+	return os.Remove(s.path)
+}
+
+// lock takes the exclusive flock guarding the lease file, blocking while another
+// instance holds it, and returns the function releasing it
+func (s *fileLeaseStore) lock() (func(), error) {
+	file, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lease lock: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock lease: %w", err)
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
+
+func (s *fileLeaseStore) read() (*lease, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lease: %w", err)
+	}
+
+	var current lease
+	if err := json.Unmarshal(data, &current); err != nil {
+		return nil, fmt.Errorf("failed to parse lease: %w", err)
+	}
+	return &current, nil
+}
+
+func (s *fileLeaseStore) write(l *lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".lease-*")
+	if err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write lease: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// standbyElector decides whether this instance is the active operator. Only the
+// active instance processes and submits task responses; a standby keeps its price
+// monitor and task tracking warm and takes over once the active lease expires.
+type standbyElector struct {
+	store    LeaseStore
+	holder   string
+	ttl      time.Duration
+	now      func() time.Time
+	logger   *logrus.Logger
+	validTil time.Time
+	mutex    sync.RWMutex
+}
+
+// newStandbyElector creates an elector competing for the lease as holder
+func newStandbyElector(store LeaseStore, holder string, ttl time.Duration, logger *logrus.Logger) *standbyElector {
+	if ttl <= 0 {
+		ttl = defaultLeaseTTL
+	}
+
+	return &standbyElector{
+		store:  store,
+		holder: holder,
+		ttl:    ttl,
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+// Run renews or contends for the lease until ctx is cancelled, then releases it
+func (e *standbyElector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	e.tick()
+	for {
+		select {
+		case <-ctx.Done():
+			if e.IsActive() {
+				if err := e.store.Release(e.holder); err != nil {
+					e.logger.WithError(err).Warn("Failed to release active lease")
+				}
+			}
+			return
+		case <-ticker.C:
+			e.tick()
+		}
+	}
+}
+
+// tick makes a single attempt to acquire or renew the lease
+func (e *standbyElector) tick() {
+	wasActive := e.IsActive()
+	attemptedAt := e.now()
+
+	acquired, err := e.store.TryAcquire(e.holder, e.ttl)
+	if err != nil {
+		e.logger.WithError(err).Warn("Failed to renew active lease")
+	}
+
+	e.mutex.Lock()
+	if acquired {
+		// Measure validity from before the attempt so the local view never outlives the lease
+		e.validTil = attemptedAt.Add(e.ttl)
+	} else if err == nil {
+		e.validTil = time.Time{}
+	}
+	e.mutex.Unlock()
+
+	isActive := e.IsActive()
+	if isActive && !wasActive {
+		e.logger.WithField("instance", e.holder).Info("Promoted to active operator instance")
+	} else if !isActive && wasActive {
+		e.logger.WithField("instance", e.holder).Warn("Lost active lease, entering standby")
+	}
+}
+
+// IsActive reports whether this instance currently holds a valid lease
+func (e *standbyElector) IsActive() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.now().Before(e.validTil)
+}
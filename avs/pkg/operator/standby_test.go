@@ -0,0 +1,159 @@
+package operator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// fakeClock is a manually advanced clock shared by test components
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestStandbyPromotesAfterActiveFailure(t *testing.T) {
+	clock := newFakeClock()
+	store := NewFileLeaseStore(filepath.Join(t.TempDir(), "operator.lease")).(*fileLeaseStore)
+	store.now = clock.Now
+
+	ttl := 15 * time.Second
+	primary := newStandbyElector(store, "primary", ttl, newTestLogger())
+	primary.now = clock.Now
+	standby := newStandbyElector(store, "standby", ttl, newTestLogger())
+	standby.now = clock.Now
+
+	assertExclusive := func() {
+		t.Helper()
+		if primary.IsActive() && standby.IsActive() {
+			t.Fatal("both instances are active at the same time")
+		}
+	}
+
+	primary.tick()
+	standby.tick()
+	if !primary.IsActive() || standby.IsActive() {
+		t.Fatalf("expected primary active and standby passive, got %v/%v", primary.IsActive(), standby.IsActive())
+	}
+
+	// The primary keeps renewing, so the standby can't take over
+	for i := 0; i < 5; i++ {
+		clock.Advance(ttl / 3)
+		primary.tick()
+		standby.tick()
+		assertExclusive()
+		if !primary.IsActive() {
+			t.Fatal("primary lost the lease while renewing")
+		}
+	}
+
+	// The primary stops renewing; the standby waits for the lease to expire
+	clock.Advance(ttl / 2)
+	standby.tick()
+	assertExclusive()
+	if standby.IsActive() {
+		t.Fatal("standby promoted before the primary's lease expired")
+	}
+
+	clock.Advance(ttl / 2)
+	standby.tick()
+	assertExclusive()
+	if !standby.IsActive() {
+		t.Fatal("standby was not promoted after the primary's lease expired")
+	}
+
+	// A recovered primary rejoins as a standby
+	primary.tick()
+	assertExclusive()
+	if primary.IsActive() {
+		t.Fatal("recovered primary should not preempt the new active instance")
+	}
+}
+
+func TestFileLeaseStoreGrantsExpiredLeaseOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "operator.lease")
+	ttl := 15 * time.Second
+
+	// Every instance has its own store on the shared file, as separate processes do
+	for round := 0; round < 20; round++ {
+		const instances = 8
+		var wg sync.WaitGroup
+		acquired := make(chan string, instances)
+		for i := 0; i < instances; i++ {
+			store := NewFileLeaseStore(path).(*fileLeaseStore)
+			holder := fmt.Sprintf("instance-%d-%d", round, i)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok, err := store.TryAcquire(holder, ttl)
+				if err != nil {
+					t.Errorf("TryAcquire(%s): %v", holder, err)
+				}
+				if ok {
+					acquired <- holder
+				}
+			}()
+		}
+		wg.Wait()
+		close(acquired)
+
+		var holders []string
+		for holder := range acquired {
+			holders = append(holders, holder)
+		}
+		if len(holders) != 1 {
+			t.Fatalf("round %d: lease acquired by %v, want exactly one instance", round, holders)
+		}
+		if err := NewFileLeaseStore(path).Release(holders[0]); err != nil {
+			t.Fatalf("Release: %v", err)
+		}
+	}
+}
+
+func TestStandbySkipsTaskProcessing(t *testing.T) {
+	clock := newFakeClock()
+	store := NewFileLeaseStore(filepath.Join(t.TempDir(), "operator.lease")).(*fileLeaseStore)
+	store.now = clock.Now
+
+	if acquired, err := store.TryAcquire("other", time.Minute); err != nil || !acquired {
+		t.Fatalf("failed to seed lease: %v", err)
+	}
+
+	coord := newFakeCoordinator()
+	coord.auctions["auction-a"] = &types.Auction{ID: "auction-a", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+	coord.tasks = []*types.Task{{ID: 1, AuctionID: "auction-a", Deadline: time.Now().Add(time.Minute)}}
+
+	op := newTestOperator(t, coord)
+	op.elector = newStandbyElector(store, "standby", time.Minute, op.logger)
+	op.elector.now = clock.Now
+	op.elector.tick()
+
+	op.processTasks()
+	time.Sleep(20 * time.Millisecond)
+
+	coord.mutex.Lock()
+	defer coord.mutex.Unlock()
+	if len(coord.responses) != 0 {
+		t.Fatalf("standby submitted %d responses", len(coord.responses))
+	}
+}
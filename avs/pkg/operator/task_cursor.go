@@ -0,0 +1,147 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// backfillRangeBlocks caps the block range of each event query while backfilling,
+// since RPC providers limit the range eth_getLogs scans
+const backfillRangeBlocks = 5000
+
+// taskCursor persists the last processed block: every task created at or before
+// it has been responded to or has expired
+type taskCursor struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// taskCursorFile is the on-disk format of a taskCursor
+type taskCursorFile struct {
+	Block uint64 `json:"block"`
+}
+
+func newTaskCursor(path string) *taskCursor {
+	return &taskCursor{path: path}
+}
+
+// load returns the persisted block, or 0 if none was persisted yet
+func (c *taskCursor) load() (uint64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read task cursor: %w", err)
+	}
+
+	var cursor taskCursorFile
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return 0, fmt.Errorf("failed to parse task cursor: %w", err)
+	}
+	return cursor.Block, nil
+}
+
+func (c *taskCursor) save(block uint64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	data, err := json.Marshal(taskCursorFile{Block: block})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".task-cursor-*")
+	if err != nil {
+		return fmt.Errorf("failed to write task cursor: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write task cursor: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write task cursor: %w", err)
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// backfillTasks scans the NewTaskCreated events emitted after the persisted
+// cursor up to the chain head, so tasks created while the operator was offline
+// are pending again. Without a persisted cursor only new tasks are tracked.
+func (ac *AuctionCoordinator) backfillTasks(ctx context.Context, client taskEventSource) error {
+	if ac.cursor == nil {
+		return nil
+	}
+	cursor, err := ac.cursor.load()
+	if err != nil || cursor == 0 {
+		return err
+	}
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	head := header.Number.Uint64()
+	if cursor >= head {
+		return nil
+	}
+
+	ac.logger.WithFields(logrus.Fields{
+		"from_block": cursor + 1,
+		"to_block":   head,
+	}).Info("Backfilling tasks created while offline")
+
+	var found int
+	for from := cursor + 1; from <= head; from += backfillRangeBlocks {
+		query := ac.taskQuery()
+		query.FromBlock = new(big.Int).SetUint64(from)
+		query.ToBlock = new(big.Int).SetUint64(min(from+backfillRangeBlocks-1, head))
+		logs, err := client.FilterLogs(ctx, query)
+		if err != nil {
+			return err
+		}
+		found += ac.trackTaskLogs(logs)
+	}
+
+	ac.advanceHead(head, header.Time)
+	ac.saveCursor()
+	ac.logger.WithField("tasks", found).Info("Backfill complete")
+	return nil
+}
+
+// saveCursor persists the last processed block: the chain head, held back to
+// just before the oldest task still awaiting a response
+func (ac *AuctionCoordinator) saveCursor() {
+	if ac.cursor == nil {
+		return
+	}
+
+	ac.mutex.RLock()
+	block := ac.lastBlock
+	for _, task := range ac.tasks {
+		if !task.Completed && task.CreatedBlock > 0 && uint64(task.CreatedBlock) <= block && taskOpen(task, ac.lastBlockTime) {
+			block = uint64(task.CreatedBlock) - 1
+		}
+	}
+	ac.mutex.RUnlock()
+
+	if block == 0 {
+		return
+	}
+	if err := ac.cursor.save(block); err != nil {
+		ac.logger.WithError(err).Warn("Failed to persist task cursor")
+	}
+}
@@ -0,0 +1,148 @@
+package operator
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// fakeTaskChain serves the NewTaskCreated events of a chain at head, recording
+// the block ranges scanned
+type fakeTaskChain struct {
+	head   uint64
+	logs   []ethtypes.Log
+	ranges [][2]uint64
+}
+
+func (c *fakeTaskChain) HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error) {
+	return &ethtypes.Header{Number: new(big.Int).SetUint64(c.head), Time: uint64(time.Now().Unix())}, nil
+}
+
+func (c *fakeTaskChain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]ethtypes.Log, error) {
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	c.ranges = append(c.ranges, [2]uint64{from, to})
+
+	var logs []ethtypes.Log
+	for _, log := range c.logs {
+		if log.BlockNumber >= from && log.BlockNumber <= to {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (c *fakeTaskChain) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- ethtypes.Log) (ethereum.Subscription, error) {
+	return nil, ethereum.NotFound
+}
+
+// newCursorCoordinator creates a coordinator persisting its cursor at path
+func newCursorCoordinator(contractABI abi.ABI, path string) *AuctionCoordinator {
+	return &AuctionCoordinator{
+		contractABI: contractABI,
+		logger:      newTestLogger(),
+		tasks:       make(map[uint32]*types.Task),
+		auctions:    make(map[string]*types.Auction),
+		bids:        make(map[string][]types.Bid),
+		cursor:      newTaskCursor(path),
+		now:         time.Now,
+	}
+}
+
+func TestBackfillRecoversTasksMissedBetweenRuns(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(serviceManagerABI))
+	if err != nil {
+		t.Fatalf("abi.JSON: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "cursor.json")
+	ctx := context.Background()
+
+	// First run: task 1 is answered, task 2 is still unanswered at shutdown
+	chain := &fakeTaskChain{head: 100, logs: []ethtypes.Log{
+		newTaskCreatedLog(t, contractABI, 1, 100),
+	}}
+	first := newCursorCoordinator(contractABI, path)
+	first.lastBlock = 99
+	if err := first.pollTasks(ctx, chain); err != nil {
+		t.Fatalf("pollTasks: %v", err)
+	}
+	first.mutex.Lock()
+	first.tasks[1].Completed = true
+	first.mutex.Unlock()
+	first.saveCursor()
+
+	chain.head = 105
+	chain.logs = append(chain.logs, newTaskCreatedLog(t, contractABI, 2, 103))
+	if err := first.pollTasks(ctx, chain); err != nil {
+		t.Fatalf("pollTasks: %v", err)
+	}
+	if block, err := first.cursor.load(); err != nil || block != 102 {
+		t.Fatalf("cursor = %d, %v, want 102 just before the unanswered task", block, err)
+	}
+
+	// Task 3 is created while the operator is offline
+	chain.head = 110
+	chain.logs = append(chain.logs, newTaskCreatedLog(t, contractABI, 3, 108))
+	chain.ranges = nil
+
+	second := newCursorCoordinator(contractABI, path)
+	if err := second.backfillTasks(ctx, chain); err != nil {
+		t.Fatalf("backfillTasks: %v", err)
+	}
+	if len(chain.ranges) != 1 || chain.ranges[0] != [2]uint64{103, 110} {
+		t.Fatalf("scanned %v, want blocks 103 to 110", chain.ranges)
+	}
+
+	pending, _ := second.GetPendingTasks()
+	ids := make(map[uint32]bool)
+	for _, task := range pending {
+		ids[task.ID] = true
+	}
+	if len(pending) != 2 || !ids[2] || !ids[3] {
+		t.Fatalf("pending tasks %v, want the unanswered tasks 2 and 3", ids)
+	}
+	if head, _ := second.CurrentBlock(); head != 110 {
+		t.Fatalf("current block = %d, want the backfilled head", head)
+	}
+
+	// Live scanning resumes after the backfilled head
+	chain.head = 111
+	chain.ranges = nil
+	if err := second.pollTasks(ctx, chain); err != nil {
+		t.Fatalf("pollTasks: %v", err)
+	}
+	if len(chain.ranges) != 1 || chain.ranges[0] != [2]uint64{111, 111} {
+		t.Fatalf("scanned %v after backfill, want only block 111", chain.ranges)
+	}
+}
+
+func TestBackfillScansLongGapsInRanges(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(serviceManagerABI))
+	if err != nil {
+		t.Fatalf("abi.JSON: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "cursor.json")
+	if err := newTaskCursor(path).save(1000); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	chain := &fakeTaskChain{head: 1000 + 2*backfillRangeBlocks + 10}
+	ac := newCursorCoordinator(contractABI, path)
+	if err := ac.backfillTasks(context.Background(), chain); err != nil {
+		t.Fatalf("backfillTasks: %v", err)
+	}
+	if len(chain.ranges) != 3 || chain.ranges[2][1] != chain.head {
+		t.Fatalf("scanned %v, want 3 ranges up to the head", chain.ranges)
+	}
+	if block, _ := ac.cursor.load(); block != chain.head {
+		t.Fatalf("cursor = %d, want the head %d with no pending tasks", block, chain.head)
+	}
+}
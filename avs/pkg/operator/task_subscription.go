@@ -0,0 +1,165 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+const (
+	// newTaskBuffer is the number of subscribed tasks queued for the operator loop
+	newTaskBuffer = 64
+	// minReconnectBackoff is the default delay before the first reconnection attempt,
+	// doubled after each failed attempt
+	minReconnectBackoff = time.Second
+	// maxReconnectBackoff caps the delay between reconnection attempts
+	maxReconnectBackoff = time.Minute
+)
+
+// cancelReasonReorged cancels the auctions of tasks whose creation was reorged out
+const cancelReasonReorged = "reorged"
+
+// errSubscriptionClosed is returned when the websocket closes a subscription without an error
+var errSubscriptionClosed = errors.New("subscription closed")
+
+// taskSubscriber is the websocket client new tasks and chain heads are streamed over
+type taskSubscriber interface {
+	taskEventSource
+	SubscribeNewHead(ctx context.Context, ch chan<- *ethtypes.Header) (ethereum.Subscription, error)
+	Close()
+}
+
+func dialTaskSubscriber(ctx context.Context, url string) (taskSubscriber, error) {
+	return ethclient.DialContext(ctx, url)
+}
+
+// subscribeTasks streams NewTaskCreated events over the websocket endpoint until
+// ctx is cancelled, reconnecting with exponential backoff whenever the connection
+// drops. The service manager is scanned instead while the subscription is down.
+func (ac *AuctionCoordinator) subscribeTasks(ctx context.Context) {
+	backoff := ac.reconnectBackoff
+	for {
+		connected, err := ac.streamTasks(ctx)
+		ac.subscribed.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = ac.reconnectBackoff
+		}
+
+		ac.logger.WithError(err).WithField("backoff", backoff).Warn("Task subscription down, polling until it reconnects")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxReconnectBackoff)
+	}
+}
+
+// streamTasks subscribes to new tasks and chain heads, and relays them until the
+// subscription fails. It reports whether the subscription was established.
+func (ac *AuctionCoordinator) streamTasks(ctx context.Context) (bool, error) {
+	client, err := ac.dial(ctx, ac.wsURL)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+
+	logs := make(chan ethtypes.Log, newTaskBuffer)
+	logSub, err := client.SubscribeFilterLogs(ctx, ac.taskQuery(), logs)
+	if err != nil {
+		return false, err
+	}
+	defer logSub.Unsubscribe()
+
+	heads := make(chan *ethtypes.Header, newTaskBuffer)
+	headSub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return false, err
+	}
+	defer headSub.Unsubscribe()
+
+	ac.subscribed.Store(true)
+	ac.logger.WithField("ws_url", ac.wsURL).Info("Subscribed to new auction tasks")
+
+	// Catch up on tasks created while the subscription was down, and hand every
+	// pending task to the operator since it stops polling once subscribed
+	if err := ac.pollTasks(ctx, client); err != nil {
+		ac.logger.WithError(err).Warn("Failed to scan for tasks missed while unsubscribed")
+	}
+	pending, _ := ac.GetPendingTasks()
+	for _, task := range pending {
+		if !ac.publishTask(ctx, task) {
+			return true, nil
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case err := <-logSub.Err():
+			if err == nil {
+				err = errSubscriptionClosed
+			}
+			return true, err
+		case err := <-headSub.Err():
+			if err == nil {
+				err = errSubscriptionClosed
+			}
+			return true, err
+		case header := <-heads:
+			ac.advanceHead(header.Number.Uint64(), header.Time)
+			ac.saveCursor()
+		case log := <-logs:
+			taskIndex, event, err := ac.decodeTaskLog(log)
+			if err != nil {
+				ac.logger.WithError(err).WithField("tx_hash", log.TxHash.Hex()).Warn("Failed to decode NewTaskCreated event")
+				continue
+			}
+			// A removed log's task was created in a block reorged out of the chain
+			if log.Removed {
+				if err := ac.cancelTask(taskIndex, cancelReasonReorged); err != nil {
+					ac.logger.WithError(err).WithField("task_id", taskIndex).Warn("Failed to cancel reorged task")
+				}
+				continue
+			}
+			ac.advanceHead(log.BlockNumber, 0)
+			if task := ac.trackTask(taskIndex, event); task != nil && !ac.publishTask(ctx, task) {
+				return true, nil
+			}
+		}
+	}
+}
+
+// advanceHead records a chain head observed by the subscription, and its
+// timestamp unless zero for unknown
+func (ac *AuctionCoordinator) advanceHead(head, timestamp uint64) {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+	if head > ac.lastBlock {
+		ac.lastBlock = head
+		if timestamp > 0 {
+			ac.lastBlockTime = time.Unix(int64(timestamp), 0)
+		}
+	}
+}
+
+// publishTask queues a task for the operator loop. It reports false if ctx was
+// cancelled first.
+func (ac *AuctionCoordinator) publishTask(ctx context.Context, task *types.Task) bool {
+	select {
+	case ac.newTasks <- task:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
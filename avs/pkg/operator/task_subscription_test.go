@@ -0,0 +1,245 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// fakeSubscription is an ethereum.Subscription failed by sending on errs
+type fakeSubscription struct {
+	errs chan error
+}
+
+func newFakeSubscription() *fakeSubscription {
+	return &fakeSubscription{errs: make(chan error, 1)}
+}
+
+func (s *fakeSubscription) Err() <-chan error { return s.errs }
+func (s *fakeSubscription) Unsubscribe()      {}
+
+// fakeTaskSubscriber is a websocket connection whose log subscription is exposed to the test
+type fakeTaskSubscriber struct {
+	head   uint64
+	logs   chan<- ethtypes.Log
+	logSub *fakeSubscription
+}
+
+func (f *fakeTaskSubscriber) HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error) {
+	return &ethtypes.Header{Number: new(big.Int).SetUint64(f.head), Time: uint64(time.Now().Unix())}, nil
+}
+
+func (f *fakeTaskSubscriber) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]ethtypes.Log, error) {
+	return nil, nil
+}
+
+func (f *fakeTaskSubscriber) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- ethtypes.Log) (ethereum.Subscription, error) {
+	f.logs, f.logSub = ch, newFakeSubscription()
+	return f.logSub, nil
+}
+
+func (f *fakeTaskSubscriber) SubscribeNewHead(ctx context.Context, ch chan<- *ethtypes.Header) (ethereum.Subscription, error) {
+	return newFakeSubscription(), nil
+}
+
+func (f *fakeTaskSubscriber) Close() {}
+
+// newTaskCreatedLog builds the NewTaskCreated event for a task created at block
+func newTaskCreatedLog(t *testing.T, contractABI abi.ABI, taskIndex uint32, block uint32) ethtypes.Log {
+	t.Helper()
+	var event newTaskCreatedEvent
+	event.Task.AuctionId = common.HexToHash("0xa1")
+	event.Task.PoolId = common.HexToHash(testPoolID)
+	event.Task.TaskCreatedBlock = block
+	event.Task.Deadline = big.NewInt(time.Now().Add(time.Minute).Unix())
+
+	data, err := contractABI.Events["NewTaskCreated"].Inputs.NonIndexed().Pack(event.Task)
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	return ethtypes.Log{
+		Topics:      []common.Hash{contractABI.Events["NewTaskCreated"].ID, common.BigToHash(big.NewInt(int64(taskIndex)))},
+		Data:        data,
+		BlockNumber: uint64(block),
+	}
+}
+
+func TestSubscribeTasksStreamsAndReconnects(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(serviceManagerABI))
+	if err != nil {
+		t.Fatalf("abi.JSON: %v", err)
+	}
+
+	var mutex sync.Mutex
+	var dials int
+	connections := make(chan *fakeTaskSubscriber, 2)
+	ac := &AuctionCoordinator{
+		contractABI: contractABI,
+		logger:      newTestLogger(),
+		wsURL:       "ws://localhost:8546",
+		dial: func(ctx context.Context, url string) (taskSubscriber, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			dials++
+			if dials == 2 {
+				return nil, errors.New("connection refused")
+			}
+			conn := &fakeTaskSubscriber{head: 100}
+			connections <- conn
+			return conn, nil
+		},
+		newTasks:         make(chan *types.Task, newTaskBuffer),
+		reconnectBackoff: time.Millisecond,
+		tasks:            make(map[uint32]*types.Task),
+		auctions:         make(map[string]*types.Auction),
+		bids:             make(map[string][]types.Bid),
+		now:              time.Now,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ac.subscribeTasks(ctx)
+
+	conn := <-connections
+	waitFor(t, ac.Subscribed)
+	conn.logs <- newTaskCreatedLog(t, contractABI, 7, 101)
+
+	select {
+	case task := <-ac.NewTasks():
+		if task.ID != 7 || task.PoolID != testPoolID || task.CreatedBlock != 101 {
+			t.Fatalf("unexpected task %+v", task)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the subscribed task to be delivered")
+	}
+	if head, _ := ac.CurrentBlock(); head != 101 {
+		t.Fatalf("current block = %d, want the block of the streamed event", head)
+	}
+
+	// Polling takes over when the connection drops, until a later dial succeeds
+	conn.logSub.errs <- errors.New("websocket closed")
+	waitFor(t, func() bool { return !ac.Subscribed() })
+
+	select {
+	case conn = <-connections:
+	case <-time.After(time.Second):
+		t.Fatal("expected the subscription to reconnect")
+	}
+	waitFor(t, ac.Subscribed)
+
+	// The task still pending is handed over again once resubscribed
+	select {
+	case task := <-ac.NewTasks():
+		if task.ID != 7 {
+			t.Fatalf("unexpected task %+v after reconnecting", task)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected pending tasks to be delivered after reconnecting")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if dials != 3 {
+		t.Fatalf("dialed %d times, want 3", dials)
+	}
+}
+
+func TestRunProcessesSubscribedTasks(t *testing.T) {
+	coord := newFakeCoordinator()
+	coord.newTasks = make(chan *types.Task)
+	coord.auctions["auction-1"] = &types.Auction{ID: "auction-1", PoolID: testPoolID, BlockNumber: 1, State: types.AuctionBiddingOpen}
+
+	op := newTestOperator(t, coord)
+	go op.run()
+
+	coord.newTasks <- &types.Task{ID: 1, AuctionID: "auction-1", PoolID: testPoolID, Deadline: time.Now().Add(time.Minute)}
+	waitFor(t, func() bool {
+		coord.mutex.Lock()
+		defer coord.mutex.Unlock()
+		return coord.responses[1] != nil
+	})
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReorgedTaskCancelsItsAuction(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(serviceManagerABI))
+	if err != nil {
+		t.Fatalf("abi.JSON: %v", err)
+	}
+	connections := make(chan *fakeTaskSubscriber, 1)
+	ac := &AuctionCoordinator{
+		contractABI: contractABI,
+		logger:      newTestLogger(),
+		wsURL:       "ws://localhost:8546",
+		dial: func(ctx context.Context, url string) (taskSubscriber, error) {
+			conn := &fakeTaskSubscriber{head: 100}
+			connections <- conn
+			return conn, nil
+		},
+		newTasks:         make(chan *types.Task, newTaskBuffer),
+		reconnectBackoff: time.Millisecond,
+		tasks:            make(map[uint32]*types.Task),
+		auctions:         make(map[string]*types.Auction),
+		bids:             make(map[string][]types.Bid),
+		now:              time.Now,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ac.subscribeTasks(ctx)
+
+	conn := <-connections
+	waitFor(t, ac.Subscribed)
+	created := newTaskCreatedLog(t, contractABI, 7, 101)
+	conn.logs <- created
+	task := <-ac.NewTasks()
+
+	// The block creating the task is reorged out before the operator responds
+	created.Removed = true
+	conn.logs <- created
+	waitFor(t, func() bool {
+		auction, err := ac.GetAuction(task.AuctionID)
+		return err == nil && auction.State == types.AuctionCancelled
+	})
+	if pending, _ := ac.GetPendingTasks(); len(pending) != 0 {
+		t.Fatalf("pending tasks = %+v, want the cancelled task excluded", pending)
+	}
+
+	// The operator abstains from the task even when inactive auctions are allowed
+	op := newTestOperator(t, newFakeCoordinator())
+	op.config.AllowInactiveAuctions = true
+	op.auctionCoord = ac
+	op.processTask(task)
+	if skipped := op.skippedTasks[skipReasonCancelledAuction]; skipped != 1 {
+		t.Fatalf("cancelled_auction skips = %d, want 1", skipped)
+	}
+
+	if err := ac.CancelAuction(task.AuctionID, "reorged"); err != nil {
+		t.Fatalf("cancelling a cancelled auction: %v", err)
+	}
+	if err := ac.CancelAuction("0xunknown", "reorged"); err == nil {
+		t.Fatal("expected cancelling an unknown auction to fail")
+	}
+}
@@ -0,0 +1,200 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// erc20MetadataABI is the subset of the ERC-20 metadata extension read to name tokens
+const erc20MetadataABI = `[
+	{
+		"type": "function",
+		"name": "symbol",
+		"stateMutability": "view",
+		"inputs": [],
+		"outputs": [{"name": "", "type": "string"}]
+	},
+	{
+		"type": "function",
+		"name": "name",
+		"stateMutability": "view",
+		"inputs": [],
+		"outputs": [{"name": "", "type": "string"}]
+	}
+]`
+
+var (
+	// ErrUnknownToken is returned for a symbol no token is known by
+	ErrUnknownToken = errors.New("unknown token")
+	// ErrTokenSymbolCollision is returned for a symbol several tokens go by, which
+	// can only be referred to by address
+	ErrTokenSymbolCollision = errors.New("token symbol is ambiguous")
+)
+
+// TokenRegistry resolves token symbols to addresses and back. Configured tokens
+// always own their symbols; tokens named by their on-chain symbol() claim theirs
+// only while no other token uses it.
+type TokenRegistry struct {
+	// caller reads the symbols of unconfigured tokens, nil to only know configured ones
+	caller      ethereum.ContractCaller
+	contractABI abi.ABI
+
+	mutex sync.RWMutex
+	// addresses maps normalized symbols to their token, and symbols the reverse
+	addresses map[string]common.Address
+	symbols   map[common.Address]string
+	// configured are the normalized symbols of configured tokens
+	configured map[string]bool
+	// ambiguous are the normalized symbols claimed on-chain by several tokens
+	ambiguous map[string]bool
+}
+
+// NewTokenRegistry creates a registry of the configured tokens. caller may be nil.
+func NewTokenRegistry(tokens []types.TokenConfig, caller ethereum.ContractCaller) (*TokenRegistry, error) {
+	contractABI, err := abi.JSON(strings.NewReader(erc20MetadataABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ERC-20 ABI: %w", err)
+	}
+
+	r := &TokenRegistry{
+		caller:      caller,
+		contractABI: contractABI,
+		addresses:   make(map[string]common.Address),
+		symbols:     make(map[common.Address]string),
+		configured:  make(map[string]bool),
+		ambiguous:   make(map[string]bool),
+	}
+	for _, token := range tokens {
+		if token.Symbol == "" || !common.IsHexAddress(token.Address) {
+			return nil, fmt.Errorf("token %q: invalid address %q", token.Symbol, token.Address)
+		}
+		key, address := normalizeSymbol(token.Symbol), common.HexToAddress(token.Address)
+		if existing, claimed := r.addresses[key]; claimed && existing != address {
+			return nil, fmt.Errorf("token %q: %w, configured for %s and %s", token.Symbol, ErrTokenSymbolCollision, existing.Hex(), address.Hex())
+		}
+		if symbol, named := r.symbols[address]; named && normalizeSymbol(symbol) != key {
+			return nil, fmt.Errorf("token %s configured as both %q and %q", address.Hex(), symbol, token.Symbol)
+		}
+		r.addresses[key] = address
+		r.symbols[address] = token.Symbol
+		r.configured[key] = true
+	}
+	return r, nil
+}
+
+// normalizeSymbol returns the form symbols are compared in
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// ResolveAddress returns the address of token, a hex address or a known symbol
+func (r *TokenRegistry) ResolveAddress(token string) (common.Address, error) {
+	if common.IsHexAddress(token) {
+		return common.HexToAddress(token), nil
+	}
+
+	key := normalizeSymbol(token)
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.ambiguous[key] && !r.configured[key] {
+		return common.Address{}, fmt.Errorf("%w: %q", ErrTokenSymbolCollision, token)
+	}
+	address, known := r.addresses[key]
+	if !known {
+		return common.Address{}, fmt.Errorf("%w: %q", ErrUnknownToken, token)
+	}
+	return address, nil
+}
+
+// Symbol returns the symbol of the token at address, reading its symbol(), or
+// its name() for tokens without a string symbol, if it is not configured
+func (r *TokenRegistry) Symbol(ctx context.Context, address common.Address) (string, error) {
+	r.mutex.RLock()
+	symbol, known := r.symbols[address]
+	r.mutex.RUnlock()
+	if known {
+		return symbol, nil
+	}
+	if r.caller == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownToken, address.Hex())
+	}
+
+	symbol, err := r.readString(ctx, address, "symbol")
+	if err != nil || symbol == "" {
+		if symbol, err = r.readString(ctx, address, "name"); err != nil {
+			return "", fmt.Errorf("token %s: %w", address.Hex(), err)
+		}
+	}
+	r.learn(address, symbol)
+	return symbol, nil
+}
+
+// learn records the on-chain symbol of an unconfigured token. A symbol already
+// claimed by another token becomes ambiguous, unless it was configured.
+func (r *TokenRegistry) learn(address common.Address, symbol string) {
+	key := normalizeSymbol(symbol)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.symbols[address] = symbol
+	if existing, claimed := r.addresses[key]; claimed && existing != address {
+		if !r.configured[key] {
+			r.ambiguous[key] = true
+		}
+		return
+	}
+	if !r.ambiguous[key] {
+		r.addresses[key] = address
+	}
+}
+
+// readString calls a view method of a token returning a string
+func (r *TokenRegistry) readString(ctx context.Context, address common.Address, method string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, contractCallTimeout)
+	defer cancel()
+
+	data, err := r.contractABI.Pack(method)
+	if err != nil {
+		return "", err
+	}
+	out, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &address, Data: data}, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s(): %w", method, err)
+	}
+	values, err := r.contractABI.Unpack(method, out)
+	if err != nil {
+		return "", fmt.Errorf("%s(): %w", method, err)
+	}
+	return values[0].(string), nil
+}
+
+// resolveFeeds returns a copy of feeds whose pairs name their tokens by address
+func (r *TokenRegistry) resolveFeeds(feeds []types.PriceFeedConfig) ([]types.PriceFeedConfig, error) {
+	resolved := make([]types.PriceFeedConfig, len(feeds))
+	for i, feed := range feeds {
+		feed.Pairs = append([]types.TokenPair(nil), feed.Pairs...)
+		for j := range feed.Pairs {
+			pair := &feed.Pairs[j]
+			token0, err := r.ResolveAddress(pair.Token0)
+			if err != nil {
+				return nil, fmt.Errorf("price feed %q pair %s token0: %w", feed.Name, pair.Symbol, err)
+			}
+			token1, err := r.ResolveAddress(pair.Token1)
+			if err != nil {
+				return nil, fmt.Errorf("price feed %q pair %s token1: %w", feed.Name, pair.Symbol, err)
+			}
+			pair.Token0, pair.Token1 = token0.Hex(), token1.Hex()
+		}
+		resolved[i] = feed
+	}
+	return resolved, nil
+}
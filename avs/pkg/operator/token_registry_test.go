@@ -0,0 +1,192 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+var (
+	wethAddress = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	usdcAddress = common.HexToAddress("0xA0b86a33E6417C8a9bbE78fE047cE5c17Aed0ADA")
+)
+
+// fakeTokenBackend answers ERC-20 symbol() and name() calls, reverting symbol()
+// for tokens without one
+type fakeTokenBackend struct {
+	registry *TokenRegistry
+	symbols  map[common.Address]string
+	names    map[common.Address]string
+}
+
+func (f *fakeTokenBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeTokenBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := f.registry.contractABI.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	values := f.symbols
+	if method.Name == "name" {
+		values = f.names
+	}
+	value, exists := values[*msg.To]
+	if !exists {
+		return nil, fmt.Errorf("execution reverted")
+	}
+	return method.Outputs.Pack(value)
+}
+
+// newTestTokenRegistry creates a registry of WETH and USDC reading other tokens from backend
+func newTestTokenRegistry(t *testing.T, backend *fakeTokenBackend) *TokenRegistry {
+	t.Helper()
+	tokens := []types.TokenConfig{
+		{Symbol: "WETH", Address: wethAddress.Hex()},
+		{Symbol: "USDC", Address: usdcAddress.Hex()},
+	}
+	var caller ethereum.ContractCaller
+	if backend != nil {
+		caller = backend
+	}
+	registry, err := NewTokenRegistry(tokens, caller)
+	if err != nil {
+		t.Fatalf("NewTokenRegistry: %v", err)
+	}
+	if backend != nil {
+		backend.registry = registry
+	}
+	return registry
+}
+
+func TestTokenRegistryResolvesSymbols(t *testing.T) {
+	registry := newTestTokenRegistry(t, nil)
+
+	for _, token := range []string{"WETH", "weth", " Weth ", wethAddress.Hex()} {
+		address, err := registry.ResolveAddress(token)
+		if err != nil || address != wethAddress {
+			t.Fatalf("ResolveAddress(%q) = %s, %v, want %s", token, address.Hex(), err, wethAddress.Hex())
+		}
+	}
+	if _, err := registry.ResolveAddress("DAI"); !errors.Is(err, ErrUnknownToken) {
+		t.Fatalf("ResolveAddress(DAI) error = %v, want ErrUnknownToken", err)
+	}
+
+	if symbol, err := registry.Symbol(context.Background(), usdcAddress); err != nil || symbol != "USDC" {
+		t.Fatalf("Symbol(USDC) = %q, %v", symbol, err)
+	}
+	if _, err := registry.Symbol(context.Background(), common.HexToAddress("0xd1")); !errors.Is(err, ErrUnknownToken) {
+		t.Fatalf("Symbol of an unconfigured token without a client error = %v, want ErrUnknownToken", err)
+	}
+}
+
+func TestTokenRegistryReadsOnChainSymbols(t *testing.T) {
+	dai := common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+	mkr := common.HexToAddress("0x9f8F72aA9304c8B593d555F12eF6589cC3A579A2")
+	backend := &fakeTokenBackend{
+		symbols: map[common.Address]string{dai: "DAI"},
+		names:   map[common.Address]string{dai: "Dai Stablecoin", mkr: "Maker"},
+	}
+	registry := newTestTokenRegistry(t, backend)
+
+	if symbol, err := registry.Symbol(context.Background(), dai); err != nil || symbol != "DAI" {
+		t.Fatalf("Symbol(DAI) = %q, %v", symbol, err)
+	}
+	if address, err := registry.ResolveAddress("dai"); err != nil || address != dai {
+		t.Fatalf("ResolveAddress(dai) = %s, %v, want the learned token", address.Hex(), err)
+	}
+
+	// Tokens without a string symbol() are named by name()
+	if symbol, err := registry.Symbol(context.Background(), mkr); err != nil || symbol != "Maker" {
+		t.Fatalf("Symbol(MKR) = %q, %v, want its name", symbol, err)
+	}
+	if _, err := registry.Symbol(context.Background(), common.HexToAddress("0xd1")); err == nil {
+		t.Fatal("expected an error for an address that is not a token")
+	}
+}
+
+func TestTokenRegistrySymbolCollisions(t *testing.T) {
+	_, err := NewTokenRegistry([]types.TokenConfig{
+		{Symbol: "USDC", Address: usdcAddress.Hex()},
+		{Symbol: "usdc", Address: "0x00000000000000000000000000000000000000c1"},
+	}, nil)
+	if !errors.Is(err, ErrTokenSymbolCollision) {
+		t.Fatalf("NewTokenRegistry error = %v, want ErrTokenSymbolCollision", err)
+	}
+	if _, err := NewTokenRegistry([]types.TokenConfig{
+		{Symbol: "WETH", Address: wethAddress.Hex()},
+		{Symbol: "ETH", Address: wethAddress.Hex()},
+	}, nil); err == nil {
+		t.Fatal("expected an error for a token configured under two symbols")
+	}
+
+	fakeUSDC := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	first, second := common.HexToAddress("0x00000000000000000000000000000000000000d1"), common.HexToAddress("0x00000000000000000000000000000000000000d2")
+	backend := &fakeTokenBackend{symbols: map[common.Address]string{fakeUSDC: "USDC", first: "PEPE", second: "pepe"}}
+	registry := newTestTokenRegistry(t, backend)
+	for _, address := range []common.Address{fakeUSDC, first, second} {
+		if _, err := registry.Symbol(context.Background(), address); err != nil {
+			t.Fatalf("Symbol(%s): %v", address.Hex(), err)
+		}
+	}
+
+	// A token claiming a configured symbol does not take it over
+	if address, err := registry.ResolveAddress("USDC"); err != nil || address != usdcAddress {
+		t.Fatalf("ResolveAddress(USDC) = %s, %v, want the configured token", address.Hex(), err)
+	}
+	// Unconfigured tokens sharing a symbol can only be referred to by address
+	if _, err := registry.ResolveAddress("PEPE"); !errors.Is(err, ErrTokenSymbolCollision) {
+		t.Fatalf("ResolveAddress(PEPE) error = %v, want ErrTokenSymbolCollision", err)
+	}
+	if symbol, _ := registry.Symbol(context.Background(), second); symbol != "pepe" {
+		t.Fatalf("Symbol of a colliding token = %q, want its own symbol", symbol)
+	}
+}
+
+func TestPriceMonitorCachesSymbolPairsByAddress(t *testing.T) {
+	registry := newTestTokenRegistry(t, nil)
+	feed := newTestPriceFeed(t, "binance", "2000000000", time.Now())
+	feed.Pairs[0].Token0, feed.Pairs[0].Token1 = "WETH", "usdc"
+
+	feeds, err := registry.resolveFeeds([]types.PriceFeedConfig{feed})
+	if err != nil {
+		t.Fatalf("resolveFeeds: %v", err)
+	}
+	if feed.Pairs[0].Token0 != "WETH" {
+		t.Fatal("resolveFeeds modified the configured pairs")
+	}
+	if pair := feeds[0].Pairs[0]; pair.Token0 != wethAddress.Hex() || pair.Token1 != usdcAddress.Hex() {
+		t.Fatalf("resolved pair %s/%s, want token addresses", pair.Token0, pair.Token1)
+	}
+
+	pm, err := NewPriceMonitor(feeds, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, nil, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	pm.tokens = registry
+	pm.updatePrices(context.Background(), feeds[0])
+
+	// The pair is found by symbol or by address, in either order
+	for _, lookup := range [][2]string{{"WETH", "USDC"}, {"usdc", "weth"}, {wethAddress.Hex(), "USDC"}, {usdcAddress.Hex(), wethAddress.Hex()}} {
+		if _, exists := pm.GetPairPrice(lookup[0], lookup[1]); !exists {
+			t.Fatalf("GetPairPrice(%s, %s) found no price", lookup[0], lookup[1])
+		}
+	}
+	if size := pm.GetCacheSize(); size != 1 {
+		t.Fatalf("cache size = %d, want the pair cached once", size)
+	}
+
+	feed.Pairs[0].Token1 = "DAI"
+	if _, err := registry.resolveFeeds([]types.PriceFeedConfig{feed}); !errors.Is(err, ErrUnknownToken) {
+		t.Fatalf("resolveFeeds error = %v, want ErrUnknownToken", err)
+	}
+}
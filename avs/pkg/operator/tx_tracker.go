@@ -0,0 +1,274 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// txPollInterval is how often pending transactions are checked
+	txPollInterval = 4 * time.Second
+	// txDropTimeout is how long a transaction the node no longer knows of stays
+	// pending before it is considered dropped
+	txDropTimeout = 10 * time.Minute
+)
+
+// Outcomes of tracked transactions
+const (
+	txStatusConfirmed = "confirmed"
+	txStatusReverted  = "reverted"
+	txStatusDropped   = "dropped"
+)
+
+// On-chain actions the operator tracks transactions of
+const (
+	txActionRegister = "register"
+)
+
+// txBackend is the chain access needed to follow transactions
+type txBackend interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethtypes.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*ethtypes.Transaction, bool, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// trackedTx is a sent transaction awaiting its outcome
+type trackedTx struct {
+	tx     *ethtypes.Transaction
+	from   common.Address
+	action string
+	sentAt time.Time
+	// minedBlock is the block the transaction was last seen mined in, 0 until mined
+	minedBlock uint64
+}
+
+// TxOutcome is the final status of a tracked transaction
+type TxOutcome struct {
+	Hash   common.Hash `json:"hash"`
+	Action string      `json:"action"`
+	Status string      `json:"status"`
+	Block  uint64      `json:"block,omitempty"`
+	// RevertReason is the reverted transaction's error, when the node reports one
+	RevertReason string `json:"revert_reason,omitempty"`
+}
+
+// txTracker follows the operator's sent transactions until they have the
+// configured number of confirmations, or are found reverted or dropped
+type txTracker struct {
+	backend       txBackend
+	confirmations uint64
+	now           func() time.Time
+	metrics       *operatorMetrics
+	logger        *logrus.Logger
+
+	mutex   sync.Mutex
+	pending map[common.Hash]*trackedTx
+	// outcomes counts finished transactions by status
+	outcomes map[string]uint64
+	last     *TxOutcome
+}
+
+// newTxTracker creates a tracker requiring confirmations blocks, counting the one
+// a transaction is mined in, before it is confirmed
+func newTxTracker(backend txBackend, confirmations uint64, metrics *operatorMetrics, logger *logrus.Logger) *txTracker {
+	return &txTracker{
+		backend:       backend,
+		confirmations: max(confirmations, 1),
+		now:           time.Now,
+		metrics:       metrics,
+		logger:        logger,
+		pending:       make(map[common.Hash]*trackedTx),
+		outcomes:      make(map[string]uint64),
+	}
+}
+
+// Track follows a transaction sent from the operator's address for action
+func (t *txTracker) Track(tx *ethtypes.Transaction, from common.Address, action string) {
+	t.mutex.Lock()
+	t.pending[tx.Hash()] = &trackedTx{tx: tx, from: from, action: action, sentAt: t.now()}
+	pending := len(t.pending)
+	t.mutex.Unlock()
+
+	t.metrics.observePendingTransactions(pending)
+	t.logger.WithFields(logrus.Fields{"tx_hash": tx.Hash().Hex(), "action": action}).Info("Tracking transaction")
+}
+
+// run checks the pending transactions every txPollInterval until ctx is cancelled
+func (t *txTracker) run(ctx context.Context) {
+	ticker := time.NewTicker(txPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.check(ctx); err != nil && ctx.Err() == nil {
+				t.logger.WithError(err).Warn("Failed to check pending transactions")
+			}
+		}
+	}
+}
+
+// check looks up the receipt of every pending transaction, finishing those that
+// are confirmed, reverted or dropped
+func (t *txTracker) check(ctx context.Context) error {
+	head, err := t.backend.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	pending := make([]*trackedTx, 0, len(t.pending))
+	for _, tracked := range t.pending {
+		pending = append(pending, tracked)
+	}
+	t.mutex.Unlock()
+
+	var errs []error
+	for _, tracked := range pending {
+		outcome, err := t.checkOne(ctx, tracked, head)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if outcome != nil {
+			t.finish(*outcome)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkOne returns the outcome of a pending transaction, or nil while it awaits
+// inclusion or confirmations
+func (t *txTracker) checkOne(ctx context.Context, tracked *trackedTx, head uint64) (*TxOutcome, error) {
+	hash := tracked.tx.Hash()
+	receipt, err := t.backend.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		// Reorged out or not yet mined; the transaction is dropped once the node
+		// has forgotten it for txDropTimeout
+		t.setMinedBlock(hash, 0)
+		if _, _, err := t.backend.TransactionByHash(ctx, hash); !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		if t.now().Sub(tracked.sentAt) < txDropTimeout {
+			return nil, nil
+		}
+		return &TxOutcome{Hash: hash, Action: tracked.action, Status: txStatusDropped}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block := receipt.BlockNumber.Uint64()
+	if t.setMinedBlock(hash, block) {
+		t.logger.WithFields(logrus.Fields{"tx_hash": hash.Hex(), "action": tracked.action, "block": block}).Info("Transaction mined")
+	}
+	if head < block || head-block+1 < t.confirmations {
+		return nil, nil
+	}
+
+	outcome := &TxOutcome{Hash: hash, Action: tracked.action, Status: txStatusConfirmed, Block: block}
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		outcome.Status = txStatusReverted
+		outcome.RevertReason = t.revertReason(ctx, tracked, receipt.BlockNumber)
+	}
+	return outcome, nil
+}
+
+// setMinedBlock records the block a pending transaction is mined in, reporting
+// whether it changed
+func (t *txTracker) setMinedBlock(hash common.Hash, block uint64) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	tracked, exists := t.pending[hash]
+	if !exists || tracked.minedBlock == block {
+		return false
+	}
+	tracked.minedBlock = block
+	return true
+}
+
+// revertReason replays a reverted transaction at the block it was mined in and
+// returns the error it reverts with, empty if the node reports none
+func (t *txTracker) revertReason(ctx context.Context, tracked *trackedTx, block *big.Int) string {
+	tx := tracked.tx
+	_, err := t.backend.CallContract(ctx, ethereum.CallMsg{
+		From:     tracked.from,
+		To:       tx.To(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}, block)
+	if err == nil {
+		return ""
+	}
+
+	// Nodes return the revert data of a failed call alongside the error
+	var dataErr interface{ ErrorData() interface{} }
+	if errors.As(err, &dataErr) {
+		if encoded, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(encoded); decodeErr == nil {
+				if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+					return reason
+				}
+			}
+		}
+	}
+	return err.Error()
+}
+
+// finish records the outcome of a transaction and stops tracking it
+func (t *txTracker) finish(outcome TxOutcome) {
+	t.mutex.Lock()
+	delete(t.pending, outcome.Hash)
+	t.outcomes[outcome.Status]++
+	t.last = &outcome
+	pending := len(t.pending)
+	t.mutex.Unlock()
+
+	t.metrics.observeTransaction(outcome.Action, outcome.Status)
+	t.metrics.observePendingTransactions(pending)
+
+	fields := logrus.Fields{"tx_hash": outcome.Hash.Hex(), "action": outcome.Action, "block": outcome.Block}
+	switch outcome.Status {
+	case txStatusConfirmed:
+		t.logger.WithFields(fields).Info("Transaction confirmed")
+	case txStatusReverted:
+		t.logger.WithFields(fields).WithField("revert_reason", outcome.RevertReason).Error("Transaction reverted")
+	default:
+		t.logger.WithFields(fields).Warn("Transaction dropped")
+	}
+}
+
+// Metrics returns the number of pending transactions, of finished ones by status
+// and the latest outcome
+func (t *txTracker) Metrics() map[string]interface{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	outcomes := make(map[string]uint64, len(t.outcomes))
+	for status, count := range t.outcomes {
+		outcomes[status] = count
+	}
+	metrics := map[string]interface{}{
+		"pending_transactions": len(t.pending),
+		"transaction_outcomes": outcomes,
+	}
+	if t.last != nil {
+		metrics["last_transaction"] = *t.last
+	}
+	return metrics
+}
@@ -0,0 +1,159 @@
+package operator
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// revertError is a call error carrying revert data, as returned by nodes
+type revertError struct{ data string }
+
+func (e revertError) Error() string          { return "execution reverted" }
+func (e revertError) ErrorData() interface{} { return e.data }
+
+// fakeTxBackend mines transactions at blocks set by the test
+type fakeTxBackend struct {
+	mutex    sync.Mutex
+	head     uint64
+	receipts map[common.Hash]*ethtypes.Receipt
+	known    map[common.Hash]bool
+	callErr  error
+}
+
+func newFakeTxBackend() *fakeTxBackend {
+	return &fakeTxBackend{receipts: make(map[common.Hash]*ethtypes.Receipt), known: make(map[common.Hash]bool)}
+}
+
+func (f *fakeTxBackend) mine(tx *ethtypes.Transaction, block uint64, status uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.receipts[tx.Hash()] = &ethtypes.Receipt{TxHash: tx.Hash(), Status: status, BlockNumber: new(big.Int).SetUint64(block)}
+}
+
+func (f *fakeTxBackend) setHead(head uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.head = head
+}
+
+func (f *fakeTxBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.head, nil
+}
+
+func (f *fakeTxBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethtypes.Receipt, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if receipt, exists := f.receipts[txHash]; exists {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *fakeTxBackend) TransactionByHash(ctx context.Context, hash common.Hash) (*ethtypes.Transaction, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.known[hash] {
+		return nil, true, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func (f *fakeTxBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, f.callErr
+}
+
+func newTestTransaction(nonce uint64) *ethtypes.Transaction {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	return ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: nonce, To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+}
+
+func TestTxTrackerConfirmsMinedTransactions(t *testing.T) {
+	backend := newFakeTxBackend()
+	backend.setHead(100)
+	metrics := newOperatorMetrics()
+	tracker := newTxTracker(backend, 3, metrics, newTestLogger())
+	ctx := context.Background()
+
+	tx := newTestTransaction(0)
+	backend.known[tx.Hash()] = true
+	tracker.Track(tx, common.HexToAddress("0x01"), txActionRegister)
+
+	// Pending in the mempool, then mined with too few confirmations
+	if err := tracker.check(ctx); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	backend.mine(tx, 101, ethtypes.ReceiptStatusSuccessful)
+	backend.setHead(102)
+	if err := tracker.check(ctx); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if pending := tracker.Metrics()["pending_transactions"]; pending != 1 {
+		t.Fatalf("pending transactions = %v, want the transaction awaiting confirmations", pending)
+	}
+
+	backend.setHead(103)
+	if err := tracker.check(ctx); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	got := tracker.Metrics()
+	if got["pending_transactions"] != 0 || got["transaction_outcomes"].(map[string]uint64)[txStatusConfirmed] != 1 {
+		t.Fatalf("metrics = %v, want the transaction confirmed", got)
+	}
+	if last := got["last_transaction"].(TxOutcome); last.Hash != tx.Hash() || last.Block != 101 || last.Action != txActionRegister {
+		t.Fatalf("last outcome = %+v", last)
+	}
+	if count := testutil.ToFloat64(metrics.transactions.WithLabelValues(txActionRegister, txStatusConfirmed)); count != 1 {
+		t.Fatalf("transactions_total{confirmed} = %v, want 1", count)
+	}
+	if pending := testutil.ToFloat64(metrics.pendingTxs); pending != 0 {
+		t.Fatalf("pending_transactions = %v, want 0", pending)
+	}
+}
+
+func TestTxTrackerReportsRevertsAndDrops(t *testing.T) {
+	backend := newFakeTxBackend()
+	backend.setHead(50)
+	tracker := newTxTracker(backend, 1, nil, newTestLogger())
+	ctx := context.Background()
+
+	// Error(string) "Already registered"
+	backend.callErr = revertError{data: "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000012" +
+		hexutil.Encode([]byte("Already registered"))[2:] + "0000000000000000000000000000"}
+	reverted := newTestTransaction(1)
+	tracker.Track(reverted, common.HexToAddress("0x01"), txActionRegister)
+	backend.mine(reverted, 50, ethtypes.ReceiptStatusFailed)
+	if err := tracker.check(ctx); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if last := tracker.Metrics()["last_transaction"].(TxOutcome); last.Status != txStatusReverted || last.RevertReason != "Already registered" {
+		t.Fatalf("last outcome = %+v, want a revert with its reason", last)
+	}
+
+	// A transaction the node never knew of is dropped once txDropTimeout passes
+	dropped := newTestTransaction(2)
+	tracker.Track(dropped, common.HexToAddress("0x01"), txActionRegister)
+	if err := tracker.check(ctx); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	tracker.now = func() time.Time { return time.Now().Add(txDropTimeout) }
+	if err := tracker.check(ctx); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	outcomes := tracker.Metrics()["transaction_outcomes"].(map[string]uint64)
+	if outcomes[txStatusReverted] != 1 || outcomes[txStatusDropped] != 1 {
+		t.Fatalf("outcomes = %v, want one reverted and one dropped", outcomes)
+	}
+}
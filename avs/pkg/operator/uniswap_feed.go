@@ -0,0 +1,122 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// stateViewABI is the subset of the Uniswap v4 StateView ABI read by the operator
+const stateViewABI = `[
+	{
+		"type": "function",
+		"name": "getSlot0",
+		"stateMutability": "view",
+		"inputs": [{"name": "poolId", "type": "bytes32"}],
+		"outputs": [
+			{"name": "sqrtPriceX96", "type": "uint160"},
+			{"name": "tick", "type": "int24"},
+			{"name": "protocolFee", "type": "uint24"},
+			{"name": "lpFee", "type": "uint24"}
+		]
+	}
+]`
+
+// q192 is 2^192, the scale of a squared sqrtPriceX96
+var q192 = new(big.Int).Lsh(big.NewInt(1), 192)
+
+// poolPriceReader reads the spot price of Uniswap v4 pools from the StateView
+// contract, so the pool's own price can be compared against external oracles
+type poolPriceReader struct {
+	caller      ethereum.ContractCaller
+	contractABI abi.ABI
+	pools       *PoolRegistry
+}
+
+func newPoolPriceReader(caller ethereum.ContractCaller, pools *PoolRegistry) (*poolPriceReader, error) {
+	contractABI, err := abi.JSON(strings.NewReader(stateViewABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state view ABI: %w", err)
+	}
+	return &poolPriceReader{caller: caller, contractABI: contractABI, pools: pools}, nil
+}
+
+// fetchPrice reads the pair's pool slot0 and converts its sqrtPriceX96 to the
+// price of the pair's token0 in token1, scaled to normalizedPriceDecimals
+func (r *poolPriceReader) fetchPrice(ctx context.Context, feed types.PriceFeedConfig, pair types.TokenPair) (*types.PriceData, error) {
+	pool, err := r.pools.Lookup(pair.PoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pool quotes currency1 per currency0; pairs may list its tokens either way
+	var invert bool
+	switch {
+	case strings.EqualFold(pair.Token0, pool.Token0.Hex()) && strings.EqualFold(pair.Token1, pool.Token1.Hex()):
+	case strings.EqualFold(pair.Token0, pool.Token1.Hex()) && strings.EqualFold(pair.Token1, pool.Token0.Hex()):
+		invert = true
+	default:
+		return nil, fmt.Errorf("pool %s does not trade %s/%s", pair.PoolID, pair.Token0, pair.Token1)
+	}
+
+	data, err := r.contractABI.Pack("getSlot0", pool.ID)
+	if err != nil {
+		return nil, err
+	}
+	stateView := common.HexToAddress(feed.StateView)
+
+	ctx, cancel := context.WithTimeout(ctx, contractCallTimeout)
+	defer cancel()
+	result, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &stateView, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getSlot0 of pool %s: %w", pair.PoolID, err)
+	}
+	out, err := r.contractABI.Unpack("getSlot0", result)
+	if err != nil {
+		return nil, err
+	}
+	sqrtPriceX96 := out[0].(*big.Int)
+	if sqrtPriceX96.Sign() == 0 {
+		return nil, fmt.Errorf("pool %s is not initialized", pair.PoolID)
+	}
+
+	now := time.Now()
+	return &types.PriceData{
+		Token0:    pair.Token0,
+		Token1:    pair.Token1,
+		Price:     sqrtPriceX96ToPrice(sqrtPriceX96, pair.Decimals, pair.QuoteDecimals, invert),
+		Decimals:  normalizedPriceDecimals,
+		Timestamp: now,
+		Source:    feed.Name,
+	}, nil
+}
+
+// sqrtPriceX96ToPrice converts a pool's sqrtPriceX96, the square root of the
+// currency1/currency0 ratio of raw amounts as a Q64.96, to the price of one whole
+// base token in whole quote tokens scaled to normalizedPriceDecimals. The base token
+// is currency0, or currency1 when inverted, and the decimals are those of the
+// base and quote tokens.
+func sqrtPriceX96ToPrice(sqrtPriceX96 *big.Int, baseDecimals, quoteDecimals int, invert bool) *big.Int {
+	num := new(big.Int).Mul(sqrtPriceX96, sqrtPriceX96)
+	den := new(big.Int).Set(q192)
+	if invert {
+		num, den = den, num
+	}
+
+	// Converting raw amounts to whole tokens scales the ratio by 10^(base - quote)
+	scale := normalizedPriceDecimals + baseDecimals - quoteDecimals
+	if scale >= 0 {
+		num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	} else {
+		den.Mul(den, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+	}
+	return num.Quo(num, den)
+}
@@ -0,0 +1,112 @@
+package operator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/lvr-auction-hook/avs/pkg/types"
+)
+
+// fakeStateView returns a fixed sqrtPriceX96 from getSlot0
+type fakeStateView struct {
+	reader       *poolPriceReader
+	sqrtPriceX96 *big.Int
+	poolIDs      []common.Hash
+}
+
+func (f *fakeStateView) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeStateView) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method := f.reader.contractABI.Methods["getSlot0"]
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	f.poolIDs = append(f.poolIDs, common.Hash(args[0].([32]byte)))
+	return method.Outputs.Pack(f.sqrtPriceX96, big.NewInt(0), big.NewInt(0), big.NewInt(3000))
+}
+
+// q96 returns n * 2^96
+func q96(n int64) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(n), 96)
+}
+
+func TestSqrtPriceX96ToPrice(t *testing.T) {
+	e18 := big.NewInt(1e18)
+	for _, tc := range []struct {
+		name         string
+		sqrtPriceX96 *big.Int
+		base, quote  int
+		invert       bool
+		want         *big.Int
+	}{
+		{"parity", q96(1), 18, 18, false, e18},
+		{"price of 4", q96(2), 18, 18, false, new(big.Int).Mul(big.NewInt(4), e18)},
+		{"inverted price of 4", q96(2), 18, 18, true, big.NewInt(25e16)},
+		// A USDC (6) / WETH (18) pool pricing ETH at 2500 USDC holds 4e8 wei per raw USDC unit
+		{"ETH in USDC", q96(20000), 18, 6, true, new(big.Int).Mul(big.NewInt(2500), e18)},
+		{"USDC in ETH", q96(20000), 6, 18, false, big.NewInt(4e14)},
+	} {
+		if got := sqrtPriceX96ToPrice(tc.sqrtPriceX96, tc.base, tc.quote, tc.invert); got.Cmp(tc.want) != 0 {
+			t.Errorf("%s: price = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPoolPriceFeedOrientsToPair(t *testing.T) {
+	pools, err := NewPoolRegistry([]types.PoolConfig{testPool}, "", newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPoolRegistry: %v", err)
+	}
+	stateView := &fakeStateView{sqrtPriceX96: q96(20000)}
+
+	// The pair lists the pool's currency1 first, so the pool price is inverted
+	feed := types.PriceFeedConfig{
+		Name:      "pool",
+		Type:      feedTypeUniswapV4,
+		StateView: "0x00000000000000000000000000000000000000c2",
+		Pairs: []types.TokenPair{{
+			Token0:        testPool.Currency1,
+			Token1:        testPool.Currency0,
+			Symbol:        "ETH/USDC",
+			Decimals:      18,
+			QuoteDecimals: 6,
+			IsActive:      true,
+			PoolID:        testPoolID,
+		}},
+	}
+	pm, err := NewPriceMonitor([]types.PriceFeedConfig{feed}, types.PriceAlertConfig{}, types.PriceAggregationConfig{}, pools, stateView, newTestLogger())
+	if err != nil {
+		t.Fatalf("NewPriceMonitor: %v", err)
+	}
+	stateView.reader = pm.poolPrices
+
+	priceData, err := pm.fetchPrice(context.Background(), feed, feed.Pairs[0])
+	if err != nil {
+		t.Fatalf("fetchPrice: %v", err)
+	}
+	if want := new(big.Int).Mul(big.NewInt(2500), big.NewInt(1e18)); priceData.Price.Cmp(want) != 0 {
+		t.Fatalf("price = %s, want %s", priceData.Price, want)
+	}
+	if len(stateView.poolIDs) != 1 || stateView.poolIDs[0].Hex() != testPoolID {
+		t.Fatalf("getSlot0 called for %v, want the pair's pool", stateView.poolIDs)
+	}
+
+	// The pool price is one of the sources compared against the oracles
+	oracle := &types.PriceData{Price: new(big.Int).Mul(big.NewInt(2600), big.NewInt(1e18)), Timestamp: priceData.Timestamp}
+	pm.updateCache(testPool.Currency1, testPool.Currency0, "oracle", oracle)
+	pm.updateCache(testPool.Currency1, testPool.Currency0, feed.Name, priceData)
+	discrepancy, err := pm.GetDiscrepancyBps(testPool.Currency1, testPool.Currency0)
+	if err != nil {
+		t.Fatalf("GetDiscrepancyBps: %v", err)
+	}
+	if discrepancy != 400 {
+		t.Fatalf("discrepancy = %d bps, want 400", discrepancy)
+	}
+}
@@ -11,46 +11,81 @@ type Auction struct {
 	PoolID      string    `json:"pool_id"`
 	StartTime   time.Time `json:"start_time"`
 	Duration    int64     `json:"duration"`
-	IsActive    bool      `json:"is_active"`
-	IsComplete  bool      `json:"is_complete"`
 	Winner      string    `json:"winner"`
 	WinningBid  *big.Int  `json:"winning_bid"`
 	TotalBids   int       `json:"total_bids"`
 	BlockNumber uint64    `json:"block_number"`
+	// State is the auction's lifecycle phase, changed through Transition
+	State AuctionState `json:"state"`
+	// StateTimes records when the auction entered each state it went through
+	StateTimes map[AuctionState]time.Time `json:"state_times,omitempty"`
+	// Bids are committed for Duration seconds from StartTime, then revealed for
+	// RevealDuration seconds. An auction without a Duration takes bids until it
+	// completes.
+	RevealDuration int64 `json:"reveal_duration"`
+	// BidRoots are Merkle roots committing many sealed bids at once, whose bids
+	// are revealed with a proof of inclusion instead of committed one by one
+	BidRoots []string `json:"bid_roots,omitempty"`
+	// ReservePrice is the smallest winning bid, in wei of the settlement token, the
+	// auction settles at. When nil the operator derives it from its pool config.
+	ReservePrice *big.Int `json:"reserve_price,omitempty"`
 }
 
 // Bid represents a sealed bid in an auction
 type Bid struct {
-	Bidder     string   `json:"bidder"`
-	Amount     *big.Int `json:"amount"`
-	Commitment string   `json:"commitment"`
-	Revealed   bool     `json:"revealed"`
+	Bidder     string    `json:"bidder"`
+	Amount     *big.Int  `json:"amount"`
+	Commitment string    `json:"commitment"`
+	Revealed   bool      `json:"revealed"`
 	Timestamp  time.Time `json:"timestamp"`
+	// Salt is the hex encoded 32 byte salt the bid was committed with, set on reveal
+	Salt string `json:"salt"`
+	// SettlementData is the hex encoded calldata the bidder executes to settle the
+	// auction, performing their arbitrage and paying the bid
+	SettlementData string `json:"settlement_data"`
+	// MerkleRoot is the bid root the bid's commitment was included in, empty for
+	// bids committed individually. MerkleProof leads from the commitment to it.
+	MerkleRoot  string   `json:"merkle_root,omitempty"`
+	MerkleProof []string `json:"merkle_proof,omitempty"`
 }
 
 // PriceData represents price information from an oracle
 type PriceData struct {
-	Token0       string    `json:"token0"`
-	Token1       string    `json:"token1"`
-	Price        *big.Int  `json:"price"`
-	Timestamp    time.Time `json:"timestamp"`
-	Source       string    `json:"source"`
-	IsStale      bool      `json:"is_stale"`
-	Discrepancy  *big.Int  `json:"discrepancy"`
+	Token0      string    `json:"token0"`
+	Token1      string    `json:"token1"`
+	Price       *big.Int  `json:"price"`
+	Timestamp   time.Time `json:"timestamp"`
+	Source      string    `json:"source"`
+	IsStale     bool      `json:"is_stale"`
+	Discrepancy *big.Int  `json:"discrepancy"`
+	// Decimals is the fixed point precision of Price
+	Decimals int `json:"decimals"`
+	// RawPrice is the unsmoothed price when Price is an exponential moving
+	// average of the source's prices, and nil otherwise
+	RawPrice *big.Int `json:"raw_price,omitempty"`
 }
 
 // Task represents an AVS task for auction validation
 type Task struct {
-	ID            uint32    `json:"id"`
-	AuctionID     string    `json:"auction_id"`
-	PoolID        string    `json:"pool_id"`
-	CreatedBlock  uint32    `json:"created_block"`
-	Deadline      time.Time `json:"deadline"`
-	Completed     bool      `json:"completed"`
-	Responses     []TaskResponse `json:"responses"`
+	ID           uint32 `json:"id"`
+	AuctionID    string `json:"auction_id"`
+	PoolID       string `json:"pool_id"`
+	CreatedBlock uint32 `json:"created_block"`
+	// AuctionNonce distinguishes auctions of the pool started in CreatedBlock, as
+	// DeriveAuctionID derives AuctionID from them
+	AuctionNonce uint64 `json:"auction_nonce"`
+	// Deadline is the service manager's response deadline. It is judged against
+	// the latest block's timestamp, not the local clock.
+	Deadline  time.Time      `json:"deadline"`
+	Completed bool           `json:"completed"`
+	Responses []TaskResponse `json:"responses"`
+	// Cancelled is set once the task's auction is cancelled, after which operators
+	// abstain from it
+	Cancelled bool `json:"cancelled"`
 }
 
-// TaskResponse represents an operator's response to a task
+// TaskResponse represents an operator's response to a task. WinningBid is in wei
+// of the settlement token the aggregator is configured with.
 type TaskResponse struct {
 	Operator   string    `json:"operator"`
 	AuctionID  string    `json:"auction_id"`
@@ -58,87 +93,342 @@ type TaskResponse struct {
 	WinningBid *big.Int  `json:"winning_bid"`
 	Signature  string    `json:"signature"`
 	Timestamp  time.Time `json:"timestamp"`
+	// Confidence is how far the operator trusts the prices the response was built
+	// on, in (0, 1]
+	Confidence float64 `json:"confidence"`
 }
 
 // Operator represents an AVS operator
 type Operator struct {
-	Address      string   `json:"address"`
-	Stake        *big.Int `json:"stake"`
-	Registered   bool     `json:"registered"`
-	LastSeen     time.Time `json:"last_seen"`
-	Accuracy     float64  `json:"accuracy"`
-	TotalTasks   uint64   `json:"total_tasks"`
-	SuccessfulTasks uint64 `json:"successful_tasks"`
+	Address         string    `json:"address"`
+	Stake           *big.Int  `json:"stake"`
+	Registered      bool      `json:"registered"`
+	LastSeen        time.Time `json:"last_seen"`
+	Accuracy        float64   `json:"accuracy"`
+	TotalTasks      uint64    `json:"total_tasks"`
+	SuccessfulTasks uint64    `json:"successful_tasks"`
 }
 
 // MEVDistribution represents MEV distribution to LPs
 type MEVDistribution struct {
-	PoolID          string   `json:"pool_id"`
-	TotalAmount     *big.Int `json:"total_amount"`
-	LPAmount        *big.Int `json:"lp_amount"`
-	AVSAmount       *big.Int `json:"avs_amount"`
-	ProtocolAmount  *big.Int `json:"protocol_amount"`
-	GasAmount       *big.Int `json:"gas_amount"`
-	BlockNumber     uint64   `json:"block_number"`
-	Timestamp       time.Time `json:"timestamp"`
+	PoolID         string    `json:"pool_id"`
+	TotalAmount    *big.Int  `json:"total_amount"`
+	LPAmount       *big.Int  `json:"lp_amount"`
+	AVSAmount      *big.Int  `json:"avs_amount"`
+	ProtocolAmount *big.Int  `json:"protocol_amount"`
+	GasAmount      *big.Int  `json:"gas_amount"`
+	BlockNumber    uint64    `json:"block_number"`
+	Timestamp      time.Time `json:"timestamp"`
 }
 
 // LPReward represents rewards for liquidity providers
 type LPReward struct {
-	LPAddress       string   `json:"lp_address"`
-	PoolID          string   `json:"pool_id"`
-	LiquidityShare  *big.Int `json:"liquidity_share"`
-	RewardAmount    *big.Int `json:"reward_amount"`
-	ClaimedAmount   *big.Int `json:"claimed_amount"`
-	LastClaimTime   time.Time `json:"last_claim_time"`
+	LPAddress      string    `json:"lp_address"`
+	PoolID         string    `json:"pool_id"`
+	LiquidityShare *big.Int  `json:"liquidity_share"`
+	RewardAmount   *big.Int  `json:"reward_amount"`
+	ClaimedAmount  *big.Int  `json:"claimed_amount"`
+	LastClaimTime  time.Time `json:"last_claim_time"`
 }
 
 // AuctionMetrics represents metrics for auction performance
 type AuctionMetrics struct {
-	TotalAuctions       uint64    `json:"total_auctions"`
-	SuccessfulAuctions  uint64    `json:"successful_auctions"`
-	TotalMEVRecovered   *big.Int  `json:"total_mev_recovered"`
-	AverageBidAmount    *big.Int  `json:"average_bid_amount"`
-	AverageAuctionTime  float64   `json:"average_auction_time"`
-	LPCompensationRate  float64   `json:"lp_compensation_rate"`
-	LastUpdated         time.Time `json:"last_updated"`
+	TotalAuctions      uint64    `json:"total_auctions"`
+	SuccessfulAuctions uint64    `json:"successful_auctions"`
+	TotalMEVRecovered  *big.Int  `json:"total_mev_recovered"`
+	AverageBidAmount   *big.Int  `json:"average_bid_amount"`
+	AverageAuctionTime float64   `json:"average_auction_time"`
+	LPCompensationRate float64   `json:"lp_compensation_rate"`
+	LastUpdated        time.Time `json:"last_updated"`
 }
 
 // NetworkConfig represents network configuration
 type NetworkConfig struct {
-	ChainID           uint64 `json:"chain_id"`
-	RPCURL            string `json:"rpc_url"`
-	WSURL             string `json:"ws_url"`
-	ContractAddresses map[string]string `json:"contract_addresses"`
-	BlockConfirmations uint64 `json:"block_confirmations"`
+	ChainID            uint64            `json:"chain_id"`
+	RPCURL             string            `json:"rpc_url"`
+	WSURL              string            `json:"ws_url"`
+	ContractAddresses  ContractAddresses `json:"contract_addresses"`
+	BlockConfirmations uint64            `json:"block_confirmations"`
+	// FeeStrategy prices transactions as "legacy" gas price or "dynamic" EIP-1559
+	// fees. "auto" (default) uses dynamic fees when the chain reports a base fee.
+	FeeStrategy string `json:"fee_strategy"`
 }
 
 // PriceFeedConfig represents price feed configuration
 type PriceFeedConfig struct {
-	Name       string `json:"name"`
-	URL        string `json:"url"`
-	APIKey     string `json:"api_key"`
-	UpdateFreq int64  `json:"update_frequency_seconds"`
+	Name string `json:"name"`
+	// Type is "http" (default) for REST price APIs, "chainlink" to read each
+	// pair's on-chain AggregatorV3Interface contract, or "uniswap_v4" to read the
+	// spot price of each pair's pool from the StateView contract
+	Type       string      `json:"type"`
+	URL        string      `json:"url"`
+	APIKey     string      `json:"api_key"`
+	UpdateFreq int64       `json:"update_frequency_seconds"`
 	Pairs      []TokenPair `json:"pairs"`
+	// FailureThreshold is the number of consecutive failed polls after which the
+	// feed's circuit breaker opens (default 3). The breaker stays open for the
+	// update interval, doubled after each failed probe up to MaxBackoff seconds (default 300).
+	FailureThreshold int   `json:"failure_threshold"`
+	MaxBackoff       int64 `json:"max_backoff_seconds"`
+	// StateView is the Uniswap v4 StateView contract, for uniswap_v4 feeds
+	StateView string `json:"state_view"`
+	// MaxStaleness is how old, in seconds, the feed's prices may be before they are
+	// marked stale and evicted from the cache (default 3600)
+	MaxStaleness int64 `json:"max_staleness_seconds"`
+	// MaxRetries is how many times a poll retries a pair's price after a network
+	// error, 429 or 5xx response, with jittered exponential backoff (default 2).
+	// Other failures are not retried; a negative value disables retries.
+	MaxRetries int `json:"max_retries"`
+	// Weight is the feed's confidence in a weighted_mean price aggregation (default 1)
+	Weight uint64 `json:"weight"`
+	// SmoothingAlpha, between 0 and 1, smooths the feed's prices with an
+	// exponential moving average giving each new price this weight. Zero (default)
+	// or one uses the raw prices. Discrepancies are computed from raw prices.
+	SmoothingAlpha float64 `json:"smoothing_alpha"`
+	// ResponseMapping locates the price fields in an http feed's responses,
+	// defaulting to {"price", "timestamp", "source"} at the top level
+	ResponseMapping PriceResponseMapping `json:"response_mapping"`
 }
 
-// TokenPair represents a trading pair
+// PriceResponseMapping locates the fields of an http feed's price response by
+// JSONPath-style paths such as "$.data.quotes[0].price", so feeds of different
+// vendors can be read without code changes. Unset paths use the top level
+// field of the default name.
+type PriceResponseMapping struct {
+	// Version is the mapping schema version; only 1 (default) is supported
+	Version   int    `json:"version"`
+	Price     string `json:"price"`
+	Timestamp string `json:"timestamp"`
+	// Source may be missing from responses, in which case the feed name is used
+	Source string `json:"source"`
+	// TimestampUnit is "seconds" (default) or "milliseconds"
+	TimestampUnit string `json:"timestamp_unit"`
+}
+
+// TokenPair represents a trading pair. Decimals is the fixed point precision of
+// the prices an http feed quotes for the pair, or the decimals of token0 for
+// uniswap_v4 feeds.
 type TokenPair struct {
-	Token0    string `json:"token0"`
-	Token1    string `json:"token1"`
-	Symbol    string `json:"symbol"`
-	Decimals  int    `json:"decimals"`
-	IsActive  bool   `json:"is_active"`
+	// Token0 and Token1 are token addresses, or symbols of the configured tokens
+	Token0   string `json:"token0"`
+	Token1   string `json:"token1"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+	IsActive bool   `json:"is_active"`
+	// Aggregator is the Chainlink aggregator contract of the pair, for chainlink feeds
+	Aggregator string `json:"aggregator"`
+	// PoolID is the Uniswap v4 pool of the pair, for uniswap_v4 feeds. The pool's
+	// price is converted with Decimals for token0 and QuoteDecimals for token1.
+	PoolID        string `json:"pool_id"`
+	QuoteDecimals int    `json:"quote_decimals"`
+}
+
+// TokenConfig names a token, so price feed pairs and price lookups may refer to
+// it by symbol
+type TokenConfig struct {
+	Symbol  string `json:"symbol"`
+	Address string `json:"address"`
 }
 
 // OperatorConfig represents operator configuration
 type OperatorConfig struct {
-	PrivateKey     string            `json:"private_key"`
-	Address        string            `json:"address"`
-	StakeAmount    string            `json:"stake_amount"`
-	ServiceManager string            `json:"service_manager"`
-	NetworkConfig  NetworkConfig     `json:"network_config"`
-	PriceFeeds     []PriceFeedConfig `json:"price_feeds"`
-	LogLevel       string            `json:"log_level"`
-	MetricsPort    int               `json:"metrics_port"`
+	PrivateKey    string            `json:"private_key"`
+	Address       string            `json:"address"`
+	StakeAmount   string            `json:"stake_amount"`
+	NetworkConfig NetworkConfig     `json:"network_config"`
+	PriceFeeds    []PriceFeedConfig `json:"price_feeds"`
+	LogLevel      string            `json:"log_level"`
+	MetricsPort   int               `json:"metrics_port"`
+	AggregatorURL string            `json:"aggregator_url"`
+	// AggregatorURLs are failover aggregators, tried in order when aggregator_url
+	// cannot take a task response
+	AggregatorURLs []string `json:"aggregator_urls"`
+	// PriceServerAddress is where the cached prices are served, defaulting to
+	// metrics_port + 1 when metrics are served
+	PriceServerAddress string `json:"price_server_address"`
+	// BLSKeyStorePath is the operator's BLS keystore, which task responses are
+	// signed with. Its password is read from the OPERATOR_BLS_KEY_PASSWORD
+	// environment variable.
+	BLSKeyStorePath string `json:"bls_key_store_path"`
+	// DuplicateAuctionWindow is how long, in seconds, a validated auction result is
+	// reused for other tasks referencing the same pool and block (default 300)
+	DuplicateAuctionWindow int64         `json:"duplicate_auction_window_seconds"`
+	Standby                StandbyConfig `json:"standby"`
+	// AllowInactiveAuctions disables the check that a task's auction exists, matches
+	// the task and is still active before a response is computed
+	AllowInactiveAuctions bool                       `json:"allow_inactive_auctions"`
+	PriceAlerts           PriceAlertConfig           `json:"price_alerts"`
+	PriceAggregation      PriceAggregationConfig     `json:"price_aggregation"`
+	SettlementSimulation  SettlementSimulationConfig `json:"settlement_simulation"`
+	DecisionExport        DecisionExportConfig       `json:"decision_export"`
+	// TaskPollInterval is how often, in seconds, tasks are polled for while no
+	// network_config.ws_url subscription is up. It sets both the service manager
+	// event scan (default 2) and the pending task poll (default 1).
+	TaskPollInterval int64 `json:"task_poll_interval_seconds"`
+	// HeartbeatInterval is how often, in seconds, liveness is reported to every
+	// aggregator (default 30)
+	HeartbeatInterval int64 `json:"heartbeat_interval_seconds"`
+	// TaskCursorPath persists the last processed block, so that tasks created
+	// while the operator was offline are backfilled on restart. Backfill is
+	// disabled when empty.
+	TaskCursorPath string `json:"task_cursor_path"`
+	// BidWindow is how long, in seconds from when the operator first sees an
+	// auction, sealed bids are committed for; committed bids are then revealed for
+	// RevealWindow seconds before the auction settles. Bidding stays open until the
+	// auction completes when BidWindow is 0.
+	BidWindow    int64 `json:"bid_window_seconds"`
+	RevealWindow int64 `json:"reveal_window_seconds"`
+	// ShutdownTimeout is how long, in seconds, shutdown waits for in-flight tasks
+	// to finish before abandoning them (default 30)
+	ShutdownTimeout int64 `json:"shutdown_timeout_seconds"`
+	// Performance bounds the operator's task processing
+	Performance PerformanceConfig `json:"performance"`
+	// DryRun runs the full task pipeline but only logs registration and task
+	// responses instead of sending them
+	DryRun bool `json:"dry_run"`
+	// AuctionConfig sets the discrepancy auctions are LVR opportunities above
+	AuctionConfig AuctionConfig `json:"auction_config"`
+	// Pools are the Uniswap v4 pools whose task pool IDs the operator can resolve
+	Pools         []PoolConfig        `json:"pools"`
+	PoolDiscovery PoolDiscoveryConfig `json:"pool_discovery"`
+	BidPolicy     BidPolicyConfig     `json:"bid_policy"`
+	// AllowedPools, when set, are the only pool IDs whose tasks are processed;
+	// tasks for other pools are skipped
+	AllowedPools []string `json:"allowed_pools"`
+	// Tokens are the symbols price feed pairs and price lookups may use in place
+	// of token addresses. Other tokens are named by their on-chain symbol().
+	Tokens []TokenConfig `json:"tokens"`
+	// VerifyAuctionIDs skips tasks whose auction id is not the DeriveAuctionID of
+	// the task's pool, creation block and auction nonce
+	VerifyAuctionIDs bool `json:"verify_auction_ids"`
+	// PrivateKeySource selects where the operator's ECDSA key is read from: the
+	// inline private_key (default), the Ethereum keystore at
+	// private_key_store_path, or the OPERATOR_PRIVATE_KEY environment variable.
+	// The keystore's password is read from private_key_password_file, or from the
+	// OPERATOR_ECDSA_KEY_PASSWORD environment variable when no file is set.
+	PrivateKeySource       string `json:"private_key_source"`
+	PrivateKeyStorePath    string `json:"private_key_store_path"`
+	PrivateKeyPasswordFile string `json:"private_key_password_file"`
+}
+
+// Sources of OperatorConfig.PrivateKeySource
+const (
+	PrivateKeySourceInline   = "inline"
+	PrivateKeySourceKeystore = "keystore"
+	PrivateKeySourceEnv      = "env"
+)
+
+// PoolConfig identifies a Uniswap v4 pool by the fields of its PoolKey
+type PoolConfig struct {
+	Currency0   string `json:"currency0"`
+	Currency1   string `json:"currency1"`
+	Fee         uint32 `json:"fee"`
+	TickSpacing int32  `json:"tick_spacing"`
+	// Hooks defaults to the lvrHook contract
+	Hooks string `json:"hooks"`
+	// Sources names the price feeds relevant to the pool. Its price and
+	// discrepancy are computed over these feeds only; all feeds are used when empty.
+	Sources []string `json:"sources"`
+	// MinDiscrepancyBps overrides the operator's auction_config.lvr_threshold for the pool
+	MinDiscrepancyBps uint64 `json:"min_discrepancy_bps"`
+	// ReserveWei is the reserve price of the pool's auctions, in wei of the
+	// settlement token. When empty and LiquidityWei is set, the reserve is the LVR
+	// the price discrepancy is estimated to cost the pool's liquidity,
+	// LiquidityWei * discrepancy bps / 10000. Auctions have no reserve otherwise.
+	ReserveWei   string `json:"reserve_wei"`
+	LiquidityWei string `json:"liquidity_wei"`
+}
+
+// AuctionConfig configures how auctions are judged
+type AuctionConfig struct {
+	// LVRThreshold is the cross-source price discrepancy, in basis points, an
+	// auction's pool must exceed to be an LVR opportunity, unless the pool sets its
+	// own min_discrepancy_bps (default 50)
+	LVRThreshold uint64 `json:"lvr_threshold"`
+}
+
+// PerformanceConfig bounds the operator's task processing
+type PerformanceConfig struct {
+	// MaxConcurrentTasks caps how many tasks are processed at once; the rest queue
+	// and are processed most urgent deadline first (default 8)
+	MaxConcurrentTasks int `json:"max_concurrent_tasks"`
+}
+
+// PoolDiscoveryConfig configures registering pools from PoolManager Initialize events
+type PoolDiscoveryConfig struct {
+	Enabled bool `json:"enabled"`
+	// PoolManager defaults to the poolManager contract address
+	PoolManager string `json:"pool_manager"`
+	FromBlock   uint64 `json:"from_block"`
+}
+
+// PriceAlertConfig configures alerts on cross-source price deviations, raised
+// independently of auction processing
+type PriceAlertConfig struct {
+	Enabled bool `json:"enabled"`
+	// MaxDeviationBps is the spread between the lowest and highest source price,
+	// in basis points of the lowest, above which an alert is raised
+	MaxDeviationBps uint64 `json:"max_deviation_bps"`
+	// Cooldown is the minimum number of seconds between alerts for a pair (default 300)
+	Cooldown int64 `json:"cooldown_seconds"`
+	// MaxPriceAge excludes source prices older than this many seconds (0 disables)
+	MaxPriceAge int64  `json:"max_price_age_seconds"`
+	WebhookURL  string `json:"webhook_url"`
+}
+
+// PriceAggregationConfig configures how the prices a pair's sources report are
+// combined into its canonical price
+type PriceAggregationConfig struct {
+	// Method is "median" (default), or "weighted_mean" to weight each source by its
+	// feed's weight
+	Method string `json:"method"`
+	// OutlierBps drops source prices deviating from the median by more than this
+	// many basis points, when a pair has at least three fresh sources (0 disables)
+	OutlierBps uint64 `json:"outlier_bps"`
+	// AnomalyFactor rejects a source price more than this factor above or below
+	// the median of the pair's last AnomalyWindow prices (default 20), and makes
+	// the pair abstain from auctions until the source reports a plausible price
+	// again (0 disables)
+	AnomalyFactor float64 `json:"anomaly_factor"`
+	AnomalyWindow int     `json:"anomaly_window"`
+}
+
+// BidPolicyConfig restricts which bidders the operator may select as an auction's
+// winner. Bids from the operator's own address are always excluded.
+type BidPolicyConfig struct {
+	// Blocklist names further addresses whose bids are excluded, such as other
+	// addresses controlled by the operator
+	Blocklist []string `json:"blocklist"`
+}
+
+// SettlementSimulationConfig configures simulating the winner's settlement with
+// eth_call before the operator signs that they won
+type SettlementSimulationConfig struct {
+	Enabled bool `json:"enabled"`
+	// TargetAddress receives the settlement call (defaults to the lvrHook contract)
+	TargetAddress string `json:"target_address"`
+	GasLimit      uint64 `json:"gas_limit"`
+	Timeout       int64  `json:"timeout_seconds"`
+}
+
+// DecisionExportConfig configures exporting structured records of operator
+// decisions to a data warehouse sink
+type DecisionExportConfig struct {
+	Enabled bool `json:"enabled"`
+	// Sink selects the destination; "file" appends newline delimited JSON to Path
+	Sink          string `json:"sink"`
+	Path          string `json:"path"`
+	BatchSize     int    `json:"batch_size"`
+	FlushInterval int64  `json:"flush_interval_seconds"`
+	// BufferSize is the number of records queued before new records are dropped
+	BufferSize int `json:"buffer_size"`
+}
+
+// StandbyConfig configures active/standby coordination between operator instances
+// sharing the same key
+type StandbyConfig struct {
+	Enabled    bool   `json:"enabled"`
+	InstanceID string `json:"instance_id"`
+	LeasePath  string `json:"lease_path"`
+	LeaseTTL   int64  `json:"lease_ttl_seconds"`
 }
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrAuctionIDMismatch is returned for an auction id that is not the derivation
+// of the pool, block and nonce it claims
+var ErrAuctionIDMismatch = errors.New("auction id does not match its pool, block and nonce")
+
+// DeriveAuctionID returns the canonical id of the auction of a pool started at
+// blockNumber, the keccak256 of the Solidity abi.encodePacked(bytes32 poolId,
+// uint256 blockNumber, uint256 nonce). nonce distinguishes auctions of the same
+// pool started in the same block.
+func DeriveAuctionID(poolID common.Hash, blockNumber, nonce uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		poolID.Bytes(),
+		math.U256Bytes(new(big.Int).SetUint64(blockNumber)),
+		math.U256Bytes(new(big.Int).SetUint64(nonce)),
+	)
+}
+
+// ValidateAuctionID checks that auctionID, a hex encoded 32 byte id, is the
+// DeriveAuctionID of the hex encoded poolID, blockNumber and nonce
+func ValidateAuctionID(auctionID, poolID string, blockNumber, nonce uint64) error {
+	id, err := decodeHash(auctionID)
+	if err != nil {
+		return fmt.Errorf("auction id: %w", err)
+	}
+	pool, err := decodeHash(poolID)
+	if err != nil {
+		return fmt.Errorf("pool id: %w", err)
+	}
+	if derived := DeriveAuctionID(pool, blockNumber, nonce); id != derived {
+		return fmt.Errorf("%w: got %s, derived %s", ErrAuctionIDMismatch, id.Hex(), derived.Hex())
+	}
+	return nil
+}
+
+// VerifyAuctionID checks that the task's auction id is derived from its pool,
+// creation block and auction nonce
+func (t *Task) VerifyAuctionID() error {
+	return ValidateAuctionID(t.AuctionID, t.PoolID, uint64(t.CreatedBlock), t.AuctionNonce)
+}
+
+// decodeHash decodes a 0x prefixed hex encoded 32 byte value
+func decodeHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%q is not a 32 byte hex value", s)
+	}
+	return common.BytesToHash(b), nil
+}
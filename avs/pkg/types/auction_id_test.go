@@ -0,0 +1,48 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testAuctionPoolID = "0x00000000000000000000000000000000000000000000000000000000000000a1"
+
+func TestDeriveAuctionIDIsStable(t *testing.T) {
+	pool := common.HexToHash(testAuctionPoolID)
+
+	// abi.encodePacked(bytes32 poolId, uint256 blockNumber, uint256 nonce)
+	packed := append(pool.Bytes(), common.LeftPadBytes([]byte{0x01, 0x00}, 32)...)
+	packed = append(packed, common.LeftPadBytes([]byte{0x02}, 32)...)
+	if got, want := DeriveAuctionID(pool, 256, 2), crypto.Keccak256Hash(packed); got != want {
+		t.Fatalf("DeriveAuctionID = %s, want %s", got.Hex(), want.Hex())
+	}
+	if DeriveAuctionID(pool, 256, 2) != DeriveAuctionID(pool, 256, 2) {
+		t.Fatal("DeriveAuctionID is not deterministic")
+	}
+	if DeriveAuctionID(pool, 256, 2) == DeriveAuctionID(pool, 256, 3) || DeriveAuctionID(pool, 256, 2) == DeriveAuctionID(pool, 257, 2) {
+		t.Fatal("expected auctions of another block or nonce to get another id")
+	}
+}
+
+func TestValidateAuctionIDRejectsMismatches(t *testing.T) {
+	auctionID := DeriveAuctionID(common.HexToHash(testAuctionPoolID), 100, 0).Hex()
+	if err := ValidateAuctionID(auctionID, testAuctionPoolID, 100, 0); err != nil {
+		t.Fatalf("ValidateAuctionID: %v", err)
+	}
+
+	for name, task := range map[string]Task{
+		"other block": {AuctionID: auctionID, PoolID: testAuctionPoolID, CreatedBlock: 101},
+		"other nonce": {AuctionID: auctionID, PoolID: testAuctionPoolID, CreatedBlock: 100, AuctionNonce: 1},
+		"other pool":  {AuctionID: auctionID, PoolID: "0x00000000000000000000000000000000000000000000000000000000000000a2", CreatedBlock: 100},
+	} {
+		if err := task.VerifyAuctionID(); !errors.Is(err, ErrAuctionIDMismatch) {
+			t.Fatalf("%s: VerifyAuctionID error = %v, want ErrAuctionIDMismatch", name, err)
+		}
+	}
+	if err := ValidateAuctionID("auction-1", testAuctionPoolID, 100, 0); err == nil || errors.Is(err, ErrAuctionIDMismatch) {
+		t.Fatalf("ValidateAuctionID of a malformed id error = %v, want a decoding error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AuctionState is the lifecycle phase of an auction
+type AuctionState string
+
+const (
+	// AuctionPending is an auction created but not yet taking bids
+	AuctionPending AuctionState = "pending"
+	// AuctionBiddingOpen takes sealed bid commitments
+	AuctionBiddingOpen AuctionState = "bidding_open"
+	// AuctionRevealPhase no longer takes commitments, only reveals of committed bids
+	AuctionRevealPhase AuctionState = "reveal_phase"
+	// AuctionSettling has a winner whose settlement is executing
+	AuctionSettling AuctionState = "settling"
+	// AuctionComplete has settled
+	AuctionComplete AuctionState = "complete"
+	// AuctionCancelled ended without settling
+	AuctionCancelled AuctionState = "cancelled"
+)
+
+// ErrIllegalTransition is returned when an auction cannot move to the requested state
+var ErrIllegalTransition = errors.New("illegal auction state transition")
+
+// auctionTransitions lists the states each state may move to. An auction can be
+// cancelled until it completes; complete and cancelled auctions are final.
+var auctionTransitions = map[AuctionState][]AuctionState{
+	AuctionPending:     {AuctionBiddingOpen, AuctionCancelled},
+	AuctionBiddingOpen: {AuctionRevealPhase, AuctionCancelled},
+	AuctionRevealPhase: {AuctionSettling, AuctionCancelled},
+	AuctionSettling:    {AuctionComplete, AuctionCancelled},
+}
+
+// Valid reports whether s is a known auction state
+func (s AuctionState) Valid() bool {
+	switch s {
+	case AuctionPending, AuctionBiddingOpen, AuctionRevealPhase, AuctionSettling, AuctionComplete, AuctionCancelled:
+		return true
+	}
+	return false
+}
+
+// Terminal reports whether s is a final state
+func (s AuctionState) Terminal() bool {
+	return s == AuctionComplete || s == AuctionCancelled
+}
+
+// CanTransition reports whether an auction in state s may move to state to
+func (s AuctionState) CanTransition(to AuctionState) bool {
+	for _, next := range auctionTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves the auction to state to, recording in StateTimes when it was
+// entered. Illegal transitions return ErrIllegalTransition and leave the
+// auction unchanged. An auction without a state is pending.
+func (a *Auction) Transition(to AuctionState, at time.Time) error {
+	from := a.State
+	if from == "" {
+		from = AuctionPending
+	}
+	if !from.CanTransition(to) {
+		return fmt.Errorf("%w: %s to %s", ErrIllegalTransition, from, to)
+	}
+
+	a.State = to
+	if a.StateTimes == nil {
+		a.StateTimes = make(map[AuctionState]time.Time)
+	}
+	a.StateTimes[to] = at
+	return nil
+}
+
+// Live reports whether bidding has opened and the auction has neither completed
+// nor been cancelled
+func (a *Auction) Live() bool {
+	return a.State == AuctionBiddingOpen || a.State == AuctionRevealPhase || a.State == AuctionSettling
+}
+
+// AcceptsBids reports whether sealed bids may be committed to the auction
+func (a *Auction) AcceptsBids() bool {
+	return a.State == AuctionBiddingOpen
+}
+
+// AcceptsReveals reports whether committed bids may be revealed. Reveals are
+// taken from the moment bidding opens until the reveal phase ends.
+func (a *Auction) AcceptsReveals() bool {
+	return a.State == AuctionBiddingOpen || a.State == AuctionRevealPhase
+}
+
+// BiddingEnd returns when the auction stops taking bid commitments, or the zero
+// time if its bidding has no window
+func (a *Auction) BiddingEnd() time.Time {
+	if a.Duration <= 0 {
+		return time.Time{}
+	}
+	return a.StartTime.Add(time.Duration(a.Duration) * time.Second)
+}
+
+// RevealEnd returns when the auction stops taking reveals, or the zero time if
+// its bidding has no window
+func (a *Auction) RevealEnd() time.Time {
+	end := a.BiddingEnd()
+	if end.IsZero() {
+		return end
+	}
+	return end.Add(time.Duration(max(a.RevealDuration, 0)) * time.Second)
+}
+
+// Advance moves the auction past the bidding and reveal windows that have closed
+// by now, into the reveal phase and then settling. Each state is stamped with
+// the moment its window closed. It reports whether the state changed.
+func (a *Auction) Advance(now time.Time) bool {
+	biddingEnd := a.BiddingEnd()
+	if biddingEnd.IsZero() {
+		return false
+	}
+
+	from := a.State
+	if a.State == AuctionBiddingOpen && !now.Before(biddingEnd) {
+		a.Transition(AuctionRevealPhase, biddingEnd)
+	}
+	if revealEnd := a.RevealEnd(); a.State == AuctionRevealPhase && !now.Before(revealEnd) {
+		a.Transition(AuctionSettling, revealEnd)
+	}
+	return a.State != from
+}
@@ -0,0 +1,109 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuctionLifecycleTransitions(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	auction := &Auction{ID: "auction-1"}
+
+	for i, state := range []AuctionState{AuctionBiddingOpen, AuctionRevealPhase, AuctionSettling, AuctionComplete} {
+		at := start.Add(time.Duration(i) * time.Minute)
+		if err := auction.Transition(state, at); err != nil {
+			t.Fatalf("Transition to %s: %v", state, err)
+		}
+		if auction.State != state || !auction.StateTimes[state].Equal(at) {
+			t.Fatalf("after transition to %s: state %s entered at %v", state, auction.State, auction.StateTimes[state])
+		}
+	}
+	if len(auction.StateTimes) != 4 || auction.Live() || !auction.State.Terminal() {
+		t.Fatalf("expected a final auction with 4 stamped states, got %+v", auction)
+	}
+}
+
+func TestAuctionRejectsIllegalTransitions(t *testing.T) {
+	tests := []struct {
+		from AuctionState
+		to   AuctionState
+		want bool
+	}{
+		{AuctionPending, AuctionBiddingOpen, true},
+		{AuctionPending, AuctionRevealPhase, false},
+		{AuctionBiddingOpen, AuctionSettling, false},
+		{AuctionBiddingOpen, AuctionBiddingOpen, false},
+		{AuctionRevealPhase, AuctionBiddingOpen, false},
+		{AuctionSettling, AuctionCancelled, true},
+		{AuctionSettling, AuctionRevealPhase, false},
+		{AuctionComplete, AuctionCancelled, false},
+		{AuctionCancelled, AuctionBiddingOpen, false},
+		{AuctionRevealPhase, "unknown", false},
+	}
+	for _, tc := range tests {
+		auction := &Auction{State: tc.from}
+		err := auction.Transition(tc.to, time.Now())
+		if tc.want {
+			if err != nil {
+				t.Fatalf("%s to %s: %v", tc.from, tc.to, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrIllegalTransition) {
+			t.Fatalf("%s to %s: error %v, want ErrIllegalTransition", tc.from, tc.to, err)
+		}
+		if auction.State != tc.from || auction.StateTimes != nil {
+			t.Fatalf("%s to %s: rejected transition changed the auction to %+v", tc.from, tc.to, auction)
+		}
+	}
+}
+
+func TestAuctionStatePhases(t *testing.T) {
+	tests := []struct {
+		state                        AuctionState
+		live, acceptsBids, canReveal bool
+	}{
+		{AuctionPending, false, false, false},
+		{AuctionBiddingOpen, true, true, true},
+		{AuctionRevealPhase, true, false, true},
+		{AuctionSettling, true, false, false},
+		{AuctionComplete, false, false, false},
+		{AuctionCancelled, false, false, false},
+	}
+	for _, tc := range tests {
+		auction := &Auction{State: tc.state}
+		if !tc.state.Valid() || auction.Live() != tc.live || auction.AcceptsBids() != tc.acceptsBids || auction.AcceptsReveals() != tc.canReveal {
+			t.Fatalf("%s: live %v, accepts bids %v, accepts reveals %v", tc.state, auction.Live(), auction.AcceptsBids(), auction.AcceptsReveals())
+		}
+	}
+	if AuctionState("open").Valid() {
+		t.Fatal("expected an unknown state to be invalid")
+	}
+}
+
+func TestAuctionAdvanceClosesWindows(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	auction := &Auction{StartTime: start, Duration: 60, RevealDuration: 30, State: AuctionBiddingOpen}
+
+	if auction.Advance(start.Add(59*time.Second)) || auction.State != AuctionBiddingOpen {
+		t.Fatalf("advanced to %s inside the bidding window", auction.State)
+	}
+	if !auction.Advance(start.Add(60*time.Second)) || auction.State != AuctionRevealPhase {
+		t.Fatalf("state %s at the bidding close, want the reveal phase", auction.State)
+	}
+
+	// Both windows may have closed since the auction was last advanced
+	late := &Auction{StartTime: start, Duration: 60, RevealDuration: 30, State: AuctionBiddingOpen}
+	if !late.Advance(start.Add(time.Hour)) || late.State != AuctionSettling {
+		t.Fatalf("state %s long after both windows, want settling", late.State)
+	}
+	if !late.StateTimes[AuctionSettling].Equal(late.RevealEnd()) || !late.StateTimes[AuctionRevealPhase].Equal(late.BiddingEnd()) {
+		t.Fatalf("states stamped at %v, want the window closes", late.StateTimes)
+	}
+
+	unbounded := &Auction{StartTime: start, State: AuctionBiddingOpen}
+	if unbounded.Advance(start.Add(time.Hour)) || !unbounded.BiddingEnd().IsZero() {
+		t.Fatal("expected an auction without a bidding window to stay open")
+	}
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Well-known keys of ContractAddresses
+const (
+	ContractRegistryCoordinator    = "registryCoordinator"
+	ContractOperatorStateRetriever = "operatorStateRetriever"
+	ContractServiceManager         = "serviceManager"
+	ContractStakeRegistry          = "stakeRegistry"
+	ContractLVRHook                = "lvrHook"
+	ContractPoolManager            = "poolManager"
+)
+
+// ContractAddresses maps contract names to their hex addresses. Names match
+// regardless of case, underscores and dashes, so "serviceManager" and
+// "service_manager" name the same contract.
+type ContractAddresses map[string]string
+
+// contractKey normalizes a contract name for matching
+func contractKey(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// Get returns the address of the named contract, or "" if it isn't configured
+func (c ContractAddresses) Get(name string) string {
+	if address, ok := c[name]; ok {
+		return address
+	}
+	key := contractKey(name)
+	for configured, address := range c {
+		if contractKey(configured) == key {
+			return address
+		}
+	}
+	return ""
+}
+
+// Address returns the address of the named contract, or the zero address if it
+// isn't configured
+func (c ContractAddresses) Address(name string) common.Address {
+	return common.HexToAddress(c.Get(name))
+}
+
+// Validate checks that every configured address is well-formed, that no contract
+// is configured under two names, and that the required contracts are set to
+// non-zero addresses. field names the addresses in errors.
+func (c ContractAddresses) Validate(field string, required ...string) error {
+	var errs []error
+
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]string, len(names))
+	for _, name := range names {
+		if first, ok := seen[contractKey(name)]; ok {
+			errs = append(errs, fmt.Errorf("%s: %q and %q name the same contract", field, first, name))
+			continue
+		}
+		seen[contractKey(name)] = name
+		if !common.IsHexAddress(c[name]) {
+			errs = append(errs, fmt.Errorf("%s.%s: invalid address %q", field, name, c[name]))
+		}
+	}
+
+	for _, name := range required {
+		if _, ok := seen[contractKey(name)]; !ok {
+			errs = append(errs, fmt.Errorf("%s.%s is required", field, name))
+		} else if address := c.Get(name); common.IsHexAddress(address) {
+			if err := validateAddress(address); err != nil {
+				errs = append(errs, fmt.Errorf("%s.%s: %w", field, name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
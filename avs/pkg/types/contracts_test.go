@@ -0,0 +1,89 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestContractAddressesGet(t *testing.T) {
+	addresses := ContractAddresses{
+		"service_manager": "0x1234567890123456789012345678901234567890",
+		"LVRHook":         "0x00000000000000000000000000000000000000aa",
+	}
+
+	if got := addresses.Get(ContractServiceManager); got != "0x1234567890123456789012345678901234567890" {
+		t.Fatalf("Get(%q) = %q, want the service_manager address", ContractServiceManager, got)
+	}
+	if got := addresses.Address(ContractLVRHook); got != common.HexToAddress("0xaa") {
+		t.Fatalf("Address(%q) = %s, want the LVRHook address", ContractLVRHook, got.Hex())
+	}
+	if got := addresses.Get(ContractStakeRegistry); got != "" {
+		t.Fatalf("Get(%q) = %q for an unconfigured contract", ContractStakeRegistry, got)
+	}
+}
+
+func TestContractAddressesValidate(t *testing.T) {
+	const address = "0x1234567890123456789012345678901234567890"
+
+	tests := []struct {
+		name      string
+		addresses ContractAddresses
+		want      []string
+	}{
+		{
+			name:      "valid",
+			addresses: ContractAddresses{ContractServiceManager: address, ContractStakeRegistry: address},
+		},
+		{
+			name:      "missing required key",
+			addresses: ContractAddresses{ContractStakeRegistry: address},
+			want:      []string{"contract_addresses.serviceManager is required"},
+		},
+		{
+			name:      "nil addresses",
+			addresses: nil,
+			want:      []string{"contract_addresses.serviceManager is required"},
+		},
+		{
+			name:      "duplicate keys",
+			addresses: ContractAddresses{ContractServiceManager: address, "service_manager": address},
+			want:      []string{`contract_addresses: "serviceManager" and "service_manager" name the same contract`},
+		},
+		{
+			name:      "invalid optional address",
+			addresses: ContractAddresses{ContractServiceManager: address, ContractPoolManager: "0xnope"},
+			want:      []string{`contract_addresses.poolManager: invalid address "0xnope"`},
+		},
+		{
+			name:      "zero required address",
+			addresses: ContractAddresses{ContractServiceManager: "0x0000000000000000000000000000000000000000"},
+			want:      []string{"contract_addresses.serviceManager: address must not be the zero address"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.addresses.Validate("contract_addresses", ContractServiceManager)
+			if len(tc.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tc.want) {
+				t.Fatalf("got %d errors, want %d:\n%v", len(lines), len(tc.want), err)
+			}
+			for i, want := range tc.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("error %d = %q, want it to contain %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}
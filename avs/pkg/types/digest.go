@@ -0,0 +1,54 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// taskResponseEncodingSize is the length of an encoded task response
+const taskResponseEncodingSize = 4 + 20 + 32 + 4
+
+// EncodeTaskResponse returns the canonical encoding of a task response, the
+// Solidity abi.encodePacked(uint32 taskIndex, address winner, uint256 winningBid,
+// uint32 totalBids). A nil winning bid encodes as zero; bids outside the uint256
+// range are rejected by TaskResponseDigest.
+func EncodeTaskResponse(taskIndex uint32, winner common.Address, winningBid *big.Int, totalBids uint32) []byte {
+	bid := new(big.Int)
+	if winningBid != nil {
+		bid.Set(winningBid)
+	}
+
+	packed := make([]byte, 0, taskResponseEncodingSize)
+	packed = binary.BigEndian.AppendUint32(packed, taskIndex)
+	packed = append(packed, winner.Bytes()...)
+	packed = append(packed, math.U256Bytes(bid)...)
+	return binary.BigEndian.AppendUint32(packed, totalBids)
+}
+
+// TaskResponseDigest returns the message operators BLS-sign for a task response,
+// the keccak256 of its EncodeTaskResponse encoding. Operators and the aggregator
+// must agree on it byte for byte.
+func TaskResponseDigest(taskIndex uint32, winner common.Address, winningBid *big.Int, totalBids uint32) ([32]byte, error) {
+	if winningBid == nil || winningBid.Sign() < 0 || winningBid.BitLen() > 256 {
+		return [32]byte{}, errors.New("invalid winning bid")
+	}
+	return crypto.Keccak256Hash(EncodeTaskResponse(taskIndex, winner, winningBid, totalBids)), nil
+}
+
+// OperatorID returns the EigenLayer id of the operator with the BLS G1 public key
+// (x, y), the keccak256 of its coordinates as BN254.hashG1Point computes it
+func OperatorID(x, y *big.Int) [32]byte {
+	return crypto.Keccak256Hash(math.PaddedBigBytes(x, 32), math.PaddedBigBytes(y, 32))
+}
+
+// TaskResponseIdempotencyKey returns the key identifying an operator's response
+// to a task across re-sends, the hex keccak256 of the operator id followed by the
+// big-endian task index
+func TaskResponseIdempotencyKey(operatorID [32]byte, taskIndex uint32) string {
+	return crypto.Keccak256Hash(operatorID[:], binary.BigEndian.AppendUint32(nil, taskIndex)).Hex()
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Validate checks that the fields the operator needs to start are present and
+// well-formed, reporting every problem at once
+func (c *OperatorConfig) Validate() error {
+	var errs []error
+
+	switch c.PrivateKeySource {
+	case "", PrivateKeySourceInline:
+		if c.PrivateKey == "" {
+			errs = append(errs, errors.New("private_key is required"))
+		} else if _, err := crypto.HexToECDSA(strings.TrimPrefix(c.PrivateKey, "0x")); err != nil {
+			errs = append(errs, fmt.Errorf("private_key: %w", err))
+		}
+	case PrivateKeySourceKeystore, PrivateKeySourceEnv:
+		if c.PrivateKeySource == PrivateKeySourceKeystore && c.PrivateKeyStorePath == "" {
+			errs = append(errs, errors.New("private_key_store_path is required when private_key_source is keystore"))
+		}
+		if c.PrivateKey != "" {
+			errs = append(errs, fmt.Errorf("private_key must be empty when private_key_source is %s", c.PrivateKeySource))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("private_key_source: unknown source %q, expected inline, keystore or env", c.PrivateKeySource))
+	}
+
+	if c.BLSKeyStorePath == "" {
+		errs = append(errs, errors.New("bls_key_store_path is required"))
+	}
+
+	if err := c.NetworkConfig.ContractAddresses.Validate("network_config.contract_addresses", ContractServiceManager); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.NetworkConfig.RPCURL == "" {
+		errs = append(errs, errors.New("network_config.rpc_url is required"))
+	} else if err := validateURL(c.NetworkConfig.RPCURL, "http", "https", "ws", "wss"); err != nil {
+		errs = append(errs, fmt.Errorf("network_config.rpc_url: %w", err))
+	}
+	if c.NetworkConfig.WSURL != "" {
+		if err := validateURL(c.NetworkConfig.WSURL, "ws", "wss"); err != nil {
+			errs = append(errs, fmt.Errorf("network_config.ws_url: %w", err))
+		}
+	}
+
+	if c.AggregatorURL != "" {
+		if err := validateURL(c.AggregatorURL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("aggregator_url: %w", err))
+		}
+	}
+	for i, aggregatorURL := range c.AggregatorURLs {
+		if err := validateURL(aggregatorURL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("aggregator_urls[%d]: %w", i, err))
+		}
+	}
+
+	switch c.NetworkConfig.FeeStrategy {
+	case "", "auto", "legacy", "dynamic":
+	default:
+		errs = append(errs, fmt.Errorf("network_config.fee_strategy: unknown strategy %q, expected auto, legacy or dynamic", c.NetworkConfig.FeeStrategy))
+	}
+
+	for i, address := range c.BidPolicy.Blocklist {
+		if !common.IsHexAddress(address) {
+			errs = append(errs, fmt.Errorf("bid_policy.blocklist[%d]: invalid address %q", i, address))
+		}
+	}
+
+	for i, poolID := range c.AllowedPools {
+		if decoded, err := hexutil.Decode(poolID); err != nil || len(decoded) != common.HashLength {
+			errs = append(errs, fmt.Errorf("allowed_pools[%d]: invalid pool id %q", i, poolID))
+		}
+	}
+
+	for i, token := range c.Tokens {
+		if token.Symbol == "" {
+			errs = append(errs, fmt.Errorf("tokens[%d]: symbol is required", i))
+		}
+		if err := validateAddress(token.Address); err != nil {
+			errs = append(errs, fmt.Errorf("tokens[%d] (%s): %w", i, token.Symbol, err))
+		}
+	}
+
+	for i, feed := range c.PriceFeeds {
+		if feed.UpdateFreq <= 0 {
+			errs = append(errs, fmt.Errorf("price_feeds[%d] (%s): update_frequency_seconds must be positive, got %d", i, feed.Name, feed.UpdateFreq))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateAddress checks that address is a well-formed, non-zero hex address
+func validateAddress(address string) error {
+	if address == "" {
+		return errors.New("address is required")
+	}
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid address %q", address)
+	}
+	if common.HexToAddress(address) == (common.Address{}) {
+		return errors.New("address must not be the zero address")
+	}
+	return nil
+}
+
+// validateURL checks that rawURL parses and uses one of schemes
+func validateURL(rawURL string, schemes ...string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported url scheme %q, expected one of %v", parsed.Scheme, schemes)
+}
@@ -0,0 +1,135 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+// validOperatorConfig returns a config that passes validation
+func validOperatorConfig() OperatorConfig {
+	return OperatorConfig{
+		PrivateKey:      "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+		BLSKeyStorePath: "keys/operator.bls.key.json",
+		NetworkConfig: NetworkConfig{
+			RPCURL:            "http://localhost:8545",
+			ContractAddresses: ContractAddresses{ContractServiceManager: "0x1234567890123456789012345678901234567890"},
+		},
+		PriceFeeds: []PriceFeedConfig{{Name: "binance", UpdateFreq: 5}},
+	}
+}
+
+func TestOperatorConfigValidate(t *testing.T) {
+	valid := validOperatorConfig()
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate on a valid config: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*OperatorConfig)
+		want   []string
+	}{
+		{
+			name:   "missing private key",
+			mutate: func(c *OperatorConfig) { c.PrivateKey = "" },
+			want:   []string{"private_key is required"},
+		},
+		{
+			name:   "malformed private key",
+			mutate: func(c *OperatorConfig) { c.PrivateKey = "0xnot-a-key" },
+			want:   []string{"private_key: invalid hex character"},
+		},
+		{
+			name: "keystore source without a keystore",
+			mutate: func(c *OperatorConfig) {
+				c.PrivateKeySource = PrivateKeySourceKeystore
+			},
+			want: []string{
+				"private_key_store_path is required when private_key_source is keystore",
+				"private_key must be empty when private_key_source is keystore",
+			},
+		},
+		{
+			name:   "unknown private key source",
+			mutate: func(c *OperatorConfig) { c.PrivateKeySource = "vault" },
+			want:   []string{`private_key_source: unknown source "vault"`},
+		},
+		{
+			name:   "missing bls keystore",
+			mutate: func(c *OperatorConfig) { c.BLSKeyStorePath = "" },
+			want:   []string{"bls_key_store_path is required"},
+		},
+		{
+			name: "zero service manager",
+			mutate: func(c *OperatorConfig) {
+				c.NetworkConfig.ContractAddresses[ContractServiceManager] = "0x0000000000000000000000000000000000000000"
+			},
+			want: []string{"network_config.contract_addresses.serviceManager: address must not be the zero address"},
+		},
+		{
+			name:   "bad websocket scheme",
+			mutate: func(c *OperatorConfig) { c.NetworkConfig.WSURL = "http://localhost:8546" },
+			want:   []string{`network_config.ws_url: unsupported url scheme "http"`},
+		},
+		{
+			name:   "bad failover aggregator scheme",
+			mutate: func(c *OperatorConfig) { c.AggregatorURLs = []string{"http://localhost:9090", "ws://localhost:9091"} },
+			want:   []string{`aggregator_urls[1]: unsupported url scheme "ws"`},
+		},
+		{
+			name:   "short allowed pool id",
+			mutate: func(c *OperatorConfig) { c.AllowedPools = []string{"0x1234"} },
+			want:   []string{`allowed_pools[0]: invalid pool id "0x1234"`},
+		},
+		{
+			name:   "malformed blocklist address",
+			mutate: func(c *OperatorConfig) { c.BidPolicy.Blocklist = []string{"0xnope"} },
+			want:   []string{`bid_policy.blocklist[0]: invalid address "0xnope"`},
+		},
+		{
+			name:   "token without symbol",
+			mutate: func(c *OperatorConfig) { c.Tokens = []TokenConfig{{Address: "0xnope"}} },
+			want:   []string{"tokens[0]: symbol is required", `tokens[0] (): invalid address "0xnope"`},
+		},
+		{
+			name:   "unknown fee strategy",
+			mutate: func(c *OperatorConfig) { c.NetworkConfig.FeeStrategy = "eip4844" },
+			want:   []string{`network_config.fee_strategy: unknown strategy "eip4844"`},
+		},
+		{
+			name: "every problem at once",
+			mutate: func(c *OperatorConfig) {
+				c.NetworkConfig.ContractAddresses[ContractServiceManager] = "not-an-address"
+				c.NetworkConfig.RPCURL = ""
+				c.PriceFeeds = append(c.PriceFeeds, PriceFeedConfig{Name: "kraken"}, PriceFeedConfig{Name: "coinbase", UpdateFreq: -1})
+			},
+			want: []string{
+				`network_config.contract_addresses.serviceManager: invalid address "not-an-address"`,
+				"network_config.rpc_url is required",
+				"price_feeds[1] (kraken): update_frequency_seconds must be positive, got 0",
+				"price_feeds[2] (coinbase): update_frequency_seconds must be positive, got -1",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := validOperatorConfig()
+			tc.mutate(&config)
+
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tc.want) {
+				t.Fatalf("got %d problems, want %d: %v", len(lines), len(tc.want), err)
+			}
+			for i, want := range tc.want {
+				if !strings.HasPrefix(lines[i], want) {
+					t.Fatalf("problem %d = %q, want prefix %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}